@@ -77,4 +77,7 @@ func main() {
 	if err := a.Echo.Shutdown(ctx); err != nil {
 		a.Echo.Logger.Fatal(err)
 	}
+	if a.LocationFlusher != nil {
+		a.LocationFlusher.Flush(ctx)
+	}
 }
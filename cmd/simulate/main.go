@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/cdxy1/go-courier-service/internal/app/dispatch"
+	"github.com/cdxy1/go-courier-service/internal/geo"
+	"github.com/cdxy1/go-courier-service/internal/model"
+	"github.com/cdxy1/go-courier-service/internal/simulation"
+	ucd "github.com/cdxy1/go-courier-service/internal/usecase/delivery"
+	"github.com/cdxy1/go-courier-service/pkg/config"
+)
+
+var defaultStrategies = []model.DispatchStrategy{
+	model.DispatchLeastRecent,
+	model.DispatchLeastToday,
+	model.DispatchRoundRobin,
+	model.DispatchLongestIdle,
+	model.DispatchRandomWeighted,
+	model.DispatchNearest,
+}
+
+func main() {
+	input := pflag.String("input", "", "scenario file in JSON lines, generated when empty")
+	write := pflag.String("write", "", "save the generated scenario to this file")
+	couriers := pflag.Int("couriers", 25, "couriers in a generated scenario")
+	orders := pflag.Int("orders", 200, "orders in a generated scenario")
+	radius := pflag.Float64("radius", 3000, "radius in meters of the area of a generated scenario")
+	period := pflag.Duration("period", 8*time.Hour, "length of a generated scenario")
+	seed := pflag.Uint64("seed", 1, "seed of a generated scenario")
+	strategies := pflag.String("strategies", joinStrategies(defaultStrategies), "comma separated dispatch strategies to compare")
+	handling := pflag.Duration("handling", 5*time.Minute, "time spent at the pickup and the dropoff of a delivery")
+	defaultTrip := pflag.Duration("default-trip", 20*time.Minute, "length of a delivery without pickup or dropoff point")
+	pflag.Parse()
+
+	events, err := loadScenario(*input, *write, simulation.GenerateParams{
+		Start:    time.Now().UTC().Truncate(time.Hour),
+		Duration: *period,
+		Couriers: *couriers,
+		Orders:   *orders,
+		Center:   geo.Point{Lat: 55.7558, Lon: 37.6173},
+		Radius:   *radius,
+		Seed:     *seed,
+	})
+	if err != nil {
+		log.Fatalln("scenario not loaded:", err)
+	}
+
+	cfg := config.GetDeliveryEnv()
+	timeFactory, err := dispatch.NewTimeFactory(cfg)
+	if err != nil {
+		log.Fatalln("deadline policy not loaded:", err)
+	}
+	opts, err := dispatch.NewOptions(cfg)
+	if err != nil {
+		log.Fatalln("delivery settings not loaded:", err)
+	}
+	settings := simulation.Settings{
+		Speeds:      dispatch.TransportSpeeds(cfg),
+		Handling:    *handling,
+		DefaultTrip: *defaultTrip,
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "strategy\torders\tdelivered\tunassigned\tutilization\tmean wait\tmax wait\tdeadline misses\tpromise misses\tmin/max per courier\tload cv\t")
+	for _, name := range strings.Split(*strategies, ",") {
+		strategy := model.DispatchStrategy(strings.TrimSpace(name))
+		if !strategy.IsValid() {
+			log.Fatalf("unknown dispatch strategy %q", strategy)
+		}
+		// A selector keeps state between assignments, every run gets its own.
+		runOpts := append(opts[:len(opts):len(opts)], ucd.WithCourierSelector(dispatch.NewCourierSelector(cfg, strategy)))
+		r, err := simulation.Run(context.Background(), events, timeFactory, settings, runOpts...)
+		if err != nil {
+			log.Fatalf("simulation of %s failed: %v", strategy, err)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%.1f%%\t%s\t%s\t%d\t%d\t%d/%d\t%.2f\t\n",
+			strategy, r.Orders, r.Delivered, r.Unassigned, 100*r.Utilization,
+			r.MeanWait.Round(time.Second), r.MaxWait.Round(time.Second),
+			r.DeadlineMisses, r.PromiseMisses, r.MinDeliveries, r.MaxDeliveries, r.LoadCV)
+	}
+	if err := w.Flush(); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// loadScenario reads the scenario file, or generates a scenario and saves it
+// when a file to write is given.
+func loadScenario(input, write string, params simulation.GenerateParams) ([]simulation.Event, error) {
+	if input != "" {
+		f, err := os.Open(input)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return simulation.ReadScenario(f)
+	}
+
+	events := simulation.Generate(params)
+	if write == "" {
+		return events, nil
+	}
+	f, err := os.Create(write)
+	if err != nil {
+		return nil, err
+	}
+	if err := simulation.WriteScenario(f, events); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return events, f.Close()
+}
+
+func joinStrategies(strategies []model.DispatchStrategy) string {
+	names := make([]string, len(strategies))
+	for i, s := range strategies {
+		names[i] = string(s)
+	}
+	return strings.Join(names, ",")
+}
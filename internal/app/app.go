@@ -5,21 +5,28 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/cdxy1/go-courier-service/internal/app/dispatch"
 	"github.com/cdxy1/go-courier-service/internal/gateway/order"
 	"github.com/cdxy1/go-courier-service/internal/gateway/orderhttp"
 	hc "github.com/cdxy1/go-courier-service/internal/handler/courier"
 	hd "github.com/cdxy1/go-courier-service/internal/handler/delivery"
+	hs "github.com/cdxy1/go-courier-service/internal/handler/shift"
+	hz "github.com/cdxy1/go-courier-service/internal/handler/zone"
 	ipostgres "github.com/cdxy1/go-courier-service/internal/infra/postgres"
 	"github.com/cdxy1/go-courier-service/internal/model"
 	"github.com/cdxy1/go-courier-service/internal/observability"
 	"github.com/cdxy1/go-courier-service/internal/ratelimit"
 	rc "github.com/cdxy1/go-courier-service/internal/repository/courier"
 	rd "github.com/cdxy1/go-courier-service/internal/repository/delivery"
+	rs "github.com/cdxy1/go-courier-service/internal/repository/shift"
+	rz "github.com/cdxy1/go-courier-service/internal/repository/zone"
 	"github.com/cdxy1/go-courier-service/internal/routes"
 	"github.com/cdxy1/go-courier-service/internal/transport/kafka"
 	ucc "github.com/cdxy1/go-courier-service/internal/usecase/courier"
 	ucd "github.com/cdxy1/go-courier-service/internal/usecase/delivery"
 	"github.com/cdxy1/go-courier-service/internal/usecase/order_event"
+	ucs "github.com/cdxy1/go-courier-service/internal/usecase/shift"
+	ucz "github.com/cdxy1/go-courier-service/internal/usecase/zone"
 	"github.com/cdxy1/go-courier-service/internal/worker"
 	"github.com/cdxy1/go-courier-service/pkg/config"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -31,6 +38,10 @@ type App struct {
 	Echo             *echo.Echo
 	Worker           *worker.OrderAssigner
 	DeliveryMonitor  *worker.DeliveryMonitor
+	PendingAssigner  *worker.PendingAssigner
+	LocationFlusher  *worker.LocationFlusher
+	CourierScorer    *worker.CourierScorer
+	ShiftScheduler   *worker.ShiftScheduler
 	OrderGateway     *order.OrderGateway
 	OrderHTTPGateway *orderhttp.OrderGateway
 	EventConsumer    *kafka.Consumer
@@ -42,34 +53,60 @@ func NewApp(ctx context.Context, conn *pgxpool.Pool, cfg *config.Сonfig) *App {
 	e.Use(observability.MetricsAndLogging())
 	e.GET("/metrics", echo.WrapHandler(promhttp.Handler()))
 
+	tm := ipostgres.NewTxManager(conn)
 	crepo := rc.NewCourierRepository(conn)
-	cuc := ucc.NewCourierUsecase(crepo)
+	cuc := ucc.NewCourierUsecase(crepo, tm)
 	ch := hc.NewCourierHandler(cuc)
+	luc := ucc.NewLocationUsecase(
+		crepo, model.UTCNow,
+		ucc.WithLocationBatchSize(cfg.Location.BatchSize),
+		ucc.WithTrailRetention(cfg.Location.TrailRetention),
+	)
+	lh := hc.NewLocationHandler(luc)
+	locationFlusher := worker.NewLocationFlusher(luc, cfg.Location.FlushInterval, cfg.Location.TrimInterval, nil)
+	suc := ucc.NewScoreUsecase(crepo, model.UTCNow, ucc.WithScoreWindow(cfg.Delivery.ScoreWindow))
+	courierScorer := worker.NewCourierScorer(suc, cfg.Delivery.ScoreInterval, nil)
+
+	zrepo := rz.NewZoneRepository(conn)
+	zuc := ucz.NewZoneUsecase(zrepo)
+	zh := hz.NewZoneHandler(zuc)
+
+	srepo := rs.NewShiftRepository(conn)
+	shuc := ucs.NewShiftUsecase(srepo, tm, model.UTCNow, ucs.WithHorizon(cfg.Delivery.ShiftHorizon))
+	sh := hs.NewShiftHandler(shuc)
+	shiftScheduler := worker.NewShiftScheduler(shuc, cfg.Delivery.ShiftInterval, nil)
 
-	tm := ipostgres.NewTxManager(conn)
 	drepo := rd.NewDeliveryRepository(conn)
-	timeFactory := model.NewDeliveryTimeFactory(
-		cfg.Delivery.OnFootDuration,
-		cfg.Delivery.ScooterDuration,
-		cfg.Delivery.CarDuration,
+	timeFactory, err := dispatch.NewTimeFactory(cfg.Delivery)
+	if err != nil {
+		panic(fmt.Sprintf("failed to load deadline policy: %v", err))
+	}
+	deliveryOpts, err := dispatch.NewOptions(cfg.Delivery)
+	if err != nil {
+		panic(fmt.Sprintf("failed to load delivery settings: %v", err))
+	}
+	deliveryOpts = append(deliveryOpts,
+		ucd.WithZones(zrepo, model.ZoneFallback(cfg.Delivery.ZoneFallback)),
+		ucd.WithShifts(srepo),
 	)
-	duc := ucd.NewDeliveryUsecase(crepo, drepo, tm, timeFactory, model.UTCNow)
+	duc := ucd.NewDeliveryUsecase(crepo, drepo, tm, timeFactory, model.UTCNow, deliveryOpts...)
 	cd := hd.NewDeliveryHandler(duc)
 	deliveryMonitor := worker.NewDeliveryMonitor(duc, cfg.Delivery.MonitorInterval, nil)
+	pendingAssigner := worker.NewPendingAssigner(duc, cfg.Delivery.PendingInterval, cfg.Delivery.PendingBatch, nil)
 
 	apiLimiter := ratelimit.NewTokenBucketLimiter(5, 5, time.Minute)
 	apiRateLimitMiddleware := ratelimit.Middleware(apiLimiter, nil)
-	r := routes.NewRoutes(ch, cd, apiRateLimitMiddleware)
+	r := routes.NewRoutes(ch, cd, lh, zh, sh, apiRateLimitMiddleware)
 	r.Register(e)
 
 	orderGateway, err := order.NewOrderGateway(cfg.OrderServiceGRPC)
 	if err != nil {
 		panic(fmt.Sprintf("failed to create order gateway: %v", err))
 	}
-	orderAssigner := worker.NewOrderAssigner(orderGateway, duc)
+	orderAssigner := worker.NewOrderAssigner(orderGateway, duc, model.AssignMode(cfg.Delivery.AssignMode))
 
 	orderHTTPGateway := orderhttp.NewOrderGateway(cfg.OrderServiceHTTP)
-	eventFactory := order_event.NewHandlerFactory(duc)
+	eventFactory := order_event.NewHandlerFactory(duc, orderGateway)
 	eventProcessor := order_event.NewProcessor(eventFactory, orderHTTPGateway)
 
 	var eventConsumer *kafka.Consumer
@@ -85,6 +122,10 @@ func NewApp(ctx context.Context, conn *pgxpool.Pool, cfg *config.Сonfig) *App {
 		Echo:             e,
 		Worker:           orderAssigner,
 		DeliveryMonitor:  deliveryMonitor,
+		PendingAssigner:  pendingAssigner,
+		LocationFlusher:  locationFlusher,
+		CourierScorer:    courierScorer,
+		ShiftScheduler:   shiftScheduler,
 		OrderGateway:     orderGateway,
 		OrderHTTPGateway: orderHTTPGateway,
 		EventConsumer:    eventConsumer,
@@ -99,6 +140,18 @@ func (a *App) Start(ctx context.Context) {
 	if a.DeliveryMonitor != nil {
 		go a.DeliveryMonitor.Start(ctx)
 	}
+	if a.PendingAssigner != nil {
+		go a.PendingAssigner.Start(ctx)
+	}
+	if a.LocationFlusher != nil {
+		go a.LocationFlusher.Start(ctx)
+	}
+	if a.CourierScorer != nil {
+		go a.CourierScorer.Start(ctx)
+	}
+	if a.ShiftScheduler != nil {
+		go a.ShiftScheduler.Start(ctx)
+	}
 	if a.EventConsumer != nil {
 		go func() {
 			if err := a.EventConsumer.Start(ctx); err != nil {
@@ -0,0 +1,89 @@
+// Package dispatch builds the delivery usecase settings from the delivery
+// configuration, shared by the service and the simulator.
+package dispatch
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+	ucd "github.com/cdxy1/go-courier-service/internal/usecase/delivery"
+	"github.com/cdxy1/go-courier-service/pkg/config"
+)
+
+// NewTimeFactory builds the deadline policy set by the delivery settings.
+func NewTimeFactory(cfg *config.DeliveryConfig) (*model.DeliveryTimeFactory, error) {
+	var deadlineOpts []model.DeliveryTimeOption
+	if model.DeadlineMode(cfg.DeadlinePolicy) == model.DeadlineDistance {
+		deadlineOpts = append(deadlineOpts, model.WithDistanceDeadlines(TransportSpeeds(cfg), cfg.HandlingOverhead))
+	}
+	if cfg.CalendarFile != "" {
+		data, err := os.ReadFile(cfg.CalendarFile)
+		if err != nil {
+			return nil, fmt.Errorf("read deadline calendar: %w", err)
+		}
+		calendar, err := model.ParseDeadlineCalendar(data)
+		if err != nil {
+			return nil, fmt.Errorf("load deadline calendar: %w", err)
+		}
+		deadlineOpts = append(deadlineOpts, model.WithDeadlineCalendar(calendar))
+	}
+	return model.NewDeliveryTimeFactory(
+		cfg.OnFootDuration,
+		cfg.ScooterDuration,
+		cfg.CarDuration,
+		deadlineOpts...,
+	), nil
+}
+
+// NewOptions returns the delivery usecase options set by the delivery
+// settings, apart from zones, which need a repository. The courier selector
+// follows the configured strategy.
+func NewOptions(cfg *config.DeliveryConfig) ([]ucd.Option, error) {
+	eligibility, err := model.ParseEligibilityRules(cfg.EligibilityRules)
+	if err != nil {
+		return nil, fmt.Errorf("load delivery eligibility rules: %w", err)
+	}
+	shiftStarts, err := model.ParseShiftStarts(cfg.ShiftStarts)
+	if err != nil {
+		return nil, fmt.Errorf("load shift starts: %w", err)
+	}
+	return []ucd.Option{
+		ucd.WithExpiredPolicy(model.ExpiredPolicy(cfg.ExpiredPolicy)),
+		ucd.WithCourierCapacity(model.NewCourierCapacity(
+			cfg.OnFootCapacity,
+			cfg.ScooterCapacity,
+			cfg.CarCapacity,
+		)),
+		ucd.WithEligibilityRules(eligibility),
+		ucd.WithBatchCost(TransportSpeeds(cfg), cfg.LoadPenalty),
+		ucd.WithFairnessWindow(model.NewFairnessWindow(
+			model.FairnessMode(cfg.FairnessWindow),
+			cfg.FairnessPeriod,
+			cfg.FairnessHalfLife,
+			shiftStarts,
+		)),
+		ucd.WithCourierSelector(NewCourierSelector(cfg, model.DispatchStrategy(cfg.DispatchStrategy))),
+	}, nil
+}
+
+// NewCourierSelector returns the built-in selector for the strategy, tuned by
+// the delivery settings. A warning is logged for deprecated strategy names.
+func NewCourierSelector(cfg *config.DeliveryConfig, strategy model.DispatchStrategy) ucd.CourierSelector {
+	if strategy == model.DispatchLeastLifetime {
+		log.Printf("[WARN] dispatch strategy %q is deprecated and ranks like %q, use the new name",
+			strategy, model.DispatchLeastRecent)
+	}
+	return ucd.NewCourierSelector(
+		strategy,
+		ucd.WithMaxRadius(float64(cfg.NearestRadius)),
+		ucd.WithTransportSpeeds(TransportSpeeds(cfg)),
+		ucd.WithScoreWeight(cfg.ScoreWeight),
+	)
+}
+
+// TransportSpeeds returns the configured average speeds.
+func TransportSpeeds(cfg *config.DeliveryConfig) model.TransportSpeeds {
+	return model.NewTransportSpeeds(cfg.OnFootSpeed, cfg.ScooterSpeed, cfg.CarSpeed)
+}
@@ -50,42 +50,62 @@ func (g *OrderGateway) GetOrders(ctx context.Context, from time.Time) ([]*model.
 
 	orders := make([]*model.Order, 0, len(resp.Orders))
 	for _, pbOrder := range resp.Orders {
-		order := &model.Order{
-			ID:                pbOrder.Id,
-			UserID:            pbOrder.UserId,
-			OrderNumber:       pbOrder.OrderNumber,
-			FIO:               pbOrder.Fio,
-			RestaurantID:      pbOrder.RestaurantId,
-			TotalPrice:        pbOrder.TotalPrice,
-			Status:            pbOrder.Status,
-			CreatedAt:         pbOrder.CreatedAt.AsTime(),
-			UpdatedAt:         pbOrder.UpdatedAt.AsTime(),
-			EstimatedDelivery: pbOrder.EstimatedDelivery.AsTime(),
-		}
+		orders = append(orders, orderFromProto(pbOrder))
+	}
 
-		items := make([]model.Item, 0, len(pbOrder.Items))
-		for _, pbItem := range pbOrder.Items {
-			items = append(items, model.Item{
-				FoodID:   "",
-				Name:     pbItem.Name,
-				Quantity: int(pbItem.Quantity),
-				Price:    int(pbItem.Price),
-			})
-		}
-		order.Items = items
+	return orders, nil
+}
 
-		if pbOrder.Address != nil {
-			order.Address = model.DeliveryAddress{
-				Street:    pbOrder.Address.Street,
-				House:     pbOrder.Address.House,
-				Apartment: pbOrder.Address.Apartment,
-				Floor:     pbOrder.Address.Floor,
-				Comment:   pbOrder.Address.Comment,
-			}
-		}
+// GetOrder fetches a single order with its contents.
+func (g *OrderGateway) GetOrder(ctx context.Context, orderID string) (*model.Order, error) {
+	resp, err := g.client.GetOrderById(ctx, &proto.GetOrderByIdRequest{Id: orderID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	if resp.Order == nil {
+		return nil, fmt.Errorf("failed to get order: order %s not returned", orderID)
+	}
+	return orderFromProto(resp.Order), nil
+}
 
-		orders = append(orders, order)
+// orderFromProto maps an order of the order service. The service does not
+// report the weight of orders, so WeightGrams stays zero.
+func orderFromProto(pbOrder *proto.Order) *model.Order {
+	order := &model.Order{
+		ID:           pbOrder.Id,
+		UserID:       pbOrder.UserId,
+		OrderNumber:  pbOrder.OrderNumber,
+		FIO:          pbOrder.Fio,
+		RestaurantID: pbOrder.RestaurantId,
+		TotalPrice:   pbOrder.TotalPrice,
+		Status:       pbOrder.Status,
+		CreatedAt:    pbOrder.CreatedAt.AsTime(),
+		UpdatedAt:    pbOrder.UpdatedAt.AsTime(),
+	}
+	// A missing promise must stay zero rather than become the Unix epoch.
+	if pbOrder.EstimatedDelivery != nil {
+		order.EstimatedDelivery = pbOrder.EstimatedDelivery.AsTime()
 	}
 
-	return orders, nil
+	items := make([]model.Item, 0, len(pbOrder.Items))
+	for _, pbItem := range pbOrder.Items {
+		items = append(items, model.Item{
+			FoodID:   "",
+			Name:     pbItem.Name,
+			Quantity: int(pbItem.Quantity),
+			Price:    int(pbItem.Price),
+		})
+	}
+	order.Items = items
+
+	if pbOrder.Address != nil {
+		order.Address = model.DeliveryAddress{
+			Street:    pbOrder.Address.Street,
+			House:     pbOrder.Address.House,
+			Apartment: pbOrder.Address.Apartment,
+			Floor:     pbOrder.Address.Floor,
+			Comment:   pbOrder.Address.Comment,
+		}
+	}
+	return order
 }
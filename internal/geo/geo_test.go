@@ -0,0 +1,143 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		a, b     Point
+		expected float64
+	}{
+		{name: "same point", a: Point{Lat: 55.75, Lon: 37.61}, b: Point{Lat: 55.75, Lon: 37.61}, expected: 0},
+		{name: "paris to london", a: Point{Lat: 48.8566, Lon: 2.3522}, b: Point{Lat: 51.5074, Lon: -0.1278}, expected: 343_550},
+		{name: "one degree of latitude", a: Point{Lat: 0, Lon: 0}, b: Point{Lat: 1, Lon: 0}, expected: 111_195},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := Distance(tt.a, tt.b); math.Abs(got-tt.expected) > 500 {
+				t.Fatalf("expected ~%.0f m, got %.0f m", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	t.Parallel()
+
+	if got := Encode(Point{Lat: 57.64911, Lon: 10.40744}, 11); got != "u4pruydqqvj" {
+		t.Fatalf("unexpected geohash: %s", got)
+	}
+	if got := Encode(Point{Lat: 57.64911, Lon: 10.40744}, CellPrecision); got != "u4pruy" {
+		t.Fatalf("unexpected cell: %s", got)
+	}
+
+	center, ok := Decode("u4pruydqqvj")
+	if !ok || Distance(center, Point{Lat: 57.64911, Lon: 10.40744}) > 1 {
+		t.Fatalf("unexpected decoded point: %+v", center)
+	}
+	if _, ok := Decode("u4pa"); ok {
+		t.Fatalf("invalid geohash must not decode")
+	}
+}
+
+func TestCover(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		center Point
+		radius float64
+	}{
+		{name: "city", center: Point{Lat: 55.7558, Lon: 37.6173}, radius: 3000},
+		{name: "equator", center: Point{Lat: 0.001, Lon: -0.001}, radius: 1500},
+		{name: "antimeridian", center: Point{Lat: -16.5, Lon: 179.999}, radius: 2000},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cells := map[string]bool{}
+			for _, cell := range Cover(tt.center, tt.radius, CellPrecision) {
+				cells[cell] = true
+			}
+
+			for bearing := 0.0; bearing < 360; bearing += 15 {
+				for _, share := range []float64{0.3, 0.7, 0.99} {
+					p := destination(tt.center, tt.radius*share, bearing)
+					if !cells[Encode(p, CellPrecision)] {
+						t.Fatalf("point %+v at %.0f m is not covered", p, Distance(tt.center, p))
+					}
+				}
+			}
+			if far := destination(tt.center, tt.radius*4, 90); cells[Encode(far, CellPrecision)] {
+				t.Fatalf("point far outside the radius must not be covered")
+			}
+		})
+	}
+}
+
+// destination moves from p by distance meters along the bearing in degrees.
+func destination(p Point, distance, bearing float64) Point {
+	lat1 := p.Lat * math.Pi / 180
+	lon1 := p.Lon * math.Pi / 180
+	theta := bearing * math.Pi / 180
+	delta := distance / earthRadiusMeters
+
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(delta) + math.Cos(lat1)*math.Sin(delta)*math.Cos(theta))
+	lon2 := lon1 + math.Atan2(math.Sin(theta)*math.Sin(delta)*math.Cos(lat1), math.Cos(delta)-math.Sin(lat1)*math.Sin(lat2))
+	return Point{Lat: lat2 * 180 / math.Pi, Lon: normalizeLon(lon2 * 180 / math.Pi)}
+}
+
+func TestPolygon(t *testing.T) {
+	t.Parallel()
+
+	// A concave "L" shaped district.
+	district := Polygon{
+		{Lat: 0, Lon: 0},
+		{Lat: 0, Lon: 2},
+		{Lat: 1, Lon: 2},
+		{Lat: 1, Lon: 1},
+		{Lat: 2, Lon: 1},
+		{Lat: 2, Lon: 0},
+	}
+	if !district.Valid() {
+		t.Fatalf("district must be valid")
+	}
+
+	tests := []struct {
+		name     string
+		point    Point
+		expected bool
+	}{
+		{name: "inside the base", point: Point{Lat: 0.5, Lon: 1.5}, expected: true},
+		{name: "inside the stem", point: Point{Lat: 1.5, Lon: 0.5}, expected: true},
+		{name: "in the notch", point: Point{Lat: 1.5, Lon: 1.5}, expected: false},
+		{name: "outside", point: Point{Lat: -1, Lon: 1}, expected: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := district.Contains(tt.point); got != tt.expected {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	if (Polygon{{Lat: 0, Lon: 0}, {Lat: 1, Lon: 1}, {Lat: 0, Lon: 0}}).Valid() {
+		t.Fatalf("polygon with two distinct points must be invalid")
+	}
+	if (Polygon{{Lat: 0, Lon: 0}, {Lat: 1, Lon: 1}, {Lat: 91, Lon: 0}}).Valid() {
+		t.Fatalf("polygon with an invalid point must be invalid")
+	}
+}
@@ -0,0 +1,147 @@
+package geo
+
+import (
+	"math"
+	"strings"
+)
+
+// CellPrecision is the geohash length couriers are indexed by. A cell is about
+// 1.2 km wide and 0.6 km high at the equator.
+const CellPrecision = 6
+
+const base32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// Encode returns the geohash of p with the given number of characters.
+func Encode(p Point, precision int) string {
+	latBits, lonBits := cellBits(precision)
+	return encodeIndex(cellIndex(p.Lat, -90, 180, latBits), cellIndex(p.Lon, -180, 360, lonBits), precision)
+}
+
+// Cover returns the geohash cells of the given precision that intersect the
+// bounding box of the circle around center. Querying couriers by these cells and
+// then checking the exact Distance finds everyone within radius meters.
+func Cover(center Point, radius float64, precision int) []string {
+	latBits, lonBits := cellBits(precision)
+	latCells, lonCells := 1<<latBits, 1<<lonBits
+
+	dLat := radius / earthRadiusMeters * 180 / math.Pi
+	minLat := math.Max(center.Lat-dLat, -90)
+	maxLat := math.Min(center.Lat+dLat, 90)
+
+	// Longitude degrees shrink towards the poles, use the widest latitude of the box.
+	cosLat := math.Cos(math.Max(math.Abs(minLat), math.Abs(maxLat)) * math.Pi / 180)
+	dLon := 180.0
+	if cosLat > 0 {
+		dLon = math.Min(dLat/cosLat, 180)
+	}
+
+	minLatIdx := cellIndex(minLat, -90, 180, latBits)
+	maxLatIdx := cellIndex(maxLat, -90, 180, latBits)
+	minLonIdx := cellIndex(center.Lon-dLon, -180, 360, lonBits)
+	lonSpan := cellIndex(center.Lon+dLon, -180, 360, lonBits) - minLonIdx
+	if center.Lon-dLon < -180 || center.Lon+dLon > 180 {
+		// The box crosses the antimeridian, count the cells the long way round.
+		minLonIdx = cellIndex(normalizeLon(center.Lon-dLon), -180, 360, lonBits)
+		lonSpan = (cellIndex(normalizeLon(center.Lon+dLon), -180, 360, lonBits) - minLonIdx + lonCells) % lonCells
+	}
+	if dLon >= 180 || lonSpan >= lonCells {
+		minLonIdx, lonSpan = 0, lonCells-1
+	}
+
+	cells := make([]string, 0, (maxLatIdx-minLatIdx+1)*(lonSpan+1))
+	for latIdx := minLatIdx; latIdx <= maxLatIdx && latIdx < latCells; latIdx++ {
+		for i := 0; i <= lonSpan; i++ {
+			cells = append(cells, encodeIndex(latIdx, (minLonIdx+i)%lonCells, precision))
+		}
+	}
+	return cells
+}
+
+// Decode returns the center of the geohash cell.
+func Decode(hash string) (Point, bool) {
+	latMin, latMax := -90.0, 90.0
+	lonMin, lonMax := -180.0, 180.0
+	even := true
+	for _, r := range hash {
+		idx := strings.IndexRune(base32, r)
+		if idx < 0 {
+			return Point{}, false
+		}
+		for bit := 4; bit >= 0; bit-- {
+			set := idx>>bit&1 == 1
+			if even {
+				mid := (lonMin + lonMax) / 2
+				if set {
+					lonMin = mid
+				} else {
+					lonMax = mid
+				}
+			} else {
+				mid := (latMin + latMax) / 2
+				if set {
+					latMin = mid
+				} else {
+					latMax = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return Point{Lat: (latMin + latMax) / 2, Lon: (lonMin + lonMax) / 2}, true
+}
+
+// cellBits splits the 5 bits per character between longitude and latitude;
+// longitude gets the extra bit on odd totals.
+func cellBits(precision int) (latBits, lonBits int) {
+	total := precision * 5
+	return total / 2, (total + 1) / 2
+}
+
+func cellIndex(value, min, span float64, bits int) int {
+	cells := 1 << bits
+	idx := int(math.Floor((value - min) / span * float64(cells)))
+	if idx < 0 {
+		return 0
+	}
+	if idx >= cells {
+		return cells - 1
+	}
+	return idx
+}
+
+// encodeIndex interleaves the longitude and latitude cell indexes, starting
+// with longitude, into base32 characters.
+func encodeIndex(latIdx, lonIdx, precision int) string {
+	latBits, lonBits := cellBits(precision)
+	var sb strings.Builder
+	sb.Grow(precision)
+
+	char, n := 0, 0
+	for i, total := 0, latBits+lonBits; i < total; i++ {
+		var bit int
+		if i%2 == 0 {
+			lonBits--
+			bit = lonIdx >> lonBits & 1
+		} else {
+			latBits--
+			bit = latIdx >> latBits & 1
+		}
+		char = char<<1 | bit
+		n++
+		if n == 5 {
+			sb.WriteByte(base32[char])
+			char, n = 0, 0
+		}
+	}
+	return sb.String()
+}
+
+func normalizeLon(lon float64) float64 {
+	for lon < -180 {
+		lon += 360
+	}
+	for lon > 180 {
+		lon -= 360
+	}
+	return lon
+}
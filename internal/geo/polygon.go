@@ -0,0 +1,31 @@
+package geo
+
+// Polygon is a closed ring of points; the last point connects back to the first.
+// Edges are straight lines in latitude and longitude, which is precise enough
+// for city districts that do not cross the antimeridian.
+type Polygon []Point
+
+// Valid reports whether the polygon has at least three distinct valid points.
+func (pg Polygon) Valid() bool {
+	distinct := make(map[Point]struct{}, len(pg))
+	for _, p := range pg {
+		if !p.Valid() {
+			return false
+		}
+		distinct[p] = struct{}{}
+	}
+	return len(distinct) >= 3
+}
+
+// Contains reports whether p lies inside the polygon, using the even-odd rule.
+func (pg Polygon) Contains(p Point) bool {
+	inside := false
+	for i, j := 0, len(pg)-1; i < len(pg); j, i = i, i+1 {
+		a, b := pg[i], pg[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lon < (b.Lon-a.Lon)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lon {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -2,6 +2,7 @@ package courier
 
 import (
 	"context"
+	"time"
 
 	"github.com/cdxy1/go-courier-service/internal/model"
 )
@@ -10,5 +11,13 @@ type courierUsecase interface {
 	GetOneById(ctx context.Context, id int) (*model.CourierModel, error)
 	GetAll(ctx context.Context) ([]*model.CourierModel, error)
 	Create(ctx context.Context, req *model.CourierModel) (int, error)
-	Update(ctx context.Context, req *model.CourierModel) error
+	Update(ctx context.Context, req *model.CourierModel, match model.VersionMatch) error
+	Patch(ctx context.Context, id int, patch *model.CourierPatch, match model.VersionMatch) (*model.CourierModel, error)
+	ChangeStatus(ctx context.Context, id int, status model.CourierStatus, force bool) (*model.CourierModel, error)
+}
+
+type locationUsecase interface {
+	ReportLocation(ctx context.Context, ping *model.LocationPing) error
+	GetLocation(ctx context.Context, courierId int) (*model.LocationPing, error)
+	Trail(ctx context.Context, courierId int, from, to time.Time) ([]*model.LocationPing, error)
 }
@@ -2,8 +2,11 @@ package courier
 
 import (
 	"errors"
+	"io"
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
 
 	handlerErrors "github.com/cdxy1/go-courier-service/internal/handler/errors"
 	"github.com/cdxy1/go-courier-service/internal/model"
@@ -45,15 +48,8 @@ func (h *CourierHandler) GetByID(c echo.Context) error {
 		}
 	}
 
-	response := &courierResponse{
-		ID:            result.ID,
-		Name:          result.Name,
-		Phone:         result.Phone,
-		Status:        result.Status,
-		TransportType: result.TransportType,
-	}
-
-	return c.JSON(http.StatusOK, response)
+	setETag(c, result.Version)
+	return c.JSON(http.StatusOK, newCourierResponse(result))
 }
 
 func (h *CourierHandler) GetAll(c echo.Context) error {
@@ -65,11 +61,13 @@ func (h *CourierHandler) GetAll(c echo.Context) error {
 	response := make([]*courierResponse, 0, len(result))
 	for _, v := range result {
 		courier := &courierResponse{
-			ID:            v.ID,
-			Name:          v.Name,
-			Phone:         v.Phone,
-			Status:        v.Status,
-			TransportType: v.TransportType,
+			ID:               v.ID,
+			Name:             v.Name,
+			Phone:            v.Phone,
+			Status:           v.Status,
+			TransportType:    v.TransportType,
+			ActiveDeliveries: v.ActiveDeliveries,
+			ZoneID:           v.ZoneID,
 		}
 		response = append(response, courier)
 	}
@@ -88,6 +86,7 @@ func (h *CourierHandler) Create(c echo.Context) error {
 		Phone:         req.Phone,
 		Status:        req.Status,
 		TransportType: req.TransportType,
+		ZoneID:        req.ZoneID,
 	}
 
 	id, err := h.uc.Create(c.Request().Context(), courier)
@@ -97,13 +96,18 @@ func (h *CourierHandler) Create(c echo.Context) error {
 			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 		case repo.ErrPhoneExists:
 			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		case repo.ErrZoneNotFound:
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 		default:
-			if errors.Is(err, usecase.ErrInvalidName) || errors.Is(err, usecase.ErrInvalidPhone) {
+			if errors.Is(err, usecase.ErrInvalidName) || errors.Is(err, usecase.ErrInvalidPhone) || errors.Is(err, usecase.ErrInvalidStatus) {
 				return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 			}
 			if errors.Is(err, repo.ErrPhoneExists) {
 				return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
 			}
+			if errors.Is(err, repo.ErrZoneNotFound) {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+			}
 			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
 		}
 	}
@@ -112,6 +116,11 @@ func (h *CourierHandler) Create(c echo.Context) error {
 }
 
 func (h *CourierHandler) Update(c echo.Context) error {
+	match, err := parseIfMatch(c.Request().Header.Get("If-Match"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
 	var req updateCourierRequest
 	if err := c.Bind(&req); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": handlerErrors.ErrBadRequest.Error()})
@@ -123,9 +132,10 @@ func (h *CourierHandler) Update(c echo.Context) error {
 		Phone:         req.Phone,
 		Status:        req.Status,
 		TransportType: req.TransportType,
+		ZoneID:        req.ZoneID,
 	}
 
-	err := h.uc.Update(c.Request().Context(), courier)
+	err = h.uc.Update(c.Request().Context(), courier, match)
 	if err != nil {
 		switch err {
 		case usecase.ErrInvalidID, usecase.ErrInvalidName, usecase.ErrInvalidPhone:
@@ -134,19 +144,174 @@ func (h *CourierHandler) Update(c echo.Context) error {
 			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
 		case repo.ErrPhoneExists:
 			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		case repo.ErrZoneNotFound:
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 		default:
-			if errors.Is(err, usecase.ErrInvalidID) || errors.Is(err, usecase.ErrInvalidName) || errors.Is(err, usecase.ErrInvalidPhone) {
+			if errors.Is(err, usecase.ErrInvalidID) || errors.Is(err, usecase.ErrInvalidName) || errors.Is(err, usecase.ErrInvalidPhone) ||
+				errors.Is(err, usecase.ErrInvalidStatus) {
 				return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 			}
+			if errors.Is(err, usecase.ErrInvalidStatusTransition) || errors.Is(err, usecase.ErrActiveDeliveries) {
+				return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+			}
+			if errors.Is(err, usecase.ErrVersionMismatch) {
+				return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": err.Error()})
+			}
 			if errors.Is(err, repo.ErrCourierNotFound) {
 				return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
 			}
 			if errors.Is(err, repo.ErrPhoneExists) {
 				return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
 			}
+			if errors.Is(err, repo.ErrZoneNotFound) {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+			}
 			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
 		}
 	}
 
+	setETag(c, courier.Version)
 	return c.JSON(http.StatusOK, map[string]string{"status": "updated"})
 }
+
+// Patch applies a JSON Merge Patch to the courier. With If-Match the patch is
+// only applied while the courier is at one of the versions listed, otherwise
+// 412 is returned.
+func (h *CourierHandler) Patch(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	if contentType := c.Request().Header.Get(echo.HeaderContentType); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != mimeMergePatch && mediaType != echo.MIMEApplicationJSON) {
+			return c.JSON(http.StatusUnsupportedMediaType, map[string]string{"error": "unsupported content type"})
+		}
+	}
+	match, err := parseIfMatch(c.Request().Header.Get("If-Match"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": handlerErrors.ErrBadRequest.Error()})
+	}
+	patch, err := parseCourierPatch(body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	result, err := h.uc.Patch(c.Request().Context(), id, patch, match)
+	if err != nil {
+		return patchError(c, err)
+	}
+
+	setETag(c, result.Version)
+	return c.JSON(http.StatusOK, newCourierResponse(result))
+}
+
+func (h *CourierHandler) ChangeStatus(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+
+	var req statusRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": handlerErrors.ErrBadRequest.Error()})
+	}
+
+	result, err := h.uc.ChangeStatus(c.Request().Context(), id, req.Status, req.Force)
+	if err != nil {
+		return statusError(c, err)
+	}
+
+	setETag(c, result.Version)
+	return c.JSON(http.StatusOK, newCourierResponse(result))
+}
+
+// statusError maps errors of courier status changes to HTTP responses.
+func statusError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidID), errors.Is(err, usecase.ErrInvalidStatus):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, repo.ErrCourierNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, usecase.ErrInvalidStatusTransition), errors.Is(err, usecase.ErrActiveDeliveries):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+}
+
+// patchError maps errors of courier patches to HTTP responses.
+func patchError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidID),
+		errors.Is(err, usecase.ErrInvalidName),
+		errors.Is(err, usecase.ErrInvalidPhone),
+		errors.Is(err, usecase.ErrInvalidStatus),
+		errors.Is(err, repo.ErrZoneNotFound):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, repo.ErrCourierNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, repo.ErrPhoneExists),
+		errors.Is(err, usecase.ErrInvalidStatusTransition),
+		errors.Is(err, usecase.ErrActiveDeliveries):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, usecase.ErrVersionMismatch):
+		return c.JSON(http.StatusPreconditionFailed, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+}
+
+const mimeMergePatch = "application/merge-patch+json"
+
+var errInvalidIfMatch = errors.New("invalid If-Match header")
+
+// setETag sets the ETag of the courier version, a strong validator.
+func setETag(c echo.Context, version int) {
+	c.Response().Header().Set("ETag", `"`+strconv.Itoa(version)+`"`)
+}
+
+// parseIfMatch reads the If-Match header, a list of ETags, into the versions
+// the courier may be at. A missing header or "*" puts no condition. Matching
+// is strong, so weak ETags and ETags that are no courier version match nothing.
+func parseIfMatch(header string) (model.VersionMatch, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return model.VersionMatch{}, nil
+	}
+
+	match := model.VersionMatch{Required: true}
+	for rest := header; ; {
+		weak := strings.HasPrefix(rest, "W/")
+		if weak {
+			rest = rest[2:]
+		}
+		if !strings.HasPrefix(rest, `"`) {
+			return model.VersionMatch{}, errInvalidIfMatch
+		}
+		end := strings.IndexByte(rest[1:], '"')
+		if end < 0 {
+			return model.VersionMatch{}, errInvalidIfMatch
+		}
+		tag := rest[1 : end+1]
+		// Courier ETags are plain version numbers and compare as strings.
+		if version, err := strconv.Atoi(tag); err == nil && version > 0 && !weak && strconv.Itoa(version) == tag {
+			match.Versions = append(match.Versions, version)
+		}
+
+		rest = strings.TrimLeft(rest[end+2:], " \t")
+		if rest == "" {
+			return match, nil
+		}
+		if rest[0] != ',' {
+			return model.VersionMatch{}, errInvalidIfMatch
+		}
+		rest = strings.TrimLeft(rest[1:], " \t,")
+		if rest == "" {
+			return model.VersionMatch{}, errInvalidIfMatch
+		}
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/cdxy1/go-courier-service/internal/model"
 	repo "github.com/cdxy1/go-courier-service/internal/repository/courier"
@@ -20,7 +22,9 @@ type mockCourierUsecase struct {
 	getOneByIDFn func(ctx context.Context, id int) (*model.CourierModel, error)
 	getAllFn     func(ctx context.Context) ([]*model.CourierModel, error)
 	createFn     func(ctx context.Context, req *model.CourierModel) (int, error)
-	updateFn     func(ctx context.Context, req *model.CourierModel) error
+	updateFn     func(ctx context.Context, req *model.CourierModel, match model.VersionMatch) error
+	patchFn      func(ctx context.Context, id int, patch *model.CourierPatch, match model.VersionMatch) (*model.CourierModel, error)
+	statusFn     func(ctx context.Context, id int, status model.CourierStatus, force bool) (*model.CourierModel, error)
 }
 
 func newMockCourierUsecase(t *testing.T) *mockCourierUsecase {
@@ -48,16 +52,32 @@ func (m *mockCourierUsecase) Create(ctx context.Context, req *model.CourierModel
 	return m.createFn(ctx, req)
 }
 
-func (m *mockCourierUsecase) Update(ctx context.Context, req *model.CourierModel) error {
+func (m *mockCourierUsecase) Update(ctx context.Context, req *model.CourierModel, match model.VersionMatch) error {
 	if m.updateFn == nil {
 		m.t.Fatalf("Update called unexpectedly")
 	}
-	return m.updateFn(ctx, req)
+	return m.updateFn(ctx, req, match)
+}
+
+func (m *mockCourierUsecase) Patch(ctx context.Context, id int, patch *model.CourierPatch, match model.VersionMatch) (*model.CourierModel, error) {
+	if m.patchFn == nil {
+		m.t.Fatalf("Patch called unexpectedly")
+	}
+	return m.patchFn(ctx, id, patch, match)
+}
+
+func (m *mockCourierUsecase) ChangeStatus(ctx context.Context, id int, status model.CourierStatus, force bool) (*model.CourierModel, error) {
+	if m.statusFn == nil {
+		m.t.Fatalf("ChangeStatus called unexpectedly")
+	}
+	return m.statusFn(ctx, id, status, force)
 }
 
 func TestCourierHandler_GetByID(t *testing.T) {
 	t.Parallel()
 
+	scoredAt := time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC)
+
 	tests := []struct {
 		name       string
 		param      string
@@ -120,6 +140,25 @@ func TestCourierHandler_GetByID(t *testing.T) {
 			wantStatus: http.StatusOK,
 			wantResp:   &courierResponse{ID: 3, Name: "Alice", Phone: "+79991234567", Status: model.CourierStatusAvailable, TransportType: model.TransportCar},
 		},
+		{
+			name:  "success with score",
+			param: "4",
+			setup: func(m *mockCourierUsecase) {
+				m.getOneByIDFn = func(ctx context.Context, id int) (*model.CourierModel, error) {
+					return &model.CourierModel{
+						ID: id, Name: "Bob", Phone: "+79991234568", Status: model.CourierStatusAvailable, TransportType: model.TransportOnFoot,
+						Score: &model.CourierScore{
+							CourierID: id, Score: 0.8, OnTimeRate: 0.9, CancelRate: 0.1, DeadlineUsage: 0.6, Deliveries: 10, UpdatedAt: scoredAt,
+						},
+					}, nil
+				}
+			},
+			wantStatus: http.StatusOK,
+			wantResp: &courierResponse{
+				ID: 4, Name: "Bob", Phone: "+79991234568", Status: model.CourierStatusAvailable, TransportType: model.TransportOnFoot,
+				Score: &scoreResponse{Score: 0.8, OnTimeRate: 0.9, CancelRate: 0.1, DeadlineUsage: 0.6, Deliveries: 10, UpdatedAt: scoredAt},
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -150,7 +189,7 @@ func TestCourierHandler_GetByID(t *testing.T) {
 				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 					t.Fatalf("failed to decode response: %v", err)
 				}
-				if resp != *tc.wantResp {
+				if !reflect.DeepEqual(resp, *tc.wantResp) {
 					t.Fatalf("unexpected response: %+v", resp)
 				}
 			} else {
@@ -261,6 +300,20 @@ func TestCourierHandler_Create(t *testing.T) {
 			wantStatus: http.StatusConflict,
 			wantErr:    repo.ErrPhoneExists.Error(),
 		},
+		{
+			name: "unknown zone",
+			body: `{"name":"Alice","phone":"+79991234567","status":"available","transport_type":"car","zone_id":9}`,
+			setup: func(m *mockCourierUsecase) {
+				m.createFn = func(ctx context.Context, req *model.CourierModel) (int, error) {
+					if req.ZoneID == nil || *req.ZoneID != 9 {
+						m.t.Fatalf("unexpected zone: %v", req.ZoneID)
+					}
+					return 0, repo.ErrZoneNotFound
+				}
+			},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    repo.ErrZoneNotFound.Error(),
+		},
 		{
 			name: "internal error",
 			body: `{"name":"Alice","phone":"+79991234567","status":"available","transport_type":"car"}`,
@@ -349,7 +402,7 @@ func TestCourierHandler_Update(t *testing.T) {
 			name: "invalid data",
 			body: `{"id":1,"name":"","phone":"+79991234567","status":"available","transport_type":"car"}`,
 			setup: func(m *mockCourierUsecase) {
-				m.updateFn = func(ctx context.Context, req *model.CourierModel) error {
+				m.updateFn = func(ctx context.Context, req *model.CourierModel, match model.VersionMatch) error {
 					return usecase.ErrInvalidName
 				}
 			},
@@ -360,7 +413,7 @@ func TestCourierHandler_Update(t *testing.T) {
 			name: "not found",
 			body: `{"id":1,"name":"Alice","phone":"+79991234567","status":"available","transport_type":"car"}`,
 			setup: func(m *mockCourierUsecase) {
-				m.updateFn = func(ctx context.Context, req *model.CourierModel) error {
+				m.updateFn = func(ctx context.Context, req *model.CourierModel, match model.VersionMatch) error {
 					return repo.ErrCourierNotFound
 				}
 			},
@@ -371,7 +424,7 @@ func TestCourierHandler_Update(t *testing.T) {
 			name: "phone exists",
 			body: `{"id":1,"name":"Alice","phone":"+79991234567","status":"available","transport_type":"car"}`,
 			setup: func(m *mockCourierUsecase) {
-				m.updateFn = func(ctx context.Context, req *model.CourierModel) error {
+				m.updateFn = func(ctx context.Context, req *model.CourierModel, match model.VersionMatch) error {
 					return repo.ErrPhoneExists
 				}
 			},
@@ -382,7 +435,7 @@ func TestCourierHandler_Update(t *testing.T) {
 			name: "internal error",
 			body: `{"id":1,"name":"Alice","phone":"+79991234567","status":"available","transport_type":"car"}`,
 			setup: func(m *mockCourierUsecase) {
-				m.updateFn = func(ctx context.Context, req *model.CourierModel) error {
+				m.updateFn = func(ctx context.Context, req *model.CourierModel, match model.VersionMatch) error {
 					return errors.New("boom")
 				}
 			},
@@ -393,7 +446,7 @@ func TestCourierHandler_Update(t *testing.T) {
 			name: "success",
 			body: `{"id":1,"name":"Alice","phone":"+79991234567","status":"available","transport_type":"car"}`,
 			setup: func(m *mockCourierUsecase) {
-				m.updateFn = func(ctx context.Context, req *model.CourierModel) error {
+				m.updateFn = func(ctx context.Context, req *model.CourierModel, match model.VersionMatch) error {
 					if req.ID != 1 {
 						m.t.Fatalf("unexpected id: %d", req.ID)
 					}
@@ -446,3 +499,284 @@ func TestCourierHandler_Update(t *testing.T) {
 		})
 	}
 }
+
+func TestCourierHandler_ChangeStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		param      string
+		body       string
+		setup      func(*mockCourierUsecase)
+		wantStatus int
+	}{
+		{
+			name:       "invalid path param",
+			param:      "abc",
+			body:       `{"status":"paused"}`,
+			setup:      func(_ *mockCourierUsecase) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid body",
+			param:      "1",
+			body:       "{",
+			setup:      func(_ *mockCourierUsecase) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "unknown status",
+			param: "1",
+			body:  `{"status":"active"}`,
+			setup: func(m *mockCourierUsecase) {
+				m.statusFn = func(ctx context.Context, id int, status model.CourierStatus, force bool) (*model.CourierModel, error) {
+					return nil, usecase.ErrInvalidStatus
+				}
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "not found",
+			param: "1",
+			body:  `{"status":"paused"}`,
+			setup: func(m *mockCourierUsecase) {
+				m.statusFn = func(ctx context.Context, id int, status model.CourierStatus, force bool) (*model.CourierModel, error) {
+					return nil, repo.ErrCourierNotFound
+				}
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:  "active deliveries",
+			param: "1",
+			body:  `{"status":"paused"}`,
+			setup: func(m *mockCourierUsecase) {
+				m.statusFn = func(ctx context.Context, id int, status model.CourierStatus, force bool) (*model.CourierModel, error) {
+					return nil, usecase.ErrActiveDeliveries
+				}
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:  "transition not allowed",
+			param: "1",
+			body:  `{"status":"busy"}`,
+			setup: func(m *mockCourierUsecase) {
+				m.statusFn = func(ctx context.Context, id int, status model.CourierStatus, force bool) (*model.CourierModel, error) {
+					return nil, usecase.ErrInvalidStatusTransition
+				}
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:  "forced",
+			param: "1",
+			body:  `{"status":"paused","force":true}`,
+			setup: func(m *mockCourierUsecase) {
+				m.statusFn = func(ctx context.Context, id int, status model.CourierStatus, force bool) (*model.CourierModel, error) {
+					if id != 1 || status != model.CourierStatusPaused || !force {
+						m.t.Fatalf("unexpected change of courier %d to %s, force %v", id, status, force)
+					}
+					return &model.CourierModel{ID: id, Status: status, ActiveDeliveries: 1}, nil
+				}
+			},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/couriers/"+tc.param+"/status", strings.NewReader(tc.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tc.param)
+
+			uc := newMockCourierUsecase(t)
+			tc.setup(uc)
+			handler := NewCourierHandler(uc)
+
+			if err := handler.ChangeStatus(c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d", tc.wantStatus, rec.Code)
+			}
+			if tc.wantStatus == http.StatusOK {
+				var resp courierResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if resp.Status != model.CourierStatusPaused || resp.ActiveDeliveries != 1 {
+					t.Fatalf("unexpected response: %+v", resp)
+				}
+			}
+		})
+	}
+}
+
+// patchAtVersion makes Patch answer like a courier at the version.
+func patchAtVersion(version int) func(*mockCourierUsecase) {
+	return func(m *mockCourierUsecase) {
+		m.patchFn = func(ctx context.Context, id int, patch *model.CourierPatch, match model.VersionMatch) (*model.CourierModel, error) {
+			if !match.Matches(version) {
+				return nil, usecase.ErrVersionMismatch
+			}
+			return &model.CourierModel{ID: id, Name: *patch.Name, Phone: "+79991234567", Status: model.CourierStatusAvailable, Version: version + 1}, nil
+		}
+	}
+}
+
+func TestCourierHandler_Patch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		contentType string
+		ifMatch     string
+		body        string
+		setup       func(*mockCourierUsecase)
+		wantStatus  int
+		wantETag    string
+	}{
+		{
+			name:        "unsupported content type",
+			contentType: echo.MIMETextPlain,
+			body:        `{"name":"Bob"}`,
+			setup:       func(_ *mockCourierUsecase) {},
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name:       "malformed etag",
+			ifMatch:    `3`,
+			body:       `{"name":"Bob"}`,
+			setup:      func(_ *mockCourierUsecase) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "weak etag never matches",
+			ifMatch:    `W/"3"`,
+			body:       `{"name":"Bob"}`,
+			setup:      patchAtVersion(3),
+			wantStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:       "list without the current version",
+			ifMatch:    `"1", W/"3", "4"`,
+			body:       `{"name":"Bob"}`,
+			setup:      patchAtVersion(3),
+			wantStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:       "list with the current version",
+			ifMatch:    `"2", "3"`,
+			body:       `{"name":"Bob"}`,
+			setup:      patchAtVersion(3),
+			wantStatus: http.StatusOK,
+			wantETag:   `"4"`,
+		},
+		{
+			name:       "not an object",
+			body:       `["name"]`,
+			setup:      func(_ *mockCourierUsecase) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unknown field",
+			body:       `{"active_deliveries":0}`,
+			setup:      func(_ *mockCourierUsecase) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "name removed",
+			body:       `{"name":null}`,
+			setup:      func(_ *mockCourierUsecase) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "modified concurrently",
+			ifMatch: `"3"`,
+			body:    `{"name":"Bob"}`,
+			setup: func(m *mockCourierUsecase) {
+				m.patchFn = func(ctx context.Context, id int, patch *model.CourierPatch, match model.VersionMatch) (*model.CourierModel, error) {
+					return nil, usecase.ErrVersionMismatch
+				}
+			},
+			wantStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:    "not found",
+			ifMatch: "*",
+			body:    `{"name":"Bob"}`,
+			setup: func(m *mockCourierUsecase) {
+				m.patchFn = func(ctx context.Context, id int, patch *model.CourierPatch, match model.VersionMatch) (*model.CourierModel, error) {
+					if match.Required {
+						m.t.Fatalf("expected no condition, got %+v", match)
+					}
+					return nil, repo.ErrCourierNotFound
+				}
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:        "success",
+			contentType: "application/merge-patch+json",
+			ifMatch:     `"3"`,
+			body:        `{"phone":"+79991234569","zone_id":null}`,
+			setup: func(m *mockCourierUsecase) {
+				m.patchFn = func(ctx context.Context, id int, patch *model.CourierPatch, match model.VersionMatch) (*model.CourierModel, error) {
+					if id != 5 || !match.Matches(3) || match.Matches(4) {
+						m.t.Fatalf("unexpected patch of courier %d at %+v", id, match)
+					}
+					if patch.Name != nil || patch.Phone == nil || *patch.Phone != "+79991234569" || !patch.ZoneSet || patch.ZoneID != nil {
+						m.t.Fatalf("unexpected patch: %+v", patch)
+					}
+					return &model.CourierModel{ID: id, Name: "Alice", Phone: *patch.Phone, Status: model.CourierStatusAvailable, Version: 4}, nil
+				}
+			},
+			wantStatus: http.StatusOK,
+			wantETag:   `"4"`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPatch, "/couriers/5", strings.NewReader(tc.body))
+			contentType := tc.contentType
+			if contentType == "" {
+				contentType = echo.MIMEApplicationJSON
+			}
+			req.Header.Set(echo.HeaderContentType, contentType)
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("5")
+
+			uc := newMockCourierUsecase(t)
+			tc.setup(uc)
+			handler := NewCourierHandler(uc)
+
+			if err := handler.Patch(c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d", tc.wantStatus, rec.Code)
+			}
+			if etag := rec.Header().Get("ETag"); etag != tc.wantETag {
+				t.Fatalf("expected ETag %q, got %q", tc.wantETag, etag)
+			}
+		})
+	}
+}
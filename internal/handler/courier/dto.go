@@ -1,12 +1,21 @@
 package courier
 
-import "github.com/cdxy1/go-courier-service/internal/model"
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	handlerErrors "github.com/cdxy1/go-courier-service/internal/handler/errors"
+	"github.com/cdxy1/go-courier-service/internal/model"
+)
 
 type createCourierRequest struct {
 	Name          string              `json:"name"`
 	Phone         string              `json:"phone"`
 	Status        model.CourierStatus `json:"status"`
 	TransportType model.TransportType `json:"transport_type"`
+	ZoneID        *int                `json:"zone_id"`
 }
 
 type updateCourierRequest struct {
@@ -15,12 +24,148 @@ type updateCourierRequest struct {
 	Phone         string              `json:"phone"`
 	Status        model.CourierStatus `json:"status"`
 	TransportType model.TransportType `json:"transport_type"`
+	ZoneID        *int                `json:"zone_id"`
+}
+
+// statusRequest asks for a status change. Force pauses a courier that still
+// holds deliveries.
+type statusRequest struct {
+	Status model.CourierStatus `json:"status"`
+	Force  bool                `json:"force"`
+}
+
+// parseCourierPatch reads a JSON Merge Patch (RFC 7396) of a courier: members
+// left out are kept and null removes the zone. The other fields cannot be
+// removed, and unknown or read-only members are rejected.
+func parseCourierPatch(body []byte) (*model.CourierPatch, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(body, &members); err != nil || members == nil {
+		return nil, handlerErrors.ErrBadRequest
+	}
+
+	patch := &model.CourierPatch{}
+	for name, raw := range members {
+		null := bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+		var dest any
+		switch name {
+		case "name":
+			dest = &patch.Name
+		case "phone":
+			dest = &patch.Phone
+		case "status":
+			dest = &patch.Status
+		case "transport_type":
+			dest = &patch.TransportType
+		case "zone_id":
+			patch.ZoneSet = true
+			dest = &patch.ZoneID
+		default:
+			return nil, fmt.Errorf("%w: unknown field %q", handlerErrors.ErrBadRequest, name)
+		}
+		if null && name != "zone_id" {
+			return nil, fmt.Errorf("%w: %s cannot be removed", handlerErrors.ErrBadRequest, name)
+		}
+		if err := json.Unmarshal(raw, dest); err != nil {
+			return nil, fmt.Errorf("%w: invalid %s", handlerErrors.ErrBadRequest, name)
+		}
+	}
+	return patch, nil
 }
 
 type courierResponse struct {
-	ID            int                 `json:"id"`
-	Name          string              `json:"name"`
-	Phone         string              `json:"phone"`
-	Status        model.CourierStatus `json:"status"`
-	TransportType model.TransportType `json:"transport_type"`
+	ID               int                 `json:"id"`
+	Name             string              `json:"name"`
+	Phone            string              `json:"phone"`
+	Status           model.CourierStatus `json:"status"`
+	TransportType    model.TransportType `json:"transport_type"`
+	ActiveDeliveries int                 `json:"active_deliveries"`
+	ZoneID           *int                `json:"zone_id,omitempty"`
+	// Score is only filled in for a single courier.
+	Score *scoreResponse `json:"score,omitempty"`
+}
+
+type scoreResponse struct {
+	Score         float64   `json:"score"`
+	OnTimeRate    float64   `json:"on_time_rate"`
+	CancelRate    float64   `json:"cancel_rate"`
+	DeadlineUsage float64   `json:"deadline_usage"`
+	Deliveries    int       `json:"deliveries"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type locationRequest struct {
+	Lat       *float64   `json:"lat"`
+	Lon       *float64   `json:"lon"`
+	Accuracy  float64    `json:"accuracy"`
+	Timestamp *time.Time `json:"timestamp"`
+}
+
+type locationResponse struct {
+	CourierID int       `json:"courier_id"`
+	Lat       float64   `json:"lat"`
+	Lon       float64   `json:"lon"`
+	Accuracy  float64   `json:"accuracy"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type trailResponse struct {
+	CourierID int                  `json:"courier_id"`
+	Points    []trailPointResponse `json:"points"`
+}
+
+type trailPointResponse struct {
+	Lat       float64   `json:"lat"`
+	Lon       float64   `json:"lon"`
+	Accuracy  float64   `json:"accuracy"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func newCourierResponse(courier *model.CourierModel) *courierResponse {
+	return &courierResponse{
+		ID:               courier.ID,
+		Name:             courier.Name,
+		Phone:            courier.Phone,
+		Status:           courier.Status,
+		TransportType:    courier.TransportType,
+		ActiveDeliveries: courier.ActiveDeliveries,
+		ZoneID:           courier.ZoneID,
+		Score:            newScoreResponse(courier.Score),
+	}
+}
+
+func newScoreResponse(score *model.CourierScore) *scoreResponse {
+	if score == nil {
+		return nil
+	}
+	return &scoreResponse{
+		Score:         score.Score,
+		OnTimeRate:    score.OnTimeRate,
+		CancelRate:    score.CancelRate,
+		DeadlineUsage: score.DeadlineUsage,
+		Deliveries:    score.Deliveries,
+		UpdatedAt:     score.UpdatedAt,
+	}
+}
+
+func newLocationResponse(ping *model.LocationPing) *locationResponse {
+	return &locationResponse{
+		CourierID: ping.CourierID,
+		Lat:       ping.Point.Lat,
+		Lon:       ping.Point.Lon,
+		Accuracy:  ping.Accuracy,
+		Timestamp: ping.RecordedAt,
+	}
+}
+
+func newTrailResponse(courierId int, trail []*model.LocationPing) *trailResponse {
+	points := make([]trailPointResponse, 0, len(trail))
+	for _, p := range trail {
+		points = append(points, trailPointResponse{
+			Lat:       p.Point.Lat,
+			Lon:       p.Point.Lon,
+			Accuracy:  p.Accuracy,
+			Timestamp: p.RecordedAt,
+		})
+	}
+	return &trailResponse{CourierID: courierId, Points: points}
 }
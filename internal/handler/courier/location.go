@@ -0,0 +1,108 @@
+package courier
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/geo"
+	handlerErrors "github.com/cdxy1/go-courier-service/internal/handler/errors"
+	"github.com/cdxy1/go-courier-service/internal/model"
+	repo "github.com/cdxy1/go-courier-service/internal/repository/courier"
+	usecase "github.com/cdxy1/go-courier-service/internal/usecase/courier"
+	"github.com/labstack/echo/v4"
+)
+
+type LocationHandler struct {
+	uc locationUsecase
+}
+
+func NewLocationHandler(uc locationUsecase) *LocationHandler {
+	return &LocationHandler{uc: uc}
+}
+
+func (h *LocationHandler) Report(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+
+	var req locationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": handlerErrors.ErrBadRequest.Error()})
+	}
+	if req.Lat == nil || req.Lon == nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": usecase.ErrInvalidLocation.Error()})
+	}
+
+	ping := &model.LocationPing{
+		CourierID: id,
+		Point:     geo.Point{Lat: *req.Lat, Lon: *req.Lon},
+		Accuracy:  req.Accuracy,
+	}
+	if req.Timestamp != nil {
+		ping.RecordedAt = *req.Timestamp
+	}
+
+	if err := h.uc.ReportLocation(c.Request().Context(), ping); err != nil {
+		return locationError(c, err)
+	}
+	return c.JSON(http.StatusAccepted, map[string]string{"status": "accepted"})
+}
+
+func (h *LocationHandler) Get(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+
+	ping, err := h.uc.GetLocation(c.Request().Context(), id)
+	if err != nil {
+		return locationError(c, err)
+	}
+	return c.JSON(http.StatusOK, newLocationResponse(ping))
+}
+
+// Trail returns the positions of the courier between the from and to query
+// parameters (RFC 3339). Both are optional and default to the last hour.
+func (h *LocationHandler) Trail(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+
+	var from, to time.Time
+	if v := c.QueryParam("from"); v != "" {
+		if from, err = time.Parse(time.RFC3339, v); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": usecase.ErrInvalidTimeRange.Error()})
+		}
+	}
+	if v := c.QueryParam("to"); v != "" {
+		if to, err = time.Parse(time.RFC3339, v); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": usecase.ErrInvalidTimeRange.Error()})
+		}
+	}
+
+	trail, err := h.uc.Trail(c.Request().Context(), id, from, to)
+	if err != nil {
+		return locationError(c, err)
+	}
+	return c.JSON(http.StatusOK, newTrailResponse(id, trail))
+}
+
+func locationError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidID),
+		errors.Is(err, usecase.ErrInvalidLocation),
+		errors.Is(err, usecase.ErrInvalidTimeRange):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, repo.ErrCourierNotFound), errors.Is(err, repo.ErrLocationNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, usecase.ErrStaleLocation):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, usecase.ErrLocationOverload):
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+}
@@ -0,0 +1,335 @@
+package courier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/geo"
+	"github.com/cdxy1/go-courier-service/internal/model"
+	repo "github.com/cdxy1/go-courier-service/internal/repository/courier"
+	usecase "github.com/cdxy1/go-courier-service/internal/usecase/courier"
+	"github.com/labstack/echo/v4"
+)
+
+type mockLocationUsecase struct {
+	t             *testing.T
+	reportFn      func(ctx context.Context, ping *model.LocationPing) error
+	getLocationFn func(ctx context.Context, courierId int) (*model.LocationPing, error)
+	trailFn       func(ctx context.Context, courierId int, from, to time.Time) ([]*model.LocationPing, error)
+}
+
+func newMockLocationUsecase(t *testing.T) *mockLocationUsecase {
+	return &mockLocationUsecase{t: t}
+}
+
+func (m *mockLocationUsecase) ReportLocation(ctx context.Context, ping *model.LocationPing) error {
+	if m.reportFn == nil {
+		m.t.Fatalf("ReportLocation called unexpectedly")
+	}
+	return m.reportFn(ctx, ping)
+}
+
+func (m *mockLocationUsecase) GetLocation(ctx context.Context, courierId int) (*model.LocationPing, error) {
+	if m.getLocationFn == nil {
+		m.t.Fatalf("GetLocation called unexpectedly")
+	}
+	return m.getLocationFn(ctx, courierId)
+}
+
+func (m *mockLocationUsecase) Trail(ctx context.Context, courierId int, from, to time.Time) ([]*model.LocationPing, error) {
+	if m.trailFn == nil {
+		m.t.Fatalf("Trail called unexpectedly")
+	}
+	return m.trailFn(ctx, courierId, from, to)
+}
+
+func TestLocationHandler_Report(t *testing.T) {
+	t.Parallel()
+
+	recordedAt := time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		param      string
+		body       string
+		setup      func(*mockLocationUsecase)
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "invalid path param",
+			param:      "abc",
+			body:       `{"lat":55.75,"lon":37.61}`,
+			setup:      func(_ *mockLocationUsecase) {},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "invalid id",
+		},
+		{
+			name:       "invalid body",
+			param:      "1",
+			body:       "{",
+			setup:      func(_ *mockLocationUsecase) {},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "invalid request body",
+		},
+		{
+			name:       "missing coordinates",
+			param:      "1",
+			body:       `{"lat":55.75}`,
+			setup:      func(_ *mockLocationUsecase) {},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    usecase.ErrInvalidLocation.Error(),
+		},
+		{
+			name:  "courier not found",
+			param: "1",
+			body:  `{"lat":55.75,"lon":37.61}`,
+			setup: func(m *mockLocationUsecase) {
+				m.reportFn = func(ctx context.Context, ping *model.LocationPing) error {
+					return repo.ErrCourierNotFound
+				}
+			},
+			wantStatus: http.StatusNotFound,
+			wantErr:    repo.ErrCourierNotFound.Error(),
+		},
+		{
+			name:  "out of order",
+			param: "1",
+			body:  `{"lat":55.75,"lon":37.61,"timestamp":"2026-10-16T11:00:00Z"}`,
+			setup: func(m *mockLocationUsecase) {
+				m.reportFn = func(ctx context.Context, ping *model.LocationPing) error {
+					return usecase.ErrStaleLocation
+				}
+			},
+			wantStatus: http.StatusConflict,
+			wantErr:    usecase.ErrStaleLocation.Error(),
+		},
+		{
+			name:  "buffer full",
+			param: "1",
+			body:  `{"lat":55.75,"lon":37.61}`,
+			setup: func(m *mockLocationUsecase) {
+				m.reportFn = func(ctx context.Context, ping *model.LocationPing) error {
+					return usecase.ErrLocationOverload
+				}
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantErr:    usecase.ErrLocationOverload.Error(),
+		},
+		{
+			name:  "internal error",
+			param: "1",
+			body:  `{"lat":55.75,"lon":37.61}`,
+			setup: func(m *mockLocationUsecase) {
+				m.reportFn = func(ctx context.Context, ping *model.LocationPing) error {
+					return errors.New("boom")
+				}
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantErr:    "internal server error",
+		},
+		{
+			name:  "accepted",
+			param: "4",
+			body:  `{"lat":55.75,"lon":37.61,"accuracy":12.5,"timestamp":"2026-10-16T12:00:00Z"}`,
+			setup: func(m *mockLocationUsecase) {
+				m.reportFn = func(ctx context.Context, ping *model.LocationPing) error {
+					expected := model.LocationPing{CourierID: 4, Point: geo.Point{Lat: 55.75, Lon: 37.61}, Accuracy: 12.5, RecordedAt: recordedAt}
+					if ping.CourierID != expected.CourierID || ping.Point != expected.Point || ping.Accuracy != expected.Accuracy || !ping.RecordedAt.Equal(recordedAt) {
+						m.t.Fatalf("unexpected ping: %+v", ping)
+					}
+					return nil
+				}
+			},
+			wantStatus: http.StatusAccepted,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/couriers/"+tc.param+"/location", strings.NewReader(tc.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tc.param)
+
+			uc := newMockLocationUsecase(t)
+			tc.setup(uc)
+			handler := NewLocationHandler(uc)
+
+			if err := handler.Report(c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d", tc.wantStatus, rec.Code)
+			}
+			if tc.wantErr != "" {
+				var resp map[string]string
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if resp["error"] != tc.wantErr {
+					t.Fatalf("expected error %q, got %q", tc.wantErr, resp["error"])
+				}
+			}
+		})
+	}
+}
+
+func TestLocationHandler_Get(t *testing.T) {
+	t.Parallel()
+
+	recordedAt := time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		param      string
+		setup      func(*mockLocationUsecase)
+		wantStatus int
+		wantResp   *locationResponse
+	}{
+		{
+			name:  "location unknown",
+			param: "1",
+			setup: func(m *mockLocationUsecase) {
+				m.getLocationFn = func(ctx context.Context, courierId int) (*model.LocationPing, error) {
+					return nil, repo.ErrLocationNotFound
+				}
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:  "success",
+			param: "2",
+			setup: func(m *mockLocationUsecase) {
+				m.getLocationFn = func(ctx context.Context, courierId int) (*model.LocationPing, error) {
+					return &model.LocationPing{CourierID: courierId, Point: geo.Point{Lat: 1.5, Lon: 2.5}, Accuracy: 7, RecordedAt: recordedAt}, nil
+				}
+			},
+			wantStatus: http.StatusOK,
+			wantResp:   &locationResponse{CourierID: 2, Lat: 1.5, Lon: 2.5, Accuracy: 7, Timestamp: recordedAt},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/couriers/"+tc.param+"/location", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tc.param)
+
+			uc := newMockLocationUsecase(t)
+			tc.setup(uc)
+			handler := NewLocationHandler(uc)
+
+			if err := handler.Get(c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d", tc.wantStatus, rec.Code)
+			}
+			if tc.wantResp != nil {
+				var resp locationResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if resp != *tc.wantResp {
+					t.Fatalf("unexpected response: %+v", resp)
+				}
+			}
+		})
+	}
+}
+
+func TestLocationHandler_Trail(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		query      string
+		setup      func(*mockLocationUsecase)
+		wantStatus int
+		wantPoints int
+	}{
+		{
+			name:       "invalid from",
+			query:      "?from=yesterday",
+			setup:      func(_ *mockLocationUsecase) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "invalid range",
+			query: "?from=2026-10-16T12:00:00Z&to=2026-10-16T11:00:00Z",
+			setup: func(m *mockLocationUsecase) {
+				m.trailFn = func(ctx context.Context, courierId int, from, to time.Time) ([]*model.LocationPing, error) {
+					return nil, usecase.ErrInvalidTimeRange
+				}
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "success",
+			query: "?from=2026-10-16T11:00:00Z&to=2026-10-16T12:00:00Z",
+			setup: func(m *mockLocationUsecase) {
+				m.trailFn = func(ctx context.Context, courierId int, from, to time.Time) ([]*model.LocationPing, error) {
+					if !from.Equal(time.Date(2026, time.October, 16, 11, 0, 0, 0, time.UTC)) || !to.Equal(time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC)) {
+						m.t.Fatalf("unexpected range: %s - %s", from, to)
+					}
+					return []*model.LocationPing{{CourierID: courierId}, {CourierID: courierId}}, nil
+				}
+			},
+			wantStatus: http.StatusOK,
+			wantPoints: 2,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/couriers/1/trail"+tc.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("1")
+
+			uc := newMockLocationUsecase(t)
+			tc.setup(uc)
+			handler := NewLocationHandler(uc)
+
+			if err := handler.Trail(c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d", tc.wantStatus, rec.Code)
+			}
+			if tc.wantStatus == http.StatusOK {
+				var resp trailResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if resp.CourierID != 1 || len(resp.Points) != tc.wantPoints {
+					t.Fatalf("unexpected response: %+v", resp)
+				}
+			}
+		})
+	}
+}
@@ -7,6 +7,15 @@ import (
 )
 
 type deliveryUsecase interface {
-	Assign(ctx context.Context, orderID string) (*model.DeliveryModel, *model.CourierModel, error)
+	AssignCourier(ctx context.Context, req model.AssignCourierRequest) (*model.DeliveryModel, *model.CourierModel, error)
 	Unassign(ctx context.Context, orderID string) (*model.DeliveryModel, error)
+	Reassign(ctx context.Context, orderID string, toCourierID *int, reason string) (*model.DeliveryModel, *model.CourierModel, error)
+	PickUp(ctx context.Context, orderID string) (*model.DeliveryModel, error)
+	StartTransit(ctx context.Context, orderID string) (*model.DeliveryModel, error)
+	Complete(ctx context.Context, orderID string) (*model.DeliveryModel, error)
+	History(ctx context.Context, orderID string) ([]*model.DeliveryEvent, error)
+	PendingStats(ctx context.Context) (*model.PendingQueueStats, error)
+	Get(ctx context.Context, orderID string) (*model.DeliveryModel, error)
+	List(ctx context.Context, filter model.DeliveryFilter) (*model.DeliveryPage, error)
+	ListByCourier(ctx context.Context, courierID int, filter model.DeliveryFilter) (*model.DeliveryPage, error)
 }
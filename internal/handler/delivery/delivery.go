@@ -1,15 +1,25 @@
 package delivery
 
 import (
+	"context"
 	"errors"
 	"net/http"
 
 	handlerErrors "github.com/cdxy1/go-courier-service/internal/handler/errors"
+	"github.com/cdxy1/go-courier-service/internal/model"
 	courierRepo "github.com/cdxy1/go-courier-service/internal/repository/courier"
 	deliveryRepo "github.com/cdxy1/go-courier-service/internal/repository/delivery"
+	usecase "github.com/cdxy1/go-courier-service/internal/usecase/delivery"
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	// headerActor carries the identifier of the operator or app performing the request.
+	headerActor = "X-Actor"
+	// headerIdempotencyKey lets clients retry an assignment without creating a second delivery.
+	headerIdempotencyKey = "Idempotency-Key"
+)
+
 type DeliveryHandler struct {
 	uc deliveryUsecase
 }
@@ -25,11 +35,57 @@ func (h *DeliveryHandler) Assign(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": handlerErrors.ErrBadRequest.Error()})
 	}
 
-	delivery, courier, err := h.uc.Assign(c.Request().Context(), orderIdRequest.OrderId)
+	req := model.AssignCourierRequest{
+		OrderID:        orderIdRequest.OrderId,
+		Priority:       orderIdRequest.Priority,
+		IdempotencyKey: c.Request().Header.Get(headerIdempotencyKey),
+	}
+	if orderIdRequest.Pickup != nil {
+		if !orderIdRequest.Pickup.Valid() {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": handlerErrors.ErrBadRequest.Error()})
+		}
+		req.Pickup = orderIdRequest.Pickup
+	}
+	if orderIdRequest.Dropoff != nil {
+		if !orderIdRequest.Dropoff.Valid() {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": handlerErrors.ErrBadRequest.Error()})
+		}
+		req.Dropoff = orderIdRequest.Dropoff
+	}
+	if orderIdRequest.Order != nil {
+		if !orderIdRequest.Order.Valid() {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": handlerErrors.ErrBadRequest.Error()})
+		}
+		req.Order = orderIdRequest.Order
+	}
+	if orderIdRequest.PromisedBy != nil {
+		if orderIdRequest.PromisedBy.IsZero() {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": handlerErrors.ErrBadRequest.Error()})
+		}
+		req.PromisedBy = orderIdRequest.PromisedBy
+	}
+	if orderIdRequest.CourierId != nil {
+		if *orderIdRequest.CourierId <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": handlerErrors.ErrBadRequest.Error()})
+		}
+		req.CourierID = *orderIdRequest.CourierId
+	}
+
+	delivery, courier, err := h.uc.AssignCourier(eventContext(c, ""), req)
 	if err != nil {
+		if errors.Is(err, usecase.ErrAssignmentQueued) {
+			return c.JSON(http.StatusAccepted, &queuedResponse{OrderId: req.OrderID, Status: "queued"})
+		}
 		if errors.Is(err, courierRepo.ErrCourierNotFound) || errors.Is(err, deliveryRepo.ErrDeliveryNotFound) {
 			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
 		}
+		if errors.Is(err, usecase.ErrCourierUnavailable) || errors.Is(err, usecase.ErrOrderAlreadyAssigned) ||
+			errors.Is(err, usecase.ErrTransportNotAllowed) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, usecase.ErrIdempotencyKeyReused) || errors.Is(err, usecase.ErrNoEligibleTransport) {
+			return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
 	}
 
@@ -38,6 +94,7 @@ func (h *DeliveryHandler) Assign(c echo.Context) error {
 		OrderID:          delivery.OrderId,
 		TransportType:    courier.TransportType,
 		DeliveryDeadline: delivery.Deadline,
+		AtRisk:           delivery.AtRisk,
 	}
 
 	return c.JSON(http.StatusOK, assignResponse)
@@ -50,12 +107,9 @@ func (h *DeliveryHandler) Unassign(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": handlerErrors.ErrBadRequest.Error()})
 	}
 
-	unassignResult, err := h.uc.Unassign(c.Request().Context(), orderIdRequest.OrderId)
+	unassignResult, err := h.uc.Unassign(eventContext(c, orderIdRequest.Reason), orderIdRequest.OrderId)
 	if err != nil {
-		if errors.Is(err, deliveryRepo.ErrDeliveryNotFound) {
-			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
-		}
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return lifecycleError(c, err)
 	}
 
 	unassignResponse := &unassignResponse{
@@ -66,3 +120,153 @@ func (h *DeliveryHandler) Unassign(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, unassignResponse)
 }
+
+func (h *DeliveryHandler) Reassign(c echo.Context) error {
+	var req reassignRequest
+
+	if err := c.Bind(&req); err != nil || req.OrderId == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": handlerErrors.ErrBadRequest.Error()})
+	}
+	if req.CourierId != nil && *req.CourierId <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": handlerErrors.ErrBadRequest.Error()})
+	}
+
+	delivery, courier, err := h.uc.Reassign(eventContext(c, req.Reason), req.OrderId, req.CourierId, req.Reason)
+	if err != nil {
+		if errors.Is(err, courierRepo.ErrCourierNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, usecase.ErrCourierUnavailable) || errors.Is(err, usecase.ErrSameCourier) ||
+			errors.Is(err, usecase.ErrTransportNotAllowed) || errors.Is(err, usecase.ErrNoCourierAvailable) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, usecase.ErrNoEligibleTransport) {
+			return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		}
+		return lifecycleError(c, err)
+	}
+
+	response := &reassignResponse{
+		OrderID:          delivery.OrderId,
+		CourierId:        courier.ID,
+		TransportType:    courier.TransportType,
+		DeliveryDeadline: delivery.Deadline,
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+func (h *DeliveryHandler) Queue(c echo.Context) error {
+	stats, err := h.uc.PendingStats(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, &queueStatsResponse{
+		Depth:             stats.Depth,
+		OldestEnqueuedAt:  stats.OldestEnqueuedAt,
+		OldestWaitSeconds: stats.OldestWait.Seconds(),
+	})
+}
+
+func (h *DeliveryHandler) History(c echo.Context) error {
+	orderId := c.Param("order_id")
+	if orderId == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": handlerErrors.ErrBadRequest.Error()})
+	}
+
+	events, err := h.uc.History(c.Request().Context(), orderId)
+	if err != nil {
+		if errors.Is(err, deliveryRepo.ErrDeliveryNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": deliveryRepo.ErrDeliveryNotFound.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	response := &historyResponse{
+		OrderId: orderId,
+		Events:  make([]*deliveryEventResponse, 0, len(events)),
+	}
+	for _, event := range events {
+		response.Events = append(response.Events, &deliveryEventResponse{
+			ID:         event.ID,
+			DeliveryId: event.DeliveryID,
+			CourierId:  event.CourierID,
+			Type:       event.Type,
+			FromStatus: event.FromStatus,
+			ToStatus:   event.ToStatus,
+			Source:     event.Source,
+			Actor:      event.Actor,
+			Reason:     event.Reason,
+			CreatedAt:  event.CreatedAt,
+		})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// eventContext tags the request context as an HTTP-triggered change so it is
+// attributed correctly in the delivery history.
+func eventContext(c echo.Context, reason string) context.Context {
+	return model.WithEventTrigger(c.Request().Context(), model.EventTrigger{
+		Source: model.EventSourceHTTP,
+		Actor:  c.Request().Header.Get(headerActor),
+		Reason: reason,
+	})
+}
+
+func (h *DeliveryHandler) PickUp(c echo.Context) error {
+	var req pickupRequest
+
+	if err := c.Bind(&req); err != nil || req.OrderId == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": handlerErrors.ErrBadRequest.Error()})
+	}
+
+	delivery, err := h.uc.PickUp(eventContext(c, ""), req.OrderId)
+	if err != nil {
+		return lifecycleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, newDeliveryResponse(delivery))
+}
+
+func (h *DeliveryHandler) StartTransit(c echo.Context) error {
+	var req inTransitRequest
+
+	if err := c.Bind(&req); err != nil || req.OrderId == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": handlerErrors.ErrBadRequest.Error()})
+	}
+
+	delivery, err := h.uc.StartTransit(eventContext(c, ""), req.OrderId)
+	if err != nil {
+		return lifecycleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, newDeliveryResponse(delivery))
+}
+
+func (h *DeliveryHandler) Complete(c echo.Context) error {
+	var req completeRequest
+
+	if err := c.Bind(&req); err != nil || req.OrderId == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": handlerErrors.ErrBadRequest.Error()})
+	}
+
+	delivery, err := h.uc.Complete(eventContext(c, ""), req.OrderId)
+	if err != nil {
+		return lifecycleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, newDeliveryResponse(delivery))
+}
+
+// lifecycleError maps errors of delivery status changes to HTTP responses.
+func lifecycleError(c echo.Context, err error) error {
+	if errors.Is(err, deliveryRepo.ErrDeliveryNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": deliveryRepo.ErrDeliveryNotFound.Error()})
+	}
+	if errors.Is(err, usecase.ErrInvalidStatusTransition) {
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+}
@@ -4,33 +4,46 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	handlerErrors "github.com/cdxy1/go-courier-service/internal/handler/errors"
 	"github.com/cdxy1/go-courier-service/internal/model"
+	deliveryRepo "github.com/cdxy1/go-courier-service/internal/repository/delivery"
+	usecase "github.com/cdxy1/go-courier-service/internal/usecase/delivery"
 	"github.com/labstack/echo/v4"
 )
 
 var errBoom = errors.New("failed")
 
 type mockDeliveryUsecase struct {
-	t          *testing.T
-	assignFn   func(ctx context.Context, orderID string) (*model.DeliveryModel, *model.CourierModel, error)
-	unassignFn func(ctx context.Context, orderID string) (*model.DeliveryModel, error)
+	t               *testing.T
+	assignFn        func(ctx context.Context, req model.AssignCourierRequest) (*model.DeliveryModel, *model.CourierModel, error)
+	unassignFn      func(ctx context.Context, orderID string) (*model.DeliveryModel, error)
+	historyFn       func(ctx context.Context, orderID string) ([]*model.DeliveryEvent, error)
+	getFn           func(ctx context.Context, orderID string) (*model.DeliveryModel, error)
+	listFn          func(ctx context.Context, filter model.DeliveryFilter) (*model.DeliveryPage, error)
+	listByCourierFn func(ctx context.Context, courierID int, filter model.DeliveryFilter) (*model.DeliveryPage, error)
+	reassignFn      func(ctx context.Context, orderID string, toCourierID *int, reason string) (*model.DeliveryModel, *model.CourierModel, error)
+	pickUpFn        func(ctx context.Context, orderID string) (*model.DeliveryModel, error)
+	inTransitFn     func(ctx context.Context, orderID string) (*model.DeliveryModel, error)
+	completeFn      func(ctx context.Context, orderID string) (*model.DeliveryModel, error)
+	pendingStatsFn  func(ctx context.Context) (*model.PendingQueueStats, error)
 }
 
 func newMockDeliveryUsecase(t *testing.T) *mockDeliveryUsecase {
 	return &mockDeliveryUsecase{t: t}
 }
 
-func (m *mockDeliveryUsecase) Assign(ctx context.Context, orderID string) (*model.DeliveryModel, *model.CourierModel, error) {
+func (m *mockDeliveryUsecase) AssignCourier(ctx context.Context, req model.AssignCourierRequest) (*model.DeliveryModel, *model.CourierModel, error) {
 	if m.assignFn == nil {
-		m.t.Fatalf("Assign called unexpectedly")
+		m.t.Fatalf("AssignCourier called unexpectedly")
 	}
-	return m.assignFn(ctx, orderID)
+	return m.assignFn(ctx, req)
 }
 
 func (m *mockDeliveryUsecase) Unassign(ctx context.Context, orderID string) (*model.DeliveryModel, error) {
@@ -40,16 +53,81 @@ func (m *mockDeliveryUsecase) Unassign(ctx context.Context, orderID string) (*mo
 	return m.unassignFn(ctx, orderID)
 }
 
+func (m *mockDeliveryUsecase) History(ctx context.Context, orderID string) ([]*model.DeliveryEvent, error) {
+	if m.historyFn == nil {
+		m.t.Fatalf("History called unexpectedly")
+	}
+	return m.historyFn(ctx, orderID)
+}
+
+func (m *mockDeliveryUsecase) Get(ctx context.Context, orderID string) (*model.DeliveryModel, error) {
+	if m.getFn == nil {
+		m.t.Fatalf("Get called unexpectedly")
+	}
+	return m.getFn(ctx, orderID)
+}
+
+func (m *mockDeliveryUsecase) List(ctx context.Context, filter model.DeliveryFilter) (*model.DeliveryPage, error) {
+	if m.listFn == nil {
+		m.t.Fatalf("List called unexpectedly")
+	}
+	return m.listFn(ctx, filter)
+}
+
+func (m *mockDeliveryUsecase) ListByCourier(ctx context.Context, courierID int, filter model.DeliveryFilter) (*model.DeliveryPage, error) {
+	if m.listByCourierFn == nil {
+		m.t.Fatalf("ListByCourier called unexpectedly")
+	}
+	return m.listByCourierFn(ctx, courierID, filter)
+}
+
+func (m *mockDeliveryUsecase) Reassign(ctx context.Context, orderID string, toCourierID *int, reason string) (*model.DeliveryModel, *model.CourierModel, error) {
+	if m.reassignFn == nil {
+		m.t.Fatalf("Reassign called unexpectedly")
+	}
+	return m.reassignFn(ctx, orderID, toCourierID, reason)
+}
+
+func (m *mockDeliveryUsecase) PickUp(ctx context.Context, orderID string) (*model.DeliveryModel, error) {
+	if m.pickUpFn == nil {
+		m.t.Fatalf("PickUp called unexpectedly")
+	}
+	return m.pickUpFn(ctx, orderID)
+}
+
+func (m *mockDeliveryUsecase) StartTransit(ctx context.Context, orderID string) (*model.DeliveryModel, error) {
+	if m.inTransitFn == nil {
+		m.t.Fatalf("StartTransit called unexpectedly")
+	}
+	return m.inTransitFn(ctx, orderID)
+}
+
+func (m *mockDeliveryUsecase) Complete(ctx context.Context, orderID string) (*model.DeliveryModel, error) {
+	if m.completeFn == nil {
+		m.t.Fatalf("Complete called unexpectedly")
+	}
+	return m.completeFn(ctx, orderID)
+}
+
+func (m *mockDeliveryUsecase) PendingStats(ctx context.Context) (*model.PendingQueueStats, error) {
+	if m.pendingStatsFn == nil {
+		m.t.Fatalf("PendingStats called unexpectedly")
+	}
+	return m.pendingStatsFn(ctx)
+}
+
 func TestDeliveryHandler_Assign(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
 		name       string
 		body       string
+		key        string
 		setup      func(*mockDeliveryUsecase)
 		wantStatus int
 		wantErr    string
 		wantResp   *assignResponse
+		wantQueued bool
 	}{
 		{
 			name:       "invalid body",
@@ -69,9 +147,9 @@ func TestDeliveryHandler_Assign(t *testing.T) {
 			name: "usecase error",
 			body: `{"order_id":"order-1"}`,
 			setup: func(uc *mockDeliveryUsecase) {
-				uc.assignFn = func(ctx context.Context, orderID string) (*model.DeliveryModel, *model.CourierModel, error) {
-					if orderID != "order-1" {
-						uc.t.Fatalf("unexpected orderID: %s", orderID)
+				uc.assignFn = func(ctx context.Context, req model.AssignCourierRequest) (*model.DeliveryModel, *model.CourierModel, error) {
+					if req.OrderID != "order-1" {
+						uc.t.Fatalf("unexpected orderID: %s", req.OrderID)
 					}
 					return nil, nil, errBoom
 				}
@@ -79,12 +157,177 @@ func TestDeliveryHandler_Assign(t *testing.T) {
 			wantStatus: http.StatusInternalServerError,
 			wantErr:    "internal server error",
 		},
+		{
+			name:       "invalid courier id",
+			body:       `{"order_id":"order-1","courier_id":0}`,
+			setup:      func(_ *mockDeliveryUsecase) {},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    handlerErrors.ErrBadRequest.Error(),
+		},
+		{
+			name: "courier busy",
+			body: `{"order_id":"order-1","courier_id":11}`,
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.assignFn = func(ctx context.Context, req model.AssignCourierRequest) (*model.DeliveryModel, *model.CourierModel, error) {
+					return nil, nil, fmt.Errorf("%w: courier 11 is busy", usecase.ErrCourierUnavailable)
+				}
+			},
+			wantStatus: http.StatusConflict,
+			wantErr:    usecase.ErrCourierUnavailable.Error() + ": courier 11 is busy",
+		},
+		{
+			name: "order assigned to another courier",
+			body: `{"order_id":"order-1","courier_id":11}`,
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.assignFn = func(ctx context.Context, req model.AssignCourierRequest) (*model.DeliveryModel, *model.CourierModel, error) {
+					return nil, nil, fmt.Errorf("%w: courier 4", usecase.ErrOrderAlreadyAssigned)
+				}
+			},
+			wantStatus: http.StatusConflict,
+			wantErr:    usecase.ErrOrderAlreadyAssigned.Error() + ": courier 4",
+		},
+		{
+			name: "idempotency key reused",
+			body: `{"order_id":"order-1"}`,
+			key:  "key-1",
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.assignFn = func(ctx context.Context, req model.AssignCourierRequest) (*model.DeliveryModel, *model.CourierModel, error) {
+					return nil, nil, fmt.Errorf("%w: order-2", usecase.ErrIdempotencyKeyReused)
+				}
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantErr:    usecase.ErrIdempotencyKeyReused.Error() + ": order-2",
+		},
+		{
+			name: "idempotency key passed",
+			body: `{"order_id":"order-1"}`,
+			key:  "key-1",
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.assignFn = func(ctx context.Context, req model.AssignCourierRequest) (*model.DeliveryModel, *model.CourierModel, error) {
+					if req.IdempotencyKey != "key-1" {
+						uc.t.Fatalf("unexpected idempotency key: %q", req.IdempotencyKey)
+					}
+					return &model.DeliveryModel{OrderId: req.OrderID, CourierId: 11}, &model.CourierModel{ID: 11, TransportType: model.TransportCar}, nil
+				}
+			},
+			wantStatus: http.StatusOK,
+			wantResp:   &assignResponse{CourierId: 11, OrderID: "order-1", TransportType: model.TransportCar},
+		},
+		{
+			name:       "invalid pickup",
+			body:       `{"order_id":"order-1","pickup":{"lat":91,"lon":37.6}}`,
+			setup:      func(_ *mockDeliveryUsecase) {},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    handlerErrors.ErrBadRequest.Error(),
+		},
+		{
+			name:       "invalid dropoff",
+			body:       `{"order_id":"order-1","dropoff":{"lat":55.7,"lon":181}}`,
+			setup:      func(_ *mockDeliveryUsecase) {},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    handlerErrors.ErrBadRequest.Error(),
+		},
+		{
+			name: "pickup and dropoff passed",
+			body: `{"order_id":"order-1","pickup":{"lat":55.7558,"lon":37.6173},"dropoff":{"lat":55.7,"lon":37.5}}`,
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.assignFn = func(ctx context.Context, req model.AssignCourierRequest) (*model.DeliveryModel, *model.CourierModel, error) {
+					if req.Pickup == nil || req.Pickup.Lat != 55.7558 || req.Pickup.Lon != 37.6173 {
+						uc.t.Fatalf("unexpected pickup: %+v", req.Pickup)
+					}
+					if req.Dropoff == nil || req.Dropoff.Lat != 55.7 || req.Dropoff.Lon != 37.5 {
+						uc.t.Fatalf("unexpected dropoff: %+v", req.Dropoff)
+					}
+					return &model.DeliveryModel{OrderId: req.OrderID, CourierId: 11}, &model.CourierModel{ID: 11, TransportType: model.TransportCar}, nil
+				}
+			},
+			wantStatus: http.StatusOK,
+			wantResp:   &assignResponse{CourierId: 11, OrderID: "order-1", TransportType: model.TransportCar},
+		},
+		{
+			name:       "invalid order details",
+			body:       `{"order_id":"order-1","order":{"item_count":-1}}`,
+			setup:      func(_ *mockDeliveryUsecase) {},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    handlerErrors.ErrBadRequest.Error(),
+		},
+		{
+			name: "no eligible transport",
+			body: `{"order_id":"order-1","order":{"item_count":12,"quantity":14,"total_price":540000,"weight_grams":9000}}`,
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.assignFn = func(ctx context.Context, req model.AssignCourierRequest) (*model.DeliveryModel, *model.CourierModel, error) {
+					expected := model.OrderDetails{ItemCount: 12, Quantity: 14, TotalPrice: 540000, WeightGrams: 9000}
+					if req.Order == nil || *req.Order != expected {
+						uc.t.Fatalf("unexpected order details: %+v", req.Order)
+					}
+					return nil, nil, usecase.ErrNoEligibleTransport
+				}
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantErr:    usecase.ErrNoEligibleTransport.Error(),
+		},
+		{
+			name: "courier transport not allowed",
+			body: `{"order_id":"order-1","courier_id":11,"order":{"item_count":12}}`,
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.assignFn = func(ctx context.Context, req model.AssignCourierRequest) (*model.DeliveryModel, *model.CourierModel, error) {
+					return nil, nil, usecase.ErrTransportNotAllowed
+				}
+			},
+			wantStatus: http.StatusConflict,
+			wantErr:    usecase.ErrTransportNotAllowed.Error(),
+		},
+		{
+			name: "queued",
+			body: `{"order_id":"order-1","priority":5}`,
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.assignFn = func(ctx context.Context, req model.AssignCourierRequest) (*model.DeliveryModel, *model.CourierModel, error) {
+					if req.Priority != 5 {
+						uc.t.Fatalf("unexpected priority: %d", req.Priority)
+					}
+					return nil, nil, fmt.Errorf("%w: %s", usecase.ErrAssignmentQueued, req.OrderID)
+				}
+			},
+			wantStatus: http.StatusAccepted,
+			wantQueued: true,
+		},
+		{
+			name: "promise at risk",
+			body: `{"order_id":"order-1","promised_by":"2025-12-22T11:00:00Z"}`,
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.assignFn = func(ctx context.Context, req model.AssignCourierRequest) (*model.DeliveryModel, *model.CourierModel, error) {
+					if req.PromisedBy == nil || !req.PromisedBy.Equal(time.Date(2025, time.December, 22, 11, 0, 0, 0, time.UTC)) {
+						uc.t.Fatalf("unexpected promise: %v", req.PromisedBy)
+					}
+					return &model.DeliveryModel{OrderId: req.OrderID, CourierId: 11, AtRisk: true}, &model.CourierModel{ID: 11, TransportType: model.TransportCar}, nil
+				}
+			},
+			wantStatus: http.StatusOK,
+			wantResp:   &assignResponse{CourierId: 11, OrderID: "order-1", TransportType: model.TransportCar, AtRisk: true},
+		},
+		{
+			name: "manual success",
+			body: `{"order_id":"order-1","courier_id":11}`,
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.assignFn = func(ctx context.Context, req model.AssignCourierRequest) (*model.DeliveryModel, *model.CourierModel, error) {
+					if req.CourierID != 11 {
+						uc.t.Fatalf("unexpected courier id: %d", req.CourierID)
+					}
+					return &model.DeliveryModel{OrderId: req.OrderID, CourierId: 11}, &model.CourierModel{ID: 11, TransportType: model.TransportCar}, nil
+				}
+			},
+			wantStatus: http.StatusOK,
+			wantResp:   &assignResponse{CourierId: 11, OrderID: "order-1", TransportType: model.TransportCar},
+		},
 		{
 			name: "success",
 			body: `{"order_id":"order-1"}`,
 			setup: func(uc *mockDeliveryUsecase) {
-				uc.assignFn = func(ctx context.Context, orderID string) (*model.DeliveryModel, *model.CourierModel, error) {
-					return &model.DeliveryModel{OrderId: orderID, CourierId: 11}, &model.CourierModel{ID: 11, TransportType: model.TransportCar}, nil
+				uc.assignFn = func(ctx context.Context, req model.AssignCourierRequest) (*model.DeliveryModel, *model.CourierModel, error) {
+					if req.CourierID != 0 {
+						uc.t.Fatalf("unexpected courier id: %d", req.CourierID)
+					}
+					return &model.DeliveryModel{OrderId: req.OrderID, CourierId: 11}, &model.CourierModel{ID: 11, TransportType: model.TransportCar}, nil
 				}
 			},
 			wantStatus: http.StatusOK,
@@ -99,6 +342,9 @@ func TestDeliveryHandler_Assign(t *testing.T) {
 			e := echo.New()
 			req := httptest.NewRequest(http.MethodPost, "/delivery/assign", strings.NewReader(tt.body))
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.key != "" {
+				req.Header.Set(headerIdempotencyKey, tt.key)
+			}
 			rec := httptest.NewRecorder()
 			c := e.NewContext(req, rec)
 
@@ -122,6 +368,14 @@ func TestDeliveryHandler_Assign(t *testing.T) {
 				if resp.CourierId != tt.wantResp.CourierId || resp.OrderID != tt.wantResp.OrderID || resp.TransportType != tt.wantResp.TransportType {
 					t.Fatalf("unexpected response: %+v", resp)
 				}
+			} else if tt.wantQueued {
+				var resp queuedResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if resp.OrderId != "order-1" || resp.Status != "queued" {
+					t.Fatalf("unexpected response: %+v", resp)
+				}
 			} else {
 				var resp map[string]string
 				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
@@ -160,6 +414,17 @@ func TestDeliveryHandler_Unassign(t *testing.T) {
 			wantStatus: http.StatusBadRequest,
 			wantErr:    handlerErrors.ErrBadRequest.Error(),
 		},
+		{
+			name: "already finished",
+			body: `{"order_id":"order-2"}`,
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.unassignFn = func(ctx context.Context, orderID string) (*model.DeliveryModel, error) {
+					return nil, fmt.Errorf("%w: delivered -> cancelled", usecase.ErrInvalidStatusTransition)
+				}
+			},
+			wantStatus: http.StatusConflict,
+			wantErr:    usecase.ErrInvalidStatusTransition.Error() + ": delivered -> cancelled",
+		},
 		{
 			name: "usecase error",
 			body: `{"order_id":"order-2"}`,
@@ -226,3 +491,467 @@ func TestDeliveryHandler_Unassign(t *testing.T) {
 		})
 	}
 }
+
+func TestDeliveryHandler_Reassign(t *testing.T) {
+	t.Parallel()
+
+	deadline := time.Date(2025, time.December, 22, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		body       string
+		setup      func(*mockDeliveryUsecase)
+		wantStatus int
+		wantErr    string
+		wantResp   *reassignResponse
+	}{
+		{
+			name:       "empty order id",
+			body:       `{"order_id":""}`,
+			setup:      func(_ *mockDeliveryUsecase) {},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    handlerErrors.ErrBadRequest.Error(),
+		},
+		{
+			name:       "invalid courier id",
+			body:       `{"order_id":"order-3","courier_id":0}`,
+			setup:      func(_ *mockDeliveryUsecase) {},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    handlerErrors.ErrBadRequest.Error(),
+		},
+		{
+			name: "delivery not found",
+			body: `{"order_id":"order-3"}`,
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.reassignFn = func(ctx context.Context, orderID string, toCourierID *int, reason string) (*model.DeliveryModel, *model.CourierModel, error) {
+					return nil, nil, fmt.Errorf("get delivery: %w", deliveryRepo.ErrDeliveryNotFound)
+				}
+			},
+			wantStatus: http.StatusNotFound,
+			wantErr:    deliveryRepo.ErrDeliveryNotFound.Error(),
+		},
+		{
+			name: "same courier",
+			body: `{"order_id":"order-3","courier_id":4}`,
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.reassignFn = func(ctx context.Context, orderID string, toCourierID *int, reason string) (*model.DeliveryModel, *model.CourierModel, error) {
+					return nil, nil, usecase.ErrSameCourier
+				}
+			},
+			wantStatus: http.StatusConflict,
+			wantErr:    usecase.ErrSameCourier.Error(),
+		},
+		{
+			name: "no other courier available",
+			body: `{"order_id":"order-3"}`,
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.reassignFn = func(ctx context.Context, orderID string, toCourierID *int, reason string) (*model.DeliveryModel, *model.CourierModel, error) {
+					return nil, nil, fmt.Errorf("%w: order %s", usecase.ErrNoCourierAvailable, orderID)
+				}
+			},
+			wantStatus: http.StatusConflict,
+			wantErr:    usecase.ErrNoCourierAvailable.Error() + ": order order-3",
+		},
+		{
+			name: "usecase error",
+			body: `{"order_id":"order-3"}`,
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.reassignFn = func(ctx context.Context, orderID string, toCourierID *int, reason string) (*model.DeliveryModel, *model.CourierModel, error) {
+					return nil, nil, errBoom
+				}
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantErr:    "internal server error",
+		},
+		{
+			name: "success",
+			body: `{"order_id":"order-3","courier_id":9,"reason":"scooter broke down"}`,
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.reassignFn = func(ctx context.Context, orderID string, toCourierID *int, reason string) (*model.DeliveryModel, *model.CourierModel, error) {
+					if toCourierID == nil || *toCourierID != 9 {
+						t.Fatalf("unexpected courier id: %v", toCourierID)
+					}
+					if reason != "scooter broke down" {
+						t.Fatalf("unexpected reason: %q", reason)
+					}
+					return &model.DeliveryModel{OrderId: orderID, CourierId: 9, Deadline: deadline},
+						&model.CourierModel{ID: 9, TransportType: model.TransportCar}, nil
+				}
+			},
+			wantStatus: http.StatusOK,
+			wantResp: &reassignResponse{
+				OrderID:          "order-3",
+				CourierId:        9,
+				TransportType:    model.TransportCar,
+				DeliveryDeadline: deadline,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/delivery/reassign", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			uc := newMockDeliveryUsecase(t)
+			tt.setup(uc)
+			handler := NewDeliveryHandler(uc)
+
+			if err := handler.Reassign(c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+
+			if tt.wantResp != nil {
+				var resp reassignResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if resp.OrderID != tt.wantResp.OrderID || resp.CourierId != tt.wantResp.CourierId ||
+					resp.TransportType != tt.wantResp.TransportType || !resp.DeliveryDeadline.Equal(tt.wantResp.DeliveryDeadline) {
+					t.Fatalf("unexpected response: %+v", resp)
+				}
+			} else {
+				var resp map[string]string
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if resp["error"] != tt.wantErr {
+					t.Fatalf("expected error %q, got %q", tt.wantErr, resp["error"])
+				}
+			}
+		})
+	}
+}
+
+func TestDeliveryHandler_History(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2025, time.December, 22, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		orderID    string
+		setup      func(*mockDeliveryUsecase)
+		wantStatus int
+		wantErr    string
+		wantEvents int
+	}{
+		{
+			name:       "empty order id",
+			orderID:    "",
+			setup:      func(_ *mockDeliveryUsecase) {},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    handlerErrors.ErrBadRequest.Error(),
+		},
+		{
+			name:    "not found",
+			orderID: "order-3",
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.historyFn = func(ctx context.Context, orderID string) ([]*model.DeliveryEvent, error) {
+					return nil, deliveryRepo.ErrDeliveryNotFound
+				}
+			},
+			wantStatus: http.StatusNotFound,
+			wantErr:    deliveryRepo.ErrDeliveryNotFound.Error(),
+		},
+		{
+			name:    "usecase error",
+			orderID: "order-3",
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.historyFn = func(ctx context.Context, orderID string) ([]*model.DeliveryEvent, error) {
+					return nil, errBoom
+				}
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantErr:    "internal server error",
+		},
+		{
+			name:    "success",
+			orderID: "order-3",
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.historyFn = func(ctx context.Context, orderID string) ([]*model.DeliveryEvent, error) {
+					if orderID != "order-3" {
+						uc.t.Fatalf("unexpected orderID: %s", orderID)
+					}
+					return []*model.DeliveryEvent{
+						{ID: 1, OrderID: orderID, CourierID: 4, Type: model.DeliveryEventAssigned, ToStatus: model.DeliveryStatusAssigned, Source: model.EventSourceKafka, CreatedAt: createdAt},
+						{ID: 2, OrderID: orderID, CourierID: 4, Type: model.DeliveryEventExpired, FromStatus: model.DeliveryStatusAssigned, ToStatus: model.DeliveryStatusExpired, Source: model.EventSourceMonitor, CreatedAt: createdAt},
+					}, nil
+				}
+			},
+			wantStatus: http.StatusOK,
+			wantEvents: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/delivery/"+tt.orderID+"/history", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("order_id")
+			c.SetParamValues(tt.orderID)
+
+			uc := newMockDeliveryUsecase(t)
+			tt.setup(uc)
+			handler := NewDeliveryHandler(uc)
+
+			if err := handler.History(c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+
+			if tt.wantErr == "" {
+				var resp historyResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if resp.OrderId != tt.orderID || len(resp.Events) != tt.wantEvents {
+					t.Fatalf("unexpected response: %+v", resp)
+				}
+				if resp.Events[1].Source != model.EventSourceMonitor || resp.Events[1].FromStatus != model.DeliveryStatusAssigned {
+					t.Fatalf("unexpected event: %+v", resp.Events[1])
+				}
+			} else {
+				var resp map[string]string
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if resp["error"] != tt.wantErr {
+					t.Fatalf("expected error %q, got %q", tt.wantErr, resp["error"])
+				}
+			}
+		})
+	}
+}
+
+func TestDeliveryHandler_Queue(t *testing.T) {
+	t.Parallel()
+
+	oldest := time.Date(2025, time.December, 22, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		stats      *model.PendingQueueStats
+		statsErr   error
+		wantStatus int
+		wantResp   *queueStatsResponse
+	}{
+		{
+			name:       "usecase error",
+			statsErr:   errBoom,
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "empty queue",
+			stats:      &model.PendingQueueStats{},
+			wantStatus: http.StatusOK,
+			wantResp:   &queueStatsResponse{},
+		},
+		{
+			name:       "waiting orders",
+			stats:      &model.PendingQueueStats{Depth: 3, OldestEnqueuedAt: &oldest, OldestWait: time.Second * 90},
+			wantStatus: http.StatusOK,
+			wantResp:   &queueStatsResponse{Depth: 3, OldestEnqueuedAt: &oldest, OldestWaitSeconds: 90},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/delivery/queue", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			uc := newMockDeliveryUsecase(t)
+			uc.pendingStatsFn = func(ctx context.Context) (*model.PendingQueueStats, error) {
+				return tt.stats, tt.statsErr
+			}
+			handler := NewDeliveryHandler(uc)
+
+			if err := handler.Queue(c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantResp == nil {
+				return
+			}
+
+			var resp queueStatsResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Depth != tt.wantResp.Depth || resp.OldestWaitSeconds != tt.wantResp.OldestWaitSeconds {
+				t.Fatalf("unexpected response: %+v", resp)
+			}
+			if (resp.OldestEnqueuedAt == nil) != (tt.wantResp.OldestEnqueuedAt == nil) ||
+				(resp.OldestEnqueuedAt != nil && !resp.OldestEnqueuedAt.Equal(*tt.wantResp.OldestEnqueuedAt)) {
+				t.Fatalf("unexpected oldest enqueued at: %v", resp.OldestEnqueuedAt)
+			}
+		})
+	}
+}
+
+func TestDeliveryHandler_Lifecycle(t *testing.T) {
+	t.Parallel()
+
+	type call func(h *DeliveryHandler, c echo.Context) error
+
+	pickUp := func(h *DeliveryHandler, c echo.Context) error { return h.PickUp(c) }
+	inTransit := func(h *DeliveryHandler, c echo.Context) error { return h.StartTransit(c) }
+	complete := func(h *DeliveryHandler, c echo.Context) error { return h.Complete(c) }
+
+	tests := []struct {
+		name       string
+		call       call
+		body       string
+		setup      func(*mockDeliveryUsecase)
+		wantStatus int
+		wantState  model.DeliveryStatus
+	}{
+		{
+			name:       "pickup invalid body",
+			call:       pickUp,
+			body:       `{"order_id":""}`,
+			setup:      func(_ *mockDeliveryUsecase) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "pickup success",
+			call: pickUp,
+			body: `{"order_id":"order-4"}`,
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.pickUpFn = func(ctx context.Context, orderID string) (*model.DeliveryModel, error) {
+					if model.EventTriggerFromContext(ctx).Source != model.EventSourceHTTP {
+						uc.t.Fatalf("expected http event source")
+					}
+					return &model.DeliveryModel{OrderId: orderID, CourierId: 2, Status: model.DeliveryStatusPickedUp}, nil
+				}
+			},
+			wantStatus: http.StatusOK,
+			wantState:  model.DeliveryStatusPickedUp,
+		},
+		{
+			name: "in transit success",
+			call: inTransit,
+			body: `{"order_id":"order-4"}`,
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.inTransitFn = func(ctx context.Context, orderID string) (*model.DeliveryModel, error) {
+					return &model.DeliveryModel{OrderId: orderID, CourierId: 2, Status: model.DeliveryStatusInTransit}, nil
+				}
+			},
+			wantStatus: http.StatusOK,
+			wantState:  model.DeliveryStatusInTransit,
+		},
+		{
+			name: "in transit already finished",
+			call: inTransit,
+			body: `{"order_id":"order-4"}`,
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.inTransitFn = func(ctx context.Context, orderID string) (*model.DeliveryModel, error) {
+					return nil, fmt.Errorf("%w: delivered -> in_transit", usecase.ErrInvalidStatusTransition)
+				}
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name: "complete missing delivery",
+			call: complete,
+			body: `{"order_id":"order-4"}`,
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.completeFn = func(ctx context.Context, orderID string) (*model.DeliveryModel, error) {
+					return nil, fmt.Errorf("get delivery: %w", deliveryRepo.ErrDeliveryNotFound)
+				}
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "complete already finished",
+			call: complete,
+			body: `{"order_id":"order-4"}`,
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.completeFn = func(ctx context.Context, orderID string) (*model.DeliveryModel, error) {
+					return nil, fmt.Errorf("%w: cancelled -> delivered", usecase.ErrInvalidStatusTransition)
+				}
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name: "complete usecase error",
+			call: complete,
+			body: `{"order_id":"order-4"}`,
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.completeFn = func(ctx context.Context, orderID string) (*model.DeliveryModel, error) {
+					return nil, errBoom
+				}
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name: "complete success",
+			call: complete,
+			body: `{"order_id":"order-4"}`,
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.completeFn = func(ctx context.Context, orderID string) (*model.DeliveryModel, error) {
+					return &model.DeliveryModel{OrderId: orderID, CourierId: 2, Status: model.DeliveryStatusDelivered}, nil
+				}
+			},
+			wantStatus: http.StatusOK,
+			wantState:  model.DeliveryStatusDelivered,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/delivery/lifecycle", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			uc := newMockDeliveryUsecase(t)
+			tt.setup(uc)
+
+			if err := tt.call(NewDeliveryHandler(uc), c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantState != "" {
+				var resp deliveryResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if resp.Status != tt.wantState || resp.OrderId != "order-4" {
+					t.Fatalf("unexpected response: %+v", resp)
+				}
+			}
+		})
+	}
+}
@@ -3,11 +3,31 @@ package delivery
 import (
 	"time"
 
+	"github.com/cdxy1/go-courier-service/internal/geo"
 	"github.com/cdxy1/go-courier-service/internal/model"
 )
 
 type assignRequest struct {
+	OrderId   string     `json:"order_id"`
+	CourierId *int       `json:"courier_id"`
+	Priority  int        `json:"priority"`
+	Pickup    *geo.Point `json:"pickup"`
+	Dropoff   *geo.Point `json:"dropoff"`
+	// Order describes the contents of the order for transport eligibility.
+	Order *model.OrderDetails `json:"order"`
+	// PromisedBy is the delivery time promised to the customer.
+	PromisedBy *time.Time `json:"promised_by"`
+}
+
+type queuedResponse struct {
 	OrderId string `json:"order_id"`
+	Status  string `json:"status"`
+}
+
+type queueStatsResponse struct {
+	Depth             int        `json:"depth"`
+	OldestEnqueuedAt  *time.Time `json:"oldest_enqueued_at,omitempty"`
+	OldestWaitSeconds float64    `json:"oldest_wait_seconds"`
 }
 
 type assignResponse struct {
@@ -15,10 +35,12 @@ type assignResponse struct {
 	OrderID          string              `json:"order_id"`
 	TransportType    model.TransportType `json:"transport_type"`
 	DeliveryDeadline time.Time           `json:"delivery_deadline"`
+	AtRisk           bool                `json:"at_risk"`
 }
 
 type unassignRequest struct {
 	OrderId string `json:"order_id"`
+	Reason  string `json:"reason"`
 }
 
 type unassignResponse struct {
@@ -26,3 +48,102 @@ type unassignResponse struct {
 	Status    string `json:"status"`
 	CourierId int    `json:"courier_id"`
 }
+
+type reassignRequest struct {
+	OrderId   string `json:"order_id"`
+	CourierId *int   `json:"courier_id"`
+	Reason    string `json:"reason"`
+}
+
+type reassignResponse struct {
+	OrderID          string              `json:"order_id"`
+	CourierId        int                 `json:"courier_id"`
+	TransportType    model.TransportType `json:"transport_type"`
+	DeliveryDeadline time.Time           `json:"delivery_deadline"`
+}
+
+type pickupRequest struct {
+	OrderId string `json:"order_id"`
+}
+
+type inTransitRequest struct {
+	OrderId string `json:"order_id"`
+}
+
+type completeRequest struct {
+	OrderId string `json:"order_id"`
+}
+
+type historyResponse struct {
+	OrderId string                   `json:"order_id"`
+	Events  []*deliveryEventResponse `json:"events"`
+}
+
+type deliveryEventResponse struct {
+	ID         int                     `json:"id"`
+	DeliveryId int                     `json:"delivery_id"`
+	CourierId  int                     `json:"courier_id"`
+	Type       model.DeliveryEventType `json:"type"`
+	FromStatus model.DeliveryStatus    `json:"from_status,omitempty"`
+	ToStatus   model.DeliveryStatus    `json:"to_status"`
+	Source     model.EventSource       `json:"source"`
+	Actor      string                  `json:"actor,omitempty"`
+	Reason     string                  `json:"reason,omitempty"`
+	CreatedAt  time.Time               `json:"created_at"`
+}
+
+type deliveryResponse struct {
+	ID          int                  `json:"id"`
+	OrderId     string               `json:"order_id"`
+	CourierId   int                  `json:"courier_id"`
+	Status      model.DeliveryStatus `json:"status"`
+	AssignedAt  time.Time            `json:"assigned_at"`
+	Deadline    time.Time            `json:"deadline"`
+	PickedUpAt  *time.Time           `json:"picked_up_at,omitempty"`
+	InTransitAt *time.Time           `json:"in_transit_at,omitempty"`
+	DeliveredAt *time.Time           `json:"delivered_at,omitempty"`
+	CancelledAt *time.Time           `json:"cancelled_at,omitempty"`
+	ExpiredAt   *time.Time           `json:"expired_at,omitempty"`
+	PromisedBy  *time.Time           `json:"promised_by,omitempty"`
+	AtRisk      bool                 `json:"at_risk"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+}
+
+type deliveryListResponse struct {
+	Items  []*deliveryResponse `json:"items"`
+	Total  int                 `json:"total"`
+	Limit  int                 `json:"limit"`
+	Offset int                 `json:"offset"`
+}
+
+func newDeliveryResponse(d *model.DeliveryModel) *deliveryResponse {
+	return &deliveryResponse{
+		ID:          d.ID,
+		OrderId:     d.OrderId,
+		CourierId:   d.CourierId,
+		Status:      d.Status,
+		AssignedAt:  d.AssignedAt,
+		Deadline:    d.Deadline,
+		PickedUpAt:  d.PickedUpAt,
+		InTransitAt: d.InTransitAt,
+		DeliveredAt: d.DeliveredAt,
+		CancelledAt: d.CancelledAt,
+		ExpiredAt:   d.ExpiredAt,
+		PromisedBy:  d.PromisedBy,
+		AtRisk:      d.AtRisk,
+		UpdatedAt:   d.UpdatedAt,
+	}
+}
+
+func newDeliveryListResponse(page *model.DeliveryPage) *deliveryListResponse {
+	items := make([]*deliveryResponse, 0, len(page.Items))
+	for _, d := range page.Items {
+		items = append(items, newDeliveryResponse(d))
+	}
+	return &deliveryListResponse{
+		Items:  items,
+		Total:  page.Total,
+		Limit:  page.Limit,
+		Offset: page.Offset,
+	}
+}
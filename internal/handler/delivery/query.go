@@ -0,0 +1,131 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	handlerErrors "github.com/cdxy1/go-courier-service/internal/handler/errors"
+	"github.com/cdxy1/go-courier-service/internal/model"
+	courierRepo "github.com/cdxy1/go-courier-service/internal/repository/courier"
+	deliveryRepo "github.com/cdxy1/go-courier-service/internal/repository/delivery"
+	usecase "github.com/cdxy1/go-courier-service/internal/usecase/delivery"
+	"github.com/labstack/echo/v4"
+)
+
+func (h *DeliveryHandler) Get(c echo.Context) error {
+	orderId := c.Param("order_id")
+	if orderId == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": handlerErrors.ErrBadRequest.Error()})
+	}
+
+	delivery, err := h.uc.Get(c.Request().Context(), orderId)
+	if err != nil {
+		if errors.Is(err, deliveryRepo.ErrDeliveryNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": deliveryRepo.ErrDeliveryNotFound.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, newDeliveryResponse(delivery))
+}
+
+func (h *DeliveryHandler) List(c echo.Context) error {
+	filter, err := parseDeliveryFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": usecase.ErrInvalidFilter.Error()})
+	}
+
+	page, err := h.uc.List(c.Request().Context(), filter)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidFilter) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": usecase.ErrInvalidFilter.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, newDeliveryListResponse(page))
+}
+
+func (h *DeliveryHandler) ListByCourier(c echo.Context) error {
+	courierId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+
+	filter, err := parseDeliveryFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": usecase.ErrInvalidFilter.Error()})
+	}
+
+	page, err := h.uc.ListByCourier(c.Request().Context(), courierId, filter)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidFilter) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": usecase.ErrInvalidFilter.Error()})
+		}
+		if errors.Is(err, courierRepo.ErrCourierNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": courierRepo.ErrCourierNotFound.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, newDeliveryListResponse(page))
+}
+
+// parseDeliveryFilter reads the list filters from the query string:
+// courier_id, status (comma separated), assigned_from and assigned_to (RFC 3339),
+// overdue, at_risk, limit and offset.
+func parseDeliveryFilter(c echo.Context) (model.DeliveryFilter, error) {
+	var filter model.DeliveryFilter
+	var err error
+
+	if v := c.QueryParam("courier_id"); v != "" {
+		if filter.CourierID, err = strconv.Atoi(v); err != nil {
+			return filter, err
+		}
+	}
+	if v := c.QueryParam("status"); v != "" {
+		for _, status := range strings.Split(v, ",") {
+			if status = strings.TrimSpace(status); status != "" {
+				filter.Statuses = append(filter.Statuses, model.DeliveryStatus(status))
+			}
+		}
+	}
+	if v := c.QueryParam("assigned_from"); v != "" {
+		from, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, err
+		}
+		filter.AssignedFrom = &from
+	}
+	if v := c.QueryParam("assigned_to"); v != "" {
+		to, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, err
+		}
+		filter.AssignedTo = &to
+	}
+	if v := c.QueryParam("overdue"); v != "" {
+		if filter.OverdueOnly, err = strconv.ParseBool(v); err != nil {
+			return filter, err
+		}
+	}
+	if v := c.QueryParam("at_risk"); v != "" {
+		if filter.AtRiskOnly, err = strconv.ParseBool(v); err != nil {
+			return filter, err
+		}
+	}
+	if v := c.QueryParam("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil {
+			return filter, err
+		}
+	}
+	if v := c.QueryParam("offset"); v != "" {
+		if filter.Offset, err = strconv.Atoi(v); err != nil {
+			return filter, err
+		}
+	}
+	return filter, nil
+}
@@ -0,0 +1,248 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+	courierRepo "github.com/cdxy1/go-courier-service/internal/repository/courier"
+	deliveryRepo "github.com/cdxy1/go-courier-service/internal/repository/delivery"
+	usecase "github.com/cdxy1/go-courier-service/internal/usecase/delivery"
+	"github.com/labstack/echo/v4"
+)
+
+func TestDeliveryHandler_Get(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		setup      func(*mockDeliveryUsecase)
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name: "not found",
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.getFn = func(ctx context.Context, orderID string) (*model.DeliveryModel, error) {
+					return nil, deliveryRepo.ErrDeliveryNotFound
+				}
+			},
+			wantStatus: http.StatusNotFound,
+			wantErr:    deliveryRepo.ErrDeliveryNotFound.Error(),
+		},
+		{
+			name: "usecase error",
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.getFn = func(ctx context.Context, orderID string) (*model.DeliveryModel, error) {
+					return nil, errBoom
+				}
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantErr:    "internal server error",
+		},
+		{
+			name: "success",
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.getFn = func(ctx context.Context, orderID string) (*model.DeliveryModel, error) {
+					return &model.DeliveryModel{ID: 2, OrderId: orderID, CourierId: 5, Status: model.DeliveryStatusInTransit}, nil
+				}
+			},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/delivery/order-5", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("order_id")
+			c.SetParamValues("order-5")
+
+			uc := newMockDeliveryUsecase(t)
+			tt.setup(uc)
+
+			if err := NewDeliveryHandler(uc).Get(c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+
+			if tt.wantErr == "" {
+				var resp deliveryResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if resp.OrderId != "order-5" || resp.CourierId != 5 || resp.Status != model.DeliveryStatusInTransit {
+					t.Fatalf("unexpected response: %+v", resp)
+				}
+			} else {
+				var resp map[string]string
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if resp["error"] != tt.wantErr {
+					t.Fatalf("expected error %q, got %q", tt.wantErr, resp["error"])
+				}
+			}
+		})
+	}
+}
+
+func TestDeliveryHandler_List(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		query      string
+		setup      func(*mockDeliveryUsecase)
+		wantStatus int
+	}{
+		{
+			name:       "invalid courier id",
+			query:      "courier_id=abc",
+			setup:      func(_ *mockDeliveryUsecase) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid time",
+			query:      "assigned_from=yesterday",
+			setup:      func(_ *mockDeliveryUsecase) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "rejected by usecase",
+			query: "limit=1000",
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.listFn = func(ctx context.Context, filter model.DeliveryFilter) (*model.DeliveryPage, error) {
+					return nil, usecase.ErrInvalidFilter
+				}
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "filters parsed",
+			query: "courier_id=4&status=assigned,in_transit&assigned_from=2025-12-22T09:00:00Z&assigned_to=2025-12-22T11:00:00Z&overdue=true&at_risk=true&limit=10&offset=20",
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.listFn = func(ctx context.Context, filter model.DeliveryFilter) (*model.DeliveryPage, error) {
+					if filter.CourierID != 4 || len(filter.Statuses) != 2 || filter.Statuses[1] != model.DeliveryStatusInTransit {
+						uc.t.Fatalf("unexpected filter: %+v", filter)
+					}
+					if filter.AssignedFrom == nil || !filter.AssignedFrom.Equal(time.Date(2025, time.December, 22, 9, 0, 0, 0, time.UTC)) {
+						uc.t.Fatalf("unexpected assigned_from: %v", filter.AssignedFrom)
+					}
+					if filter.AssignedTo == nil || !filter.OverdueOnly || !filter.AtRiskOnly || filter.Limit != 10 || filter.Offset != 20 {
+						uc.t.Fatalf("unexpected filter: %+v", filter)
+					}
+					return &model.DeliveryPage{Items: []*model.DeliveryModel{{ID: 1}}, Total: 21, Limit: 10, Offset: 20}, nil
+				}
+			},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/delivery?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			uc := newMockDeliveryUsecase(t)
+			tt.setup(uc)
+
+			if err := NewDeliveryHandler(uc).List(c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantStatus == http.StatusOK {
+				var resp deliveryListResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if resp.Total != 21 || len(resp.Items) != 1 || resp.Offset != 20 {
+					t.Fatalf("unexpected response: %+v", resp)
+				}
+			}
+		})
+	}
+}
+
+func TestDeliveryHandler_ListByCourier(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		param      string
+		setup      func(*mockDeliveryUsecase)
+		wantStatus int
+	}{
+		{
+			name:       "invalid id",
+			param:      "abc",
+			setup:      func(_ *mockDeliveryUsecase) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "courier not found",
+			param: "8",
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.listByCourierFn = func(ctx context.Context, courierID int, filter model.DeliveryFilter) (*model.DeliveryPage, error) {
+					return nil, courierRepo.ErrCourierNotFound
+				}
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:  "success",
+			param: "8",
+			setup: func(uc *mockDeliveryUsecase) {
+				uc.listByCourierFn = func(ctx context.Context, courierID int, filter model.DeliveryFilter) (*model.DeliveryPage, error) {
+					if courierID != 8 {
+						uc.t.Fatalf("unexpected courier id: %d", courierID)
+					}
+					return &model.DeliveryPage{Items: []*model.DeliveryModel{}, Limit: 20}, nil
+				}
+			},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/couriers/"+tt.param+"/deliveries", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.param)
+
+			uc := newMockDeliveryUsecase(t)
+			tt.setup(uc)
+
+			if err := NewDeliveryHandler(uc).ListByCourier(c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package shift
+
+import (
+	"context"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+)
+
+type shiftUsecase interface {
+	GetShift(ctx context.Context, id int) (*model.Shift, error)
+	ListShifts(ctx context.Context, filter model.ShiftFilter) ([]*model.Shift, error)
+	CreateShift(ctx context.Context, req *model.Shift) (int, error)
+	UpdateShift(ctx context.Context, req *model.Shift) error
+	DeleteShift(ctx context.Context, id int) error
+	GetTemplate(ctx context.Context, id int) (*model.ShiftTemplate, error)
+	ListTemplates(ctx context.Context, courierId int) ([]*model.ShiftTemplate, error)
+	CreateTemplate(ctx context.Context, req *model.ShiftTemplate) (int, error)
+	UpdateTemplate(ctx context.Context, req *model.ShiftTemplate) error
+	DeleteTemplate(ctx context.Context, id int) error
+}
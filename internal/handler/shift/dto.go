@@ -0,0 +1,93 @@
+package shift
+
+import (
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+)
+
+type shiftRequest struct {
+	CourierID int       `json:"courier_id"`
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+}
+
+type shiftResponse struct {
+	ID         int       `json:"id"`
+	CourierID  int       `json:"courier_id"`
+	TemplateID *int      `json:"template_id"`
+	StartsAt   time.Time `json:"starts_at"`
+	EndsAt     time.Time `json:"ends_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func newShiftResponse(shift *model.Shift) *shiftResponse {
+	return &shiftResponse{
+		ID:         shift.ID,
+		CourierID:  shift.CourierID,
+		TemplateID: shift.TemplateID,
+		StartsAt:   shift.StartsAt,
+		EndsAt:     shift.EndsAt,
+		CreatedAt:  shift.CreatedAt,
+		UpdatedAt:  shift.UpdatedAt,
+	}
+}
+
+// templateRequest describes a weekly shift: weekdays as "mon", "tue" and so
+// on, start and end as "HH:MM" in UTC.
+type templateRequest struct {
+	CourierID int      `json:"courier_id"`
+	Weekdays  []string `json:"weekdays"`
+	Start     string   `json:"start"`
+	End       string   `json:"end"`
+}
+
+func (r *templateRequest) model(id int) (*model.ShiftTemplate, error) {
+	template := &model.ShiftTemplate{ID: id, CourierID: r.CourierID}
+	for _, name := range r.Weekdays {
+		day, err := model.ParseWeekday(name)
+		if err != nil {
+			return nil, err
+		}
+		template.Weekdays = append(template.Weekdays, day)
+	}
+
+	var err error
+	if template.StartMinute, err = model.ParseClock(r.Start); err != nil {
+		return nil, err
+	}
+	if template.EndMinute, err = model.ParseClock(r.End); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+type templateResponse struct {
+	ID        int      `json:"id"`
+	CourierID int      `json:"courier_id"`
+	Weekdays  []string `json:"weekdays"`
+	Start     string   `json:"start"`
+	End       string   `json:"end"`
+	// PlannedUntil is how far ahead shifts were planned from the template.
+	PlannedUntil *time.Time `json:"planned_until"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func newTemplateResponse(template *model.ShiftTemplate) *templateResponse {
+	weekdays := make([]string, len(template.Weekdays))
+	for i, day := range template.Weekdays {
+		weekdays[i] = model.WeekdayName(day)
+	}
+	return &templateResponse{
+		ID:           template.ID,
+		CourierID:    template.CourierID,
+		Weekdays:     weekdays,
+		Start:        model.FormatClock(template.StartMinute),
+		End:          model.FormatClock(template.EndMinute),
+		PlannedUntil: template.GeneratedUntil,
+		CreatedAt:    template.CreatedAt,
+		UpdatedAt:    template.UpdatedAt,
+	}
+}
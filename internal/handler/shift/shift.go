@@ -0,0 +1,236 @@
+package shift
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	handlerErrors "github.com/cdxy1/go-courier-service/internal/handler/errors"
+	"github.com/cdxy1/go-courier-service/internal/model"
+	repo "github.com/cdxy1/go-courier-service/internal/repository/shift"
+	usecase "github.com/cdxy1/go-courier-service/internal/usecase/shift"
+	"github.com/labstack/echo/v4"
+)
+
+type ShiftHandler struct {
+	uc shiftUsecase
+}
+
+func NewShiftHandler(uc shiftUsecase) *ShiftHandler {
+	return &ShiftHandler{uc: uc}
+}
+
+func (h *ShiftHandler) GetByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+
+	shift, err := h.uc.GetShift(c.Request().Context(), id)
+	if err != nil {
+		return shiftError(c, err)
+	}
+	return c.JSON(http.StatusOK, newShiftResponse(shift))
+}
+
+// List returns the shifts filtered by the query string: courier_id, from and
+// to (RFC 3339) select the shifts overlapping the period.
+func (h *ShiftHandler) List(c echo.Context) error {
+	filter, err := parseShiftFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": usecase.ErrInvalidFilter.Error()})
+	}
+
+	shifts, err := h.uc.ListShifts(c.Request().Context(), filter)
+	if err != nil {
+		return shiftError(c, err)
+	}
+
+	response := make([]*shiftResponse, 0, len(shifts))
+	for _, shift := range shifts {
+		response = append(response, newShiftResponse(shift))
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func (h *ShiftHandler) Create(c echo.Context) error {
+	var req shiftRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": handlerErrors.ErrBadRequest.Error()})
+	}
+
+	id, err := h.uc.CreateShift(c.Request().Context(), &model.Shift{
+		CourierID: req.CourierID,
+		StartsAt:  req.StartsAt,
+		EndsAt:    req.EndsAt,
+	})
+	if err != nil {
+		return shiftError(c, err)
+	}
+	return c.JSON(http.StatusCreated, map[string]int{"id": id})
+}
+
+func (h *ShiftHandler) Update(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+
+	var req shiftRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": handlerErrors.ErrBadRequest.Error()})
+	}
+
+	err = h.uc.UpdateShift(c.Request().Context(), &model.Shift{
+		ID:       id,
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+	})
+	if err != nil {
+		return shiftError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "updated"})
+}
+
+func (h *ShiftHandler) Delete(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+
+	if err := h.uc.DeleteShift(c.Request().Context(), id); err != nil {
+		return shiftError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *ShiftHandler) GetTemplate(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+
+	template, err := h.uc.GetTemplate(c.Request().Context(), id)
+	if err != nil {
+		return shiftError(c, err)
+	}
+	return c.JSON(http.StatusOK, newTemplateResponse(template))
+}
+
+// ListTemplates returns the templates, of one courier with courier_id.
+func (h *ShiftHandler) ListTemplates(c echo.Context) error {
+	var courierId int
+	if v := c.QueryParam("courier_id"); v != "" {
+		var err error
+		if courierId, err = strconv.Atoi(v); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": usecase.ErrInvalidFilter.Error()})
+		}
+	}
+
+	templates, err := h.uc.ListTemplates(c.Request().Context(), courierId)
+	if err != nil {
+		return shiftError(c, err)
+	}
+
+	response := make([]*templateResponse, 0, len(templates))
+	for _, template := range templates {
+		response = append(response, newTemplateResponse(template))
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func (h *ShiftHandler) CreateTemplate(c echo.Context) error {
+	var req templateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": handlerErrors.ErrBadRequest.Error()})
+	}
+	template, err := req.model(0)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	id, err := h.uc.CreateTemplate(c.Request().Context(), template)
+	if err != nil {
+		return shiftError(c, err)
+	}
+	return c.JSON(http.StatusCreated, map[string]int{"id": id})
+}
+
+func (h *ShiftHandler) UpdateTemplate(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+
+	var req templateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": handlerErrors.ErrBadRequest.Error()})
+	}
+	template, err := req.model(id)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if err := h.uc.UpdateTemplate(c.Request().Context(), template); err != nil {
+		return shiftError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "updated"})
+}
+
+func (h *ShiftHandler) DeleteTemplate(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+
+	if err := h.uc.DeleteTemplate(c.Request().Context(), id); err != nil {
+		return shiftError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func parseShiftFilter(c echo.Context) (model.ShiftFilter, error) {
+	var filter model.ShiftFilter
+	var err error
+
+	if v := c.QueryParam("courier_id"); v != "" {
+		if filter.CourierID, err = strconv.Atoi(v); err != nil {
+			return filter, err
+		}
+	}
+	if v := c.QueryParam("from"); v != "" {
+		from, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, err
+		}
+		filter.From = &from
+	}
+	if v := c.QueryParam("to"); v != "" {
+		to, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, err
+		}
+		filter.To = &to
+	}
+	return filter, nil
+}
+
+func shiftError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidID),
+		errors.Is(err, usecase.ErrInvalidCourierID),
+		errors.Is(err, usecase.ErrInvalidShiftTime),
+		errors.Is(err, usecase.ErrInvalidWeekdays),
+		errors.Is(err, usecase.ErrInvalidTemplateTime),
+		errors.Is(err, usecase.ErrInvalidFilter):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, repo.ErrShiftNotFound),
+		errors.Is(err, repo.ErrTemplateNotFound),
+		errors.Is(err, repo.ErrCourierNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, repo.ErrShiftOverlaps):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+}
@@ -0,0 +1,419 @@
+package shift
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+	repo "github.com/cdxy1/go-courier-service/internal/repository/shift"
+	usecase "github.com/cdxy1/go-courier-service/internal/usecase/shift"
+	"github.com/labstack/echo/v4"
+)
+
+type mockShiftUsecase struct {
+	t                *testing.T
+	getShiftFn       func(ctx context.Context, id int) (*model.Shift, error)
+	listShiftsFn     func(ctx context.Context, filter model.ShiftFilter) ([]*model.Shift, error)
+	createShiftFn    func(ctx context.Context, req *model.Shift) (int, error)
+	updateShiftFn    func(ctx context.Context, req *model.Shift) error
+	deleteShiftFn    func(ctx context.Context, id int) error
+	getTemplateFn    func(ctx context.Context, id int) (*model.ShiftTemplate, error)
+	listTemplatesFn  func(ctx context.Context, courierId int) ([]*model.ShiftTemplate, error)
+	createTemplateFn func(ctx context.Context, req *model.ShiftTemplate) (int, error)
+	updateTemplateFn func(ctx context.Context, req *model.ShiftTemplate) error
+	deleteTemplateFn func(ctx context.Context, id int) error
+}
+
+func newMockShiftUsecase(t *testing.T) *mockShiftUsecase {
+	return &mockShiftUsecase{t: t}
+}
+
+func (m *mockShiftUsecase) GetShift(ctx context.Context, id int) (*model.Shift, error) {
+	if m.getShiftFn == nil {
+		m.t.Fatalf("GetShift called unexpectedly")
+	}
+	return m.getShiftFn(ctx, id)
+}
+
+func (m *mockShiftUsecase) ListShifts(ctx context.Context, filter model.ShiftFilter) ([]*model.Shift, error) {
+	if m.listShiftsFn == nil {
+		m.t.Fatalf("ListShifts called unexpectedly")
+	}
+	return m.listShiftsFn(ctx, filter)
+}
+
+func (m *mockShiftUsecase) CreateShift(ctx context.Context, req *model.Shift) (int, error) {
+	if m.createShiftFn == nil {
+		m.t.Fatalf("CreateShift called unexpectedly")
+	}
+	return m.createShiftFn(ctx, req)
+}
+
+func (m *mockShiftUsecase) UpdateShift(ctx context.Context, req *model.Shift) error {
+	if m.updateShiftFn == nil {
+		m.t.Fatalf("UpdateShift called unexpectedly")
+	}
+	return m.updateShiftFn(ctx, req)
+}
+
+func (m *mockShiftUsecase) DeleteShift(ctx context.Context, id int) error {
+	if m.deleteShiftFn == nil {
+		m.t.Fatalf("DeleteShift called unexpectedly")
+	}
+	return m.deleteShiftFn(ctx, id)
+}
+
+func (m *mockShiftUsecase) GetTemplate(ctx context.Context, id int) (*model.ShiftTemplate, error) {
+	if m.getTemplateFn == nil {
+		m.t.Fatalf("GetTemplate called unexpectedly")
+	}
+	return m.getTemplateFn(ctx, id)
+}
+
+func (m *mockShiftUsecase) ListTemplates(ctx context.Context, courierId int) ([]*model.ShiftTemplate, error) {
+	if m.listTemplatesFn == nil {
+		m.t.Fatalf("ListTemplates called unexpectedly")
+	}
+	return m.listTemplatesFn(ctx, courierId)
+}
+
+func (m *mockShiftUsecase) CreateTemplate(ctx context.Context, req *model.ShiftTemplate) (int, error) {
+	if m.createTemplateFn == nil {
+		m.t.Fatalf("CreateTemplate called unexpectedly")
+	}
+	return m.createTemplateFn(ctx, req)
+}
+
+func (m *mockShiftUsecase) UpdateTemplate(ctx context.Context, req *model.ShiftTemplate) error {
+	if m.updateTemplateFn == nil {
+		m.t.Fatalf("UpdateTemplate called unexpectedly")
+	}
+	return m.updateTemplateFn(ctx, req)
+}
+
+func (m *mockShiftUsecase) DeleteTemplate(ctx context.Context, id int) error {
+	if m.deleteTemplateFn == nil {
+		m.t.Fatalf("DeleteTemplate called unexpectedly")
+	}
+	return m.deleteTemplateFn(ctx, id)
+}
+
+func TestShiftHandler_Create(t *testing.T) {
+	t.Parallel()
+
+	validBody := `{"courier_id":1,"starts_at":"2025-12-22T08:00:00Z","ends_at":"2025-12-22T16:00:00Z"}`
+
+	tests := []struct {
+		name       string
+		body       string
+		setup      func(*mockShiftUsecase)
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "invalid body",
+			body:       "{",
+			setup:      func(_ *mockShiftUsecase) {},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "invalid request body",
+		},
+		{
+			name: "invalid time",
+			body: validBody,
+			setup: func(m *mockShiftUsecase) {
+				m.createShiftFn = func(ctx context.Context, req *model.Shift) (int, error) {
+					return 0, usecase.ErrInvalidShiftTime
+				}
+			},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    usecase.ErrInvalidShiftTime.Error(),
+		},
+		{
+			name: "courier not found",
+			body: validBody,
+			setup: func(m *mockShiftUsecase) {
+				m.createShiftFn = func(ctx context.Context, req *model.Shift) (int, error) {
+					return 0, repo.ErrCourierNotFound
+				}
+			},
+			wantStatus: http.StatusNotFound,
+			wantErr:    repo.ErrCourierNotFound.Error(),
+		},
+		{
+			name: "overlap",
+			body: validBody,
+			setup: func(m *mockShiftUsecase) {
+				m.createShiftFn = func(ctx context.Context, req *model.Shift) (int, error) {
+					return 0, repo.ErrShiftOverlaps
+				}
+			},
+			wantStatus: http.StatusConflict,
+			wantErr:    repo.ErrShiftOverlaps.Error(),
+		},
+		{
+			name: "internal error",
+			body: validBody,
+			setup: func(m *mockShiftUsecase) {
+				m.createShiftFn = func(ctx context.Context, req *model.Shift) (int, error) {
+					return 0, errors.New("boom")
+				}
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantErr:    "internal server error",
+		},
+		{
+			name: "success",
+			body: validBody,
+			setup: func(m *mockShiftUsecase) {
+				m.createShiftFn = func(ctx context.Context, req *model.Shift) (int, error) {
+					start := time.Date(2025, time.December, 22, 8, 0, 0, 0, time.UTC)
+					if req.CourierID != 1 || !req.StartsAt.Equal(start) || !req.EndsAt.Equal(start.Add(8*time.Hour)) {
+						m.t.Fatalf("unexpected shift: %+v", req)
+					}
+					return 4, nil
+				}
+			},
+			wantStatus: http.StatusCreated,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/shifts", strings.NewReader(tc.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			uc := newMockShiftUsecase(t)
+			tc.setup(uc)
+			handler := NewShiftHandler(uc)
+
+			if err := handler.Create(c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d", tc.wantStatus, rec.Code)
+			}
+			if tc.wantErr != "" {
+				var resp map[string]string
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if resp["error"] != tc.wantErr {
+					t.Fatalf("expected error %q, got %q", tc.wantErr, resp["error"])
+				}
+			}
+		})
+	}
+}
+
+func TestShiftHandler_List(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		query      string
+		setup      func(*mockShiftUsecase)
+		wantStatus int
+	}{
+		{
+			name:       "invalid courier id",
+			query:      "courier_id=abc",
+			setup:      func(_ *mockShiftUsecase) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid from",
+			query:      "from=yesterday",
+			setup:      func(_ *mockShiftUsecase) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "success",
+			query: "courier_id=1&from=2025-12-22T00:00:00Z&to=2025-12-23T00:00:00Z",
+			setup: func(m *mockShiftUsecase) {
+				m.listShiftsFn = func(ctx context.Context, filter model.ShiftFilter) ([]*model.Shift, error) {
+					if filter.CourierID != 1 || filter.From == nil || filter.To == nil || filter.To.Sub(*filter.From) != 24*time.Hour {
+						m.t.Fatalf("unexpected filter: %+v", filter)
+					}
+					return []*model.Shift{{ID: 2, CourierID: 1}}, nil
+				}
+			},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/shifts?"+tc.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			uc := newMockShiftUsecase(t)
+			tc.setup(uc)
+			handler := NewShiftHandler(uc)
+
+			if err := handler.List(c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d", tc.wantStatus, rec.Code)
+			}
+			if tc.wantStatus == http.StatusOK {
+				var resp []shiftResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if len(resp) != 1 || resp[0].ID != 2 {
+					t.Fatalf("unexpected response: %+v", resp)
+				}
+			}
+		})
+	}
+}
+
+func TestShiftHandler_CreateTemplate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       string
+		setup      func(*mockShiftUsecase)
+		wantStatus int
+	}{
+		{
+			name:       "unknown weekday",
+			body:       `{"courier_id":1,"weekdays":["monday"],"start":"08:00","end":"16:00"}`,
+			setup:      func(_ *mockShiftUsecase) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "missing start",
+			body:       `{"courier_id":1,"weekdays":["mon"],"end":"16:00"}`,
+			setup:      func(_ *mockShiftUsecase) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "no weekdays",
+			body: `{"courier_id":1,"weekdays":[],"start":"08:00","end":"16:00"}`,
+			setup: func(m *mockShiftUsecase) {
+				m.createTemplateFn = func(ctx context.Context, req *model.ShiftTemplate) (int, error) {
+					return 0, usecase.ErrInvalidWeekdays
+				}
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "success",
+			body: `{"courier_id":1,"weekdays":["mon","FRI"],"start":"22:00","end":"06:30"}`,
+			setup: func(m *mockShiftUsecase) {
+				m.createTemplateFn = func(ctx context.Context, req *model.ShiftTemplate) (int, error) {
+					if req.CourierID != 1 || req.StartMinute != 22*60 || req.EndMinute != 6*60+30 {
+						m.t.Fatalf("unexpected template: %+v", req)
+					}
+					if len(req.Weekdays) != 2 || req.Weekdays[0] != time.Monday || req.Weekdays[1] != time.Friday {
+						m.t.Fatalf("unexpected weekdays: %v", req.Weekdays)
+					}
+					return 3, nil
+				}
+			},
+			wantStatus: http.StatusCreated,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/shift-templates", strings.NewReader(tc.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			uc := newMockShiftUsecase(t)
+			tc.setup(uc)
+			handler := NewShiftHandler(uc)
+
+			if err := handler.CreateTemplate(c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d", tc.wantStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestShiftHandler_GetTemplate(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/shift-templates/3", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("3")
+
+	uc := newMockShiftUsecase(t)
+	uc.getTemplateFn = func(ctx context.Context, id int) (*model.ShiftTemplate, error) {
+		return &model.ShiftTemplate{
+			ID: id, CourierID: 1, Weekdays: []time.Weekday{time.Sunday, time.Saturday},
+			StartMinute: 9 * 60, EndMinute: 17*60 + 30,
+		}, nil
+	}
+	handler := NewShiftHandler(uc)
+
+	if err := handler.GetTemplate(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	var resp templateResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Start != "09:00" || resp.End != "17:30" || len(resp.Weekdays) != 2 || resp.Weekdays[0] != "sun" || resp.Weekdays[1] != "sat" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
+
+func TestShiftHandler_DeleteTemplate_NotFound(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodDelete, "/shift-templates/3", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("3")
+
+	uc := newMockShiftUsecase(t)
+	uc.deleteTemplateFn = func(ctx context.Context, id int) error {
+		return repo.ErrTemplateNotFound
+	}
+	handler := NewShiftHandler(uc)
+
+	if err := handler.DeleteTemplate(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
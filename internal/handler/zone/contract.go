@@ -0,0 +1,15 @@
+package zone
+
+import (
+	"context"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+)
+
+type zoneUsecase interface {
+	GetOneById(ctx context.Context, id int) (*model.Zone, error)
+	GetAll(ctx context.Context) ([]*model.Zone, error)
+	Create(ctx context.Context, req *model.Zone) (int, error)
+	Update(ctx context.Context, req *model.Zone) error
+	Delete(ctx context.Context, id int) error
+}
@@ -0,0 +1,38 @@
+package zone
+
+import (
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/geo"
+	"github.com/cdxy1/go-courier-service/internal/model"
+)
+
+type zoneRequest struct {
+	Name         string      `json:"name"`
+	Area         geo.Polygon `json:"area"`
+	NeighbourIDs []int       `json:"neighbour_ids"`
+}
+
+type zoneResponse struct {
+	ID           int         `json:"id"`
+	Name         string      `json:"name"`
+	Area         geo.Polygon `json:"area"`
+	NeighbourIDs []int       `json:"neighbour_ids"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+func newZoneResponse(zone *model.Zone) *zoneResponse {
+	neighbours := zone.NeighbourIDs
+	if neighbours == nil {
+		neighbours = []int{}
+	}
+	return &zoneResponse{
+		ID:           zone.ID,
+		Name:         zone.Name,
+		Area:         zone.Area,
+		NeighbourIDs: neighbours,
+		CreatedAt:    zone.CreatedAt,
+		UpdatedAt:    zone.UpdatedAt,
+	}
+}
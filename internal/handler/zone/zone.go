@@ -0,0 +1,114 @@
+package zone
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	handlerErrors "github.com/cdxy1/go-courier-service/internal/handler/errors"
+	"github.com/cdxy1/go-courier-service/internal/model"
+	repo "github.com/cdxy1/go-courier-service/internal/repository/zone"
+	usecase "github.com/cdxy1/go-courier-service/internal/usecase/zone"
+	"github.com/labstack/echo/v4"
+)
+
+type ZoneHandler struct {
+	uc zoneUsecase
+}
+
+func NewZoneHandler(uc zoneUsecase) *ZoneHandler {
+	return &ZoneHandler{uc: uc}
+}
+
+func (h *ZoneHandler) GetByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+
+	zone, err := h.uc.GetOneById(c.Request().Context(), id)
+	if err != nil {
+		return zoneError(c, err)
+	}
+	return c.JSON(http.StatusOK, newZoneResponse(zone))
+}
+
+func (h *ZoneHandler) GetAll(c echo.Context) error {
+	zones, err := h.uc.GetAll(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+
+	response := make([]*zoneResponse, 0, len(zones))
+	for _, zone := range zones {
+		response = append(response, newZoneResponse(zone))
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func (h *ZoneHandler) Create(c echo.Context) error {
+	var req zoneRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": handlerErrors.ErrBadRequest.Error()})
+	}
+
+	id, err := h.uc.Create(c.Request().Context(), &model.Zone{
+		Name:         req.Name,
+		Area:         req.Area,
+		NeighbourIDs: req.NeighbourIDs,
+	})
+	if err != nil {
+		return zoneError(c, err)
+	}
+	return c.JSON(http.StatusCreated, map[string]int{"id": id})
+}
+
+func (h *ZoneHandler) Update(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+
+	var req zoneRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": handlerErrors.ErrBadRequest.Error()})
+	}
+
+	err = h.uc.Update(c.Request().Context(), &model.Zone{
+		ID:           id,
+		Name:         req.Name,
+		Area:         req.Area,
+		NeighbourIDs: req.NeighbourIDs,
+	})
+	if err != nil {
+		return zoneError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "updated"})
+}
+
+func (h *ZoneHandler) Delete(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+
+	if err := h.uc.Delete(c.Request().Context(), id); err != nil {
+		return zoneError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func zoneError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidID),
+		errors.Is(err, usecase.ErrInvalidName),
+		errors.Is(err, usecase.ErrInvalidArea),
+		errors.Is(err, usecase.ErrInvalidNeighbour):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, repo.ErrZoneNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, repo.ErrZoneNameExists):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+}
@@ -0,0 +1,296 @@
+package zone
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cdxy1/go-courier-service/internal/geo"
+	"github.com/cdxy1/go-courier-service/internal/model"
+	repo "github.com/cdxy1/go-courier-service/internal/repository/zone"
+	usecase "github.com/cdxy1/go-courier-service/internal/usecase/zone"
+	"github.com/labstack/echo/v4"
+)
+
+type mockZoneUsecase struct {
+	t            *testing.T
+	getOneByIDFn func(ctx context.Context, id int) (*model.Zone, error)
+	getAllFn     func(ctx context.Context) ([]*model.Zone, error)
+	createFn     func(ctx context.Context, req *model.Zone) (int, error)
+	updateFn     func(ctx context.Context, req *model.Zone) error
+	deleteFn     func(ctx context.Context, id int) error
+}
+
+func newMockZoneUsecase(t *testing.T) *mockZoneUsecase {
+	return &mockZoneUsecase{t: t}
+}
+
+func (m *mockZoneUsecase) GetOneById(ctx context.Context, id int) (*model.Zone, error) {
+	if m.getOneByIDFn == nil {
+		m.t.Fatalf("GetOneById called unexpectedly")
+	}
+	return m.getOneByIDFn(ctx, id)
+}
+
+func (m *mockZoneUsecase) GetAll(ctx context.Context) ([]*model.Zone, error) {
+	if m.getAllFn == nil {
+		m.t.Fatalf("GetAll called unexpectedly")
+	}
+	return m.getAllFn(ctx)
+}
+
+func (m *mockZoneUsecase) Create(ctx context.Context, req *model.Zone) (int, error) {
+	if m.createFn == nil {
+		m.t.Fatalf("Create called unexpectedly")
+	}
+	return m.createFn(ctx, req)
+}
+
+func (m *mockZoneUsecase) Update(ctx context.Context, req *model.Zone) error {
+	if m.updateFn == nil {
+		m.t.Fatalf("Update called unexpectedly")
+	}
+	return m.updateFn(ctx, req)
+}
+
+func (m *mockZoneUsecase) Delete(ctx context.Context, id int) error {
+	if m.deleteFn == nil {
+		m.t.Fatalf("Delete called unexpectedly")
+	}
+	return m.deleteFn(ctx, id)
+}
+
+func TestZoneHandler_Create(t *testing.T) {
+	t.Parallel()
+
+	validBody := `{"name":"north","area":[{"lat":0,"lon":0},{"lat":0,"lon":1},{"lat":1,"lon":1}],"neighbour_ids":[2]}`
+
+	tests := []struct {
+		name       string
+		body       string
+		setup      func(*mockZoneUsecase)
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "invalid body",
+			body:       "{",
+			setup:      func(_ *mockZoneUsecase) {},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    "invalid request body",
+		},
+		{
+			name: "invalid area",
+			body: `{"name":"north","area":[]}`,
+			setup: func(m *mockZoneUsecase) {
+				m.createFn = func(ctx context.Context, req *model.Zone) (int, error) {
+					return 0, usecase.ErrInvalidArea
+				}
+			},
+			wantStatus: http.StatusBadRequest,
+			wantErr:    usecase.ErrInvalidArea.Error(),
+		},
+		{
+			name: "name exists",
+			body: validBody,
+			setup: func(m *mockZoneUsecase) {
+				m.createFn = func(ctx context.Context, req *model.Zone) (int, error) {
+					return 0, repo.ErrZoneNameExists
+				}
+			},
+			wantStatus: http.StatusConflict,
+			wantErr:    repo.ErrZoneNameExists.Error(),
+		},
+		{
+			name: "internal error",
+			body: validBody,
+			setup: func(m *mockZoneUsecase) {
+				m.createFn = func(ctx context.Context, req *model.Zone) (int, error) {
+					return 0, errors.New("boom")
+				}
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantErr:    "internal server error",
+		},
+		{
+			name: "success",
+			body: validBody,
+			setup: func(m *mockZoneUsecase) {
+				m.createFn = func(ctx context.Context, req *model.Zone) (int, error) {
+					if req.Name != "north" || len(req.Area) != 3 || req.Area[1] != (geo.Point{Lat: 0, Lon: 1}) {
+						m.t.Fatalf("unexpected zone: %+v", req)
+					}
+					if len(req.NeighbourIDs) != 1 || req.NeighbourIDs[0] != 2 {
+						m.t.Fatalf("unexpected neighbours: %v", req.NeighbourIDs)
+					}
+					return 4, nil
+				}
+			},
+			wantStatus: http.StatusCreated,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/zones", strings.NewReader(tc.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			uc := newMockZoneUsecase(t)
+			tc.setup(uc)
+			handler := NewZoneHandler(uc)
+
+			if err := handler.Create(c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d", tc.wantStatus, rec.Code)
+			}
+			if tc.wantErr != "" {
+				var resp map[string]string
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if resp["error"] != tc.wantErr {
+					t.Fatalf("expected error %q, got %q", tc.wantErr, resp["error"])
+				}
+			}
+		})
+	}
+}
+
+func TestZoneHandler_GetByID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		param      string
+		setup      func(*mockZoneUsecase)
+		wantStatus int
+	}{
+		{
+			name:       "invalid path param",
+			param:      "abc",
+			setup:      func(_ *mockZoneUsecase) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "not found",
+			param: "1",
+			setup: func(m *mockZoneUsecase) {
+				m.getOneByIDFn = func(ctx context.Context, id int) (*model.Zone, error) {
+					return nil, repo.ErrZoneNotFound
+				}
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:  "success",
+			param: "2",
+			setup: func(m *mockZoneUsecase) {
+				m.getOneByIDFn = func(ctx context.Context, id int) (*model.Zone, error) {
+					return &model.Zone{ID: id, Name: "north"}, nil
+				}
+			},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/zones/"+tc.param, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tc.param)
+
+			uc := newMockZoneUsecase(t)
+			tc.setup(uc)
+			handler := NewZoneHandler(uc)
+
+			if err := handler.GetByID(c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d", tc.wantStatus, rec.Code)
+			}
+			if tc.wantStatus == http.StatusOK {
+				var resp zoneResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+				if resp.ID != 2 || resp.Name != "north" || resp.NeighbourIDs == nil {
+					t.Fatalf("unexpected response: %+v", resp)
+				}
+			}
+		})
+	}
+}
+
+func TestZoneHandler_Update(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPut, "/zones/3", strings.NewReader(`{"name":"south","area":[]}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("3")
+
+	uc := newMockZoneUsecase(t)
+	uc.updateFn = func(ctx context.Context, req *model.Zone) error {
+		if req.ID != 3 || req.Name != "south" {
+			t.Fatalf("unexpected zone: %+v", req)
+		}
+		return repo.ErrZoneNotFound
+	}
+	handler := NewZoneHandler(uc)
+
+	if err := handler.Update(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestZoneHandler_Delete(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodDelete, "/zones/3", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("3")
+
+	uc := newMockZoneUsecase(t)
+	uc.deleteFn = func(ctx context.Context, id int) error {
+		if id != 3 {
+			t.Fatalf("unexpected id: %d", id)
+		}
+		return nil
+	}
+	handler := NewZoneHandler(uc)
+
+	if err := handler.Delete(c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", rec.Code)
+	}
+}
@@ -2,6 +2,7 @@ package integration
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"github.com/cdxy1/go-courier-service/internal/model"
 	courierrepo "github.com/cdxy1/go-courier-service/internal/repository/courier"
 	deliveryrepo "github.com/cdxy1/go-courier-service/internal/repository/delivery"
+	shiftrepo "github.com/cdxy1/go-courier-service/internal/repository/shift"
 	courierusecase "github.com/cdxy1/go-courier-service/internal/usecase/courier"
 	deliveryusecase "github.com/cdxy1/go-courier-service/internal/usecase/delivery"
 	"github.com/docker/go-connections/nat"
@@ -34,7 +36,7 @@ func TestIntegration_CourierDeliveryFlow(t *testing.T) {
 	deliveryRepo := deliveryrepo.NewDeliveryRepository(pool)
 	txManager := ipostgres.NewTxManager(pool)
 
-	courierUC := courierusecase.NewCourierUsecase(courierRepo)
+	courierUC := courierusecase.NewCourierUsecase(courierRepo, txManager)
 	timeFactory := model.NewDeliveryTimeFactory(time.Minute*30, time.Minute*15, time.Minute*5)
 	deliveryUC := deliveryusecase.NewDeliveryUsecase(courierRepo, deliveryRepo, txManager, timeFactory, model.UTCNow)
 
@@ -65,7 +67,7 @@ func TestIntegration_CourierDeliveryFlow(t *testing.T) {
 	}
 
 	orderID := "order-integration-1"
-	delivery, assignedCourier, err := deliveryUC.Assign(ctx, orderID)
+	delivery, assignedCourier, err := deliveryUC.Assign(ctx, &model.Order{ID: orderID})
 	if err != nil {
 		t.Fatalf("assign delivery: %v", err)
 	}
@@ -85,6 +87,14 @@ func TestIntegration_CourierDeliveryFlow(t *testing.T) {
 		t.Fatalf("deadline should be set")
 	}
 
+	repeated, _, err := deliveryUC.Assign(ctx, &model.Order{ID: orderID})
+	if err != nil {
+		t.Fatalf("repeat assign delivery: %v", err)
+	}
+	if repeated.ID != delivery.ID {
+		t.Fatalf("expected repeated assign to return delivery %d, got %d", delivery.ID, repeated.ID)
+	}
+
 	var cntAfterAssign int
 	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM delivery WHERE order_id=$1`, orderID).Scan(&cntAfterAssign); err != nil {
 		t.Fatalf("query delivery count: %v", err)
@@ -112,12 +122,24 @@ func TestIntegration_CourierDeliveryFlow(t *testing.T) {
 		t.Fatalf("expected courier id %d in unassign result, got %d", courierID, unassignResult.CourierId)
 	}
 
-	var cntAfterUnassign int
-	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM delivery WHERE order_id=$1`, orderID).Scan(&cntAfterUnassign); err != nil {
-		t.Fatalf("query delivery count after unassign: %v", err)
+	var statusAfterUnassign string
+	var cancelledAt *time.Time
+	if err := pool.QueryRow(ctx, `SELECT status, cancelled_at FROM delivery WHERE order_id=$1`, orderID).Scan(&statusAfterUnassign, &cancelledAt); err != nil {
+		t.Fatalf("query delivery after unassign: %v", err)
+	}
+	if statusAfterUnassign != string(model.DeliveryStatusCancelled) {
+		t.Fatalf("expected cancelled delivery after unassign, got %s", statusAfterUnassign)
+	}
+	if cancelledAt == nil {
+		t.Fatalf("cancelled_at should be set after unassign")
+	}
+
+	history, err := deliveryUC.History(ctx, orderID)
+	if err != nil {
+		t.Fatalf("get delivery history: %v", err)
 	}
-	if cntAfterUnassign != 0 {
-		t.Fatalf("expected 0 delivery rows after unassign, got %d", cntAfterUnassign)
+	if len(history) != 2 || history[0].Type != model.DeliveryEventAssigned || history[1].Type != model.DeliveryEventUnassigned {
+		t.Fatalf("unexpected delivery history: %+v", history)
 	}
 
 	courierAfterUnassign, err := courierUC.GetOneById(ctx, courierID)
@@ -129,6 +151,113 @@ func TestIntegration_CourierDeliveryFlow(t *testing.T) {
 	}
 }
 
+func TestIntegration_CourierVersion(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	pool, terminate := startPostgres(ctx, t)
+	defer terminate()
+
+	if err := runMigrations(ctx, pool); err != nil {
+		t.Fatalf("failed to prepare schema: %v", err)
+	}
+
+	courierRepo := courierrepo.NewCourierRepository(pool)
+	courierUC := courierusecase.NewCourierUsecase(courierRepo, ipostgres.NewTxManager(pool))
+
+	courierID, err := courierUC.Create(ctx, &model.CourierModel{
+		Name:          "Bob",
+		Phone:         "+79990000002",
+		Status:        model.CourierStatusAvailable,
+		TransportType: model.TransportScooter,
+	})
+	if err != nil {
+		t.Fatalf("create courier: %v", err)
+	}
+	courier, err := courierUC.GetOneById(ctx, courierID)
+	if err != nil {
+		t.Fatalf("get courier: %v", err)
+	}
+
+	score := &model.CourierScore{CourierID: courierID, Score: 0.8, OnTimeRate: 0.9, Deliveries: 10, UpdatedAt: time.Now().UTC()}
+	for i := 0; i < 2; i++ {
+		if err := courierRepo.SaveScores(ctx, []*model.CourierScore{score}); err != nil {
+			t.Fatalf("save scores: %v", err)
+		}
+	}
+
+	name := "Robert"
+	match := model.VersionMatch{Required: true, Versions: []int{courier.Version}}
+	patched, err := courierUC.Patch(ctx, courierID, &model.CourierPatch{Name: &name}, match)
+	if err != nil {
+		t.Fatalf("patch after scorer runs: %v", err)
+	}
+	if patched.Version != courier.Version+1 {
+		t.Fatalf("expected version %d, got %d", courier.Version+1, patched.Version)
+	}
+}
+
+func TestIntegration_StartShifts(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	pool, terminate := startPostgres(ctx, t)
+	defer terminate()
+
+	if err := runMigrations(ctx, pool); err != nil {
+		t.Fatalf("failed to prepare schema: %v", err)
+	}
+
+	courierRepo := courierrepo.NewCourierRepository(pool)
+	shiftRepo := shiftrepo.NewShiftRepository(pool)
+	now := time.Now().UTC()
+
+	// Both couriers are on a shift that started long before the scheduler
+	// runs; the first was paused before it, the second by hand during it.
+	var ids []int
+	for i, pausedAt := range []time.Time{now.Add(-3 * time.Hour), now.Add(-time.Hour)} {
+		id, err := courierRepo.Create(ctx, &model.CourierModel{
+			Name:          fmt.Sprintf("Courier %d", i+1),
+			Phone:         fmt.Sprintf("+7999000001%d", i),
+			Status:        model.CourierStatusPaused,
+			TransportType: model.TransportOnFoot,
+		})
+		if err != nil {
+			t.Fatalf("create courier: %v", err)
+		}
+		if _, err := pool.Exec(ctx, `UPDATE couriers SET paused_at=$1 WHERE id=$2`, pausedAt, id); err != nil {
+			t.Fatalf("set paused at: %v", err)
+		}
+		shift := &model.Shift{CourierID: id, StartsAt: now.Add(-2 * time.Hour), EndsAt: now.Add(2 * time.Hour)}
+		if _, err := shiftRepo.Create(ctx, shift); err != nil {
+			t.Fatalf("create shift: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	started, err := shiftRepo.StartShifts(ctx, now)
+	if err != nil {
+		t.Fatalf("start shifts: %v", err)
+	}
+	if started != 1 {
+		t.Fatalf("expected 1 courier started, got %d", started)
+	}
+	for i, want := range []model.CourierStatus{model.CourierStatusAvailable, model.CourierStatusPaused} {
+		courier, err := courierRepo.GetOneById(ctx, ids[i])
+		if err != nil {
+			t.Fatalf("get courier: %v", err)
+		}
+		if courier.Status != want {
+			t.Fatalf("expected courier %d %s, got %s", ids[i], want, courier.Status)
+		}
+	}
+
+	overlapping := &model.Shift{CourierID: ids[0], StartsAt: now, EndsAt: now.Add(3 * time.Hour)}
+	if _, err := shiftRepo.Create(ctx, overlapping); !errors.Is(err, shiftrepo.ErrShiftOverlaps) {
+		t.Fatalf("expected ErrShiftOverlaps, got %v", err)
+	}
+}
+
 func startPostgres(ctx context.Context, t *testing.T) (*pgxpool.Pool, func()) {
 	t.Helper()
 
@@ -192,13 +321,37 @@ func startPostgres(ctx context.Context, t *testing.T) (*pgxpool.Pool, func()) {
 
 func runMigrations(ctx context.Context, pool *pgxpool.Pool) error {
 	statements := []string{
+		`CREATE EXTENSION IF NOT EXISTS btree_gist;`,
+		`CREATE TABLE IF NOT EXISTS zones (
+            id BIGSERIAL PRIMARY KEY,
+            name TEXT NOT NULL UNIQUE,
+            area JSONB NOT NULL,
+            neighbour_ids BIGINT[] NOT NULL DEFAULT '{}',
+            created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+            updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+        );`,
 		`CREATE TABLE IF NOT EXISTS couriers (
             id BIGSERIAL PRIMARY KEY,
             name TEXT NOT NULL,
             phone TEXT NOT NULL UNIQUE,
-            status TEXT NOT NULL,
+            status TEXT NOT NULL DEFAULT 'available' CHECK (status IN ('available', 'busy', 'paused')),
             transport_type TEXT NOT NULL DEFAULT 'on_foot',
             assignments_count BIGINT NOT NULL DEFAULT 0,
+            active_deliveries INT NOT NULL DEFAULT 0,
+            latitude DOUBLE PRECISION,
+            longitude DOUBLE PRECISION,
+            geo_cell VARCHAR(12),
+            location_updated_at TIMESTAMP,
+            location_accuracy DOUBLE PRECISION,
+            zone_id BIGINT REFERENCES zones(id) ON DELETE SET NULL,
+            score DOUBLE PRECISION,
+            on_time_rate DOUBLE PRECISION,
+            cancel_rate DOUBLE PRECISION,
+            deadline_usage DOUBLE PRECISION,
+            scored_deliveries INT NOT NULL DEFAULT 0,
+            scored_at TIMESTAMP,
+            version INT NOT NULL DEFAULT 1,
+            paused_at TIMESTAMP,
             created_at TIMESTAMP DEFAULT NOW(),
             updated_at TIMESTAMP DEFAULT NOW()
         );`,
@@ -207,7 +360,84 @@ func runMigrations(ctx context.Context, pool *pgxpool.Pool) error {
             courier_id BIGINT NOT NULL,
             order_id VARCHAR(255) NOT NULL,
             assigned_at TIMESTAMP NOT NULL DEFAULT NOW(),
-            deadline TIMESTAMP NOT NULL
+            deadline TIMESTAMP NOT NULL,
+            status TEXT NOT NULL DEFAULT 'assigned',
+            picked_up_at TIMESTAMP,
+            in_transit_at TIMESTAMP,
+            delivered_at TIMESTAMP,
+            cancelled_at TIMESTAMP,
+            expired_at TIMESTAMP,
+            updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+            idempotency_key TEXT,
+            pickup_lat DOUBLE PRECISION,
+            pickup_lon DOUBLE PRECISION,
+            dropoff_lat DOUBLE PRECISION,
+            dropoff_lon DOUBLE PRECISION,
+            order_details JSONB,
+            promised_by TIMESTAMP,
+            at_risk BOOLEAN NOT NULL DEFAULT FALSE
+        );`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS uq_delivery_active_order
+            ON delivery (order_id) WHERE status IN ('assigned', 'picked_up', 'in_transit');`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS uq_delivery_idempotency_key
+            ON delivery (idempotency_key) WHERE idempotency_key IS NOT NULL;`,
+		`CREATE TABLE IF NOT EXISTS delivery_events (
+            id BIGSERIAL PRIMARY KEY,
+            delivery_id BIGINT NOT NULL REFERENCES delivery(id),
+            order_id VARCHAR(255) NOT NULL,
+            courier_id BIGINT NOT NULL,
+            type TEXT NOT NULL,
+            from_status TEXT,
+            to_status TEXT NOT NULL,
+            source TEXT NOT NULL,
+            actor TEXT NOT NULL DEFAULT '',
+            reason TEXT NOT NULL DEFAULT '',
+            created_at TIMESTAMP NOT NULL DEFAULT NOW()
+        );`,
+		`CREATE TABLE IF NOT EXISTS pending_assignments (
+            id BIGSERIAL PRIMARY KEY,
+            order_id VARCHAR(255) NOT NULL UNIQUE,
+            priority INT NOT NULL DEFAULT 0,
+            attempts INT NOT NULL DEFAULT 0,
+            last_error TEXT NOT NULL DEFAULT '',
+            enqueued_at TIMESTAMP NOT NULL DEFAULT NOW(),
+            last_attempt_at TIMESTAMP,
+            pickup_lat DOUBLE PRECISION,
+            pickup_lon DOUBLE PRECISION,
+            dropoff_lat DOUBLE PRECISION,
+            dropoff_lon DOUBLE PRECISION,
+            order_details JSONB,
+            promised_by TIMESTAMP
+        );`,
+		`CREATE TABLE IF NOT EXISTS courier_locations (
+            courier_id BIGINT NOT NULL REFERENCES couriers(id) ON DELETE CASCADE,
+            latitude DOUBLE PRECISION NOT NULL,
+            longitude DOUBLE PRECISION NOT NULL,
+            accuracy DOUBLE PRECISION NOT NULL DEFAULT 0,
+            recorded_at TIMESTAMP NOT NULL,
+            PRIMARY KEY (courier_id, recorded_at)
+        );`,
+		`CREATE TABLE IF NOT EXISTS shift_templates (
+            id BIGSERIAL PRIMARY KEY,
+            courier_id BIGINT NOT NULL REFERENCES couriers(id) ON DELETE CASCADE,
+            weekdays INT[] NOT NULL,
+            start_minute INT NOT NULL,
+            end_minute INT NOT NULL,
+            generated_until TIMESTAMP,
+            created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+            updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+        );`,
+		`CREATE TABLE IF NOT EXISTS courier_shifts (
+            id BIGSERIAL PRIMARY KEY,
+            courier_id BIGINT NOT NULL REFERENCES couriers(id) ON DELETE CASCADE,
+            template_id BIGINT REFERENCES shift_templates(id) ON DELETE SET NULL,
+            starts_at TIMESTAMP NOT NULL,
+            ends_at TIMESTAMP NOT NULL,
+            created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+            updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+            CHECK (ends_at > starts_at),
+            CONSTRAINT ex_courier_shifts_overlap
+                EXCLUDE USING gist (courier_id WITH =, tsrange(starts_at, ends_at) WITH &&)
         );`,
 	}
 
@@ -0,0 +1,130 @@
+// Package matching solves the assignment problem: pairing rows with columns of
+// a cost matrix so that the total cost is minimal.
+package matching
+
+import "math"
+
+// Forbidden marks a pair that must never be matched.
+var Forbidden = math.Inf(1)
+
+// Solve returns a minimum cost matching of the rows of cost to its columns with
+// the Hungarian algorithm in O(n²m), n being the smaller dimension. The result
+// holds the column of every row, or -1 for rows left unmatched. As many pairs as
+// possible are matched, but never a Forbidden one, so rows may stay unmatched
+// even when columns are left. Costs must not be negative and all rows must have
+// the same length.
+func Solve(cost [][]float64) []int {
+	rows := len(cost)
+	assign := make([]int, rows)
+	for i := range assign {
+		assign[i] = -1
+	}
+	if rows == 0 || len(cost[0]) == 0 {
+		return assign
+	}
+	cols := len(cost[0])
+
+	// The algorithm needs no more rows than columns, so a tall matrix is
+	// solved transposed. Forbidden pairs get a cost above any matching
+	// made of allowed pairs only and are dropped afterwards.
+	transposed := rows > cols
+	n, m := rows, cols
+	if transposed {
+		n, m = cols, rows
+	}
+	at := func(i, j int) float64 {
+		if transposed {
+			return cost[j][i]
+		}
+		return cost[i][j]
+	}
+
+	var maxCost float64
+	for _, row := range cost {
+		for _, c := range row {
+			if !math.IsInf(c, 1) && c > maxCost {
+				maxCost = c
+			}
+		}
+	}
+	forbidden := (maxCost + 1) * float64(n+1)
+
+	a := make([][]float64, n+1)
+	for i := 1; i <= n; i++ {
+		a[i] = make([]float64, m+1)
+		for j := 1; j <= m; j++ {
+			c := at(i-1, j-1)
+			if math.IsInf(c, 1) {
+				c = forbidden
+			}
+			a[i][j] = c
+		}
+	}
+
+	// Potentials u and v, p[j] is the row matched to column j, way[j] the
+	// previous column on the augmenting path. Index 0 is a sentinel.
+	u := make([]float64, n+1)
+	v := make([]float64, m+1)
+	p := make([]int, m+1)
+	way := make([]int, m+1)
+	minv := make([]float64, m+1)
+	used := make([]bool, m+1)
+
+	for i := 1; i <= n; i++ {
+		p[0] = i
+		j0 := 0
+		for j := range minv {
+			minv[j] = math.Inf(1)
+			used[j] = false
+		}
+		for {
+			used[j0] = true
+			i0 := p[j0]
+			delta := math.Inf(1)
+			j1 := 0
+			row := a[i0]
+			for j := 1; j <= m; j++ {
+				if used[j] {
+					continue
+				}
+				if cur := row[j] - u[i0] - v[j]; cur < minv[j] {
+					minv[j] = cur
+					way[j] = j0
+				}
+				if minv[j] < delta {
+					delta = minv[j]
+					j1 = j
+				}
+			}
+			for j := 0; j <= m; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+			j0 = j1
+			if p[j0] == 0 {
+				break
+			}
+		}
+		for j0 != 0 {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+		}
+	}
+
+	for j := 1; j <= m; j++ {
+		if p[j] == 0 || a[p[j]][j] >= forbidden {
+			continue
+		}
+		if transposed {
+			assign[j-1] = p[j] - 1
+		} else {
+			assign[p[j]-1] = j - 1
+		}
+	}
+	return assign
+}
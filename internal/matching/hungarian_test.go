@@ -0,0 +1,178 @@
+package matching
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestSolve(t *testing.T) {
+	t.Parallel()
+
+	x := Forbidden
+	tests := []struct {
+		name     string
+		cost     [][]float64
+		expected []int
+	}{
+		{
+			name:     "empty",
+			cost:     nil,
+			expected: []int{},
+		},
+		{
+			name: "greedy is not optimal",
+			cost: [][]float64{
+				{9, 10},
+				{1, 20},
+			},
+			expected: []int{1, 0},
+		},
+		{
+			name: "more columns than rows",
+			cost: [][]float64{
+				{4, 1, 3},
+				{2, 0, 5},
+			},
+			expected: []int{1, 0},
+		},
+		{
+			name: "more rows than columns",
+			cost: [][]float64{
+				{4, 1},
+				{2, 0},
+				{1, 5},
+			},
+			expected: []int{-1, 1, 0},
+		},
+		{
+			name: "forbidden pair avoided",
+			cost: [][]float64{
+				{x, 7},
+				{1, 2},
+			},
+			expected: []int{1, 0},
+		},
+		{
+			name: "row without allowed column",
+			cost: [][]float64{
+				{x, x},
+				{3, 2},
+			},
+			expected: []int{-1, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := Solve(tt.cost)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("expected %v, got %v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSolve_MatchesBruteForce(t *testing.T) {
+	t.Parallel()
+
+	rnd := rand.New(rand.NewPCG(1, 2))
+	for round := 0; round < 200; round++ {
+		rows, cols := 1+rnd.IntN(6), 1+rnd.IntN(6)
+		cost := randomMatrix(rnd, rows, cols)
+		for i := range cost {
+			for j := range cost[i] {
+				if rnd.IntN(5) == 0 {
+					cost[i][j] = Forbidden
+				}
+			}
+		}
+
+		got := Solve(cost)
+		gotPairs, gotCost := score(t, cost, got)
+		wantPairs, wantCost := bruteForce(cost, 0, make([]bool, cols))
+		if gotPairs != wantPairs || math.Abs(gotCost-wantCost) > 1e-9 {
+			t.Fatalf("round %d: expected %d pairs costing %f, got %d pairs costing %f (%v)",
+				round, wantPairs, wantCost, gotPairs, gotCost, got)
+		}
+	}
+}
+
+func BenchmarkSolve(b *testing.B) {
+	for _, size := range []struct {
+		name       string
+		rows, cols int
+	}{
+		{name: "100x100", rows: 100, cols: 100},
+		{name: "1000x1000", rows: 1000, cols: 1000},
+		{name: "1000x200", rows: 1000, cols: 200},
+	} {
+		b.Run(size.name, func(b *testing.B) {
+			cost := randomMatrix(rand.New(rand.NewPCG(1, 2)), size.rows, size.cols)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				Solve(cost)
+			}
+		})
+	}
+}
+
+func randomMatrix(rnd *rand.Rand, rows, cols int) [][]float64 {
+	cost := make([][]float64, rows)
+	for i := range cost {
+		cost[i] = make([]float64, cols)
+		for j := range cost[i] {
+			cost[i][j] = float64(rnd.IntN(1000))
+		}
+	}
+	return cost
+}
+
+func score(t *testing.T, cost [][]float64, assign []int) (int, float64) {
+	t.Helper()
+	seen := make(map[int]bool)
+	var pairs int
+	var total float64
+	for i, j := range assign {
+		if j < 0 {
+			continue
+		}
+		if seen[j] || math.IsInf(cost[i][j], 1) {
+			t.Fatalf("invalid matching %v", assign)
+		}
+		seen[j] = true
+		pairs++
+		total += cost[i][j]
+	}
+	return pairs, total
+}
+
+// bruteForce returns the largest number of allowed pairs and the lowest cost
+// among matchings of that size for the rows from row on.
+func bruteForce(cost [][]float64, row int, used []bool) (int, float64) {
+	if row == len(cost) {
+		return 0, 0
+	}
+	bestPairs, bestCost := bruteForce(cost, row+1, used)
+	for j := range used {
+		if used[j] || math.IsInf(cost[row][j], 1) {
+			continue
+		}
+		used[j] = true
+		pairs, total := bruteForce(cost, row+1, used)
+		used[j] = false
+		pairs++
+		total += cost[row][j]
+		if pairs > bestPairs || (pairs == bestPairs && total < bestCost) {
+			bestPairs, bestCost = pairs, total
+		}
+	}
+	return bestPairs, bestCost
+}
@@ -0,0 +1,226 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DeadlineCalendar stretches delivery deadlines by time of day and date, e.g.
+// during the lunch rush or on holidays. Rules are checked in order and the
+// first one matching the assignment time, in the calendar's time zone, applies.
+// On a date listed by any rule only rules listing that date are considered, so
+// holidays override the weekday schedule.
+type DeadlineCalendar struct {
+	location *time.Location
+	rules    []calendarRule
+	holidays map[string]bool
+}
+
+type calendarRule struct {
+	weekdays map[time.Weekday]bool
+	dates    map[string]bool
+	// from and to are minutes since midnight, to is exclusive. A window with
+	// to before from spans midnight; equal values cover the whole day.
+	from, to   int
+	zoneID     *int
+	multiplier float64
+	extra      time.Duration
+}
+
+type calendarFile struct {
+	Timezone string             `json:"timezone"`
+	Rules    []calendarRuleFile `json:"rules"`
+}
+
+type calendarRuleFile struct {
+	Name         string   `json:"name"`
+	Days         []string `json:"days"`
+	Dates        []string `json:"dates"`
+	From         string   `json:"from"`
+	To           string   `json:"to"`
+	ZoneID       *int     `json:"zone_id"`
+	Multiplier   float64  `json:"multiplier"`
+	ExtraMinutes int      `json:"extra_minutes"`
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+const calendarDateLayout = "2006-01-02"
+
+// ParseDeadlineCalendar reads a calendar from JSON:
+//
+//	{"timezone": "Europe/Moscow", "rules": [
+//	  {"name": "lunch", "days": ["mon", "fri"], "from": "12:00", "to": "14:00", "multiplier": 1.5},
+//	  {"name": "new year", "dates": ["2026-12-31"], "zone_id": 2, "extra_minutes": 20}
+//	]}
+//
+// Rules without days or dates apply every day, rules without from and to the
+// whole day. The time zone defaults to UTC.
+func ParseDeadlineCalendar(data []byte) (*DeadlineCalendar, error) {
+	var file calendarFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse deadline calendar: %w", err)
+	}
+
+	location := time.UTC
+	if file.Timezone != "" {
+		loc, err := time.LoadLocation(file.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("parse deadline calendar: %w", err)
+		}
+		location = loc
+	}
+
+	cal := &DeadlineCalendar{location: location, holidays: make(map[string]bool)}
+	for _, raw := range file.Rules {
+		rule, err := parseCalendarRule(raw)
+		if err != nil {
+			return nil, fmt.Errorf("parse deadline calendar: rule %q: %w", raw.Name, err)
+		}
+		for date := range rule.dates {
+			cal.holidays[date] = true
+		}
+		cal.rules = append(cal.rules, rule)
+	}
+	return cal, nil
+}
+
+func parseCalendarRule(raw calendarRuleFile) (calendarRule, error) {
+	rule := calendarRule{
+		zoneID:     raw.ZoneID,
+		multiplier: raw.Multiplier,
+		extra:      time.Duration(raw.ExtraMinutes) * time.Minute,
+	}
+	if rule.multiplier == 0 {
+		rule.multiplier = 1
+	}
+	if rule.multiplier < 0 {
+		return rule, fmt.Errorf("multiplier must not be negative")
+	}
+	if len(raw.Days) > 0 && len(raw.Dates) > 0 {
+		return rule, fmt.Errorf("days and dates are exclusive")
+	}
+
+	if len(raw.Days) > 0 {
+		rule.weekdays = make(map[time.Weekday]bool, len(raw.Days))
+		for _, day := range raw.Days {
+			weekday, ok := weekdayNames[strings.ToLower(strings.TrimSpace(day))]
+			if !ok {
+				return rule, fmt.Errorf("unknown day %q", day)
+			}
+			rule.weekdays[weekday] = true
+		}
+	}
+	if len(raw.Dates) > 0 {
+		rule.dates = make(map[string]bool, len(raw.Dates))
+		for _, date := range raw.Dates {
+			parsed, err := time.Parse(calendarDateLayout, strings.TrimSpace(date))
+			if err != nil {
+				return rule, fmt.Errorf("invalid date %q", date)
+			}
+			rule.dates[parsed.Format(calendarDateLayout)] = true
+		}
+	}
+
+	var err error
+	if rule.from, err = parseClock(raw.From); err != nil {
+		return rule, err
+	}
+	if rule.to, err = parseClock(raw.To); err != nil {
+		return rule, err
+	}
+	return rule, nil
+}
+
+// parseClock converts "HH:MM" to minutes since midnight, empty means midnight.
+func parseClock(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// UsesZones reports whether any rule is limited to a zone.
+func (c *DeadlineCalendar) UsesZones() bool {
+	for _, rule := range c.rules {
+		if rule.zoneID != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// wrap adjusts base by the calendar for the delivery.
+func (c *DeadlineCalendar) wrap(base DeliveryPolicy, delivery DeliveryContext) DeliveryPolicy {
+	return calendarPolicy{base: base, calendar: c, zoneID: delivery.ZoneID}
+}
+
+func (c *DeadlineCalendar) match(now time.Time, zoneID *int) *calendarRule {
+	local := now.In(c.location)
+	date := local.Format(calendarDateLayout)
+	holiday := c.holidays[date]
+	minute := local.Hour()*60 + local.Minute()
+
+	for i := range c.rules {
+		rule := &c.rules[i]
+		if holiday {
+			if !rule.dates[date] {
+				continue
+			}
+		} else if rule.dates != nil || (rule.weekdays != nil && !rule.weekdays[local.Weekday()]) {
+			continue
+		}
+		if !rule.covers(minute) {
+			continue
+		}
+		if rule.zoneID != nil && (zoneID == nil || *rule.zoneID != *zoneID) {
+			continue
+		}
+		return rule
+	}
+	return nil
+}
+
+func (r *calendarRule) covers(minute int) bool {
+	switch {
+	case r.from == r.to:
+		return true
+	case r.from < r.to:
+		return minute >= r.from && minute < r.to
+	default:
+		return minute >= r.from || minute < r.to
+	}
+}
+
+// calendarPolicy scales the time the base policy allows by the rule matching
+// the assignment time and adds the rule's extra minutes.
+type calendarPolicy struct {
+	base     DeliveryPolicy
+	calendar *DeadlineCalendar
+	zoneID   *int
+}
+
+func (p calendarPolicy) Deadline(now time.Time) time.Time {
+	deadline := p.base.Deadline(now)
+	rule := p.calendar.match(now, p.zoneID)
+	if rule == nil {
+		return deadline
+	}
+	allowed := time.Duration(float64(deadline.Sub(now)) * rule.multiplier)
+	return now.Add(allowed + rule.extra)
+}
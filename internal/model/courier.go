@@ -1,6 +1,11 @@
 package model
 
-import "time"
+import (
+	"slices"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/geo"
+)
 
 type CourierModel struct {
 	ID               int
@@ -8,7 +13,156 @@ type CourierModel struct {
 	Phone            string
 	Status           CourierStatus
 	TransportType    TransportType
-	AssignmentsCount int
-	CreatedAt        time.Time
-	UpdatedAt        time.Time
+	ActiveDeliveries int
+	// ZoneID is the home zone of the courier, nil when the courier is not tied to one.
+	ZoneID *int
+	// Location is the last known position of the courier, nil when unknown.
+	Location          *geo.Point
+	LocationUpdatedAt *time.Time
+	// Score is the last computed performance score, nil when the courier has
+	// none yet. It is only loaded by GetOneById and for courier selection.
+	Score *CourierScore
+	// Version grows with every change of a field clients can edit: name,
+	// phone, status, transport type and zone, including status changes made by
+	// the service itself. It is the ETag clients send back in If-Match.
+	Version   int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// CourierPatch is a partial update of a courier; nil fields are kept. The
+// zone is only changed when ZoneSet is true, a nil ZoneID then removes it.
+type CourierPatch struct {
+	Name          *string
+	Phone         *string
+	Status        *CourierStatus
+	TransportType *TransportType
+	ZoneSet       bool
+	ZoneID        *int
+}
+
+// Apply changes the courier fields set in the patch except the status, which
+// has to go through the allowed status transitions.
+func (p *CourierPatch) Apply(courier *CourierModel) {
+	if p.Name != nil {
+		courier.Name = *p.Name
+	}
+	if p.Phone != nil {
+		courier.Phone = *p.Phone
+	}
+	if p.TransportType != nil {
+		courier.TransportType = *p.TransportType
+	}
+	if p.ZoneSet {
+		courier.ZoneID = p.ZoneID
+	}
+}
+
+// VersionMatch is the precondition of a courier change taken from an If-Match
+// header: when Required, the courier must be at one of Versions. Weak ETags
+// never match, so they are not listed.
+type VersionMatch struct {
+	Required bool
+	Versions []int
+}
+
+// Matches reports whether a courier at the version meets the precondition.
+func (m VersionMatch) Matches(version int) bool {
+	return !m.Required || slices.Contains(m.Versions, version)
+}
+
+// CourierCandidate is a courier that may take the next delivery, together with
+// the load figures courier selection strategies rank by.
+type CourierCandidate struct {
+	Courier          *CourierModel
+	AssignmentsToday int
+	// RecentLoad is the number of deliveries assigned within the fairness
+	// window, weighted by their age under FairnessDecay.
+	RecentLoad     float64
+	LastAssignedAt *time.Time
+}
+
+// AvailableCourierFilter narrows down the couriers considered for a new delivery.
+type AvailableCourierFilter struct {
+	Capacity   CourierCapacity
+	ExcludeIDs []int
+	// Since is the start of the day assignments are counted from.
+	Since time.Time
+	// LoadSince is the start of the fairness window, see CourierCandidate.RecentLoad.
+	LoadSince time.Time
+	// LoadByShift counts the load of a courier in a shift at Now from the
+	// start of that shift instead of LoadSince.
+	LoadByShift bool
+	// LoadHalfLife weights assignments by their age at Now when set; zero
+	// counts each one as one.
+	LoadHalfLife time.Duration
+	Now          time.Time
+	// Cells limits the search to couriers last seen in these geohash cells
+	// (geo.CellPrecision characters long). Empty means anywhere.
+	Cells []string
+	// Transports limits the search to couriers with these transport types.
+	// Nil means any transport.
+	Transports []TransportType
+}
+
+// CourierCapacity is the number of deliveries a courier may carry at once, per transport type.
+type CourierCapacity map[TransportType]int
+
+func NewCourierCapacity(onFoot, scooter, car int) CourierCapacity {
+	return CourierCapacity{
+		TransportOnFoot:  onFoot,
+		TransportScooter: scooter,
+		TransportCar:     car,
+	}
+}
+
+// For returns the capacity of the transport type. Unknown or non-positive
+// values fall back to a single delivery.
+func (c CourierCapacity) For(transport TransportType) int {
+	if capacity := c[transport]; capacity > 0 {
+		return capacity
+	}
+	return 1
+}
+
+// CanTake reports whether the courier may be given one more delivery.
+func (c CourierCapacity) CanTake(courier *CourierModel) bool {
+	switch courier.Status {
+	case CourierStatusAvailable:
+		return courier.ActiveDeliveries < c.For(courier.TransportType)
+	case CourierStatusBusy:
+		return courier.ActiveDeliveries > 0 && courier.ActiveDeliveries < c.For(courier.TransportType)
+	}
+	return false
+}
+
+// TransportSpeeds is the average speed in km/h per transport type.
+type TransportSpeeds map[TransportType]float64
+
+func NewTransportSpeeds(onFoot, scooter, car float64) TransportSpeeds {
+	return TransportSpeeds{
+		TransportOnFoot:  onFoot,
+		TransportScooter: scooter,
+		TransportCar:     car,
+	}
+}
+
+// For returns the speed of the transport type. Unknown or non-positive values
+// fall back to walking speed.
+func (s TransportSpeeds) For(transport TransportType) float64 {
+	if speed := s[transport]; speed > 0 {
+		return speed
+	}
+	if speed := s[TransportOnFoot]; speed > 0 {
+		return speed
+	}
+	return defaultWalkingSpeed
 }
+
+// TravelTime estimates how long the transport needs to cover the distance in meters.
+func (s TransportSpeeds) TravelTime(transport TransportType, meters float64) time.Duration {
+	hours := meters / 1000 / s.For(transport)
+	return time.Duration(hours * float64(time.Hour))
+}
+
+const defaultWalkingSpeed = 5
@@ -1,11 +1,75 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/geo"
+)
 
 type DeliveryModel struct {
-	ID         int
-	CourierId  int
-	OrderId    string
-	AssignedAt time.Time
-	Deadline   time.Time
+	ID          int
+	CourierId   int
+	OrderId     string
+	Status      DeliveryStatus
+	AssignedAt  time.Time
+	Deadline    time.Time
+	PickedUpAt  *time.Time
+	InTransitAt *time.Time
+	DeliveredAt *time.Time
+	CancelledAt *time.Time
+	ExpiredAt   *time.Time
+	UpdatedAt   time.Time
+	// IdempotencyKey is the client supplied key the delivery was created with, if any.
+	IdempotencyKey string
+	// Pickup is where the courier collects the order, nil when unknown.
+	Pickup *geo.Point
+	// Dropoff is the delivery address, nil when unknown.
+	Dropoff *geo.Point
+	// Order describes the contents of the order, nil when unknown.
+	Order *OrderDetails
+	// PromisedBy is the delivery time promised to the customer, nil when none.
+	PromisedBy *time.Time
+	// AtRisk is set when the deadline policy allowed more time than the
+	// promise left, so the promise is likely to be missed. The deadline is
+	// not moved up to the promise.
+	AtRisk bool
+}
+
+type DeliveryFilter struct {
+	CourierID    int
+	Statuses     []DeliveryStatus
+	AssignedFrom *time.Time
+	AssignedTo   *time.Time
+	OverdueOnly  bool
+	// AtRiskOnly keeps deliveries flagged as likely to miss the customer promise.
+	AtRiskOnly bool
+	// Now is the reference time for OverdueOnly; it is filled in by the usecase.
+	Now    time.Time
+	Limit  int
+	Offset int
+}
+
+type DeliveryPage struct {
+	Items  []*DeliveryModel
+	Total  int
+	Limit  int
+	Offset int
+}
+
+// ExpiredAction is what was done with an order whose delivery expired.
+type ExpiredAction string
+
+const (
+	ExpiredActionFlagged    ExpiredAction = "flagged"
+	ExpiredActionReassigned ExpiredAction = "reassigned"
+	ExpiredActionEscalated  ExpiredAction = "escalated"
+)
+
+// ExpiredDelivery reports one order affected by a deadline expiry run.
+type ExpiredDelivery struct {
+	OrderID      string
+	DeliveryID   int
+	CourierID    int
+	Action       ExpiredAction
+	NewCourierID int
 }
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+type DeliveryEventType string
+
+const (
+	DeliveryEventAssigned   DeliveryEventType = "assigned"
+	DeliveryEventUnassigned DeliveryEventType = "unassigned"
+	DeliveryEventPickedUp   DeliveryEventType = "picked_up"
+	DeliveryEventInTransit  DeliveryEventType = "in_transit"
+	DeliveryEventReassigned DeliveryEventType = "reassigned"
+	DeliveryEventCompleted  DeliveryEventType = "completed"
+	DeliveryEventExpired    DeliveryEventType = "expired"
+)
+
+type EventSource string
+
+const (
+	EventSourceHTTP    EventSource = "http"
+	EventSourceKafka   EventSource = "kafka"
+	EventSourcePoller  EventSource = "poller"
+	EventSourceMonitor EventSource = "monitor"
+	EventSourceQueue   EventSource = "queue"
+)
+
+type DeliveryEvent struct {
+	ID         int
+	DeliveryID int
+	OrderID    string
+	CourierID  int
+	Type       DeliveryEventType
+	FromStatus DeliveryStatus
+	ToStatus   DeliveryStatus
+	Source     EventSource
+	Actor      string
+	Reason     string
+	CreatedAt  time.Time
+}
+
+// EventTrigger describes who or what caused a delivery change. Entry points
+// (HTTP handlers, Kafka processor, workers) put it into the context so that the
+// usecase can record it in the delivery history.
+type EventTrigger struct {
+	Source EventSource
+	Actor  string
+	Reason string
+}
+
+type eventTriggerContextKey struct{}
+
+func WithEventTrigger(ctx context.Context, trigger EventTrigger) context.Context {
+	return context.WithValue(ctx, eventTriggerContextKey{}, trigger)
+}
+
+func EventTriggerFromContext(ctx context.Context) EventTrigger {
+	if v, ok := ctx.Value(eventTriggerContextKey{}).(EventTrigger); ok {
+		return v
+	}
+	return EventTrigger{}
+}
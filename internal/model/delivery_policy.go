@@ -1,11 +1,34 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/geo"
+)
 
 type DeliveryPolicy interface {
 	Deadline(now time.Time) time.Time
 }
 
+// DeliveryContext is what is known about a delivery when its deadline is set.
+type DeliveryContext struct {
+	Transport TransportType
+	Pickup    *geo.Point
+	Dropoff   *geo.Point
+	Order     *OrderDetails
+	// ZoneID is the zone of the order, nil when unknown or outside every zone.
+	ZoneID *int
+}
+
+// Distance returns the pickup to dropoff distance in meters. ok is false when
+// either point is unknown.
+func (c DeliveryContext) Distance() (meters float64, ok bool) {
+	if c.Pickup == nil || c.Dropoff == nil {
+		return 0, false
+	}
+	return geo.Distance(*c.Pickup, *c.Dropoff), true
+}
+
 type fixedDurationPolicy struct {
 	duration time.Duration
 }
@@ -14,14 +37,51 @@ func (p fixedDurationPolicy) Deadline(now time.Time) time.Time {
 	return now.Add(p.duration)
 }
 
+// distancePolicy gives the courier the time needed to travel the route plus a
+// fixed overhead for picking up and handing over the order.
+type distancePolicy struct {
+	travel   time.Duration
+	overhead time.Duration
+}
+
+func (p distancePolicy) Deadline(now time.Time) time.Time {
+	return now.Add(p.travel + p.overhead)
+}
+
 type DeliveryTimeFactory struct {
 	defaultPolicy DeliveryPolicy
 	policies      map[TransportType]DeliveryPolicy
+	speeds        TransportSpeeds
+	overhead      time.Duration
+	calendar      *DeadlineCalendar
+}
+
+// DeliveryTimeOption customizes optional behaviour of DeliveryTimeFactory.
+type DeliveryTimeOption func(*DeliveryTimeFactory)
+
+// WithDistanceDeadlines derives deadlines from the pickup to dropoff distance,
+// the average speed of the transport and a fixed handling overhead. Deliveries
+// with an unknown route keep the fixed duration of their transport.
+func WithDistanceDeadlines(speeds TransportSpeeds, overhead time.Duration) DeliveryTimeOption {
+	return func(f *DeliveryTimeFactory) {
+		f.speeds = speeds
+		if overhead > 0 {
+			f.overhead = overhead
+		}
+	}
+}
+
+// WithDeadlineCalendar adjusts every deadline by the calendar rule matching the
+// assignment time.
+func WithDeadlineCalendar(calendar *DeadlineCalendar) DeliveryTimeOption {
+	return func(f *DeliveryTimeFactory) {
+		f.calendar = calendar
+	}
 }
 
-func NewDeliveryTimeFactory(onFoot, scooter, car time.Duration) *DeliveryTimeFactory {
+func NewDeliveryTimeFactory(onFoot, scooter, car time.Duration, opts ...DeliveryTimeOption) *DeliveryTimeFactory {
 	defaultPolicy := fixedDurationPolicy{duration: onFoot}
-	return &DeliveryTimeFactory{
+	f := &DeliveryTimeFactory{
 		defaultPolicy: defaultPolicy,
 		policies: map[TransportType]DeliveryPolicy{
 			TransportOnFoot:  fixedDurationPolicy{duration: onFoot},
@@ -29,8 +89,39 @@ func NewDeliveryTimeFactory(onFoot, scooter, car time.Duration) *DeliveryTimeFac
 			TransportCar:     fixedDurationPolicy{duration: car},
 		},
 	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// For returns the deadline policy of the delivery.
+func (f *DeliveryTimeFactory) For(delivery DeliveryContext) DeliveryPolicy {
+	policy := f.basePolicy(delivery)
+	if f.calendar != nil {
+		return f.calendar.wrap(policy, delivery)
+	}
+	return policy
+}
+
+// UsesZones reports whether deadlines depend on DeliveryContext.ZoneID.
+func (f *DeliveryTimeFactory) UsesZones() bool {
+	return f.calendar != nil && f.calendar.UsesZones()
+}
+
+func (f *DeliveryTimeFactory) basePolicy(delivery DeliveryContext) DeliveryPolicy {
+	if f.speeds != nil {
+		if meters, ok := delivery.Distance(); ok {
+			return distancePolicy{
+				travel:   f.speeds.TravelTime(delivery.Transport, meters),
+				overhead: f.overhead,
+			}
+		}
+	}
+	return f.ForTransport(delivery.Transport)
 }
 
+// ForTransport returns the fixed duration policy of the transport type.
 func (f *DeliveryTimeFactory) ForTransport(transport TransportType) DeliveryPolicy {
 	if policy, ok := f.policies[transport]; ok {
 		return policy
@@ -0,0 +1,126 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// OrderDetails summarizes the contents of an order for transport eligibility.
+type OrderDetails struct {
+	ItemCount  int   `json:"item_count"`
+	Quantity   int   `json:"quantity"`
+	TotalPrice int64 `json:"total_price"`
+	// WeightGrams is the weight declared by the order service, 0 when unknown.
+	WeightGrams int `json:"weight_grams"`
+}
+
+// Valid reports whether no figure is negative.
+func (d OrderDetails) Valid() bool {
+	return d.ItemCount >= 0 && d.Quantity >= 0 && d.TotalPrice >= 0 && d.WeightGrams >= 0
+}
+
+// Details summarizes the order. Item count is the number of order lines and
+// quantity the sum of their quantities.
+func (o *Order) Details() OrderDetails {
+	details := OrderDetails{
+		ItemCount:   len(o.Items),
+		TotalPrice:  o.TotalPrice,
+		WeightGrams: o.WeightGrams,
+	}
+	for _, item := range o.Items {
+		details.Quantity += item.Quantity
+	}
+	return details
+}
+
+// EligibilityRule limits the transports allowed to carry an order. The rule
+// applies when the order reaches every threshold set on it; zero thresholds are
+// ignored.
+type EligibilityRule struct {
+	Name           string          `json:"name"`
+	MinItems       int             `json:"min_items,omitempty"`
+	MinQuantity    int             `json:"min_quantity,omitempty"`
+	MinTotalPrice  int64           `json:"min_total_price,omitempty"`
+	MinWeightGrams int             `json:"min_weight_grams,omitempty"`
+	Transports     []TransportType `json:"transports"`
+}
+
+// Matches reports whether the rule applies to the order.
+func (r EligibilityRule) Matches(d OrderDetails) bool {
+	return d.ItemCount >= r.MinItems &&
+		d.Quantity >= r.MinQuantity &&
+		d.TotalPrice >= r.MinTotalPrice &&
+		d.WeightGrams >= r.MinWeightGrams
+}
+
+func (r EligibilityRule) validate() error {
+	if r.MinItems < 0 || r.MinQuantity < 0 || r.MinTotalPrice < 0 || r.MinWeightGrams < 0 {
+		return fmt.Errorf("rule %q: thresholds must not be negative", r.Name)
+	}
+	if r.MinItems == 0 && r.MinQuantity == 0 && r.MinTotalPrice == 0 && r.MinWeightGrams == 0 {
+		return fmt.Errorf("rule %q: at least one threshold is required", r.Name)
+	}
+	if len(r.Transports) == 0 {
+		return fmt.Errorf("rule %q: no transports", r.Name)
+	}
+	for _, transport := range r.Transports {
+		switch transport {
+		case TransportOnFoot, TransportScooter, TransportCar:
+		default:
+			return fmt.Errorf("rule %q: unknown transport %q", r.Name, transport)
+		}
+	}
+	return nil
+}
+
+// EligibilityRules maps orders to the transport types allowed to carry them.
+type EligibilityRules []EligibilityRule
+
+// ParseEligibilityRules reads rules from a JSON array. Empty input means no rules.
+func ParseEligibilityRules(raw string) (EligibilityRules, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var rules EligibilityRules
+	if err := json.Unmarshal([]byte(raw), &rules); err != nil {
+		return nil, fmt.Errorf("parse eligibility rules: %w", err)
+	}
+	for _, rule := range rules {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("parse eligibility rules: %w", err)
+		}
+	}
+	return rules, nil
+}
+
+// Allowed returns the transports allowed for the order: those permitted by
+// every matching rule. ok is false when no rule matches and any transport may
+// carry the order.
+func (r EligibilityRules) Allowed(d OrderDetails) (transports []TransportType, ok bool) {
+	for _, rule := range r {
+		if !rule.Matches(d) {
+			continue
+		}
+		if !ok {
+			transports = append([]TransportType{}, rule.Transports...)
+			ok = true
+			continue
+		}
+		transports = intersectTransports(transports, rule.Transports)
+	}
+	return transports, ok
+}
+
+func intersectTransports(a, b []TransportType) []TransportType {
+	out := []TransportType{}
+	for _, transport := range a {
+		for _, other := range b {
+			if transport == other {
+				out = append(out, transport)
+				break
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,124 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FairnessMode decides over which period the recent load of a courier, used to
+// spread orders evenly, is counted.
+type FairnessMode string
+
+const (
+	// FairnessShift counts the deliveries assigned since the courier's current
+	// shift began. Couriers off their own shift schedule use the shift
+	// boundaries of the window.
+	FairnessShift FairnessMode = "shift"
+	// FairnessRolling counts the deliveries assigned within the last period.
+	FairnessRolling FairnessMode = "rolling"
+	// FairnessDecay weights the deliveries of the last period by their age.
+	FairnessDecay FairnessMode = "decay"
+)
+
+func (m FairnessMode) IsValid() bool {
+	switch m {
+	case FairnessShift, FairnessRolling, FairnessDecay:
+		return true
+	}
+	return false
+}
+
+const (
+	defaultFairnessPeriod   = 24 * time.Hour
+	defaultFairnessHalfLife = 6 * time.Hour
+)
+
+// FairnessWindow is the period the recent load of couriers is computed over,
+// from their delivery records.
+type FairnessWindow struct {
+	mode     FairnessMode
+	period   time.Duration
+	halfLife time.Duration
+	// shiftStarts are minutes since midnight UTC, sorted.
+	shiftStarts []int
+}
+
+// NewFairnessWindow creates the window. period bounds the rolling and decay
+// modes, halfLife is the age at which a delivery counts half under decay and
+// shiftStarts the times of day shifts begin. Unknown modes fall back to
+// FairnessRolling, non-positive durations to 24 hours and 6 hours, and no
+// shift starts to a single shift from midnight UTC.
+func NewFairnessWindow(mode FairnessMode, period, halfLife time.Duration, shiftStarts []int) FairnessWindow {
+	w := FairnessWindow{
+		mode:        mode,
+		period:      period,
+		halfLife:    halfLife,
+		shiftStarts: append([]int(nil), shiftStarts...),
+	}
+	if !mode.IsValid() {
+		w.mode = FairnessRolling
+	}
+	if period <= 0 {
+		w.period = defaultFairnessPeriod
+	}
+	if halfLife <= 0 {
+		w.halfLife = defaultFairnessHalfLife
+	}
+	sort.Ints(w.shiftStarts)
+	return w
+}
+
+// ParseShiftStarts reads comma separated "HH:MM" times of day in UTC.
+func ParseShiftStarts(raw string) ([]int, error) {
+	var starts []int
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		minute, err := parseClock(part)
+		if err != nil {
+			return nil, fmt.Errorf("parse shift starts: %w", err)
+		}
+		starts = append(starts, minute)
+	}
+	return starts, nil
+}
+
+// Since returns the start of the window at now. In shift mode it is the start
+// of the shift given by the window's shift starts.
+func (w FairnessWindow) Since(now time.Time) time.Time {
+	if w.mode != FairnessShift {
+		return now.Add(-w.period)
+	}
+
+	midnight := now.Truncate(24 * time.Hour)
+	minute := int(now.Sub(midnight) / time.Minute)
+	if len(w.shiftStarts) == 0 {
+		return midnight
+	}
+	// The shift that began last, yesterday's final one before the first start of today.
+	start := midnight.Add(time.Duration(w.shiftStarts[len(w.shiftStarts)-1]-24*60) * time.Minute)
+	for _, s := range w.shiftStarts {
+		if s <= minute {
+			start = midnight.Add(time.Duration(s) * time.Minute)
+		}
+	}
+	return start
+}
+
+// ByCourierShift reports whether the load of a courier on a shift is counted
+// from the start of that shift rather than from Since.
+func (w FairnessWindow) ByCourierShift() bool {
+	return w.mode == FairnessShift
+}
+
+// HalfLife returns the half-life assignments are weighted with, zero when every
+// assignment in the window counts as one.
+func (w FairnessWindow) HalfLife() time.Duration {
+	if w.mode == FairnessDecay {
+		return w.halfLife
+	}
+	return 0
+}
@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/geo"
+)
+
+// LocationPing is a position reported by the courier app.
+type LocationPing struct {
+	CourierID int
+	Point     geo.Point
+	// Accuracy is the radius of uncertainty in meters as reported by the device.
+	Accuracy   float64
+	RecordedAt time.Time
+}
@@ -1,6 +1,10 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/geo"
+)
 
 type Order struct {
 	ID                string          `json:"id"`
@@ -10,6 +14,7 @@ type Order struct {
 	RestaurantID      string          `json:"restaurant_id"`
 	Items             []Item          `json:"items"`
 	TotalPrice        int64           `json:"total_price"`
+	WeightGrams       int             `json:"weight_grams,omitempty"` // not reported by the order service
 	Address           DeliveryAddress `json:"address"`
 	Status            string          `json:"status"`
 	CreatedAt         time.Time       `json:"created_at"`
@@ -35,4 +40,27 @@ type DeliveryAddress struct {
 type AssignCourierRequest struct {
 	OrderID   string `json:"order_id"`
 	CourierID int    `json:"courier_id"`
+	// Priority orders the request in the pending queue when no courier is free.
+	Priority int `json:"priority"`
+	// Pickup is where the courier collects the order, used by geo-aware dispatch.
+	Pickup *geo.Point `json:"pickup,omitempty"`
+	// Dropoff is the delivery address; it places the order in a zone when there is no pickup.
+	Dropoff *geo.Point `json:"dropoff,omitempty"`
+	// Order describes the contents of the order, nil when unknown. It decides
+	// which transports may carry the order.
+	Order *OrderDetails `json:"order,omitempty"`
+	// PromisedBy is the delivery time promised to the customer, nil when none.
+	// The delivery deadline never exceeds it.
+	PromisedBy *time.Time `json:"promised_by,omitempty"`
+	// IdempotencyKey makes retried requests return the delivery created by the first one.
+	IdempotencyKey string `json:"-"`
+}
+
+// AssignmentResult is the outcome of assigning one order of a batch. Err is set
+// when the order got no courier, e.g. usecase errors for a queued order.
+type AssignmentResult struct {
+	OrderID  string
+	Delivery *DeliveryModel
+	Courier  *CourierModel
+	Err      error
 }
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/geo"
+)
+
+// PendingAssignment is an order waiting in the queue for a free courier.
+type PendingAssignment struct {
+	ID            int
+	OrderID       string
+	Priority      int
+	Pickup        *geo.Point
+	Dropoff       *geo.Point
+	Order         *OrderDetails
+	PromisedBy    *time.Time
+	Attempts      int
+	LastError     string
+	EnqueuedAt    time.Time
+	LastAttemptAt *time.Time
+}
+
+// PendingQueueStats describes the current state of the pending assignment queue.
+type PendingQueueStats struct {
+	Depth            int
+	OldestEnqueuedAt *time.Time
+	OldestWait       time.Duration
+}
@@ -0,0 +1,90 @@
+package model
+
+import "time"
+
+// Weights of the score components, they add up to one.
+const (
+	onTimeScoreWeight = 0.5
+	cancelScoreWeight = 0.3
+	speedScoreWeight  = 0.2
+)
+
+// NeutralScore stands in for the score of a courier without finished
+// deliveries in the scoring window.
+const NeutralScore = 0.5
+
+// CourierStats are the deliveries of a courier finished within the scoring
+// window, read from the delivery records.
+type CourierStats struct {
+	CourierID int
+	Delivered int
+	// OnTime is the number of delivered orders handed over by their deadline.
+	OnTime  int
+	Expired int
+	// Cancelled counts deliveries unassigned, reassigned to another courier or
+	// cancelled with the order.
+	Cancelled int
+	// DeadlineUsage is the average share of the time allowed by the deadline
+	// that delivered orders took, zero when none was delivered.
+	DeadlineUsage float64
+}
+
+// Finished is the number of deliveries the statistics are made of.
+func (s CourierStats) Finished() int {
+	return s.Delivered + s.Expired + s.Cancelled
+}
+
+// CourierScore rates how reliably a courier delivers, from 0 (worst) to 1.
+type CourierScore struct {
+	CourierID int
+	Score     float64
+	// OnTimeRate is the share of delivered or expired orders delivered by
+	// their deadline.
+	OnTimeRate float64
+	// CancelRate is the share of finished deliveries taken away from the courier.
+	CancelRate float64
+	// DeadlineUsage is the average share of the deadline delivered orders took.
+	DeadlineUsage float64
+	// Deliveries is the number of finished deliveries the score is based on.
+	Deliveries int
+	UpdatedAt  time.Time
+}
+
+// NewCourierScore scores the statistics of a courier at the given time, nil
+// when the courier finished no delivery. The score weights the on-time rate by
+// half, the share of deliveries not cancelled by 0.3 and the speed by 0.2.
+// Speed is full for orders delivered within half of the deadline and drops to
+// nothing at the deadline.
+func NewCourierScore(stats CourierStats, at time.Time) *CourierScore {
+	finished := stats.Finished()
+	if finished == 0 {
+		return nil
+	}
+
+	score := &CourierScore{
+		CourierID:     stats.CourierID,
+		CancelRate:    float64(stats.Cancelled) / float64(finished),
+		DeadlineUsage: stats.DeadlineUsage,
+		Deliveries:    finished,
+		UpdatedAt:     at,
+	}
+	if completed := stats.Delivered + stats.Expired; completed > 0 {
+		score.OnTimeRate = float64(stats.OnTime) / float64(completed)
+	}
+	var speed float64
+	if stats.Delivered > 0 {
+		speed = min(max(2*(1-stats.DeadlineUsage), 0), 1)
+	}
+	score.Score = onTimeScoreWeight*score.OnTimeRate +
+		cancelScoreWeight*(1-score.CancelRate) +
+		speedScoreWeight*speed
+	return score
+}
+
+// ScoreOf returns the score of the courier, NeutralScore when it has none.
+func ScoreOf(courier *CourierModel) float64 {
+	if courier.Score == nil {
+		return NeutralScore
+	}
+	return courier.Score.Score
+}
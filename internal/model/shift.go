@@ -0,0 +1,125 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxShiftLength bounds a single shift.
+const MaxShiftLength = 24 * time.Hour
+
+// Shift is a period a courier is planned to work. Couriers with shifts may
+// only take deliveries they can finish before the shift ends, couriers without
+// any shift are not restricted.
+type Shift struct {
+	ID        int
+	CourierID int
+	StartsAt  time.Time
+	EndsAt    time.Time
+	// TemplateID is the template the shift was planned from, nil for shifts
+	// planned one by one.
+	TemplateID *int
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// ShiftFilter selects shifts. Zero values do not restrict.
+type ShiftFilter struct {
+	CourierID int
+	// From and To select the shifts overlapping the period.
+	From *time.Time
+	To   *time.Time
+}
+
+// ShiftTemplate repeats a shift every week on the given weekdays. StartMinute
+// and EndMinute are minutes since midnight UTC; an end before the start makes
+// the shift run past midnight.
+type ShiftTemplate struct {
+	ID          int
+	CourierID   int
+	Weekdays    []time.Weekday
+	StartMinute int
+	EndMinute   int
+	// GeneratedUntil is how far ahead shifts were planned from the template.
+	GeneratedUntil *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Length returns how long every shift of the template lasts.
+func (t *ShiftTemplate) Length() time.Duration {
+	minutes := (t.EndMinute - t.StartMinute + 24*60) % (24 * 60)
+	return time.Duration(minutes) * time.Minute
+}
+
+// Occurrences returns the shifts of the template that start after from and no
+// later than to.
+func (t *ShiftTemplate) Occurrences(from, to time.Time) []*Shift {
+	var shifts []*Shift
+	day := from.UTC().Truncate(24 * time.Hour)
+	for ; !day.After(to); day = day.Add(24 * time.Hour) {
+		if !t.runsOn(day.Weekday()) {
+			continue
+		}
+		start := day.Add(time.Duration(t.StartMinute) * time.Minute)
+		if !start.After(from) || start.After(to) {
+			continue
+		}
+		id := t.ID
+		shifts = append(shifts, &Shift{
+			CourierID:  t.CourierID,
+			StartsAt:   start,
+			EndsAt:     start.Add(t.Length()),
+			TemplateID: &id,
+		})
+	}
+	return shifts
+}
+
+func (t *ShiftTemplate) runsOn(day time.Weekday) bool {
+	for _, d := range t.Weekdays {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// ShiftRun reports what one pass of the shift schedule changed.
+type ShiftRun struct {
+	At time.Time
+	// Planned is the number of shifts planned from templates.
+	Planned int
+	// Started and Ended count the couriers made available and paused.
+	Started int
+	Ended   int
+}
+
+// ParseWeekday reads a weekday written like in the deadline calendar: "mon",
+// "tue" and so on.
+func ParseWeekday(name string) (time.Weekday, error) {
+	day, ok := weekdayNames[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return 0, fmt.Errorf("unknown day %q", name)
+	}
+	return day, nil
+}
+
+// WeekdayName writes the weekday the way ParseWeekday reads it.
+func WeekdayName(day time.Weekday) string {
+	return strings.ToLower(day.String()[:3])
+}
+
+// ParseClock reads a "HH:MM" time of day as minutes since midnight.
+func ParseClock(value string) (int, error) {
+	if strings.TrimSpace(value) == "" {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	return parseClock(value)
+}
+
+// FormatClock writes minutes since midnight as "HH:MM".
+func FormatClock(minute int) string {
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
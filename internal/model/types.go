@@ -1,14 +1,24 @@
 package model
 
+// CourierStatus tells whether a courier takes deliveries. An available courier
+// holds none, a busy one holds at least one and a paused one takes no new
+// deliveries, though it may still finish those it holds.
 type CourierStatus string
 
 const (
 	CourierStatusAvailable CourierStatus = "available"
 	CourierStatusBusy      CourierStatus = "busy"
 	CourierStatusPaused    CourierStatus = "paused"
-	CourierStatusActive    CourierStatus = "active"
 )
 
+func (s CourierStatus) IsValid() bool {
+	switch s {
+	case CourierStatusAvailable, CourierStatusBusy, CourierStatusPaused:
+		return true
+	}
+	return false
+}
+
 type TransportType string
 
 const (
@@ -16,3 +26,129 @@ const (
 	TransportScooter TransportType = "scooter"
 	TransportCar     TransportType = "car"
 )
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusAssigned  DeliveryStatus = "assigned"
+	DeliveryStatusPickedUp  DeliveryStatus = "picked_up"
+	DeliveryStatusInTransit DeliveryStatus = "in_transit"
+	DeliveryStatusDelivered DeliveryStatus = "delivered"
+	DeliveryStatusCancelled DeliveryStatus = "cancelled"
+	DeliveryStatusExpired   DeliveryStatus = "expired"
+	// DeliveryStatusLegacy closes deliveries finished before statuses were
+	// tracked, whose outcome is unknown.
+	DeliveryStatusLegacy DeliveryStatus = "legacy"
+)
+
+// ActiveDeliveryStatuses lists the statuses of a delivery that still occupies a courier.
+var ActiveDeliveryStatuses = []DeliveryStatus{
+	DeliveryStatusAssigned,
+	DeliveryStatusPickedUp,
+	DeliveryStatusInTransit,
+}
+
+func (s DeliveryStatus) IsActive() bool {
+	for _, active := range ActiveDeliveryStatuses {
+		if s == active {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpiredPolicy decides what happens to a delivery once its deadline has passed.
+type ExpiredPolicy string
+
+const (
+	// ExpiredPolicyFlag only marks the delivery expired and frees the courier.
+	ExpiredPolicyFlag ExpiredPolicy = "flag"
+	// ExpiredPolicyReassign hands the order over to another available courier.
+	ExpiredPolicyReassign ExpiredPolicy = "reassign"
+	// ExpiredPolicyEscalate leaves the order for a dispatcher to resolve.
+	ExpiredPolicyEscalate ExpiredPolicy = "escalate"
+)
+
+func (p ExpiredPolicy) IsValid() bool {
+	switch p {
+	case ExpiredPolicyFlag, ExpiredPolicyReassign, ExpiredPolicyEscalate:
+		return true
+	}
+	return false
+}
+
+// DispatchStrategy names the rule used to choose a courier for a new delivery.
+type DispatchStrategy string
+
+const (
+	// DispatchLeastRecent prefers idle couriers, then the lowest load in the
+	// fairness window.
+	DispatchLeastRecent DispatchStrategy = "least-recent-assignments"
+	// DispatchLeastLifetime is the former name of DispatchLeastRecent.
+	//
+	// Deprecated: the lifetime counter made new couriers take every order until
+	// they caught up with veterans, the strategy now ranks like DispatchLeastRecent.
+	DispatchLeastLifetime DispatchStrategy = "least-lifetime-assignments"
+	// DispatchLeastToday prefers the fewest deliveries assigned since midnight UTC.
+	DispatchLeastToday DispatchStrategy = "least-assignments-today"
+	// DispatchRoundRobin cycles through couriers in id order.
+	DispatchRoundRobin DispatchStrategy = "round-robin"
+	// DispatchLongestIdle prefers the courier whose last assignment is the oldest.
+	DispatchLongestIdle DispatchStrategy = "longest-idle"
+	// DispatchRandomWeighted picks at random, favouring less loaded couriers.
+	DispatchRandomWeighted DispatchStrategy = "random-weighted"
+	// DispatchNearest prefers the courier that reaches the pickup point first.
+	DispatchNearest DispatchStrategy = "nearest"
+)
+
+func (s DispatchStrategy) IsValid() bool {
+	switch s {
+	case DispatchLeastRecent, DispatchLeastLifetime, DispatchLeastToday, DispatchRoundRobin, DispatchLongestIdle, DispatchRandomWeighted,
+		DispatchNearest:
+		return true
+	}
+	return false
+}
+
+// AssignMode decides how orders polled from the order service are matched with couriers.
+type AssignMode string
+
+const (
+	// AssignGreedy assigns the orders one after another, each to the best courier left.
+	AssignGreedy AssignMode = "greedy"
+	// AssignBatch matches all orders of a poll with the available couriers at
+	// the lowest total cost.
+	AssignBatch AssignMode = "batch"
+)
+
+// DeadlineMode decides how the deadline of a new delivery is computed.
+type DeadlineMode string
+
+const (
+	// DeadlineFixed gives every delivery the fixed duration of its transport.
+	DeadlineFixed DeadlineMode = "fixed"
+	// DeadlineDistance derives the deadline from the pickup to dropoff distance.
+	DeadlineDistance DeadlineMode = "distance"
+)
+
+// ZoneFallback decides which couriers may take an order when nobody from the
+// order's zone is free.
+type ZoneFallback string
+
+const (
+	// ZoneFallbackNone keeps the order for couriers of its zone and couriers
+	// without a zone.
+	ZoneFallbackNone ZoneFallback = "none"
+	// ZoneFallbackNeighbours lets couriers of neighbouring zones take the order.
+	ZoneFallbackNeighbours ZoneFallback = "neighbours"
+	// ZoneFallbackAny also lets couriers of every other zone take the order.
+	ZoneFallbackAny ZoneFallback = "any"
+)
+
+func (f ZoneFallback) IsValid() bool {
+	switch f {
+	case ZoneFallbackNone, ZoneFallbackNeighbours, ZoneFallbackAny:
+		return true
+	}
+	return false
+}
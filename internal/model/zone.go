@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/geo"
+)
+
+// Zone is a service district couriers are based in.
+type Zone struct {
+	ID   int
+	Name string
+	Area geo.Polygon
+	// NeighbourIDs are the zones whose couriers may help out, see ZoneFallback.
+	// Neighbourhood is symmetric: listing a zone on either side is enough.
+	NeighbourIDs []int
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// ZoneOf returns the zone containing p. When zones overlap the one with the
+// lowest id wins; nil means p is outside every zone.
+func ZoneOf(zones []*Zone, p geo.Point) *Zone {
+	var found *Zone
+	for _, z := range zones {
+		if z.Area.Contains(p) && (found == nil || z.ID < found.ID) {
+			found = z
+		}
+	}
+	return found
+}
+
+// NeighboursOf returns the ids of the zones neighbouring the zone with the given id.
+func NeighboursOf(zones []*Zone, id int) map[int]bool {
+	neighbours := make(map[int]bool)
+	for _, z := range zones {
+		if z.ID == id {
+			for _, n := range z.NeighbourIDs {
+				if n != id {
+					neighbours[n] = true
+				}
+			}
+			continue
+		}
+		for _, n := range z.NeighbourIDs {
+			if n == id {
+				neighbours[z.ID] = true
+			}
+		}
+	}
+	return neighbours
+}
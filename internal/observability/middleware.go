@@ -44,6 +44,56 @@ var (
 		},
 	)
 
+	deliveriesExpiredTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "deliveries_expired_total",
+			Help: "Total number of expired deliveries by follow-up action.",
+		},
+		[]string{"action"},
+	)
+
+	deliveriesAtRisk = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "deliveries_at_risk",
+			Help: "Number of active deliveries whose deadline is past the customer promise.",
+		},
+	)
+
+	pendingAssignmentsDepth = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "pending_assignments_depth",
+			Help: "Number of orders waiting for a free courier.",
+		},
+	)
+
+	pendingAssignmentsOldestWait = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "pending_assignments_oldest_wait_seconds",
+			Help: "Time the oldest queued order has been waiting for a courier.",
+		},
+	)
+
+	courierLocationsFlushedTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "courier_locations_flushed_total",
+			Help: "Total number of courier location pings written to the database.",
+		},
+	)
+
+	courierLocationsBuffered = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "courier_locations_buffered",
+			Help: "Number of courier location pings waiting to be written.",
+		},
+	)
+
+	couriersScored = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "couriers_scored",
+			Help: "Number of couriers with a performance score after the last recalculation.",
+		},
+	)
+
 	registerMetricsOnce sync.Once
 	requestLogger       = log.New(os.Stdout, "[INFO] ", log.LstdFlags)
 )
@@ -89,6 +139,13 @@ func RegisterMetrics() {
 			httpRequestDuration,
 			rateLimitExceededTotal,
 			gatewayRetriesTotal,
+			deliveriesExpiredTotal,
+			deliveriesAtRisk,
+			pendingAssignmentsDepth,
+			pendingAssignmentsOldestWait,
+			courierLocationsFlushedTotal,
+			courierLocationsBuffered,
+			couriersScored,
 		)
 	})
 }
@@ -102,3 +159,34 @@ func IncGatewayRetries() {
 	RegisterMetrics()
 	gatewayRetriesTotal.Inc()
 }
+
+func IncDeliveriesExpired(action string) {
+	RegisterMetrics()
+	deliveriesExpiredTotal.WithLabelValues(action).Inc()
+}
+
+func SetDeliveriesAtRisk(n int) {
+	RegisterMetrics()
+	deliveriesAtRisk.Set(float64(n))
+}
+
+func SetPendingAssignments(depth int, oldestWait time.Duration) {
+	RegisterMetrics()
+	pendingAssignmentsDepth.Set(float64(depth))
+	pendingAssignmentsOldestWait.Set(oldestWait.Seconds())
+}
+
+func AddCourierLocationsFlushed(n int) {
+	RegisterMetrics()
+	courierLocationsFlushedTotal.Add(float64(n))
+}
+
+func SetCourierLocationsBuffered(n int) {
+	RegisterMetrics()
+	courierLocationsBuffered.Set(float64(n))
+}
+
+func SetCouriersScored(n int) {
+	RegisterMetrics()
+	couriersScored.Set(float64(n))
+}
@@ -3,11 +3,14 @@ package courier
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 
+	"github.com/cdxy1/go-courier-service/internal/geo"
 	ipostgres "github.com/cdxy1/go-courier-service/internal/infra/postgres"
 	"github.com/cdxy1/go-courier-service/internal/model"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -22,27 +25,42 @@ func NewCourierRepository(conn *pgxpool.Pool) *CourierRepository {
 func (c *CourierRepository) Create(ctx context.Context, courier *model.CourierModel) (int, error) {
 	db := ipostgres.DBFromContext(ctx, c.conn)
 	var id int
-	query := `INSERT INTO couriers(name,phone,status,transport_type) VALUES ($1,$2,$3,$4) RETURNING id`
+	query := `INSERT INTO couriers(name,phone,status,transport_type,zone_id,paused_at)
+	          VALUES ($1,$2,$3,$4,$5,CASE WHEN $3=$6 THEN NOW() END) RETURNING id`
 
-	err := db.QueryRow(ctx, query, courier.Name, courier.Phone, courier.Status, courier.TransportType).Scan(&id)
+	err := db.QueryRow(ctx, query,
+		courier.Name, courier.Phone, courier.Status, courier.TransportType, courier.ZoneID, model.CourierStatusPaused,
+	).Scan(&id)
 	if err != nil {
 		if strings.Contains(err.Error(), "duplicate key value") {
 			return 0, ErrPhoneExists
 		}
+		if isForeignKeyViolation(err) {
+			return 0, ErrZoneNotFound
+		}
 		return 0, ErrDatabaseInternal
 	}
 
 	return id, nil
 }
 
+// Update stores the courier fields and sets the new version on the courier.
+// Pausing the courier records when it happened, see UpdateStatus.
 func (c *CourierRepository) Update(ctx context.Context, courier *model.CourierModel) error {
 	db := ipostgres.DBFromContext(ctx, c.conn)
-	query := `UPDATE couriers SET name=$1, phone=$2, status=$3, transport_type=$4, updated_at=NOW() WHERE id = $5 RETURNING id`
-	var returnedId int
-	if err := db.QueryRow(ctx, query, courier.Name, courier.Phone, courier.Status, courier.TransportType, courier.ID).Scan(&returnedId); err != nil {
+	query := `UPDATE couriers SET name=$1, phone=$2, status=$3, transport_type=$4, zone_id=$5, version=version+1, updated_at=NOW(),
+	              paused_at=CASE WHEN $3=$7 AND status<>$7 THEN NOW() ELSE paused_at END
+	          WHERE id = $6 RETURNING version`
+	err := db.QueryRow(ctx, query,
+		courier.Name, courier.Phone, courier.Status, courier.TransportType, courier.ZoneID, courier.ID, model.CourierStatusPaused,
+	).Scan(&courier.Version)
+	if err != nil {
 		if strings.Contains(err.Error(), "duplicate key value") {
 			return ErrPhoneExists
 		}
+		if isForeignKeyViolation(err) {
+			return ErrZoneNotFound
+		}
 		if errors.Is(err, pgx.ErrNoRows) {
 			return ErrCourierNotFound
 		}
@@ -54,7 +72,37 @@ func (c *CourierRepository) Update(ctx context.Context, courier *model.CourierMo
 func (c *CourierRepository) GetOneById(ctx context.Context, id int) (*model.CourierModel, error) {
 	db := ipostgres.DBFromContext(ctx, c.conn)
 	var courier model.CourierModel
-	query := `SELECT id, name, phone, status, transport_type, assignments_count FROM couriers WHERE id=$1`
+	var score scoreRow
+	query := `SELECT id, name, phone, status, transport_type, active_deliveries, zone_id, version, ` +
+		scoreColumns + ` FROM couriers WHERE id=$1`
+
+	dest := []any{
+		&courier.ID,
+		&courier.Name,
+		&courier.Phone,
+		&courier.Status,
+		&courier.TransportType,
+		&courier.ActiveDeliveries,
+		&courier.ZoneID,
+		&courier.Version,
+	}
+	err := db.QueryRow(ctx, query, id).Scan(append(dest, score.dest()...)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCourierNotFound
+		}
+		return nil, ErrDatabaseInternal
+	}
+	courier.Score = score.model(courier.ID)
+
+	return &courier, nil
+}
+
+func (c *CourierRepository) GetOneByIdForUpdate(ctx context.Context, id int) (*model.CourierModel, error) {
+	db := ipostgres.DBFromContext(ctx, c.conn)
+	var courier model.CourierModel
+	query := `SELECT id, name, phone, status, transport_type, active_deliveries, zone_id, version
+	          FROM couriers WHERE id=$1 FOR UPDATE`
 
 	err := db.QueryRow(ctx, query, id).Scan(
 		&courier.ID,
@@ -62,7 +110,9 @@ func (c *CourierRepository) GetOneById(ctx context.Context, id int) (*model.Cour
 		&courier.Phone,
 		&courier.Status,
 		&courier.TransportType,
-		&courier.AssignmentsCount,
+		&courier.ActiveDeliveries,
+		&courier.ZoneID,
+		&courier.Version,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -76,7 +126,7 @@ func (c *CourierRepository) GetOneById(ctx context.Context, id int) (*model.Cour
 
 func (c *CourierRepository) GetAll(ctx context.Context) ([]*model.CourierModel, error) {
 	db := ipostgres.DBFromContext(ctx, c.conn)
-	query := `SELECT id, name, phone, status, transport_type, assignments_count FROM couriers`
+	query := `SELECT id, name, phone, status, transport_type, active_deliveries, zone_id FROM couriers`
 
 	rows, err := db.Query(ctx, query)
 	if err != nil {
@@ -94,7 +144,8 @@ func (c *CourierRepository) GetAll(ctx context.Context) ([]*model.CourierModel,
 			&courier.Phone,
 			&courier.Status,
 			&courier.TransportType,
-			&courier.AssignmentsCount,
+			&courier.ActiveDeliveries,
+			&courier.ZoneID,
 		)
 		if err != nil {
 			return nil, ErrReadingData
@@ -116,14 +167,15 @@ func (c *CourierRepository) GetAll(ctx context.Context) ([]*model.CourierModel,
 func (c *CourierRepository) GetByStatus(ctx context.Context, status model.CourierStatus) (*model.CourierModel, error) {
 	db := ipostgres.DBFromContext(ctx, c.conn)
 	var courier model.CourierModel
-	query := `SELECT id, name, phone, status, transport_type, assignments_count FROM couriers WHERE status=$1`
+	query := `SELECT id, name, phone, status, transport_type, active_deliveries, zone_id FROM couriers WHERE status=$1`
 
 	err := db.QueryRow(ctx, query, status).Scan(&courier.ID,
 		&courier.Name,
 		&courier.Phone,
 		&courier.Status,
 		&courier.TransportType,
-		&courier.AssignmentsCount,
+		&courier.ActiveDeliveries,
+		&courier.ZoneID,
 	)
 
 	if err != nil {
@@ -136,23 +188,137 @@ func (c *CourierRepository) GetByStatus(ctx context.Context, status model.Courie
 	return &courier, nil
 }
 
-func (c *CourierRepository) GetAvailableLeastDelivered(ctx context.Context) (*model.CourierModel, error) {
+// ListAvailable returns the couriers that may take one more delivery: available
+// couriers and busy couriers with spare capacity for their transport type.
+// Their recent load is derived from the delivery records in the fairness window,
+// which starts with the courier's current shift when filter.LoadByShift is set;
+// only records since the earlier of filter.Since and the window start are read,
+// and the last assignment comes from idx_delivery_courier_assigned_at.
+// Rows are not locked, see GetOneByIdSkipLocked.
+func (c *CourierRepository) ListAvailable(ctx context.Context, filter model.AvailableCourierFilter) ([]*model.CourierCandidate, error) {
 	db := ipostgres.DBFromContext(ctx, c.conn)
-	var courier model.CourierModel
-	query := `SELECT c.id, c.name, c.phone, c.status, c.transport_type, c.assignments_count
+
+	transports := make([]string, 0, len(filter.Capacity))
+	capacities := make([]int, 0, len(filter.Capacity))
+	for transport := range filter.Capacity {
+		transports = append(transports, string(transport))
+		capacities = append(capacities, filter.Capacity.For(transport))
+	}
+	excludeIds := filter.ExcludeIDs
+	if excludeIds == nil {
+		excludeIds = []int{}
+	}
+	args := []any{
+		model.CourierStatusAvailable, model.CourierStatusBusy, transports, capacities, excludeIds, filter.Since,
+		filter.LoadSince, filter.LoadHalfLife.Seconds(), filter.Now, filter.LoadByShift,
+	}
+
+	where := ``
+	if len(filter.Cells) > 0 {
+		args = append(args, filter.Cells)
+		where = fmt.Sprintf(` AND c.geo_cell = ANY($%d)`, len(args))
+	}
+	if filter.Transports != nil {
+		allowed := make([]string, 0, len(filter.Transports))
+		for _, transport := range filter.Transports {
+			allowed = append(allowed, string(transport))
+		}
+		args = append(args, allowed)
+		where += fmt.Sprintf(` AND c.transport_type = ANY($%d)`, len(args))
+	}
+
+	query := `SELECT c.id, c.name, c.phone, c.status, c.transport_type, c.active_deliveries, c.zone_id,
+	                 c.latitude, c.longitude, c.location_updated_at, d.today, d.recent_load, l.last_assigned_at,
+	                 c.score, c.on_time_rate, c.cancel_rate, c.deadline_usage, c.scored_deliveries, c.scored_at
 	          FROM couriers c
-	          WHERE c.status = $1
-	          ORDER BY c.assignments_count ASC, c.id ASC
-	          LIMIT 1
-	          FOR UPDATE SKIP LOCKED`
+	          LEFT JOIN unnest($3::text[], $4::int[]) AS cap(transport_type, capacity)
+	            ON cap.transport_type = c.transport_type
+	          LEFT JOIN LATERAL (
+	              SELECT starts_at
+	              FROM courier_shifts
+	              WHERE $10::bool AND courier_id = c.id AND starts_at <= $9 AND ends_at > $9
+	              ORDER BY starts_at DESC
+	              LIMIT 1
+	          ) s ON TRUE
+	          CROSS JOIN LATERAL (SELECT COALESCE(s.starts_at, $7::timestamp) AS load_since) w
+	          JOIN LATERAL (
+	              SELECT COUNT(*) FILTER (WHERE assigned_at >= $6) AS today,
+	                     COALESCE(SUM(CASE WHEN $8::float8 > 0
+	                                       THEN POWER(0.5, EXTRACT(EPOCH FROM ($9::timestamp - assigned_at))::float8 / $8::float8)
+	                                       ELSE 1 END) FILTER (WHERE assigned_at >= w.load_since), 0) AS recent_load
+	              FROM delivery
+	              WHERE courier_id = c.id AND assigned_at >= LEAST($6::timestamp, w.load_since)
+	          ) d ON TRUE
+	          LEFT JOIN LATERAL (
+	              SELECT assigned_at AS last_assigned_at
+	              FROM delivery
+	              WHERE courier_id = c.id
+	              ORDER BY assigned_at DESC
+	              LIMIT 1
+	          ) l ON TRUE
+	          WHERE (c.status = $1 OR (c.status = $2 AND c.active_deliveries > 0))
+	            AND c.active_deliveries < COALESCE(cap.capacity, 1)
+	            AND NOT (c.id = ANY($5))` + where + `
+	          ORDER BY c.id ASC`
 
-	err := db.QueryRow(ctx, query, model.CourierStatusAvailable).Scan(
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, ErrDatabaseInternal
+	}
+	defer rows.Close()
+
+	candidates := []*model.CourierCandidate{}
+	for rows.Next() {
+		courier := &model.CourierModel{}
+		candidate := &model.CourierCandidate{Courier: courier}
+		var lat, lon *float64
+		var score scoreRow
+		dest := []any{
+			&courier.ID,
+			&courier.Name,
+			&courier.Phone,
+			&courier.Status,
+			&courier.TransportType,
+			&courier.ActiveDeliveries,
+			&courier.ZoneID,
+			&lat,
+			&lon,
+			&courier.LocationUpdatedAt,
+			&candidate.AssignmentsToday,
+			&candidate.RecentLoad,
+			&candidate.LastAssignedAt,
+		}
+		if err := rows.Scan(append(dest, score.dest()...)...); err != nil {
+			return nil, ErrReadingData
+		}
+		if lat != nil && lon != nil {
+			courier.Location = &geo.Point{Lat: *lat, Lon: *lon}
+		}
+		courier.Score = score.model(courier.ID)
+		candidates = append(candidates, candidate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, ErrDatabaseInternal
+	}
+	return candidates, nil
+}
+
+// GetOneByIdSkipLocked locks the courier unless another transaction already
+// holds it, in which case ErrCourierNotFound is returned.
+func (c *CourierRepository) GetOneByIdSkipLocked(ctx context.Context, id int) (*model.CourierModel, error) {
+	db := ipostgres.DBFromContext(ctx, c.conn)
+	var courier model.CourierModel
+	query := `SELECT id, name, phone, status, transport_type, active_deliveries, zone_id
+	          FROM couriers WHERE id=$1 FOR UPDATE SKIP LOCKED`
+
+	err := db.QueryRow(ctx, query, id).Scan(
 		&courier.ID,
 		&courier.Name,
 		&courier.Phone,
 		&courier.Status,
 		&courier.TransportType,
-		&courier.AssignmentsCount,
+		&courier.ActiveDeliveries,
+		&courier.ZoneID,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -163,11 +329,16 @@ func (c *CourierRepository) GetAvailableLeastDelivered(ctx context.Context) (*mo
 	return &courier, nil
 }
 
+// UpdateStatus sets the status requested for the courier, which counts as a
+// new version of it. Pausing the courier records when it happened, so a pause
+// during a shift is not undone by the shift scheduler.
 func (c *CourierRepository) UpdateStatus(ctx context.Context, status model.CourierStatus, id int) error {
 	db := ipostgres.DBFromContext(ctx, c.conn)
-	query := `UPDATE couriers SET status=$1 WHERE id=$2 RETURNING id`
+	query := `UPDATE couriers SET status=$1, version=version+1, updated_at=NOW(),
+	              paused_at=CASE WHEN $1=$3 AND status<>$3 THEN NOW() ELSE paused_at END
+	          WHERE id=$2 RETURNING id`
 	var returnedId int
-	if err := db.QueryRow(ctx, query, status, id).Scan(&returnedId); err != nil {
+	if err := db.QueryRow(ctx, query, status, id, model.CourierStatusPaused).Scan(&returnedId); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return ErrCourierNotFound
 		}
@@ -179,7 +350,10 @@ func (c *CourierRepository) UpdateStatus(ctx context.Context, status model.Couri
 
 func (c *CourierRepository) MarkAssigned(ctx context.Context, id int) error {
 	db := ipostgres.DBFromContext(ctx, c.conn)
-	query := `UPDATE couriers SET status=$1, assignments_count=assignments_count+1 WHERE id=$2 RETURNING id`
+	query := `UPDATE couriers
+	          SET status=$1, active_deliveries=active_deliveries+1,
+	              version=version + CASE WHEN status=$1 THEN 0 ELSE 1 END, updated_at=NOW()
+	          WHERE id=$2 RETURNING id`
 	var returnedId int
 	if err := db.QueryRow(ctx, query, model.CourierStatusBusy, id).Scan(&returnedId); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -189,3 +363,27 @@ func (c *CourierRepository) MarkAssigned(ctx context.Context, id int) error {
 	}
 	return nil
 }
+
+// MarkReleased drops one active delivery from the courier and makes a busy
+// courier available again once the last one is gone.
+func (c *CourierRepository) MarkReleased(ctx context.Context, id int) error {
+	db := ipostgres.DBFromContext(ctx, c.conn)
+	query := `UPDATE couriers
+	          SET active_deliveries=GREATEST(active_deliveries-1, 0),
+	              status=CASE WHEN active_deliveries <= 1 AND status=$1 THEN $2 ELSE status END,
+	              version=version + CASE WHEN active_deliveries <= 1 AND status=$1 THEN 1 ELSE 0 END, updated_at=NOW()
+	          WHERE id=$3 RETURNING id`
+	var returnedId int
+	if err := db.QueryRow(ctx, query, model.CourierStatusBusy, model.CourierStatusAvailable, id).Scan(&returnedId); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrCourierNotFound
+		}
+		return ErrDatabaseInternal
+	}
+	return nil
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
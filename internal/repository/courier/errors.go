@@ -7,4 +7,6 @@ var (
 	ErrCourierNotFound  = errors.New("courier not found")
 	ErrDatabaseInternal = errors.New("database error")
 	ErrReadingData      = errors.New("error reading data")
+	ErrLocationNotFound = errors.New("courier location not found")
+	ErrZoneNotFound     = errors.New("zone not found")
 )
@@ -0,0 +1,140 @@
+package courier
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/geo"
+	ipostgres "github.com/cdxy1/go-courier-service/internal/infra/postgres"
+	"github.com/cdxy1/go-courier-service/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// SaveLocations writes a batch of pings: every ping goes to the trail and the
+// newest ping of each courier becomes its last known position unless a newer
+// one is already stored. Pings already in the trail are skipped, so a batch
+// may safely be written again after a failure.
+func (c *CourierRepository) SaveLocations(ctx context.Context, pings []*model.LocationPing) error {
+	if len(pings) == 0 {
+		return nil
+	}
+	db := ipostgres.DBFromContext(ctx, c.conn)
+
+	var (
+		ids        = make([]int, 0, len(pings))
+		lats       = make([]float64, 0, len(pings))
+		lons       = make([]float64, 0, len(pings))
+		accuracies = make([]float64, 0, len(pings))
+		times      = make([]time.Time, 0, len(pings))
+		latest     = make(map[int]*model.LocationPing, len(pings))
+	)
+	for _, p := range pings {
+		ids = append(ids, p.CourierID)
+		lats = append(lats, p.Point.Lat)
+		lons = append(lons, p.Point.Lon)
+		accuracies = append(accuracies, p.Accuracy)
+		times = append(times, p.RecordedAt)
+		if last, ok := latest[p.CourierID]; !ok || p.RecordedAt.After(last.RecordedAt) {
+			latest[p.CourierID] = p
+		}
+	}
+
+	query := `INSERT INTO courier_locations (courier_id, latitude, longitude, accuracy, recorded_at)
+	          SELECT * FROM unnest($1::bigint[], $2::float8[], $3::float8[], $4::float8[], $5::timestamp[])
+	          ON CONFLICT (courier_id, recorded_at) DO NOTHING`
+	if err := db.Exec(ctx, query, ids, lats, lons, accuracies, times); err != nil {
+		return ErrDatabaseInternal
+	}
+
+	ids, lats, lons, accuracies, times = ids[:0], lats[:0], lons[:0], accuracies[:0], times[:0]
+	cells := make([]string, 0, len(latest))
+	for _, p := range latest {
+		ids = append(ids, p.CourierID)
+		lats = append(lats, p.Point.Lat)
+		lons = append(lons, p.Point.Lon)
+		accuracies = append(accuracies, p.Accuracy)
+		cells = append(cells, geo.Encode(p.Point, geo.CellPrecision))
+		times = append(times, p.RecordedAt)
+	}
+
+	query = `UPDATE couriers c
+	         SET latitude=l.latitude, longitude=l.longitude, location_accuracy=l.accuracy,
+	             geo_cell=l.geo_cell, location_updated_at=l.recorded_at
+	         FROM unnest($1::bigint[], $2::float8[], $3::float8[], $4::float8[], $5::text[], $6::timestamp[])
+	              AS l(courier_id, latitude, longitude, accuracy, geo_cell, recorded_at)
+	         WHERE c.id = l.courier_id
+	           AND (c.location_updated_at IS NULL OR c.location_updated_at < l.recorded_at)`
+	if err := db.Exec(ctx, query, ids, lats, lons, accuracies, cells, times); err != nil {
+		return ErrDatabaseInternal
+	}
+	return nil
+}
+
+// GetLocation returns the last known position of the courier. It returns
+// ErrLocationNotFound when the courier exists but never reported a position.
+func (c *CourierRepository) GetLocation(ctx context.Context, courierId int) (*model.LocationPing, error) {
+	db := ipostgres.DBFromContext(ctx, c.conn)
+	query := `SELECT latitude, longitude, location_accuracy, location_updated_at FROM couriers WHERE id=$1`
+
+	var (
+		lat, lon, accuracy *float64
+		recordedAt         *time.Time
+	)
+	if err := db.QueryRow(ctx, query, courierId).Scan(&lat, &lon, &accuracy, &recordedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCourierNotFound
+		}
+		return nil, ErrDatabaseInternal
+	}
+	if lat == nil || lon == nil || recordedAt == nil {
+		return nil, ErrLocationNotFound
+	}
+
+	ping := &model.LocationPing{
+		CourierID:  courierId,
+		Point:      geo.Point{Lat: *lat, Lon: *lon},
+		RecordedAt: *recordedAt,
+	}
+	if accuracy != nil {
+		ping.Accuracy = *accuracy
+	}
+	return ping, nil
+}
+
+// GetTrail returns the stored pings of the courier recorded in [from, to), oldest first.
+func (c *CourierRepository) GetTrail(ctx context.Context, courierId int, from, to time.Time) ([]*model.LocationPing, error) {
+	db := ipostgres.DBFromContext(ctx, c.conn)
+	query := `SELECT latitude, longitude, accuracy, recorded_at
+	          FROM courier_locations
+	          WHERE courier_id=$1 AND recorded_at >= $2 AND recorded_at < $3
+	          ORDER BY recorded_at ASC`
+
+	rows, err := db.Query(ctx, query, courierId, from, to)
+	if err != nil {
+		return nil, ErrDatabaseInternal
+	}
+	defer rows.Close()
+
+	trail := []*model.LocationPing{}
+	for rows.Next() {
+		ping := &model.LocationPing{CourierID: courierId}
+		if err := rows.Scan(&ping.Point.Lat, &ping.Point.Lon, &ping.Accuracy, &ping.RecordedAt); err != nil {
+			return nil, ErrReadingData
+		}
+		trail = append(trail, ping)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, ErrDatabaseInternal
+	}
+	return trail, nil
+}
+
+// TrimTrail removes the pings recorded before the given time.
+func (c *CourierRepository) TrimTrail(ctx context.Context, before time.Time) error {
+	db := ipostgres.DBFromContext(ctx, c.conn)
+	if err := db.Exec(ctx, `DELETE FROM courier_locations WHERE recorded_at < $1`, before); err != nil {
+		return ErrDatabaseInternal
+	}
+	return nil
+}
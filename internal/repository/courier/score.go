@@ -0,0 +1,127 @@
+package courier
+
+import (
+	"context"
+	"time"
+
+	ipostgres "github.com/cdxy1/go-courier-service/internal/infra/postgres"
+	"github.com/cdxy1/go-courier-service/internal/model"
+)
+
+const scoreColumns = `score, on_time_rate, cancel_rate, deadline_usage, scored_deliveries, scored_at`
+
+// scoreRow receives the score columns of a courier row.
+type scoreRow struct {
+	score         *float64
+	onTimeRate    *float64
+	cancelRate    *float64
+	deadlineUsage *float64
+	deliveries    int
+	scoredAt      *time.Time
+}
+
+func (r *scoreRow) dest() []any {
+	return []any{&r.score, &r.onTimeRate, &r.cancelRate, &r.deadlineUsage, &r.deliveries, &r.scoredAt}
+}
+
+// model returns the score, nil when the courier was not scored.
+func (r *scoreRow) model(courierId int) *model.CourierScore {
+	if r.score == nil || r.scoredAt == nil {
+		return nil
+	}
+	s := &model.CourierScore{
+		CourierID:  courierId,
+		Score:      *r.score,
+		Deliveries: r.deliveries,
+		UpdatedAt:  *r.scoredAt,
+	}
+	if r.onTimeRate != nil {
+		s.OnTimeRate = *r.onTimeRate
+	}
+	if r.cancelRate != nil {
+		s.CancelRate = *r.cancelRate
+	}
+	if r.deadlineUsage != nil {
+		s.DeadlineUsage = *r.deadlineUsage
+	}
+	return s
+}
+
+// DeliveryStats returns the statistics of the couriers with deliveries assigned
+// since the given time that were delivered, expired or cancelled.
+func (c *CourierRepository) DeliveryStats(ctx context.Context, since time.Time) ([]*model.CourierStats, error) {
+	db := ipostgres.DBFromContext(ctx, c.conn)
+	query := `SELECT courier_id,
+	                 COUNT(*) FILTER (WHERE status = $2),
+	                 COUNT(*) FILTER (WHERE status = $2 AND delivered_at <= deadline),
+	                 COUNT(*) FILTER (WHERE status = $3),
+	                 COUNT(*) FILTER (WHERE status = $4),
+	                 COALESCE(AVG(EXTRACT(EPOCH FROM (delivered_at - assigned_at))::float8
+	                              / NULLIF(EXTRACT(EPOCH FROM (deadline - assigned_at))::float8, 0))
+	                          FILTER (WHERE status = $2), 0)
+	          FROM delivery
+	          WHERE assigned_at >= $1 AND status IN ($2, $3, $4)
+	          GROUP BY courier_id
+	          ORDER BY courier_id`
+
+	rows, err := db.Query(ctx, query, since,
+		model.DeliveryStatusDelivered, model.DeliveryStatusExpired, model.DeliveryStatusCancelled)
+	if err != nil {
+		return nil, ErrDatabaseInternal
+	}
+	defer rows.Close()
+
+	stats := []*model.CourierStats{}
+	for rows.Next() {
+		var s model.CourierStats
+		if err := rows.Scan(&s.CourierID, &s.Delivered, &s.OnTime, &s.Expired, &s.Cancelled, &s.DeadlineUsage); err != nil {
+			return nil, ErrReadingData
+		}
+		stats = append(stats, &s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, ErrDatabaseInternal
+	}
+	return stats, nil
+}
+
+// SaveScores stores the scores and clears the score of every other courier,
+// in one statement.
+func (c *CourierRepository) SaveScores(ctx context.Context, scores []*model.CourierScore) error {
+	db := ipostgres.DBFromContext(ctx, c.conn)
+
+	ids := make([]int, len(scores))
+	values := make([]float64, len(scores))
+	onTime := make([]float64, len(scores))
+	cancel := make([]float64, len(scores))
+	usage := make([]float64, len(scores))
+	deliveries := make([]int, len(scores))
+	scoredAt := make([]time.Time, len(scores))
+	for i, s := range scores {
+		ids[i] = s.CourierID
+		values[i] = s.Score
+		onTime[i] = s.OnTimeRate
+		cancel[i] = s.CancelRate
+		usage[i] = s.DeadlineUsage
+		deliveries[i] = s.Deliveries
+		scoredAt[i] = s.UpdatedAt
+	}
+
+	query := `WITH s AS (
+	              SELECT * FROM unnest($1::bigint[], $2::float8[], $3::float8[], $4::float8[], $5::float8[], $6::int[], $7::timestamp[])
+	                  AS s(id, score, on_time_rate, cancel_rate, deadline_usage, deliveries, scored_at)
+	          ), cleared AS (
+	              UPDATE couriers
+	              SET score=NULL, on_time_rate=NULL, cancel_rate=NULL, deadline_usage=NULL, scored_deliveries=0, scored_at=NULL
+	              WHERE scored_at IS NOT NULL AND NOT (id = ANY($1))
+	          )
+	          UPDATE couriers c
+	          SET score=s.score, on_time_rate=s.on_time_rate, cancel_rate=s.cancel_rate,
+	              deadline_usage=s.deadline_usage, scored_deliveries=s.deliveries, scored_at=s.scored_at
+	          FROM s
+	          WHERE c.id = s.id`
+	if err := db.Exec(ctx, query, ids, values, onTime, cancel, usage, deliveries, scoredAt); err != nil {
+		return ErrDatabaseInternal
+	}
+	return nil
+}
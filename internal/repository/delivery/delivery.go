@@ -2,8 +2,13 @@ package delivery
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/cdxy1/go-courier-service/internal/geo"
 	ipostgres "github.com/cdxy1/go-courier-service/internal/infra/postgres"
 	"github.com/cdxy1/go-courier-service/internal/model"
 	"github.com/jackc/pgx/v5"
@@ -11,6 +16,17 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+const deliveryColumns = `id, courier_id, order_id, status, assigned_at, deadline,
+	picked_up_at, in_transit_at, delivered_at, cancelled_at, expired_at, updated_at,
+	COALESCE(idempotency_key, ''), pickup_lat, pickup_lon, dropoff_lat, dropoff_lon, order_details,
+	promised_by, at_risk`
+
+// Unique indexes guarding against duplicate deliveries.
+const (
+	activeOrderIndex    = "uq_delivery_active_order"
+	idempotencyKeyIndex = "uq_delivery_idempotency_key"
+)
+
 type DeliveryRepository struct {
 	conn *pgxpool.Pool
 }
@@ -21,67 +37,296 @@ func NewDeliveryRepository(conn *pgxpool.Pool) *DeliveryRepository {
 
 func (d *DeliveryRepository) Create(ctx context.Context, delivery *model.DeliveryModel) error {
 	db := ipostgres.DBFromContext(ctx, d.conn)
-	query := `INSERT INTO delivery(courier_id,order_id,deadline) VALUES ($1,$2,$3)`
-	if err := db.Exec(ctx, query, delivery.CourierId, delivery.OrderId, delivery.Deadline); err != nil {
+	query := `INSERT INTO delivery(courier_id,order_id,status,assigned_at,deadline,updated_at,idempotency_key,
+			                    pickup_lat,pickup_lon,dropoff_lat,dropoff_lon,order_details,promised_by,at_risk)
+			  VALUES ($1,$2,$3,$4,$5,$6,NULLIF($7,''),$8,$9,$10,$11,$12,$13,$14) RETURNING id`
+	pickupLat, pickupLon := pointColumns(delivery.Pickup)
+	dropoffLat, dropoffLon := pointColumns(delivery.Dropoff)
+	details, err := detailsColumn(delivery.Order)
+	if err != nil {
+		return ErrDatabaseInternal
+	}
+	err = db.QueryRow(ctx, query,
+		delivery.CourierId,
+		delivery.OrderId,
+		delivery.Status,
+		delivery.AssignedAt,
+		delivery.Deadline,
+		delivery.UpdatedAt,
+		delivery.IdempotencyKey,
+		pickupLat,
+		pickupLon,
+		dropoffLat,
+		dropoffLon,
+		details,
+		delivery.PromisedBy,
+		delivery.AtRisk,
+	).Scan(&delivery.ID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			switch pgErr.ConstraintName {
+			case activeOrderIndex:
+				return ErrActiveDeliveryExists
+			case idempotencyKeyIndex:
+				return ErrIdempotencyKeyExists
+			}
+		}
 		return ErrDatabaseInternal
 	}
 	return nil
 }
 
-func (d *DeliveryRepository) Delete(ctx context.Context, orderId string) (int, error) {
+func (d *DeliveryRepository) GetByIdempotencyKey(ctx context.Context, key string) (*model.DeliveryModel, error) {
 	db := ipostgres.DBFromContext(ctx, d.conn)
-	var courierId int
-	query := `DELETE FROM delivery WHERE order_id=$1 RETURNING courier_id`
-	if err := db.QueryRow(ctx, query, orderId).Scan(&courierId); err != nil {
+	query := `SELECT ` + deliveryColumns + ` FROM delivery WHERE idempotency_key=$1`
+
+	delivery, err := scanDelivery(db.QueryRow(ctx, query, key))
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return 0, ErrDeliveryNotFound
+			return nil, ErrDeliveryNotFound
 		}
-		return 0, ErrDatabaseInternal
+		return nil, ErrDatabaseInternal
 	}
-	return courierId, nil
+	return delivery, nil
 }
 
-func (d *DeliveryRepository) GetCourierID(ctx context.Context, orderId string) (int, error) {
+func (d *DeliveryRepository) GetByOrderID(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
 	db := ipostgres.DBFromContext(ctx, d.conn)
-	var courierId int
-	query := `SELECT courier_id FROM delivery WHERE order_id=$1`
-	if err := db.QueryRow(ctx, query, orderId).Scan(&courierId); err != nil {
+	query := `SELECT ` + deliveryColumns + ` FROM delivery
+			  WHERE order_id=$1
+			  ORDER BY id DESC
+			  LIMIT 1`
+
+	delivery, err := scanDelivery(db.QueryRow(ctx, query, orderId))
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return 0, ErrDeliveryNotFound
+			return nil, ErrDeliveryNotFound
 		}
-		return 0, ErrDatabaseInternal
+		return nil, ErrDatabaseInternal
 	}
-	return courierId, nil
+	return delivery, nil
 }
 
-func (d *DeliveryRepository) ReleaseExpiredCouriers(ctx context.Context) (int, error) {
+func (d *DeliveryRepository) List(ctx context.Context, filter model.DeliveryFilter) ([]*model.DeliveryModel, int, error) {
 	db := ipostgres.DBFromContext(ctx, d.conn)
-	query := `WITH expired AS (SELECT DISTINCT courier_id FROM delivery WHERE deadline < NOW())
-			  UPDATE couriers SET status=$1
-			  WHERE status=$2 AND id IN (SELECT courier_id FROM expired)
-				AND NOT EXISTS(SELECT 1 FROM delivery WHERE courier_id = couriers.id AND deadline >= NOW())
+
+	var conditions []string
+	var args []any
+	addCondition := func(format string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if filter.CourierID > 0 {
+		addCondition("courier_id = $%d", filter.CourierID)
+	}
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, 0, len(filter.Statuses))
+		for _, status := range filter.Statuses {
+			statuses = append(statuses, string(status))
+		}
+		addCondition("status = ANY($%d)", statuses)
+	}
+	if filter.AssignedFrom != nil {
+		addCondition("assigned_at >= $%d", *filter.AssignedFrom)
+	}
+	if filter.AssignedTo != nil {
+		addCondition("assigned_at < $%d", *filter.AssignedTo)
+	}
+	if filter.OverdueOnly {
+		addCondition("status = ANY($%d)", activeStatuses())
+		addCondition("deadline < $%d", filter.Now)
+	}
+	if filter.AtRiskOnly {
+		addCondition("at_risk = $%d", true)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := db.QueryRow(ctx, `SELECT COUNT(*) FROM delivery`+where, args...).Scan(&total); err != nil {
+		return nil, 0, ErrDatabaseInternal
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query := `SELECT ` + deliveryColumns + ` FROM delivery` + where +
+		fmt.Sprintf(` ORDER BY assigned_at DESC, id DESC LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
+
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, ErrDatabaseInternal
+	}
+	defer rows.Close()
+
+	deliveries := []*model.DeliveryModel{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, 0, ErrDatabaseInternal
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, ErrDatabaseInternal
+	}
+	return deliveries, total, nil
+}
+
+func (d *DeliveryRepository) GetByOrderIDForUpdate(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+	db := ipostgres.DBFromContext(ctx, d.conn)
+	query := `SELECT ` + deliveryColumns + ` FROM delivery
+			  WHERE order_id=$1
+			  ORDER BY id DESC
+			  LIMIT 1
+			  FOR UPDATE`
+
+	delivery, err := scanDelivery(db.QueryRow(ctx, query, orderId))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDeliveryNotFound
+		}
+		return nil, ErrDatabaseInternal
+	}
+	return delivery, nil
+}
+
+func (d *DeliveryRepository) UpdateStatus(ctx context.Context, delivery *model.DeliveryModel) error {
+	db := ipostgres.DBFromContext(ctx, d.conn)
+	query := `UPDATE delivery
+			  SET status=$1, picked_up_at=$2, in_transit_at=$3, delivered_at=$4,
+				  cancelled_at=$5, expired_at=$6, updated_at=$7
+			  WHERE id=$8
 			  RETURNING id`
+	var returnedId int
+	err := db.QueryRow(ctx, query,
+		delivery.Status,
+		delivery.PickedUpAt,
+		delivery.InTransitAt,
+		delivery.DeliveredAt,
+		delivery.CancelledAt,
+		delivery.ExpiredAt,
+		delivery.UpdatedAt,
+		delivery.ID,
+	).Scan(&returnedId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrDeliveryNotFound
+		}
+		return ErrDatabaseInternal
+	}
+	return nil
+}
+
+func (d *DeliveryRepository) GetOverdueForUpdate(ctx context.Context, now time.Time) ([]*model.DeliveryModel, error) {
+	db := ipostgres.DBFromContext(ctx, d.conn)
+	query := `SELECT ` + deliveryColumns + ` FROM delivery
+			  WHERE status = ANY($1) AND deadline < $2
+			  ORDER BY deadline ASC
+			  FOR UPDATE SKIP LOCKED`
 
-	rows, err := db.Query(ctx, query, model.CourierStatusAvailable, model.CourierStatusBusy)
+	rows, err := db.Query(ctx, query, activeStatuses(), now)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "42P01" {
-			return 0, ErrDeliveryTableMissing
+			return nil, ErrDeliveryTableMissing
 		}
-		return 0, ErrDatabaseInternal
+		return nil, ErrDatabaseInternal
 	}
 	defer rows.Close()
 
-	var updated int
+	var deliveries []*model.DeliveryModel
 	for rows.Next() {
-		var id int
-		if err := rows.Scan(&id); err != nil {
-			return 0, ErrDatabaseInternal
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, ErrDatabaseInternal
 		}
-		updated++
+		deliveries = append(deliveries, delivery)
 	}
 	if err := rows.Err(); err != nil {
-		return 0, ErrDatabaseInternal
+		return nil, ErrDatabaseInternal
+	}
+	return deliveries, nil
+}
+
+func scanDelivery(row pgx.Row) (*model.DeliveryModel, error) {
+	var delivery model.DeliveryModel
+	var pickupLat, pickupLon, dropoffLat, dropoffLon *float64
+	var details []byte
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.CourierId,
+		&delivery.OrderId,
+		&delivery.Status,
+		&delivery.AssignedAt,
+		&delivery.Deadline,
+		&delivery.PickedUpAt,
+		&delivery.InTransitAt,
+		&delivery.DeliveredAt,
+		&delivery.CancelledAt,
+		&delivery.ExpiredAt,
+		&delivery.UpdatedAt,
+		&delivery.IdempotencyKey,
+		&pickupLat,
+		&pickupLon,
+		&dropoffLat,
+		&dropoffLon,
+		&details,
+		&delivery.PromisedBy,
+		&delivery.AtRisk,
+	)
+	if err != nil {
+		return nil, err
+	}
+	delivery.Pickup = scanPoint(pickupLat, pickupLon)
+	delivery.Dropoff = scanPoint(dropoffLat, dropoffLon)
+	if delivery.Order, err = scanDetails(details); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// pointColumns splits an optional point into nullable latitude and longitude columns.
+func pointColumns(p *geo.Point) (*float64, *float64) {
+	if p == nil {
+		return nil, nil
+	}
+	return &p.Lat, &p.Lon
+}
+
+func scanPoint(lat, lon *float64) *geo.Point {
+	if lat == nil || lon == nil {
+		return nil
+	}
+	return &geo.Point{Lat: *lat, Lon: *lon}
+}
+
+// detailsColumn encodes optional order details as a nullable JSONB column.
+func detailsColumn(details *model.OrderDetails) ([]byte, error) {
+	if details == nil {
+		return nil, nil
+	}
+	return json.Marshal(details)
+}
+
+func scanDetails(raw []byte) (*model.OrderDetails, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	var details model.OrderDetails
+	if err := json.Unmarshal(raw, &details); err != nil {
+		return nil, err
+	}
+	return &details, nil
+}
+
+func activeStatuses() []string {
+	statuses := make([]string, 0, len(model.ActiveDeliveryStatuses))
+	for _, status := range model.ActiveDeliveryStatuses {
+		statuses = append(statuses, string(status))
 	}
-	return updated, nil
+	return statuses
 }
@@ -6,4 +6,6 @@ var (
 	ErrDeliveryNotFound     = errors.New("delivery not found")
 	ErrDeliveryTableMissing = errors.New("delivery table is missing")
 	ErrDatabaseInternal     = errors.New("database error")
+	ErrActiveDeliveryExists = errors.New("order already has an active delivery")
+	ErrIdempotencyKeyExists = errors.New("idempotency key already used")
 )
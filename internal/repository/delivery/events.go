@@ -0,0 +1,71 @@
+package delivery
+
+import (
+	"context"
+
+	ipostgres "github.com/cdxy1/go-courier-service/internal/infra/postgres"
+	"github.com/cdxy1/go-courier-service/internal/model"
+)
+
+func (d *DeliveryRepository) CreateEvent(ctx context.Context, event *model.DeliveryEvent) error {
+	db := ipostgres.DBFromContext(ctx, d.conn)
+	query := `INSERT INTO delivery_events(delivery_id,order_id,courier_id,type,from_status,to_status,source,actor,reason,created_at)
+			  VALUES ($1,$2,$3,$4,NULLIF($5,''),$6,$7,$8,$9,$10) RETURNING id`
+	err := db.QueryRow(ctx, query,
+		event.DeliveryID,
+		event.OrderID,
+		event.CourierID,
+		event.Type,
+		string(event.FromStatus),
+		event.ToStatus,
+		event.Source,
+		event.Actor,
+		event.Reason,
+		event.CreatedAt,
+	).Scan(&event.ID)
+	if err != nil {
+		return ErrDatabaseInternal
+	}
+	return nil
+}
+
+func (d *DeliveryRepository) GetEventsByOrderID(ctx context.Context, orderId string) ([]*model.DeliveryEvent, error) {
+	db := ipostgres.DBFromContext(ctx, d.conn)
+	query := `SELECT id, delivery_id, order_id, courier_id, type, COALESCE(from_status, ''), to_status,
+					 source, actor, reason, created_at
+			  FROM delivery_events
+			  WHERE order_id=$1
+			  ORDER BY id ASC`
+
+	rows, err := db.Query(ctx, query, orderId)
+	if err != nil {
+		return nil, ErrDatabaseInternal
+	}
+	defer rows.Close()
+
+	events := []*model.DeliveryEvent{}
+	for rows.Next() {
+		var event model.DeliveryEvent
+		err := rows.Scan(
+			&event.ID,
+			&event.DeliveryID,
+			&event.OrderID,
+			&event.CourierID,
+			&event.Type,
+			&event.FromStatus,
+			&event.ToStatus,
+			&event.Source,
+			&event.Actor,
+			&event.Reason,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, ErrDatabaseInternal
+		}
+		events = append(events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, ErrDatabaseInternal
+	}
+	return events, nil
+}
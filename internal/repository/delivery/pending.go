@@ -0,0 +1,120 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	ipostgres "github.com/cdxy1/go-courier-service/internal/infra/postgres"
+	"github.com/cdxy1/go-courier-service/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// Enqueue adds the order to the pending assignment queue. An order that is
+// already queued keeps its place.
+func (d *DeliveryRepository) Enqueue(ctx context.Context, pending *model.PendingAssignment) error {
+	db := ipostgres.DBFromContext(ctx, d.conn)
+	query := `INSERT INTO pending_assignments(order_id,priority,enqueued_at,pickup_lat,pickup_lon,dropoff_lat,dropoff_lon,order_details,promised_by)
+			  VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+			  ON CONFLICT (order_id) DO NOTHING`
+	pickupLat, pickupLon := pointColumns(pending.Pickup)
+	dropoffLat, dropoffLon := pointColumns(pending.Dropoff)
+	details, err := detailsColumn(pending.Order)
+	if err != nil {
+		return ErrDatabaseInternal
+	}
+	if err := db.Exec(ctx, query,
+		pending.OrderID, pending.Priority, pending.EnqueuedAt, pickupLat, pickupLon, dropoffLat, dropoffLon, details,
+		pending.PromisedBy,
+	); err != nil {
+		return ErrDatabaseInternal
+	}
+	return nil
+}
+
+// ListPending returns queued orders, highest priority first and oldest first
+// within the same priority.
+func (d *DeliveryRepository) ListPending(ctx context.Context, limit int) ([]*model.PendingAssignment, error) {
+	db := ipostgres.DBFromContext(ctx, d.conn)
+	query := `SELECT id, order_id, priority, attempts, last_error, enqueued_at, last_attempt_at,
+			         pickup_lat, pickup_lon, dropoff_lat, dropoff_lon, order_details, promised_by
+			  FROM pending_assignments
+			  ORDER BY priority DESC, enqueued_at ASC, id ASC
+			  LIMIT $1`
+
+	rows, err := db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, ErrDatabaseInternal
+	}
+	defer rows.Close()
+
+	pending := []*model.PendingAssignment{}
+	for rows.Next() {
+		var p model.PendingAssignment
+		var pickupLat, pickupLon, dropoffLat, dropoffLon *float64
+		var details []byte
+		err := rows.Scan(
+			&p.ID,
+			&p.OrderID,
+			&p.Priority,
+			&p.Attempts,
+			&p.LastError,
+			&p.EnqueuedAt,
+			&p.LastAttemptAt,
+			&pickupLat,
+			&pickupLon,
+			&dropoffLat,
+			&dropoffLon,
+			&details,
+			&p.PromisedBy,
+		)
+		if err != nil {
+			return nil, ErrDatabaseInternal
+		}
+		p.Pickup = scanPoint(pickupLat, pickupLon)
+		p.Dropoff = scanPoint(dropoffLat, dropoffLon)
+		if p.Order, err = scanDetails(details); err != nil {
+			return nil, ErrDatabaseInternal
+		}
+		pending = append(pending, &p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, ErrDatabaseInternal
+	}
+	return pending, nil
+}
+
+// DeletePending removes the order from the queue and reports whether it was queued.
+func (d *DeliveryRepository) DeletePending(ctx context.Context, orderId string) (bool, error) {
+	db := ipostgres.DBFromContext(ctx, d.conn)
+	var id int
+	err := db.QueryRow(ctx, `DELETE FROM pending_assignments WHERE order_id=$1 RETURNING id`, orderId).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, ErrDatabaseInternal
+	}
+	return true, nil
+}
+
+func (d *DeliveryRepository) MarkPendingAttempt(ctx context.Context, orderId string, at time.Time, reason string) error {
+	db := ipostgres.DBFromContext(ctx, d.conn)
+	query := `UPDATE pending_assignments
+			  SET attempts=attempts+1, last_attempt_at=$1, last_error=$2
+			  WHERE order_id=$3`
+	if err := db.Exec(ctx, query, at, reason, orderId); err != nil {
+		return ErrDatabaseInternal
+	}
+	return nil
+}
+
+func (d *DeliveryRepository) PendingStats(ctx context.Context) (*model.PendingQueueStats, error) {
+	db := ipostgres.DBFromContext(ctx, d.conn)
+	var stats model.PendingQueueStats
+	query := `SELECT COUNT(*), MIN(enqueued_at) FROM pending_assignments`
+	if err := db.QueryRow(ctx, query).Scan(&stats.Depth, &stats.OldestEnqueuedAt); err != nil {
+		return nil, ErrDatabaseInternal
+	}
+	return &stats, nil
+}
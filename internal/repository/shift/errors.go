@@ -0,0 +1,12 @@
+package shift
+
+import "errors"
+
+var (
+	ErrShiftNotFound    = errors.New("shift not found")
+	ErrTemplateNotFound = errors.New("shift template not found")
+	ErrShiftOverlaps    = errors.New("shift overlaps another shift of the courier")
+	ErrCourierNotFound  = errors.New("courier not found")
+	ErrDatabaseInternal = errors.New("database error")
+	ErrReadingData      = errors.New("error reading data")
+)
@@ -0,0 +1,402 @@
+package shift
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	ipostgres "github.com/cdxy1/go-courier-service/internal/infra/postgres"
+	"github.com/cdxy1/go-courier-service/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	shiftColumns    = `id, courier_id, template_id, starts_at, ends_at, created_at, updated_at`
+	templateColumns = `id, courier_id, weekdays, start_minute, end_minute, generated_until, created_at, updated_at`
+)
+
+type ShiftRepository struct {
+	conn *pgxpool.Pool
+}
+
+func NewShiftRepository(conn *pgxpool.Pool) *ShiftRepository {
+	return &ShiftRepository{conn: conn}
+}
+
+// Create stores the shift unless it overlaps another shift of the courier.
+// Overlaps are rejected by the ex_courier_shifts_overlap constraint, so
+// concurrent requests cannot both succeed.
+func (r *ShiftRepository) Create(ctx context.Context, shift *model.Shift) (int, error) {
+	db := ipostgres.DBFromContext(ctx, r.conn)
+	query := `INSERT INTO courier_shifts(courier_id, starts_at, ends_at) VALUES ($1, $2, $3) RETURNING id`
+
+	var id int
+	if err := db.QueryRow(ctx, query, shift.CourierID, shift.StartsAt, shift.EndsAt).Scan(&id); err != nil {
+		if isExclusionViolation(err) {
+			return 0, ErrShiftOverlaps
+		}
+		if isForeignKeyViolation(err) {
+			return 0, ErrCourierNotFound
+		}
+		return 0, ErrDatabaseInternal
+	}
+	return id, nil
+}
+
+// Update moves the shift unless it would overlap another shift of the courier.
+func (r *ShiftRepository) Update(ctx context.Context, shift *model.Shift) error {
+	db := ipostgres.DBFromContext(ctx, r.conn)
+	query := `UPDATE courier_shifts SET starts_at=$1, ends_at=$2, updated_at=NOW() WHERE id=$3 RETURNING id`
+
+	var returnedId int
+	if err := db.QueryRow(ctx, query, shift.StartsAt, shift.EndsAt, shift.ID).Scan(&returnedId); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrShiftNotFound
+		}
+		if isExclusionViolation(err) {
+			return ErrShiftOverlaps
+		}
+		return ErrDatabaseInternal
+	}
+	return nil
+}
+
+func (r *ShiftRepository) Delete(ctx context.Context, id int) error {
+	db := ipostgres.DBFromContext(ctx, r.conn)
+	query := `DELETE FROM courier_shifts WHERE id=$1 RETURNING id`
+	var returnedId int
+	if err := db.QueryRow(ctx, query, id).Scan(&returnedId); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrShiftNotFound
+		}
+		return ErrDatabaseInternal
+	}
+	return nil
+}
+
+func (r *ShiftRepository) GetOneById(ctx context.Context, id int) (*model.Shift, error) {
+	db := ipostgres.DBFromContext(ctx, r.conn)
+	query := `SELECT ` + shiftColumns + ` FROM courier_shifts WHERE id=$1`
+
+	shift, err := scanShift(db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrShiftNotFound
+		}
+		return nil, ErrDatabaseInternal
+	}
+	return shift, nil
+}
+
+// List returns the shifts matching the filter in start order.
+func (r *ShiftRepository) List(ctx context.Context, filter model.ShiftFilter) ([]*model.Shift, error) {
+	db := ipostgres.DBFromContext(ctx, r.conn)
+
+	var args []any
+	where := `TRUE`
+	if filter.CourierID != 0 {
+		args = append(args, filter.CourierID)
+		where += fmt.Sprintf(` AND courier_id = $%d`, len(args))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		where += fmt.Sprintf(` AND ends_at > $%d`, len(args))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		where += fmt.Sprintf(` AND starts_at < $%d`, len(args))
+	}
+	query := `SELECT ` + shiftColumns + ` FROM courier_shifts WHERE ` + where + ` ORDER BY starts_at ASC, id ASC`
+
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, ErrDatabaseInternal
+	}
+	defer rows.Close()
+
+	shifts := []*model.Shift{}
+	for rows.Next() {
+		shift, err := scanShift(rows)
+		if err != nil {
+			return nil, ErrReadingData
+		}
+		shifts = append(shifts, shift)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, ErrDatabaseInternal
+	}
+	return shifts, nil
+}
+
+func (r *ShiftRepository) CreateTemplate(ctx context.Context, template *model.ShiftTemplate) (int, error) {
+	db := ipostgres.DBFromContext(ctx, r.conn)
+	query := `INSERT INTO shift_templates(courier_id, weekdays, start_minute, end_minute)
+	          VALUES ($1, $2, $3, $4) RETURNING id`
+
+	var id int
+	err := db.QueryRow(ctx, query, template.CourierID, weekdays(template), template.StartMinute, template.EndMinute).Scan(&id)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return 0, ErrCourierNotFound
+		}
+		return 0, ErrDatabaseInternal
+	}
+	return id, nil
+}
+
+// UpdateTemplate changes the schedule of the template, shifts are planned from
+// it again.
+func (r *ShiftRepository) UpdateTemplate(ctx context.Context, template *model.ShiftTemplate) error {
+	db := ipostgres.DBFromContext(ctx, r.conn)
+	query := `UPDATE shift_templates
+	          SET weekdays=$1, start_minute=$2, end_minute=$3, generated_until=NULL, updated_at=NOW()
+	          WHERE id=$4 RETURNING id`
+
+	var returnedId int
+	err := db.QueryRow(ctx, query, weekdays(template), template.StartMinute, template.EndMinute, template.ID).Scan(&returnedId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrTemplateNotFound
+		}
+		return ErrDatabaseInternal
+	}
+	return nil
+}
+
+// DeleteTemplate removes the template. Shifts planned from it are kept.
+func (r *ShiftRepository) DeleteTemplate(ctx context.Context, id int) error {
+	db := ipostgres.DBFromContext(ctx, r.conn)
+	query := `DELETE FROM shift_templates WHERE id=$1 RETURNING id`
+	var returnedId int
+	if err := db.QueryRow(ctx, query, id).Scan(&returnedId); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrTemplateNotFound
+		}
+		return ErrDatabaseInternal
+	}
+	return nil
+}
+
+func (r *ShiftRepository) GetTemplate(ctx context.Context, id int) (*model.ShiftTemplate, error) {
+	db := ipostgres.DBFromContext(ctx, r.conn)
+	query := `SELECT ` + templateColumns + ` FROM shift_templates WHERE id=$1`
+
+	template, err := scanTemplate(db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, ErrDatabaseInternal
+	}
+	return template, nil
+}
+
+// ListTemplates returns the templates of the courier, of every courier when
+// courierId is 0.
+func (r *ShiftRepository) ListTemplates(ctx context.Context, courierId int) ([]*model.ShiftTemplate, error) {
+	db := ipostgres.DBFromContext(ctx, r.conn)
+	query := `SELECT ` + templateColumns + ` FROM shift_templates
+	          WHERE $1 = 0 OR courier_id = $1
+	          ORDER BY id ASC`
+
+	rows, err := db.Query(ctx, query, courierId)
+	if err != nil {
+		return nil, ErrDatabaseInternal
+	}
+	defer rows.Close()
+
+	templates := []*model.ShiftTemplate{}
+	for rows.Next() {
+		template, err := scanTemplate(rows)
+		if err != nil {
+			return nil, ErrReadingData
+		}
+		templates = append(templates, template)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, ErrDatabaseInternal
+	}
+	return templates, nil
+}
+
+// PlanShifts stores the shifts planned from the template, skipping those that
+// overlap a shift the courier already has, and records that the template is
+// planned until the given time. It returns the number of shifts stored.
+func (r *ShiftRepository) PlanShifts(ctx context.Context, template *model.ShiftTemplate, shifts []*model.Shift, until time.Time) (int, error) {
+	db := ipostgres.DBFromContext(ctx, r.conn)
+
+	starts := make([]time.Time, len(shifts))
+	ends := make([]time.Time, len(shifts))
+	for i, s := range shifts {
+		starts[i] = s.StartsAt
+		ends[i] = s.EndsAt
+	}
+	query := `WITH planned AS (
+	              INSERT INTO courier_shifts(courier_id, template_id, starts_at, ends_at)
+	              SELECT $1, $2, n.starts_at, n.ends_at
+	              FROM unnest($3::timestamp[], $4::timestamp[]) AS n(starts_at, ends_at)
+	              WHERE NOT EXISTS (
+	                  SELECT 1 FROM courier_shifts s
+	                  WHERE s.courier_id = $1 AND s.starts_at < n.ends_at AND s.ends_at > n.starts_at
+	              )
+	              ON CONFLICT DO NOTHING
+	              RETURNING 1
+	          )
+	          SELECT COUNT(*) FROM planned`
+
+	var planned int
+	if err := db.QueryRow(ctx, query, template.CourierID, template.ID, starts, ends).Scan(&planned); err != nil {
+		return 0, ErrDatabaseInternal
+	}
+
+	query = `UPDATE shift_templates SET generated_until=$1 WHERE id=$2`
+	if err := db.Exec(ctx, query, until, template.ID); err != nil {
+		return 0, ErrDatabaseInternal
+	}
+	return planned, nil
+}
+
+// DeletePlannedShifts removes the shifts planned from the template that start
+// after the given time.
+func (r *ShiftRepository) DeletePlannedShifts(ctx context.Context, templateId int, after time.Time) error {
+	db := ipostgres.DBFromContext(ctx, r.conn)
+	query := `DELETE FROM courier_shifts WHERE template_id=$1 AND starts_at > $2`
+	if err := db.Exec(ctx, query, templateId, after); err != nil {
+		return ErrDatabaseInternal
+	}
+	return nil
+}
+
+// StartShifts makes paused couriers available whose shift is running at now
+// and who were paused before it started, busy when they still hold
+// deliveries. Couriers paused by hand during the shift stay paused. It returns
+// the number of couriers changed.
+func (r *ShiftRepository) StartShifts(ctx context.Context, now time.Time) (int, error) {
+	db := ipostgres.DBFromContext(ctx, r.conn)
+	query := `WITH started AS (
+	              UPDATE couriers c
+	              SET status=CASE WHEN c.active_deliveries > 0 THEN $4 ELSE $1 END, version=c.version+1, updated_at=NOW()
+	              WHERE c.status = $2 AND EXISTS (
+	                  SELECT 1 FROM courier_shifts s
+	                  WHERE s.courier_id = c.id AND s.starts_at <= $3 AND s.ends_at > $3
+	                    AND (c.paused_at IS NULL OR c.paused_at < s.starts_at)
+	              )
+	              RETURNING 1
+	          )
+	          SELECT COUNT(*) FROM started`
+
+	var started int
+	err := db.QueryRow(ctx, query, model.CourierStatusAvailable, model.CourierStatusPaused, now, model.CourierStatusBusy).Scan(&started)
+	if err != nil {
+		return 0, ErrDatabaseInternal
+	}
+	return started, nil
+}
+
+// EndShifts pauses available couriers that have shifts but none running at
+// now. It returns the number of couriers changed.
+func (r *ShiftRepository) EndShifts(ctx context.Context, now time.Time) (int, error) {
+	db := ipostgres.DBFromContext(ctx, r.conn)
+	query := `WITH ended AS (
+	              UPDATE couriers c SET status=$1, version=c.version+1, updated_at=NOW(), paused_at=$3
+	              WHERE c.status = $2
+	                AND EXISTS (SELECT 1 FROM courier_shifts s WHERE s.courier_id = c.id)
+	                AND NOT EXISTS (
+	                    SELECT 1 FROM courier_shifts s
+	                    WHERE s.courier_id = c.id AND s.starts_at <= $3 AND s.ends_at > $3
+	                )
+	              RETURNING 1
+	          )
+	          SELECT COUNT(*) FROM ended`
+
+	var ended int
+	err := db.QueryRow(ctx, query, model.CourierStatusPaused, model.CourierStatusAvailable, now).Scan(&ended)
+	if err != nil {
+		return 0, ErrDatabaseInternal
+	}
+	return ended, nil
+}
+
+// ShiftEnds returns, for the couriers among ids that have shifts, the end of
+// the shift running at the given time, or the zero time when none is.
+// Couriers without shifts are left out.
+func (r *ShiftRepository) ShiftEnds(ctx context.Context, ids []int, at time.Time) (map[int]time.Time, error) {
+	db := ipostgres.DBFromContext(ctx, r.conn)
+	query := `SELECT c.id, s.ends_at
+	          FROM unnest($1::bigint[]) AS c(id)
+	          JOIN LATERAL (
+	              SELECT MAX(ends_at) FILTER (WHERE starts_at <= $2 AND ends_at > $2) AS ends_at,
+	                     COUNT(*) AS total
+	              FROM courier_shifts
+	              WHERE courier_id = c.id
+	          ) s ON s.total > 0`
+
+	rows, err := db.Query(ctx, query, ids, at)
+	if err != nil {
+		return nil, ErrDatabaseInternal
+	}
+	defer rows.Close()
+
+	ends := make(map[int]time.Time)
+	for rows.Next() {
+		var id int
+		var endsAt *time.Time
+		if err := rows.Scan(&id, &endsAt); err != nil {
+			return nil, ErrReadingData
+		}
+		ends[id] = time.Time{}
+		if endsAt != nil {
+			ends[id] = *endsAt
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, ErrDatabaseInternal
+	}
+	return ends, nil
+}
+
+func scanShift(row pgx.Row) (*model.Shift, error) {
+	var shift model.Shift
+	err := row.Scan(&shift.ID, &shift.CourierID, &shift.TemplateID, &shift.StartsAt, &shift.EndsAt,
+		&shift.CreatedAt, &shift.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &shift, nil
+}
+
+func scanTemplate(row pgx.Row) (*model.ShiftTemplate, error) {
+	var template model.ShiftTemplate
+	var days []int
+	err := row.Scan(&template.ID, &template.CourierID, &days, &template.StartMinute, &template.EndMinute,
+		&template.GeneratedUntil, &template.CreatedAt, &template.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	template.Weekdays = make([]time.Weekday, len(days))
+	for i, d := range days {
+		template.Weekdays[i] = time.Weekday(d)
+	}
+	return &template, nil
+}
+
+func weekdays(template *model.ShiftTemplate) []int {
+	days := make([]int, len(template.Weekdays))
+	for i, d := range template.Weekdays {
+		days[i] = int(d)
+	}
+	return days
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
+func isExclusionViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23P01"
+}
@@ -0,0 +1,10 @@
+package zone
+
+import "errors"
+
+var (
+	ErrZoneNotFound     = errors.New("zone not found")
+	ErrZoneNameExists   = errors.New("zone with this name already exists")
+	ErrDatabaseInternal = errors.New("database error")
+	ErrReadingData      = errors.New("error reading data")
+)
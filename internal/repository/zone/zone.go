@@ -0,0 +1,152 @@
+package zone
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	ipostgres "github.com/cdxy1/go-courier-service/internal/infra/postgres"
+	"github.com/cdxy1/go-courier-service/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const zoneColumns = `id, name, area, neighbour_ids, created_at, updated_at`
+
+type ZoneRepository struct {
+	conn *pgxpool.Pool
+}
+
+func NewZoneRepository(conn *pgxpool.Pool) *ZoneRepository {
+	return &ZoneRepository{conn: conn}
+}
+
+func (r *ZoneRepository) Create(ctx context.Context, zone *model.Zone) (int, error) {
+	db := ipostgres.DBFromContext(ctx, r.conn)
+	area, err := json.Marshal(zone.Area)
+	if err != nil {
+		return 0, ErrDatabaseInternal
+	}
+
+	var id int
+	query := `INSERT INTO zones(name, area, neighbour_ids) VALUES ($1, $2, $3) RETURNING id`
+	if err := db.QueryRow(ctx, query, zone.Name, area, neighbourIds(zone)).Scan(&id); err != nil {
+		if isUniqueViolation(err) {
+			return 0, ErrZoneNameExists
+		}
+		return 0, ErrDatabaseInternal
+	}
+	return id, nil
+}
+
+func (r *ZoneRepository) Update(ctx context.Context, zone *model.Zone) error {
+	db := ipostgres.DBFromContext(ctx, r.conn)
+	area, err := json.Marshal(zone.Area)
+	if err != nil {
+		return ErrDatabaseInternal
+	}
+
+	query := `UPDATE zones SET name=$1, area=$2, neighbour_ids=$3, updated_at=NOW() WHERE id=$4 RETURNING id`
+	var returnedId int
+	if err := db.QueryRow(ctx, query, zone.Name, area, neighbourIds(zone), zone.ID).Scan(&returnedId); err != nil {
+		if isUniqueViolation(err) {
+			return ErrZoneNameExists
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrZoneNotFound
+		}
+		return ErrDatabaseInternal
+	}
+	return nil
+}
+
+// Delete removes the zone. Its couriers lose their home zone and the zone is
+// dropped from the neighbour lists of other zones.
+func (r *ZoneRepository) Delete(ctx context.Context, id int) error {
+	db := ipostgres.DBFromContext(ctx, r.conn)
+	// Couriers lose the zone here rather than through the foreign key so that
+	// they get a new version.
+	query := `UPDATE couriers SET zone_id=NULL, version=version+1, updated_at=NOW() WHERE zone_id=$1`
+	if err := db.Exec(ctx, query, id); err != nil {
+		return ErrDatabaseInternal
+	}
+
+	query = `DELETE FROM zones WHERE id=$1 RETURNING id`
+	var returnedId int
+	if err := db.QueryRow(ctx, query, id).Scan(&returnedId); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrZoneNotFound
+		}
+		return ErrDatabaseInternal
+	}
+
+	query = `UPDATE zones SET neighbour_ids=array_remove(neighbour_ids, $1::bigint), updated_at=NOW()
+	         WHERE $1 = ANY(neighbour_ids)`
+	if err := db.Exec(ctx, query, id); err != nil {
+		return ErrDatabaseInternal
+	}
+	return nil
+}
+
+func (r *ZoneRepository) GetOneById(ctx context.Context, id int) (*model.Zone, error) {
+	db := ipostgres.DBFromContext(ctx, r.conn)
+	query := `SELECT ` + zoneColumns + ` FROM zones WHERE id=$1`
+
+	zone, err := scanZone(db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrZoneNotFound
+		}
+		return nil, ErrDatabaseInternal
+	}
+	return zone, nil
+}
+
+func (r *ZoneRepository) GetAll(ctx context.Context) ([]*model.Zone, error) {
+	db := ipostgres.DBFromContext(ctx, r.conn)
+	query := `SELECT ` + zoneColumns + ` FROM zones ORDER BY id ASC`
+
+	rows, err := db.Query(ctx, query)
+	if err != nil {
+		return nil, ErrDatabaseInternal
+	}
+	defer rows.Close()
+
+	zones := []*model.Zone{}
+	for rows.Next() {
+		zone, err := scanZone(rows)
+		if err != nil {
+			return nil, ErrReadingData
+		}
+		zones = append(zones, zone)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, ErrDatabaseInternal
+	}
+	return zones, nil
+}
+
+func scanZone(row pgx.Row) (*model.Zone, error) {
+	var zone model.Zone
+	var area []byte
+	if err := row.Scan(&zone.ID, &zone.Name, &area, &zone.NeighbourIDs, &zone.CreatedAt, &zone.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(area, &zone.Area); err != nil {
+		return nil, err
+	}
+	return &zone, nil
+}
+
+func neighbourIds(zone *model.Zone) []int {
+	if zone.NeighbourIDs == nil {
+		return []int{}
+	}
+	return zone.NeighbourIDs
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -7,9 +7,47 @@ type courierHandler interface {
 	GetAll(c echo.Context) error
 	Create(c echo.Context) error
 	Update(c echo.Context) error
+	Patch(c echo.Context) error
+	ChangeStatus(c echo.Context) error
+}
+
+type zoneHandler interface {
+	GetByID(c echo.Context) error
+	GetAll(c echo.Context) error
+	Create(c echo.Context) error
+	Update(c echo.Context) error
+	Delete(c echo.Context) error
+}
+
+type shiftHandler interface {
+	GetByID(c echo.Context) error
+	List(c echo.Context) error
+	Create(c echo.Context) error
+	Update(c echo.Context) error
+	Delete(c echo.Context) error
+	GetTemplate(c echo.Context) error
+	ListTemplates(c echo.Context) error
+	CreateTemplate(c echo.Context) error
+	UpdateTemplate(c echo.Context) error
+	DeleteTemplate(c echo.Context) error
+}
+
+type locationHandler interface {
+	Report(c echo.Context) error
+	Get(c echo.Context) error
+	Trail(c echo.Context) error
 }
 
 type deliveryHandler interface {
 	Assign(c echo.Context) error
 	Unassign(c echo.Context) error
+	Reassign(c echo.Context) error
+	PickUp(c echo.Context) error
+	StartTransit(c echo.Context) error
+	Complete(c echo.Context) error
+	History(c echo.Context) error
+	Queue(c echo.Context) error
+	Get(c echo.Context) error
+	List(c echo.Context) error
+	ListByCourier(c echo.Context) error
 }
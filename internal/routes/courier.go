@@ -11,4 +11,6 @@ func RegisterCourierRoutes(e *echo.Group, h courierHandler) {
 	couriers.GET("", h.GetAll)
 	couriers.POST("", h.Create)
 	couriers.PUT("", h.Update)
+	couriers.PATCH("/:id", h.Patch)
+	couriers.POST("/:id/status", h.ChangeStatus)
 }
@@ -9,4 +9,14 @@ func RegisterDeliveryRoutes(e *echo.Group, h deliveryHandler) {
 
 	delivery.POST("/assign", h.Assign)
 	delivery.POST("/unassign", h.Unassign)
+	delivery.POST("/reassign", h.Reassign)
+	delivery.POST("/pickup", h.PickUp)
+	delivery.POST("/in-transit", h.StartTransit)
+	delivery.POST("/complete", h.Complete)
+	delivery.GET("", h.List)
+	delivery.GET("/queue", h.Queue)
+	delivery.GET("/:order_id", h.Get)
+	delivery.GET("/:order_id/history", h.History)
+
+	e.GET("/couriers/:id/deliveries", h.ListByCourier)
 }
@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterLocationRoutes(e *echo.Group, pings *echo.Group, h locationHandler) {
+	pings.POST("/couriers/:id/location", h.Report)
+
+	e.GET("/couriers/:id/location", h.Get)
+	e.GET("/couriers/:id/trail", h.Trail)
+}
@@ -7,11 +7,28 @@ import (
 type Routes struct {
 	CourierHandler  courierHandler
 	DeliveryHandler deliveryHandler
+	LocationHandler locationHandler
+	ZoneHandler     zoneHandler
+	ShiftHandler    shiftHandler
 	APIMiddlewares  []echo.MiddlewareFunc
 }
 
-func NewRoutes(c courierHandler, d deliveryHandler, apiMiddlewares ...echo.MiddlewareFunc) *Routes {
-	return &Routes{CourierHandler: c, DeliveryHandler: d, APIMiddlewares: apiMiddlewares}
+func NewRoutes(
+	c courierHandler,
+	d deliveryHandler,
+	l locationHandler,
+	z zoneHandler,
+	s shiftHandler,
+	apiMiddlewares ...echo.MiddlewareFunc,
+) *Routes {
+	return &Routes{
+		CourierHandler:  c,
+		DeliveryHandler: d,
+		LocationHandler: l,
+		ZoneHandler:     z,
+		ShiftHandler:    s,
+		APIMiddlewares:  apiMiddlewares,
+	}
 }
 
 func (r *Routes) Register(e *echo.Echo) {
@@ -20,4 +37,8 @@ func (r *Routes) Register(e *echo.Echo) {
 	RegisterHealthRoutes(api)
 	RegisterCourierRoutes(api, r.CourierHandler)
 	RegisterDeliveryRoutes(api, r.DeliveryHandler)
+	RegisterZoneRoutes(api, r.ZoneHandler)
+	RegisterShiftRoutes(api, r.ShiftHandler)
+	// Apps ping every few seconds, so pings skip the API middlewares (rate limit).
+	RegisterLocationRoutes(api, e.Group("/api/v1"), r.LocationHandler)
 }
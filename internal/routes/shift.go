@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterShiftRoutes(e *echo.Group, h shiftHandler) {
+	shifts := e.Group("/shifts")
+
+	shifts.GET("/:id", h.GetByID)
+	shifts.GET("", h.List)
+	shifts.POST("", h.Create)
+	shifts.PUT("/:id", h.Update)
+	shifts.DELETE("/:id", h.Delete)
+
+	templates := e.Group("/shift-templates")
+
+	templates.GET("/:id", h.GetTemplate)
+	templates.GET("", h.ListTemplates)
+	templates.POST("", h.CreateTemplate)
+	templates.PUT("/:id", h.UpdateTemplate)
+	templates.DELETE("/:id", h.DeleteTemplate)
+}
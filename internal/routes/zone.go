@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterZoneRoutes(e *echo.Group, h zoneHandler) {
+	zones := e.Group("/zones")
+
+	zones.GET("/:id", h.GetByID)
+	zones.GET("", h.GetAll)
+	zones.POST("", h.Create)
+	zones.PUT("/:id", h.Update)
+	zones.DELETE("/:id", h.Delete)
+}
@@ -0,0 +1,176 @@
+// Package simulation replays a stream of orders and courier availability
+// against DeliveryUsecase with in-memory repositories and a virtual clock, to
+// compare dispatch strategies and deadline policies offline.
+package simulation
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/geo"
+	"github.com/cdxy1/go-courier-service/internal/model"
+)
+
+// EventType tells what an event of a scenario describes.
+type EventType string
+
+const (
+	// EventCourier adds a courier or changes its status, transport or position.
+	EventCourier EventType = "courier"
+	// EventOrder is an order that needs a courier.
+	EventOrder EventType = "order"
+)
+
+var ErrInvalidEvent = errors.New("invalid event")
+
+// Event is one line of a scenario. Courier events set CourierID and Status,
+// available or paused, and optionally Transport and Location. Order events set
+// OrderID and optionally the fields of an assignment request.
+type Event struct {
+	At        time.Time           `json:"at"`
+	Type      EventType           `json:"type"`
+	CourierID int                 `json:"courier_id,omitempty"`
+	Status    model.CourierStatus `json:"status,omitempty"`
+	Transport model.TransportType `json:"transport,omitempty"`
+	Location  *geo.Point          `json:"location,omitempty"`
+	OrderID   string              `json:"order_id,omitempty"`
+	Priority  int                 `json:"priority,omitempty"`
+	Pickup    *geo.Point          `json:"pickup,omitempty"`
+	Dropoff   *geo.Point          `json:"dropoff,omitempty"`
+	Order     *model.OrderDetails `json:"order,omitempty"`
+	// PromisedBy is the delivery time promised to the customer.
+	PromisedBy *time.Time `json:"promised_by,omitempty"`
+}
+
+func (e *Event) validate() error {
+	if e.At.IsZero() {
+		return fmt.Errorf("%w: missing time", ErrInvalidEvent)
+	}
+	switch e.Type {
+	case EventCourier:
+		if e.CourierID <= 0 {
+			return fmt.Errorf("%w: courier event without courier_id", ErrInvalidEvent)
+		}
+		if e.Status != model.CourierStatusAvailable && e.Status != model.CourierStatusPaused {
+			return fmt.Errorf("%w: courier %d has status %q, want available or paused", ErrInvalidEvent, e.CourierID, e.Status)
+		}
+	case EventOrder:
+		if e.OrderID == "" {
+			return fmt.Errorf("%w: order event without order_id", ErrInvalidEvent)
+		}
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidEvent, e.Type)
+	}
+	return nil
+}
+
+// ReadScenario reads a scenario in JSON lines, one event per line, and returns
+// the events in time order. Events at the same time keep their order.
+func ReadScenario(r io.Reader) ([]Event, error) {
+	var events []Event
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		raw := scanner.Bytes()
+		if len(raw) == 0 {
+			continue
+		}
+		var e Event
+		if err := json.Unmarshal(raw, &e); err != nil {
+			return nil, fmt.Errorf("line %d: %w: %v", line, ErrInvalidEvent, err)
+		}
+		if err := e.validate(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		events = append(events, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read scenario: %w", err)
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].At.Before(events[j].At) })
+	return events, nil
+}
+
+// WriteScenario writes the events in JSON lines.
+func WriteScenario(w io.Writer, events []Event) error {
+	enc := json.NewEncoder(w)
+	for i := range events {
+		if err := enc.Encode(&events[i]); err != nil {
+			return fmt.Errorf("write scenario: %w", err)
+		}
+	}
+	return nil
+}
+
+// GenerateParams describe a synthetic scenario.
+type GenerateParams struct {
+	Start    time.Time
+	Duration time.Duration
+	Couriers int
+	Orders   int
+	// Center and Radius (meters) bound courier positions, pickups and dropoffs.
+	Center geo.Point
+	Radius float64
+	Seed   uint64
+}
+
+// Generate returns a synthetic scenario. Couriers of random transports start
+// their shift within the first quarter of the period and end it within the
+// last quarter. Orders arrive at random, about half of them with a promised
+// delivery time of 30 to 60 minutes.
+func Generate(p GenerateParams) []Event {
+	rnd := rand.New(rand.NewPCG(p.Seed, p.Seed^0x9e3779b97f4a7c15))
+	point := func() *geo.Point {
+		// Uniform in the circle, 111 195 meters per degree of latitude.
+		distance := p.Radius * math.Sqrt(rnd.Float64())
+		angle := 2 * math.Pi * rnd.Float64()
+		lat := p.Center.Lat + distance*math.Cos(angle)/111_195
+		lon := p.Center.Lon + distance*math.Sin(angle)/(111_195*math.Cos(p.Center.Lat*math.Pi/180))
+		return &geo.Point{Lat: lat, Lon: lon}
+	}
+	at := func(from, span time.Duration) time.Time {
+		return p.Start.Add(from + time.Duration(rnd.Float64()*float64(span))).Truncate(time.Second)
+	}
+	transports := []model.TransportType{model.TransportOnFoot, model.TransportScooter, model.TransportCar}
+
+	events := make([]Event, 0, 2*p.Couriers+p.Orders)
+	quarter := p.Duration / 4
+	for id := 1; id <= p.Couriers; id++ {
+		transport := transports[rnd.IntN(len(transports))]
+		events = append(events,
+			Event{At: at(0, quarter), Type: EventCourier, CourierID: id, Status: model.CourierStatusAvailable,
+				Transport: transport, Location: point()},
+			Event{At: at(3*quarter, quarter), Type: EventCourier, CourierID: id, Status: model.CourierStatusPaused},
+		)
+	}
+	for i := 1; i <= p.Orders; i++ {
+		e := Event{
+			At:      at(0, p.Duration),
+			Type:    EventOrder,
+			OrderID: "sim-" + strconv.Itoa(i),
+			Pickup:  point(),
+			Dropoff: point(),
+			Order: &model.OrderDetails{
+				ItemCount:   1 + rnd.IntN(8),
+				Quantity:    1 + rnd.IntN(12),
+				TotalPrice:  int64(300 + rnd.IntN(5000)),
+				WeightGrams: 200 + rnd.IntN(6000),
+			},
+		}
+		if rnd.IntN(2) == 0 {
+			promisedBy := e.At.Add(30*time.Minute + time.Duration(rnd.IntN(31))*time.Minute)
+			e.PromisedBy = &promisedBy
+		}
+		events = append(events, e)
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].At.Before(events[j].At) })
+	return events
+}
@@ -0,0 +1,370 @@
+package simulation
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/geo"
+	"github.com/cdxy1/go-courier-service/internal/model"
+	ucd "github.com/cdxy1/go-courier-service/internal/usecase/delivery"
+)
+
+const (
+	defaultHandling     = 5 * time.Minute
+	defaultTrip         = 20 * time.Minute
+	defaultPendingBatch = 50
+)
+
+// Settings describe how couriers work in the simulation.
+type Settings struct {
+	// Speeds are the average speeds couriers travel at.
+	Speeds model.TransportSpeeds
+	// Handling is the time spent at the pickup and at the dropoff together.
+	Handling time.Duration
+	// DefaultTrip is how long a delivery without pickup or dropoff point takes.
+	DefaultTrip time.Duration
+	// PendingBatch is how many queued orders are retried when a courier frees up.
+	PendingBatch int
+}
+
+// Report summarizes a simulation run.
+type Report struct {
+	Orders    int
+	Delivered int
+	// Unassigned orders never got a courier: still queued when the scenario
+	// ended or not eligible for any transport.
+	Unassigned int
+	// Utilization is the share of the time couriers were on shift that they
+	// spent delivering.
+	Utilization float64
+	// MeanWait and MaxWait are measured from the order to its assignment.
+	MeanWait time.Duration
+	MaxWait  time.Duration
+	// DeadlineMisses counts deliveries completed after their deadline,
+	// PromiseMisses those completed after the time promised to the customer.
+	DeadlineMisses int
+	PromiseMisses  int
+	// MinDeliveries and MaxDeliveries are the fewest and most deliveries made
+	// by a courier, LoadCV the coefficient of variation of deliveries per courier.
+	MinDeliveries int
+	MaxDeliveries int
+	LoadCV        float64
+}
+
+// Run replays the events, in time order, against a DeliveryUsecase built from
+// the time factory and options over in-memory repositories and a virtual clock.
+// Orders are assigned as they arrive and queued when nobody is free. Couriers
+// work through their deliveries one after another: they travel from their last
+// dropoff, or their reported position, to the pickup and on to the dropoff at
+// the speed of their transport and spend the handling time. The queue is
+// retried whenever a courier finishes a delivery or starts a shift. Deadlines
+// are judged by the simulated completion time, the expiry monitor does not run.
+func Run(
+	ctx context.Context,
+	events []Event,
+	timeFactory *model.DeliveryTimeFactory,
+	settings Settings,
+	opts ...ucd.Option,
+) (*Report, error) {
+	if settings.Speeds == nil {
+		settings.Speeds = model.NewTransportSpeeds(5, 15, 25)
+	}
+	if settings.Handling <= 0 {
+		settings.Handling = defaultHandling
+	}
+	if settings.DefaultTrip <= 0 {
+		settings.DefaultTrip = defaultTrip
+	}
+	if settings.PendingBatch <= 0 {
+		settings.PendingBatch = defaultPendingBatch
+	}
+
+	s := &simulator{
+		settings:    settings,
+		orders:      make(map[string]*orderState),
+		freeAt:      make(map[int]time.Time),
+		position:    make(map[int]geo.Point),
+		onlineSince: make(map[int]time.Time),
+		online:      make(map[int]time.Duration),
+		delivered:   make(map[int]int),
+	}
+	if len(events) > 0 {
+		s.clock = events[0].At
+	}
+	s.couriers = newCourierStore(s.now)
+	s.deliveries = newDeliveryStore()
+	s.uc = ucd.NewDeliveryUsecase(s.couriers, s.deliveries, noTx{}, timeFactory, s.now, opts...)
+
+	for i := range events {
+		s.push(step{at: events[i].At, event: &events[i]})
+	}
+	for s.steps.Len() > 0 {
+		next := heap.Pop(&s.steps).(step)
+		s.clock = next.at
+		var err error
+		switch {
+		case next.event != nil && next.event.Type == EventCourier:
+			err = s.courierChanged(ctx, *next.event)
+		case next.event != nil:
+			err = s.orderArrived(ctx, *next.event)
+		default:
+			err = s.deliveryDone(ctx, next.orderId)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("simulate %s: %w", s.clock.Format(time.RFC3339), err)
+		}
+	}
+	return s.report(), nil
+}
+
+type orderState struct {
+	arrivedAt time.Time
+	assigned  bool
+	// dropoff is where the courier ends up after the delivery.
+	dropoff *geo.Point
+}
+
+type simulator struct {
+	settings   Settings
+	clock      time.Time
+	couriers   *courierStore
+	deliveries *deliveryStore
+	uc         *ucd.DeliveryUsecase
+	steps      stepQueue
+	seq        int
+
+	orders map[string]*orderState
+	// freeAt is when a courier completes its last delivery, position where it
+	// is by then.
+	freeAt   map[int]time.Time
+	position map[int]geo.Point
+
+	onlineSince map[int]time.Time
+	online      map[int]time.Duration
+	busy        time.Duration
+	delivered   map[int]int
+
+	waits          time.Duration
+	maxWait        time.Duration
+	assigned       int
+	deadlineMisses int
+	promiseMisses  int
+}
+
+func (s *simulator) now() time.Time {
+	return s.clock
+}
+
+func (s *simulator) push(st step) {
+	st.seq = s.seq
+	s.seq++
+	heap.Push(&s.steps, st)
+}
+
+func (s *simulator) courierChanged(ctx context.Context, e Event) error {
+	since, online := s.onlineSince[e.CourierID]
+	switch {
+	case e.Status == model.CourierStatusAvailable && !online:
+		s.onlineSince[e.CourierID] = s.clock
+		if _, ok := s.online[e.CourierID]; !ok {
+			s.online[e.CourierID] = 0
+		}
+	case e.Status != model.CourierStatusAvailable && online:
+		s.online[e.CourierID] += s.clock.Sub(since)
+		delete(s.onlineSince, e.CourierID)
+	}
+
+	s.couriers.setAvailability(e)
+	if e.Location != nil && !s.freeAt[e.CourierID].After(s.clock) {
+		s.position[e.CourierID] = *e.Location
+	}
+	if e.Status == model.CourierStatusAvailable {
+		return s.retryPending(ctx)
+	}
+	return nil
+}
+
+func (s *simulator) orderArrived(ctx context.Context, e Event) error {
+	if _, ok := s.orders[e.OrderID]; ok {
+		return nil
+	}
+	s.orders[e.OrderID] = &orderState{arrivedAt: s.clock, dropoff: e.Dropoff}
+
+	d, courier, err := s.uc.AssignCourier(ctx, model.AssignCourierRequest{
+		OrderID:    e.OrderID,
+		Priority:   e.Priority,
+		Pickup:     e.Pickup,
+		Dropoff:    e.Dropoff,
+		Order:      e.Order,
+		PromisedBy: e.PromisedBy,
+	})
+	switch {
+	case errors.Is(err, ucd.ErrAssignmentQueued), errors.Is(err, ucd.ErrNoEligibleTransport):
+		return nil
+	case err != nil:
+		return err
+	}
+	s.start(d, courier)
+	return nil
+}
+
+// start schedules the completion of a new delivery.
+func (s *simulator) start(d *model.DeliveryModel, courier *model.CourierModel) {
+	order := s.orders[d.OrderId]
+	order.assigned = true
+	wait := s.clock.Sub(order.arrivedAt)
+	s.waits += wait
+	s.maxWait = max(s.maxWait, wait)
+	s.assigned++
+
+	begin := s.clock
+	if s.freeAt[courier.ID].After(begin) {
+		begin = s.freeAt[courier.ID]
+	}
+	trip := s.settings.DefaultTrip
+	if d.Pickup != nil && d.Dropoff != nil {
+		trip = s.settings.Handling + s.travel(courier.TransportType, *d.Pickup, *d.Dropoff)
+		if from, ok := s.position[courier.ID]; ok {
+			trip += s.travel(courier.TransportType, from, *d.Pickup)
+		}
+	}
+	done := begin.Add(trip)
+	s.freeAt[courier.ID] = done
+	if d.Dropoff != nil {
+		s.position[courier.ID] = *d.Dropoff
+	}
+	s.busy += trip
+	s.push(step{at: done, orderId: d.OrderId})
+}
+
+func (s *simulator) travel(transport model.TransportType, from, to geo.Point) time.Duration {
+	return s.settings.Speeds.TravelTime(transport, geo.Distance(from, to))
+}
+
+func (s *simulator) deliveryDone(ctx context.Context, orderId string) error {
+	d, err := s.uc.Complete(ctx, orderId)
+	if err != nil {
+		return err
+	}
+	if dropoff := s.orders[orderId].dropoff; dropoff != nil {
+		s.couriers.moveTo(d.CourierId, *dropoff)
+	}
+	s.delivered[d.CourierId]++
+	if s.clock.After(d.Deadline) {
+		s.deadlineMisses++
+	}
+	if d.PromisedBy != nil && s.clock.After(*d.PromisedBy) {
+		s.promiseMisses++
+	}
+	return s.retryPending(ctx)
+}
+
+// retryPending assigns queued orders until the queue stops moving.
+func (s *simulator) retryPending(ctx context.Context) error {
+	for {
+		assigned, err := s.uc.ProcessPendingAssignments(ctx, s.settings.PendingBatch)
+		if err != nil {
+			return err
+		}
+		for _, d := range assigned {
+			courier, err := s.couriers.GetOneById(ctx, d.CourierId)
+			if err != nil {
+				return err
+			}
+			s.start(d, courier)
+		}
+		if len(assigned) < s.settings.PendingBatch {
+			return nil
+		}
+	}
+}
+
+func (s *simulator) report() *Report {
+	r := &Report{
+		Orders:         len(s.orders),
+		Delivered:      s.assigned,
+		DeadlineMisses: s.deadlineMisses,
+		PromiseMisses:  s.promiseMisses,
+		MaxWait:        s.maxWait,
+	}
+	for _, o := range s.orders {
+		if !o.assigned {
+			r.Unassigned++
+		}
+	}
+	if s.assigned > 0 {
+		r.MeanWait = s.waits / time.Duration(s.assigned)
+	}
+
+	var online time.Duration
+	for id, since := range s.onlineSince {
+		s.online[id] += s.clock.Sub(since)
+	}
+	for _, d := range s.online {
+		online += d
+	}
+	if online > 0 {
+		// Deliveries finished after the end of a shift may push it past one.
+		r.Utilization = min(float64(s.busy)/float64(online), 1)
+	}
+
+	// Every courier that was ever on shift counts, also those without deliveries.
+	counts := make([]float64, 0, len(s.online))
+	for id := range s.online {
+		counts = append(counts, float64(s.delivered[id]))
+	}
+	if len(counts) > 0 {
+		r.MinDeliveries = math.MaxInt
+		var sum float64
+		for _, c := range counts {
+			sum += c
+			r.MinDeliveries = min(r.MinDeliveries, int(c))
+			r.MaxDeliveries = max(r.MaxDeliveries, int(c))
+		}
+		mean := sum / float64(len(counts))
+		if mean > 0 {
+			var variance float64
+			for _, c := range counts {
+				variance += (c - mean) * (c - mean)
+			}
+			r.LoadCV = math.Sqrt(variance/float64(len(counts))) / mean
+		}
+	}
+	return r
+}
+
+// step is something that happens at a point of simulated time: an event of the
+// scenario, or the completion of the delivery of orderId.
+type step struct {
+	at      time.Time
+	seq     int
+	event   *Event
+	orderId string
+}
+
+// stepQueue orders steps by time, then by the order they were scheduled in.
+type stepQueue []step
+
+func (q stepQueue) Len() int { return len(q) }
+
+func (q stepQueue) Less(i, j int) bool {
+	if !q[i].at.Equal(q[j].at) {
+		return q[i].at.Before(q[j].at)
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q stepQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *stepQueue) Push(x any) { *q = append(*q, x.(step)) }
+
+func (q *stepQueue) Pop() any {
+	old := *q
+	last := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return last
+}
@@ -0,0 +1,213 @@
+package simulation
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/geo"
+	"github.com/cdxy1/go-courier-service/internal/model"
+	ucd "github.com/cdxy1/go-courier-service/internal/usecase/delivery"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+	promisedBy := at(30)
+	events := []Event{
+		{At: at(0), Type: EventCourier, CourierID: 1, Status: model.CourierStatusAvailable},
+		{At: at(1), Type: EventOrder, OrderID: "o1"},
+		// Courier 1 carries one order at a time, o2 waits until o1 is delivered.
+		{At: at(2), Type: EventOrder, OrderID: "o2", PromisedBy: &promisedBy},
+		{At: at(30), Type: EventCourier, CourierID: 2, Status: model.CourierStatusAvailable},
+		{At: at(31), Type: EventOrder, OrderID: "o3"},
+	}
+
+	report, err := Run(
+		context.Background(),
+		events,
+		model.NewDeliveryTimeFactory(30*time.Minute, 15*time.Minute, 5*time.Minute),
+		Settings{DefaultTrip: 20 * time.Minute},
+		ucd.WithCourierCapacity(model.NewCourierCapacity(1, 1, 1)),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := &Report{
+		Orders:    3,
+		Delivered: 3,
+		// Courier 1 is on shift 51 minutes, courier 2 21 minutes, they deliver for 60.
+		Utilization: 60.0 / 72.0,
+		MeanWait:    19 * time.Minute / 3,
+		MaxWait:     19 * time.Minute,
+		// o2 misses its promise but not its deadline.
+		DeadlineMisses: 0,
+		PromiseMisses:  1,
+		MinDeliveries:  1,
+		MaxDeliveries:  2,
+		LoadCV:         1.0 / 3.0,
+	}
+	if math.Abs(report.Utilization-want.Utilization) > 1e-9 || math.Abs(report.LoadCV-want.LoadCV) > 1e-9 {
+		t.Fatalf("expected utilization %v and load cv %v, got %v and %v",
+			want.Utilization, want.LoadCV, report.Utilization, report.LoadCV)
+	}
+	report.Utilization, report.LoadCV = want.Utilization, want.LoadCV
+	if *report != *want {
+		t.Fatalf("expected report %+v, got %+v", *want, *report)
+	}
+}
+
+func TestRun_Travel(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	home := geo.Point{Lat: 55.75, Lon: 37.60}
+	pickup := geo.Point{Lat: 55.76, Lon: 37.60}
+	dropoff := geo.Point{Lat: 55.77, Lon: 37.60}
+	events := []Event{
+		{At: start, Type: EventCourier, CourierID: 1, Status: model.CourierStatusAvailable,
+			Transport: model.TransportCar, Location: &home},
+		{At: start, Type: EventOrder, OrderID: "o1", Pickup: &pickup, Dropoff: &dropoff},
+	}
+	speeds := model.NewTransportSpeeds(5, 15, 25)
+	settings := Settings{Speeds: speeds, Handling: 5 * time.Minute}
+
+	report, err := Run(
+		context.Background(),
+		events,
+		model.NewDeliveryTimeFactory(30*time.Minute, 15*time.Minute, 5*time.Minute),
+		settings,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The car needs over 5 minutes to reach the pickup and the dropoff and
+	// misses its deadline, while it is busy the whole time it is on shift.
+	if report.Delivered != 1 || report.DeadlineMisses != 1 || report.Utilization != 1 {
+		t.Fatalf("expected one late delivery at full utilization, got %+v", *report)
+	}
+}
+
+func TestReadScenario(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "sorts by time",
+			input: `{"at":"2026-10-16T09:05:00Z","type":"order","order_id":"o2"}
+
+{"at":"2026-10-16T09:00:00Z","type":"courier","courier_id":1,"status":"available"}
+{"at":"2026-10-16T09:05:00Z","type":"order","order_id":"o3"}
+`,
+			want: []string{"courier", "o2", "o3"},
+		},
+		{
+			name:    "bad json",
+			input:   `{"at":`,
+			wantErr: true,
+		},
+		{
+			name:    "missing time",
+			input:   `{"type":"order","order_id":"o1"}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown type",
+			input:   `{"at":"2026-10-16T09:00:00Z","type":"shift"}`,
+			wantErr: true,
+		},
+		{
+			name:    "courier status",
+			input:   `{"at":"2026-10-16T09:00:00Z","type":"courier","courier_id":1,"status":"busy"}`,
+			wantErr: true,
+		},
+		{
+			name:    "order without id",
+			input:   `{"at":"2026-10-16T09:00:00Z","type":"order"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			events, err := ReadScenario(strings.NewReader(tt.input))
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidEvent) {
+					t.Fatalf("expected ErrInvalidEvent, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := make([]string, len(events))
+			for i, e := range events {
+				got[i] = e.OrderID
+				if e.Type == EventCourier {
+					got[i] = string(e.Type)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	params := GenerateParams{
+		Start:    time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC),
+		Duration: 8 * time.Hour,
+		Couriers: 5,
+		Orders:   40,
+		Center:   geo.Point{Lat: 55.75, Lon: 37.61},
+		Radius:   3000,
+		Seed:     7,
+	}
+	events := Generate(params)
+	if len(events) != 2*params.Couriers+params.Orders {
+		t.Fatalf("expected %d events, got %d", 2*params.Couriers+params.Orders, len(events))
+	}
+	if !reflect.DeepEqual(events, Generate(params)) {
+		t.Fatalf("expected the same scenario for the same seed")
+	}
+
+	var buf bytes.Buffer
+	if err := WriteScenario(&buf, events); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	read, err := ReadScenario(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(read) != len(events) {
+		t.Fatalf("expected %d events read back, got %d", len(events), len(read))
+	}
+	for i := range events {
+		if !read[i].At.Equal(events[i].At) || read[i].OrderID != events[i].OrderID || read[i].CourierID != events[i].CourierID {
+			t.Fatalf("event %d read back as %+v, want %+v", i, read[i], events[i])
+		}
+		if p := read[i].Pickup; p != nil && geo.Distance(*p, params.Center) > params.Radius+1 {
+			t.Fatalf("pickup %+v outside the area", *p)
+		}
+	}
+}
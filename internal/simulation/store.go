@@ -0,0 +1,388 @@
+package simulation
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/geo"
+	"github.com/cdxy1/go-courier-service/internal/model"
+	courierRepo "github.com/cdxy1/go-courier-service/internal/repository/courier"
+	deliveryRepo "github.com/cdxy1/go-courier-service/internal/repository/delivery"
+)
+
+// The stores stand in for the Postgres repositories. A simulation runs on one
+// goroutine, so nothing is locked and FOR UPDATE reads are plain reads. They
+// hand out copies, as rows read from the database would be.
+
+// courierStore keeps couriers in memory.
+type courierStore struct {
+	now      func() time.Time
+	couriers map[int]*model.CourierModel
+	// assigned holds the assignment times of every courier, oldest first.
+	assigned map[int][]time.Time
+}
+
+func newCourierStore(now func() time.Time) *courierStore {
+	return &courierStore{
+		now:      now,
+		couriers: make(map[int]*model.CourierModel),
+		assigned: make(map[int][]time.Time),
+	}
+}
+
+func copyCourier(c *model.CourierModel) *model.CourierModel {
+	copied := *c
+	return &copied
+}
+
+func (s *courierStore) Create(_ context.Context, courier *model.CourierModel) (int, error) {
+	if courier.ID == 0 {
+		courier.ID = len(s.couriers) + 1
+	}
+	s.couriers[courier.ID] = copyCourier(courier)
+	return courier.ID, nil
+}
+
+func (s *courierStore) Update(_ context.Context, courier *model.CourierModel) error {
+	if _, ok := s.couriers[courier.ID]; !ok {
+		return courierRepo.ErrCourierNotFound
+	}
+	s.couriers[courier.ID] = copyCourier(courier)
+	return nil
+}
+
+func (s *courierStore) GetOneById(_ context.Context, id int) (*model.CourierModel, error) {
+	c, ok := s.couriers[id]
+	if !ok {
+		return nil, courierRepo.ErrCourierNotFound
+	}
+	return copyCourier(c), nil
+}
+
+func (s *courierStore) GetOneByIdForUpdate(ctx context.Context, id int) (*model.CourierModel, error) {
+	return s.GetOneById(ctx, id)
+}
+
+func (s *courierStore) GetOneByIdSkipLocked(ctx context.Context, id int) (*model.CourierModel, error) {
+	return s.GetOneById(ctx, id)
+}
+
+func (s *courierStore) GetAll(_ context.Context) ([]*model.CourierModel, error) {
+	couriers := make([]*model.CourierModel, 0, len(s.couriers))
+	for _, id := range s.ids() {
+		couriers = append(couriers, copyCourier(s.couriers[id]))
+	}
+	return couriers, nil
+}
+
+func (s *courierStore) GetByStatus(_ context.Context, status model.CourierStatus) (*model.CourierModel, error) {
+	for _, id := range s.ids() {
+		if c := s.couriers[id]; c.Status == status {
+			return copyCourier(c), nil
+		}
+	}
+	return nil, courierRepo.ErrCourierNotFound
+}
+
+func (s *courierStore) UpdateStatus(_ context.Context, status model.CourierStatus, id int) error {
+	c, ok := s.couriers[id]
+	if !ok {
+		return courierRepo.ErrCourierNotFound
+	}
+	c.Status = status
+	return nil
+}
+
+func (s *courierStore) MarkAssigned(_ context.Context, id int) error {
+	c, ok := s.couriers[id]
+	if !ok {
+		return courierRepo.ErrCourierNotFound
+	}
+	c.Status = model.CourierStatusBusy
+	c.ActiveDeliveries++
+	s.assigned[id] = append(s.assigned[id], s.now())
+	return nil
+}
+
+func (s *courierStore) MarkReleased(_ context.Context, id int) error {
+	c, ok := s.couriers[id]
+	if !ok {
+		return courierRepo.ErrCourierNotFound
+	}
+	if c.ActiveDeliveries <= 1 && c.Status == model.CourierStatusBusy {
+		c.Status = model.CourierStatusAvailable
+	}
+	c.ActiveDeliveries = max(c.ActiveDeliveries-1, 0)
+	return nil
+}
+
+// ListAvailable mirrors the query of the courier repository.
+func (s *courierStore) ListAvailable(_ context.Context, filter model.AvailableCourierFilter) ([]*model.CourierCandidate, error) {
+	candidates := []*model.CourierCandidate{}
+	for _, id := range s.ids() {
+		c := s.couriers[id]
+		switch {
+		case c.Status != model.CourierStatusAvailable && !(c.Status == model.CourierStatusBusy && c.ActiveDeliveries > 0),
+			c.ActiveDeliveries >= filter.Capacity.For(c.TransportType),
+			slices.Contains(filter.ExcludeIDs, id),
+			filter.Transports != nil && !slices.Contains(filter.Transports, c.TransportType):
+			continue
+		}
+		if len(filter.Cells) > 0 && (c.Location == nil || !slices.Contains(filter.Cells, geo.Encode(*c.Location, geo.CellPrecision))) {
+			continue
+		}
+
+		candidate := &model.CourierCandidate{Courier: copyCourier(c)}
+		for _, at := range s.assigned[id] {
+			if !at.Before(filter.Since) {
+				candidate.AssignmentsToday++
+			}
+			if !at.Before(filter.LoadSince) {
+				if filter.LoadHalfLife > 0 {
+					candidate.RecentLoad += math.Pow(0.5, filter.Now.Sub(at).Seconds()/filter.LoadHalfLife.Seconds())
+				} else {
+					candidate.RecentLoad++
+				}
+			}
+			last := at
+			candidate.LastAssignedAt = &last
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, nil
+}
+
+// setAvailability applies a courier event: it adds the courier or changes its
+// status, transport and position. A courier still carrying deliveries comes
+// back busy.
+func (s *courierStore) setAvailability(e Event) {
+	c, ok := s.couriers[e.CourierID]
+	if !ok {
+		c = &model.CourierModel{ID: e.CourierID, TransportType: model.TransportOnFoot, CreatedAt: e.At}
+		s.couriers[e.CourierID] = c
+	}
+	c.Status = e.Status
+	if e.Status == model.CourierStatusAvailable && c.ActiveDeliveries > 0 {
+		c.Status = model.CourierStatusBusy
+	}
+	if e.Transport != "" {
+		c.TransportType = e.Transport
+	}
+	if e.Location != nil {
+		s.moveTo(e.CourierID, *e.Location)
+	}
+	c.UpdatedAt = e.At
+}
+
+func (s *courierStore) moveTo(id int, p geo.Point) {
+	c := s.couriers[id]
+	at := s.now()
+	c.Location = &p
+	c.LocationUpdatedAt = &at
+}
+
+func (s *courierStore) ids() []int {
+	ids := make([]int, 0, len(s.couriers))
+	for id := range s.couriers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// deliveryStore keeps deliveries, their events and the pending queue in memory.
+type deliveryStore struct {
+	deliveries []*model.DeliveryModel
+	// latest is the last delivery created for every order.
+	latest  map[string]*model.DeliveryModel
+	byKey   map[string]*model.DeliveryModel
+	pending map[string]*model.PendingAssignment
+	events  []*model.DeliveryEvent
+	// lastPendingId numbers queued orders like the serial column does.
+	lastPendingId int
+}
+
+func newDeliveryStore() *deliveryStore {
+	return &deliveryStore{
+		latest:  make(map[string]*model.DeliveryModel),
+		byKey:   make(map[string]*model.DeliveryModel),
+		pending: make(map[string]*model.PendingAssignment),
+	}
+}
+
+func copyDelivery(d *model.DeliveryModel) *model.DeliveryModel {
+	copied := *d
+	return &copied
+}
+
+func (s *deliveryStore) Create(_ context.Context, delivery *model.DeliveryModel) error {
+	if d, ok := s.latest[delivery.OrderId]; ok && d.Status.IsActive() {
+		return deliveryRepo.ErrActiveDeliveryExists
+	}
+	if _, ok := s.byKey[delivery.IdempotencyKey]; ok && delivery.IdempotencyKey != "" {
+		return deliveryRepo.ErrIdempotencyKeyExists
+	}
+	delivery.ID = len(s.deliveries) + 1
+	stored := copyDelivery(delivery)
+	s.deliveries = append(s.deliveries, stored)
+	s.latest[delivery.OrderId] = stored
+	if delivery.IdempotencyKey != "" {
+		s.byKey[delivery.IdempotencyKey] = stored
+	}
+	return nil
+}
+
+func (s *deliveryStore) GetByOrderID(_ context.Context, orderId string) (*model.DeliveryModel, error) {
+	d, ok := s.latest[orderId]
+	if !ok {
+		return nil, deliveryRepo.ErrDeliveryNotFound
+	}
+	return copyDelivery(d), nil
+}
+
+func (s *deliveryStore) GetByOrderIDForUpdate(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+	return s.GetByOrderID(ctx, orderId)
+}
+
+func (s *deliveryStore) GetByIdempotencyKey(_ context.Context, key string) (*model.DeliveryModel, error) {
+	d, ok := s.byKey[key]
+	if !ok {
+		return nil, deliveryRepo.ErrDeliveryNotFound
+	}
+	return copyDelivery(d), nil
+}
+
+func (s *deliveryStore) List(_ context.Context, filter model.DeliveryFilter) ([]*model.DeliveryModel, int, error) {
+	var matched []*model.DeliveryModel
+	for _, d := range s.deliveries {
+		switch {
+		case filter.CourierID > 0 && d.CourierId != filter.CourierID,
+			len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, d.Status),
+			filter.AssignedFrom != nil && d.AssignedAt.Before(*filter.AssignedFrom),
+			filter.AssignedTo != nil && !d.AssignedAt.Before(*filter.AssignedTo),
+			filter.OverdueOnly && !(d.Status.IsActive() && d.Deadline.Before(filter.Now)),
+			filter.AtRiskOnly && !d.AtRisk:
+			continue
+		}
+		matched = append(matched, d)
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		if !matched[i].AssignedAt.Equal(matched[j].AssignedAt) {
+			return matched[i].AssignedAt.After(matched[j].AssignedAt)
+		}
+		return matched[i].ID > matched[j].ID
+	})
+
+	page := []*model.DeliveryModel{}
+	for i := filter.Offset; i < len(matched) && (filter.Limit <= 0 || i < filter.Offset+filter.Limit); i++ {
+		page = append(page, copyDelivery(matched[i]))
+	}
+	return page, len(matched), nil
+}
+
+func (s *deliveryStore) UpdateStatus(_ context.Context, delivery *model.DeliveryModel) error {
+	if delivery.ID <= 0 || delivery.ID > len(s.deliveries) {
+		return deliveryRepo.ErrDeliveryNotFound
+	}
+	*s.deliveries[delivery.ID-1] = *delivery
+	return nil
+}
+
+func (s *deliveryStore) GetOverdueForUpdate(_ context.Context, now time.Time) ([]*model.DeliveryModel, error) {
+	overdue := []*model.DeliveryModel{}
+	for _, d := range s.deliveries {
+		if d.Status.IsActive() && d.Deadline.Before(now) {
+			overdue = append(overdue, copyDelivery(d))
+		}
+	}
+	sort.SliceStable(overdue, func(i, j int) bool { return overdue[i].Deadline.Before(overdue[j].Deadline) })
+	return overdue, nil
+}
+
+func (s *deliveryStore) CreateEvent(_ context.Context, event *model.DeliveryEvent) error {
+	copied := *event
+	copied.ID = len(s.events) + 1
+	s.events = append(s.events, &copied)
+	return nil
+}
+
+func (s *deliveryStore) GetEventsByOrderID(_ context.Context, orderId string) ([]*model.DeliveryEvent, error) {
+	events := []*model.DeliveryEvent{}
+	for _, e := range s.events {
+		if e.OrderID == orderId {
+			copied := *e
+			events = append(events, &copied)
+		}
+	}
+	return events, nil
+}
+
+func (s *deliveryStore) Enqueue(_ context.Context, pending *model.PendingAssignment) error {
+	if _, ok := s.pending[pending.OrderID]; ok {
+		return nil
+	}
+	s.lastPendingId++
+	copied := *pending
+	copied.ID = s.lastPendingId
+	s.pending[pending.OrderID] = &copied
+	return nil
+}
+
+func (s *deliveryStore) ListPending(_ context.Context, limit int) ([]*model.PendingAssignment, error) {
+	pending := make([]*model.PendingAssignment, 0, len(s.pending))
+	for _, p := range s.pending {
+		copied := *p
+		pending = append(pending, &copied)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		a, b := pending[i], pending[j]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if !a.EnqueuedAt.Equal(b.EnqueuedAt) {
+			return a.EnqueuedAt.Before(b.EnqueuedAt)
+		}
+		return a.OrderID < b.OrderID
+	})
+	if limit > 0 && len(pending) > limit {
+		pending = pending[:limit]
+	}
+	return pending, nil
+}
+
+func (s *deliveryStore) DeletePending(_ context.Context, orderId string) (bool, error) {
+	_, ok := s.pending[orderId]
+	delete(s.pending, orderId)
+	return ok, nil
+}
+
+func (s *deliveryStore) MarkPendingAttempt(_ context.Context, orderId string, at time.Time, reason string) error {
+	if p, ok := s.pending[orderId]; ok {
+		p.Attempts++
+		p.LastAttemptAt = &at
+		p.LastError = reason
+	}
+	return nil
+}
+
+func (s *deliveryStore) PendingStats(_ context.Context) (*model.PendingQueueStats, error) {
+	stats := &model.PendingQueueStats{Depth: len(s.pending)}
+	for _, p := range s.pending {
+		if stats.OldestEnqueuedAt == nil || p.EnqueuedAt.Before(*stats.OldestEnqueuedAt) {
+			enqueuedAt := p.EnqueuedAt
+			stats.OldestEnqueuedAt = &enqueuedAt
+		}
+	}
+	return stats, nil
+}
+
+// noTx runs transactions as plain calls; the stores have nothing to roll back
+// because a simulation stops at the first error.
+type noTx struct{}
+
+func (noTx) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
@@ -2,6 +2,7 @@ package courier
 
 import (
 	"context"
+	"time"
 
 	"github.com/cdxy1/go-courier-service/internal/model"
 )
@@ -10,5 +11,23 @@ type courierRepository interface {
 	Create(ctx context.Context, courier *model.CourierModel) (int, error)
 	Update(ctx context.Context, courier *model.CourierModel) error
 	GetOneById(ctx context.Context, id int) (*model.CourierModel, error)
+	GetOneByIdForUpdate(ctx context.Context, id int) (*model.CourierModel, error)
 	GetAll(ctx context.Context) ([]*model.CourierModel, error)
+	UpdateStatus(ctx context.Context, status model.CourierStatus, id int) error
+}
+
+type txManager interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type locationRepository interface {
+	SaveLocations(ctx context.Context, pings []*model.LocationPing) error
+	GetLocation(ctx context.Context, courierId int) (*model.LocationPing, error)
+	GetTrail(ctx context.Context, courierId int, from, to time.Time) ([]*model.LocationPing, error)
+	TrimTrail(ctx context.Context, before time.Time) error
+}
+
+type scoreRepository interface {
+	DeliveryStats(ctx context.Context, since time.Time) ([]*model.CourierStats, error)
+	SaveScores(ctx context.Context, scores []*model.CourierScore) error
 }
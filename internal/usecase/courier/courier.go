@@ -12,10 +12,11 @@ import (
 
 type CourierUsecase struct {
 	repo courierRepository
+	tm   txManager
 }
 
-func NewCourierUsecase(repo courierRepository) *CourierUsecase {
-	return &CourierUsecase{repo: repo}
+func NewCourierUsecase(repo courierRepository, tm txManager) *CourierUsecase {
+	return &CourierUsecase{repo: repo, tm: tm}
 }
 
 func validatePhone(phone string) bool {
@@ -48,17 +49,31 @@ func (uc *CourierUsecase) Create(ctx context.Context, req *model.CourierModel) (
 	if !validatePhone(req.Phone) {
 		return 0, ErrInvalidPhone
 	}
+	// A new courier holds no deliveries, so it cannot be busy.
+	switch req.Status {
+	case "":
+		req.Status = model.CourierStatusAvailable
+	case model.CourierStatusAvailable, model.CourierStatusPaused:
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrInvalidStatus, req.Status)
+	}
 	id, err := uc.repo.Create(ctx, req)
 	if err != nil {
 		if errors.Is(err, repo.ErrPhoneExists) {
 			return 0, repo.ErrPhoneExists
 		}
+		if errors.Is(err, repo.ErrZoneNotFound) {
+			return 0, repo.ErrZoneNotFound
+		}
 		return 0, fmt.Errorf("create courier: %w", err)
 	}
 	return id, nil
 }
 
-func (uc *CourierUsecase) Update(ctx context.Context, req *model.CourierModel) error {
+// Update replaces the courier fields. An empty status keeps the current one,
+// any other follows the rules of ChangeStatus without force. The stored version
+// must meet match. On success req.Version is set to the new version.
+func (uc *CourierUsecase) Update(ctx context.Context, req *model.CourierModel, match model.VersionMatch) error {
 	if req.ID <= 0 {
 		return ErrInvalidID
 	}
@@ -68,17 +83,97 @@ func (uc *CourierUsecase) Update(ctx context.Context, req *model.CourierModel) e
 	if !validatePhone(req.Phone) {
 		return ErrInvalidPhone
 	}
-	err := uc.repo.Update(ctx, req)
+	courier, err := uc.modify(ctx, req.ID, match, func(current *model.CourierModel) (*model.CourierModel, error) {
+		update := *req
+		update.Status = current.Status
+		if req.Status != "" {
+			var err error
+			if update.Status, err = nextStatus(current, req.Status, false); err != nil {
+				return nil, err
+			}
+		}
+		return &update, nil
+	})
+	if err != nil {
+		return err
+	}
+	req.Version = courier.Version
+	return nil
+}
+
+// Patch changes the courier fields set in the patch and returns the courier.
+// A status follows the rules of ChangeStatus without force. The stored version
+// must meet match.
+func (uc *CourierUsecase) Patch(ctx context.Context, id int, patch *model.CourierPatch, match model.VersionMatch) (*model.CourierModel, error) {
+	if id <= 0 {
+		return nil, ErrInvalidID
+	}
+	return uc.modify(ctx, id, match, func(current *model.CourierModel) (*model.CourierModel, error) {
+		patched := *current
+		patch.Apply(&patched)
+		if patched.Name == "" {
+			return nil, ErrInvalidName
+		}
+		if !validatePhone(patched.Phone) {
+			return nil, ErrInvalidPhone
+		}
+		if patch.Status != nil {
+			var err error
+			if patched.Status, err = nextStatus(current, *patch.Status, false); err != nil {
+				return nil, err
+			}
+		}
+		return &patched, nil
+	})
+}
+
+// modify locks the courier, checks its version meets match and stores the
+// courier change derives from it.
+func (uc *CourierUsecase) modify(
+	ctx context.Context,
+	id int,
+	match model.VersionMatch,
+	change func(current *model.CourierModel) (*model.CourierModel, error),
+) (*model.CourierModel, error) {
+	var courier *model.CourierModel
+	err := uc.tm.WithTx(ctx, func(ctx context.Context) error {
+		current, err := uc.repo.GetOneByIdForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !match.Matches(current.Version) {
+			return fmt.Errorf("%w: courier %d is at version %d", ErrVersionMismatch, id, current.Version)
+		}
+		if courier, err = change(current); err != nil {
+			return err
+		}
+		return uc.repo.Update(ctx, courier)
+	})
 	if err != nil {
+		for _, known := range []error{
+			ErrInvalidName,
+			ErrInvalidPhone,
+			ErrInvalidStatus,
+			ErrInvalidStatusTransition,
+			ErrActiveDeliveries,
+			ErrVersionMismatch,
+		} {
+			if errors.Is(err, known) {
+				return nil, err
+			}
+		}
 		if errors.Is(err, repo.ErrCourierNotFound) {
-			return repo.ErrCourierNotFound
+			return nil, repo.ErrCourierNotFound
 		}
 		if errors.Is(err, repo.ErrPhoneExists) {
-			return repo.ErrPhoneExists
+			return nil, repo.ErrPhoneExists
 		}
-		return fmt.Errorf("update courier: %w", err)
+		if errors.Is(err, repo.ErrZoneNotFound) {
+			return nil, repo.ErrZoneNotFound
+		}
+		return nil, fmt.Errorf("update courier: %w", err)
 	}
-	return nil
+	return courier, nil
 }
 
 func (uc *CourierUsecase) AssignCourierToOrder(ctx context.Context, orderID string) (int, error) {
@@ -93,7 +188,7 @@ func (uc *CourierUsecase) AssignCourierToOrder(ctx context.Context, orderID stri
 
 	var selectedCourier *model.CourierModel
 	for _, courier := range couriers {
-		if courier.Status == model.CourierStatusActive || courier.Status == "" {
+		if courier.Status == model.CourierStatusAvailable {
 			selectedCourier = courier
 			break
 		}
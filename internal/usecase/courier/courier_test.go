@@ -3,6 +3,7 @@ package courier
 import (
 	"context"
 	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/cdxy1/go-courier-service/internal/model"
@@ -12,11 +13,13 @@ import (
 var errBoom = errors.New("failed")
 
 type mockCourierRepository struct {
-	t            *testing.T
-	createFn     func(ctx context.Context, courier *model.CourierModel) (int, error)
-	updateFn     func(ctx context.Context, courier *model.CourierModel) error
-	getOneByIDFn func(ctx context.Context, id int) (*model.CourierModel, error)
-	getAllFn     func(ctx context.Context) ([]*model.CourierModel, error)
+	t              *testing.T
+	createFn       func(ctx context.Context, courier *model.CourierModel) (int, error)
+	updateFn       func(ctx context.Context, courier *model.CourierModel) error
+	getOneByIDFn   func(ctx context.Context, id int) (*model.CourierModel, error)
+	getForUpdateFn func(ctx context.Context, id int) (*model.CourierModel, error)
+	getAllFn       func(ctx context.Context) ([]*model.CourierModel, error)
+	updateStatusFn func(ctx context.Context, status model.CourierStatus, id int) error
 }
 
 func newMockCourierRepository(t *testing.T) *mockCourierRepository {
@@ -44,6 +47,13 @@ func (m *mockCourierRepository) GetOneById(ctx context.Context, id int) (*model.
 	return m.getOneByIDFn(ctx, id)
 }
 
+func (m *mockCourierRepository) GetOneByIdForUpdate(ctx context.Context, id int) (*model.CourierModel, error) {
+	if m.getForUpdateFn == nil {
+		m.t.Fatalf("GetOneByIdForUpdate called unexpectedly")
+	}
+	return m.getForUpdateFn(ctx, id)
+}
+
 func (m *mockCourierRepository) GetAll(ctx context.Context) ([]*model.CourierModel, error) {
 	if m.getAllFn == nil {
 		m.t.Fatalf("GetAll called unexpectedly")
@@ -51,6 +61,19 @@ func (m *mockCourierRepository) GetAll(ctx context.Context) ([]*model.CourierMod
 	return m.getAllFn(ctx)
 }
 
+func (m *mockCourierRepository) UpdateStatus(ctx context.Context, status model.CourierStatus, id int) error {
+	if m.updateStatusFn == nil {
+		m.t.Fatalf("UpdateStatus called unexpectedly")
+	}
+	return m.updateStatusFn(ctx, status, id)
+}
+
+type mockTxManager struct{}
+
+func (mockTxManager) WithTx(ctx context.Context, fn func(context.Context) error) error {
+	return fn(ctx)
+}
+
 func TestCourierUsecase_GetOneById(t *testing.T) {
 	t.Parallel()
 
@@ -110,7 +133,7 @@ func TestCourierUsecase_GetOneById(t *testing.T) {
 			t.Parallel()
 			repo := newMockCourierRepository(t)
 			tt.repoSetup(repo)
-			uc := NewCourierUsecase(repo)
+			uc := NewCourierUsecase(repo, mockTxManager{})
 
 			result, err := uc.GetOneById(ctx, tt.id)
 
@@ -164,6 +187,18 @@ func TestCourierUsecase_Create(t *testing.T) {
 			setupRepo: func(_ *mockCourierRepository) {},
 			expectErr: ErrInvalidPhone,
 		},
+		{
+			name:      "busy status",
+			input:     &model.CourierModel{Name: "Alice", Phone: "+71234567890", Status: model.CourierStatusBusy},
+			setupRepo: func(_ *mockCourierRepository) {},
+			expectErr: ErrInvalidStatus,
+		},
+		{
+			name:      "unknown status",
+			input:     &model.CourierModel{Name: "Alice", Phone: "+71234567890", Status: "active"},
+			setupRepo: func(_ *mockCourierRepository) {},
+			expectErr: ErrInvalidStatus,
+		},
 		{
 			name:  "phone exists",
 			input: validCourier,
@@ -207,7 +242,7 @@ func TestCourierUsecase_Create(t *testing.T) {
 			t.Parallel()
 			repo := newMockCourierRepository(t)
 			tt.setupRepo(repo)
-			uc := NewCourierUsecase(repo)
+			uc := NewCourierUsecase(repo, mockTxManager{})
 
 			id, err := uc.Create(ctx, tt.input)
 
@@ -242,6 +277,7 @@ func TestCourierUsecase_Update(t *testing.T) {
 	tests := []struct {
 		name      string
 		input     *model.CourierModel
+		match     model.VersionMatch
 		setupRepo func(*mockCourierRepository)
 		expectErr error
 	}{
@@ -305,6 +341,54 @@ func TestCourierUsecase_Update(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:  "empty status keeps the current one",
+			input: &model.CourierModel{ID: 1, Name: "Alice", Phone: "+71234567890"},
+			setupRepo: func(repo *mockCourierRepository) {
+				repo.getForUpdateFn = func(ctx context.Context, id int) (*model.CourierModel, error) {
+					return &model.CourierModel{ID: id, Status: model.CourierStatusBusy, ActiveDeliveries: 1}, nil
+				}
+				repo.updateFn = func(ctx context.Context, courier *model.CourierModel) error {
+					if courier.Status != model.CourierStatusBusy {
+						repo.t.Fatalf("expected busy status, got %s", courier.Status)
+					}
+					return nil
+				}
+			},
+		},
+		{
+			name:      "busy is not set by clients",
+			input:     &model.CourierModel{ID: 1, Name: "Alice", Phone: "+71234567890", Status: model.CourierStatusBusy},
+			setupRepo: func(_ *mockCourierRepository) {},
+			expectErr: ErrInvalidStatusTransition,
+		},
+		{
+			name:  "pause with active deliveries",
+			input: &model.CourierModel{ID: 1, Name: "Alice", Phone: "+71234567890", Status: model.CourierStatusPaused},
+			setupRepo: func(repo *mockCourierRepository) {
+				repo.getForUpdateFn = func(ctx context.Context, id int) (*model.CourierModel, error) {
+					return &model.CourierModel{ID: id, Status: model.CourierStatusBusy, ActiveDeliveries: 2}, nil
+				}
+			},
+			expectErr: ErrActiveDeliveries,
+		},
+		{
+			name:      "modified concurrently",
+			input:     &model.CourierModel{ID: 1, Name: "Alice", Phone: "+71234567890"},
+			match:     model.VersionMatch{Required: true, Versions: []int{2}},
+			setupRepo: func(_ *mockCourierRepository) {},
+			expectErr: ErrVersionMismatch,
+		},
+		{
+			name:  "courier not found before update",
+			input: validCourier,
+			setupRepo: func(repo *mockCourierRepository) {
+				repo.getForUpdateFn = func(ctx context.Context, id int) (*model.CourierModel, error) {
+					return nil, repoerrors.ErrCourierNotFound
+				}
+			},
+			expectErr: repoerrors.ErrCourierNotFound,
+		},
 	}
 
 	ctx := context.Background()
@@ -314,10 +398,13 @@ func TestCourierUsecase_Update(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 			repo := newMockCourierRepository(t)
+			repo.getForUpdateFn = func(ctx context.Context, id int) (*model.CourierModel, error) {
+				return &model.CourierModel{ID: id, Status: model.CourierStatusAvailable}, nil
+			}
 			tt.setupRepo(repo)
-			uc := NewCourierUsecase(repo)
+			uc := NewCourierUsecase(repo, mockTxManager{})
 
-			err := uc.Update(ctx, tt.input)
+			err := uc.Update(ctx, tt.input, tt.match)
 
 			if tt.expectErr != nil {
 				if err == nil || !errors.Is(err, tt.expectErr) {
@@ -333,6 +420,110 @@ func TestCourierUsecase_Update(t *testing.T) {
 	}
 }
 
+func TestCourierUsecase_Patch(t *testing.T) {
+	t.Parallel()
+
+	name := "Bob"
+	badPhone := "123"
+	paused := model.CourierStatusPaused
+	zone := 4
+
+	tests := []struct {
+		name      string
+		patch     model.CourierPatch
+		match     model.VersionMatch
+		current   model.CourierModel
+		expect    model.CourierModel
+		expectErr error
+	}{
+		{
+			name:    "fields left out are kept",
+			patch:   model.CourierPatch{Name: &name},
+			match:   model.VersionMatch{Required: true, Versions: []int{1}},
+			current: model.CourierModel{ID: 1, Name: "Alice", Phone: "+71234567890", Status: model.CourierStatusAvailable, ZoneID: &zone, Version: 1},
+			expect:  model.CourierModel{ID: 1, Name: "Bob", Phone: "+71234567890", Status: model.CourierStatusAvailable, ZoneID: &zone, Version: 2},
+		},
+		{
+			name:    "zone removed",
+			patch:   model.CourierPatch{ZoneSet: true},
+			current: model.CourierModel{ID: 1, Name: "Alice", Phone: "+71234567890", Status: model.CourierStatusAvailable, ZoneID: &zone, Version: 5},
+			expect:  model.CourierModel{ID: 1, Name: "Alice", Phone: "+71234567890", Status: model.CourierStatusAvailable, Version: 6},
+		},
+		{
+			name:    "status follows the transitions",
+			patch:   model.CourierPatch{Status: &paused},
+			current: model.CourierModel{ID: 1, Name: "Alice", Phone: "+71234567890", Status: model.CourierStatusAvailable, Version: 1},
+			expect:  model.CourierModel{ID: 1, Name: "Alice", Phone: "+71234567890", Status: model.CourierStatusPaused, Version: 2},
+		},
+		{
+			name:      "pause with active deliveries",
+			patch:     model.CourierPatch{Status: &paused},
+			current:   model.CourierModel{ID: 1, Name: "Alice", Phone: "+71234567890", Status: model.CourierStatusBusy, ActiveDeliveries: 1},
+			expectErr: ErrActiveDeliveries,
+		},
+		{
+			name:      "invalid phone",
+			patch:     model.CourierPatch{Phone: &badPhone},
+			current:   model.CourierModel{ID: 1, Name: "Alice", Phone: "+71234567890", Status: model.CourierStatusAvailable},
+			expectErr: ErrInvalidPhone,
+		},
+		{
+			name:      "modified concurrently",
+			patch:     model.CourierPatch{Name: &name},
+			match:     model.VersionMatch{Required: true, Versions: []int{1}},
+			current:   model.CourierModel{ID: 1, Name: "Alice", Phone: "+71234567890", Status: model.CourierStatusAvailable, Version: 2},
+			expectErr: ErrVersionMismatch,
+		},
+		{
+			name:      "only weak etags",
+			patch:     model.CourierPatch{Name: &name},
+			match:     model.VersionMatch{Required: true},
+			current:   model.CourierModel{ID: 1, Name: "Alice", Phone: "+71234567890", Status: model.CourierStatusAvailable, Version: 2},
+			expectErr: ErrVersionMismatch,
+		},
+		{
+			name:    "one of several versions",
+			patch:   model.CourierPatch{Name: &name},
+			match:   model.VersionMatch{Required: true, Versions: []int{1, 2}},
+			current: model.CourierModel{ID: 1, Name: "Alice", Phone: "+71234567890", Status: model.CourierStatusAvailable, Version: 2},
+			expect:  model.CourierModel{ID: 1, Name: "Bob", Phone: "+71234567890", Status: model.CourierStatusAvailable, Version: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := newMockCourierRepository(t)
+			repo.getForUpdateFn = func(ctx context.Context, id int) (*model.CourierModel, error) {
+				current := tt.current
+				return &current, nil
+			}
+			repo.updateFn = func(ctx context.Context, courier *model.CourierModel) error {
+				courier.Version++
+				return nil
+			}
+			uc := NewCourierUsecase(repo, mockTxManager{})
+
+			patch := tt.patch
+			courier, err := uc.Patch(context.Background(), 1, &patch, tt.match)
+			if tt.expectErr != nil {
+				if !errors.Is(err, tt.expectErr) {
+					t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(*courier, tt.expect) {
+				t.Fatalf("expected %+v, got %+v", tt.expect, *courier)
+			}
+		})
+	}
+}
+
 func TestCourierUsecase_GetAll(t *testing.T) {
 	t.Parallel()
 
@@ -341,7 +532,7 @@ func TestCourierUsecase_GetAll(t *testing.T) {
 		return []*model.CourierModel{{ID: 1}, {ID: 2}}, nil
 	}
 
-	uc := NewCourierUsecase(repo)
+	uc := NewCourierUsecase(repo, mockTxManager{})
 
 	result, err := uc.GetAll(context.Background())
 	if err != nil {
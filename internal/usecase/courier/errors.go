@@ -6,4 +6,14 @@ var (
 	ErrInvalidID    = errors.New("invalid id")
 	ErrInvalidPhone = errors.New("invalid phone")
 	ErrInvalidName  = errors.New("invalid name")
+
+	ErrInvalidStatus           = errors.New("invalid status")
+	ErrInvalidStatusTransition = errors.New("invalid courier status transition")
+	ErrActiveDeliveries        = errors.New("courier has active deliveries")
+	ErrVersionMismatch         = errors.New("courier was modified by another request")
+
+	ErrInvalidLocation  = errors.New("invalid location")
+	ErrStaleLocation    = errors.New("location is older than the last known one")
+	ErrLocationOverload = errors.New("too many locations waiting to be saved")
+	ErrInvalidTimeRange = errors.New("invalid time range")
 )
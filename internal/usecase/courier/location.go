@@ -0,0 +1,269 @@
+package courier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+	repo "github.com/cdxy1/go-courier-service/internal/repository/courier"
+)
+
+const (
+	defaultLocationBatchSize = 500
+	defaultTrailRetention    = time.Hour * 24
+	defaultTrailWindow       = time.Hour
+	// maxClockSkew is how far ahead of the server clock a ping may be stamped.
+	maxClockSkew = time.Minute
+	// maxPendingBatches bounds the buffer while the database is unavailable.
+	maxPendingBatches = 10
+)
+
+// LocationUsecase accepts position pings from the courier app. Accepted pings
+// are buffered in memory and written in batches by FlushLocations, so once the
+// last timestamp of a courier is cached a ping costs no database round trip.
+type LocationUsecase struct {
+	repo      locationRepository
+	now       func() time.Time
+	batchSize int
+	retention time.Duration
+
+	mu       sync.Mutex
+	lastSeen map[int]time.Time
+	pending  []*model.LocationPing
+	ready    chan struct{}
+
+	flushMu sync.Mutex
+}
+
+// LocationOption customizes optional behaviour of LocationUsecase.
+type LocationOption func(*LocationUsecase)
+
+// WithLocationBatchSize sets how many pings are written to the database at once.
+func WithLocationBatchSize(size int) LocationOption {
+	return func(uc *LocationUsecase) {
+		if size > 0 {
+			uc.batchSize = size
+		}
+	}
+}
+
+// WithTrailRetention sets how long pings are kept in the trail.
+func WithTrailRetention(retention time.Duration) LocationOption {
+	return func(uc *LocationUsecase) {
+		if retention > 0 {
+			uc.retention = retention
+		}
+	}
+}
+
+func NewLocationUsecase(repo locationRepository, now func() time.Time, opts ...LocationOption) *LocationUsecase {
+	uc := &LocationUsecase{
+		repo:      repo,
+		now:       now,
+		batchSize: defaultLocationBatchSize,
+		retention: defaultTrailRetention,
+		lastSeen:  make(map[int]time.Time),
+		ready:     make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// ReportLocation validates the ping and buffers it. Pings not newer than the
+// last accepted one of the courier are rejected with ErrStaleLocation, a ping
+// without a timestamp is stamped with the current time.
+func (uc *LocationUsecase) ReportLocation(ctx context.Context, ping *model.LocationPing) error {
+	if ping.CourierID <= 0 {
+		return ErrInvalidID
+	}
+	now := uc.now()
+	if ping.RecordedAt.IsZero() {
+		ping.RecordedAt = now
+	}
+	// Postgres keeps microseconds, truncate so cached and stored timestamps compare equal.
+	ping.RecordedAt = ping.RecordedAt.UTC().Truncate(time.Microsecond)
+	if !ping.Point.Valid() || !(ping.Accuracy >= 0) || ping.RecordedAt.After(now.Add(maxClockSkew)) {
+		return ErrInvalidLocation
+	}
+
+	if err := uc.loadLastSeen(ctx, ping.CourierID); err != nil {
+		return err
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if !ping.RecordedAt.After(uc.lastSeen[ping.CourierID]) {
+		return ErrStaleLocation
+	}
+	if len(uc.pending) >= uc.batchSize*maxPendingBatches {
+		return ErrLocationOverload
+	}
+	uc.pending = append(uc.pending, ping)
+	uc.lastSeen[ping.CourierID] = ping.RecordedAt
+	if len(uc.pending) >= uc.batchSize {
+		select {
+		case uc.ready <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// loadLastSeen caches the timestamp of the stored position of the courier. It
+// also makes sure the courier exists before its first ping is accepted.
+func (uc *LocationUsecase) loadLastSeen(ctx context.Context, courierId int) error {
+	uc.mu.Lock()
+	_, ok := uc.lastSeen[courierId]
+	uc.mu.Unlock()
+	if ok {
+		return nil
+	}
+
+	var last time.Time
+	stored, err := uc.repo.GetLocation(ctx, courierId)
+	switch {
+	case err == nil:
+		last = stored.RecordedAt
+	case errors.Is(err, repo.ErrLocationNotFound):
+	case errors.Is(err, repo.ErrCourierNotFound):
+		return repo.ErrCourierNotFound
+	default:
+		return fmt.Errorf("get courier location: %w", err)
+	}
+
+	uc.mu.Lock()
+	if cached, ok := uc.lastSeen[courierId]; !ok || cached.Before(last) {
+		uc.lastSeen[courierId] = last
+	}
+	uc.mu.Unlock()
+	return nil
+}
+
+// BatchReady signals that a full batch of pings is waiting to be flushed.
+// Signals are coalesced.
+func (uc *LocationUsecase) BatchReady() <-chan struct{} {
+	return uc.ready
+}
+
+// Buffered returns the number of pings waiting to be flushed.
+func (uc *LocationUsecase) Buffered() int {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	return len(uc.pending)
+}
+
+// FlushLocations writes the buffered pings in batches and returns how many
+// were written. Pings of a failed batch are put back and retried on the next flush.
+func (uc *LocationUsecase) FlushLocations(ctx context.Context) (int, error) {
+	uc.flushMu.Lock()
+	defer uc.flushMu.Unlock()
+
+	uc.mu.Lock()
+	pings := uc.pending
+	uc.pending = nil
+	uc.mu.Unlock()
+
+	written := 0
+	for len(pings) > 0 {
+		n := min(uc.batchSize, len(pings))
+		if err := uc.repo.SaveLocations(ctx, pings[:n]); err != nil {
+			uc.mu.Lock()
+			uc.pending = append(pings, uc.pending...)
+			uc.mu.Unlock()
+			return written, fmt.Errorf("save locations: %w", err)
+		}
+		written += n
+		pings = pings[n:]
+	}
+	return written, nil
+}
+
+// GetLocation returns the last known position of the courier, including pings
+// not flushed yet.
+func (uc *LocationUsecase) GetLocation(ctx context.Context, courierId int) (*model.LocationPing, error) {
+	if courierId <= 0 {
+		return nil, ErrInvalidID
+	}
+
+	uc.mu.Lock()
+	for i := len(uc.pending) - 1; i >= 0; i-- {
+		if uc.pending[i].CourierID == courierId {
+			ping := *uc.pending[i]
+			uc.mu.Unlock()
+			return &ping, nil
+		}
+	}
+	uc.mu.Unlock()
+
+	ping, err := uc.repo.GetLocation(ctx, courierId)
+	if err != nil {
+		if errors.Is(err, repo.ErrCourierNotFound) {
+			return nil, repo.ErrCourierNotFound
+		}
+		if errors.Is(err, repo.ErrLocationNotFound) {
+			return nil, repo.ErrLocationNotFound
+		}
+		return nil, fmt.Errorf("get courier location: %w", err)
+	}
+	return ping, nil
+}
+
+// Trail returns the pings of the courier recorded in [from, to), oldest first.
+// A zero to means now and a zero from means an hour before to.
+func (uc *LocationUsecase) Trail(ctx context.Context, courierId int, from, to time.Time) ([]*model.LocationPing, error) {
+	if courierId <= 0 {
+		return nil, ErrInvalidID
+	}
+	if to.IsZero() {
+		to = uc.now()
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultTrailWindow)
+	}
+	if !from.Before(to) {
+		return nil, ErrInvalidTimeRange
+	}
+	from, to = from.UTC(), to.UTC()
+
+	if _, err := uc.repo.GetLocation(ctx, courierId); err != nil && !errors.Is(err, repo.ErrLocationNotFound) {
+		if errors.Is(err, repo.ErrCourierNotFound) {
+			return nil, repo.ErrCourierNotFound
+		}
+		return nil, fmt.Errorf("get courier location: %w", err)
+	}
+
+	trail, err := uc.repo.GetTrail(ctx, courierId, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("get courier trail: %w", err)
+	}
+
+	stored := make(map[int64]bool, len(trail))
+	for _, p := range trail {
+		stored[p.RecordedAt.UnixMicro()] = true
+	}
+	uc.mu.Lock()
+	for _, p := range uc.pending {
+		if p.CourierID == courierId && !p.RecordedAt.Before(from) && p.RecordedAt.Before(to) && !stored[p.RecordedAt.UnixMicro()] {
+			ping := *p
+			trail = append(trail, &ping)
+		}
+	}
+	uc.mu.Unlock()
+
+	sort.SliceStable(trail, func(i, j int) bool { return trail[i].RecordedAt.Before(trail[j].RecordedAt) })
+	return trail, nil
+}
+
+// TrimTrail drops the pings older than the retention period.
+func (uc *LocationUsecase) TrimTrail(ctx context.Context) error {
+	if err := uc.repo.TrimTrail(ctx, uc.now().Add(-uc.retention)); err != nil {
+		return fmt.Errorf("trim courier trail: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,370 @@
+package courier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/geo"
+	"github.com/cdxy1/go-courier-service/internal/model"
+	repoerrors "github.com/cdxy1/go-courier-service/internal/repository/courier"
+)
+
+type mockLocationRepository struct {
+	t             *testing.T
+	saveFn        func(ctx context.Context, pings []*model.LocationPing) error
+	getLocationFn func(ctx context.Context, courierId int) (*model.LocationPing, error)
+	getTrailFn    func(ctx context.Context, courierId int, from, to time.Time) ([]*model.LocationPing, error)
+	trimFn        func(ctx context.Context, before time.Time) error
+}
+
+func newMockLocationRepository(t *testing.T) *mockLocationRepository {
+	return &mockLocationRepository{t: t}
+}
+
+func (m *mockLocationRepository) SaveLocations(ctx context.Context, pings []*model.LocationPing) error {
+	if m.saveFn == nil {
+		m.t.Fatalf("SaveLocations called unexpectedly")
+	}
+	return m.saveFn(ctx, pings)
+}
+
+func (m *mockLocationRepository) GetLocation(ctx context.Context, courierId int) (*model.LocationPing, error) {
+	if m.getLocationFn == nil {
+		m.t.Fatalf("GetLocation called unexpectedly")
+	}
+	return m.getLocationFn(ctx, courierId)
+}
+
+func (m *mockLocationRepository) GetTrail(ctx context.Context, courierId int, from, to time.Time) ([]*model.LocationPing, error) {
+	if m.getTrailFn == nil {
+		m.t.Fatalf("GetTrail called unexpectedly")
+	}
+	return m.getTrailFn(ctx, courierId, from, to)
+}
+
+func (m *mockLocationRepository) TrimTrail(ctx context.Context, before time.Time) error {
+	if m.trimFn == nil {
+		m.t.Fatalf("TrimTrail called unexpectedly")
+	}
+	return m.trimFn(ctx, before)
+}
+
+func TestLocationUsecase_ReportLocation(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC)
+	stored := &model.LocationPing{CourierID: 1, Point: geo.Point{Lat: 55.75, Lon: 37.61}, RecordedAt: now.Add(-time.Minute)}
+
+	tests := []struct {
+		name      string
+		ping      model.LocationPing
+		repoSetup func(*mockLocationRepository)
+		expectErr error
+	}{
+		{
+			name:      "invalid id",
+			ping:      model.LocationPing{Point: geo.Point{Lat: 55.75, Lon: 37.61}},
+			repoSetup: func(_ *mockLocationRepository) {},
+			expectErr: ErrInvalidID,
+		},
+		{
+			name:      "invalid point",
+			ping:      model.LocationPing{CourierID: 1, Point: geo.Point{Lat: 95, Lon: 37.61}},
+			repoSetup: func(_ *mockLocationRepository) {},
+			expectErr: ErrInvalidLocation,
+		},
+		{
+			name:      "negative accuracy",
+			ping:      model.LocationPing{CourierID: 1, Point: geo.Point{Lat: 55.75, Lon: 37.61}, Accuracy: -1},
+			repoSetup: func(_ *mockLocationRepository) {},
+			expectErr: ErrInvalidLocation,
+		},
+		{
+			name:      "timestamp in the future",
+			ping:      model.LocationPing{CourierID: 1, Point: geo.Point{Lat: 55.75, Lon: 37.61}, RecordedAt: now.Add(time.Hour)},
+			repoSetup: func(_ *mockLocationRepository) {},
+			expectErr: ErrInvalidLocation,
+		},
+		{
+			name: "courier not found",
+			ping: model.LocationPing{CourierID: 1, Point: geo.Point{Lat: 55.75, Lon: 37.61}},
+			repoSetup: func(repo *mockLocationRepository) {
+				repo.getLocationFn = func(ctx context.Context, courierId int) (*model.LocationPing, error) {
+					return nil, repoerrors.ErrCourierNotFound
+				}
+			},
+			expectErr: repoerrors.ErrCourierNotFound,
+		},
+		{
+			name: "older than stored",
+			ping: model.LocationPing{CourierID: 1, Point: geo.Point{Lat: 55.75, Lon: 37.61}, RecordedAt: now.Add(-time.Hour)},
+			repoSetup: func(repo *mockLocationRepository) {
+				repo.getLocationFn = func(ctx context.Context, courierId int) (*model.LocationPing, error) {
+					return stored, nil
+				}
+			},
+			expectErr: ErrStaleLocation,
+		},
+		{
+			name: "repository error",
+			ping: model.LocationPing{CourierID: 1, Point: geo.Point{Lat: 55.75, Lon: 37.61}},
+			repoSetup: func(repo *mockLocationRepository) {
+				repo.getLocationFn = func(ctx context.Context, courierId int) (*model.LocationPing, error) {
+					return nil, errBoom
+				}
+			},
+			expectErr: errBoom,
+		},
+		{
+			name: "first location",
+			ping: model.LocationPing{CourierID: 1, Point: geo.Point{Lat: 55.75, Lon: 37.61}},
+			repoSetup: func(repo *mockLocationRepository) {
+				repo.getLocationFn = func(ctx context.Context, courierId int) (*model.LocationPing, error) {
+					return nil, repoerrors.ErrLocationNotFound
+				}
+			},
+		},
+		{
+			name: "newer than stored",
+			ping: model.LocationPing{CourierID: 1, Point: geo.Point{Lat: 55.75, Lon: 37.61}, RecordedAt: now},
+			repoSetup: func(repo *mockLocationRepository) {
+				repo.getLocationFn = func(ctx context.Context, courierId int) (*model.LocationPing, error) {
+					return stored, nil
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := newMockLocationRepository(t)
+			tt.repoSetup(repo)
+			uc := NewLocationUsecase(repo, func() time.Time { return now })
+
+			ping := tt.ping
+			err := uc.ReportLocation(context.Background(), &ping)
+			if tt.expectErr != nil {
+				if !errors.Is(err, tt.expectErr) {
+					t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+				}
+				if uc.Buffered() != 0 {
+					t.Fatalf("rejected ping must not be buffered")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if uc.Buffered() != 1 {
+				t.Fatalf("expected 1 buffered ping, got %d", uc.Buffered())
+			}
+		})
+	}
+}
+
+func TestLocationUsecase_OutOfOrder(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC)
+	repo := newMockLocationRepository(t)
+	lookups := 0
+	repo.getLocationFn = func(ctx context.Context, courierId int) (*model.LocationPing, error) {
+		lookups++
+		return nil, repoerrors.ErrLocationNotFound
+	}
+	uc := NewLocationUsecase(repo, func() time.Time { return now })
+
+	report := func(at time.Time) error {
+		return uc.ReportLocation(context.Background(), &model.LocationPing{CourierID: 3, Point: geo.Point{Lat: 1, Lon: 1}, RecordedAt: at})
+	}
+	if err := report(now.Add(-time.Second * 10)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := report(now.Add(-time.Second * 20)); !errors.Is(err, ErrStaleLocation) {
+		t.Fatalf("expected stale location, got %v", err)
+	}
+	if err := report(now.Add(-time.Second * 10)); !errors.Is(err, ErrStaleLocation) {
+		t.Fatalf("duplicate timestamp must be rejected, got %v", err)
+	}
+	if err := report(now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lookups != 1 {
+		t.Fatalf("expected the stored location to be read once, got %d", lookups)
+	}
+	if uc.Buffered() != 2 {
+		t.Fatalf("expected 2 buffered pings, got %d", uc.Buffered())
+	}
+}
+
+func TestLocationUsecase_FlushLocations(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC)
+	repo := newMockLocationRepository(t)
+	repo.getLocationFn = func(ctx context.Context, courierId int) (*model.LocationPing, error) {
+		return nil, repoerrors.ErrLocationNotFound
+	}
+	uc := NewLocationUsecase(repo, func() time.Time { return now }, WithLocationBatchSize(2))
+
+	for i := 0; i < 3; i++ {
+		ping := &model.LocationPing{CourierID: i + 1, Point: geo.Point{Lat: 1, Lon: 1}, RecordedAt: now}
+		if err := uc.ReportLocation(context.Background(), ping); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	select {
+	case <-uc.BatchReady():
+	default:
+		t.Fatalf("expected a full batch signal")
+	}
+
+	var batches [][]*model.LocationPing
+	repo.saveFn = func(ctx context.Context, pings []*model.LocationPing) error {
+		if len(batches) == 1 {
+			return errBoom
+		}
+		batches = append(batches, pings)
+		return nil
+	}
+	written, err := uc.FlushLocations(context.Background())
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("expected error %v, got %v", errBoom, err)
+	}
+	if written != 2 || uc.Buffered() != 1 {
+		t.Fatalf("expected 2 written and 1 kept, got %d and %d", written, uc.Buffered())
+	}
+
+	repo.saveFn = func(ctx context.Context, pings []*model.LocationPing) error {
+		if len(pings) != 1 || pings[0].CourierID != 3 {
+			t.Fatalf("unexpected retried batch: %+v", pings)
+		}
+		return nil
+	}
+	if written, err = uc.FlushLocations(context.Background()); err != nil || written != 1 {
+		t.Fatalf("unexpected flush result: %d, %v", written, err)
+	}
+	if uc.Buffered() != 0 {
+		t.Fatalf("expected empty buffer, got %d", uc.Buffered())
+	}
+}
+
+func TestLocationUsecase_GetLocation(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC)
+	repo := newMockLocationRepository(t)
+	repo.getLocationFn = func(ctx context.Context, courierId int) (*model.LocationPing, error) {
+		if courierId == 2 {
+			return nil, repoerrors.ErrLocationNotFound
+		}
+		return &model.LocationPing{CourierID: courierId, Point: geo.Point{Lat: 1, Lon: 1}, RecordedAt: now.Add(-time.Minute)}, nil
+	}
+	uc := NewLocationUsecase(repo, func() time.Time { return now })
+
+	if _, err := uc.GetLocation(context.Background(), 0); !errors.Is(err, ErrInvalidID) {
+		t.Fatalf("expected invalid id, got %v", err)
+	}
+	if _, err := uc.GetLocation(context.Background(), 2); !errors.Is(err, repoerrors.ErrLocationNotFound) {
+		t.Fatalf("expected location not found, got %v", err)
+	}
+
+	ping, err := uc.GetLocation(context.Background(), 1)
+	if err != nil || !ping.RecordedAt.Equal(now.Add(-time.Minute)) {
+		t.Fatalf("unexpected stored location: %+v, %v", ping, err)
+	}
+
+	if err := uc.ReportLocation(context.Background(), &model.LocationPing{CourierID: 1, Point: geo.Point{Lat: 2, Lon: 2}, RecordedAt: now}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ping, err = uc.GetLocation(context.Background(), 1)
+	if err != nil || ping.Point != (geo.Point{Lat: 2, Lon: 2}) {
+		t.Fatalf("expected the buffered location, got %+v, %v", ping, err)
+	}
+}
+
+func TestLocationUsecase_Trail(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC)
+
+	t.Run("invalid range", func(t *testing.T) {
+		t.Parallel()
+		uc := NewLocationUsecase(newMockLocationRepository(t), func() time.Time { return now })
+		if _, err := uc.Trail(context.Background(), 1, now, now.Add(-time.Hour)); !errors.Is(err, ErrInvalidTimeRange) {
+			t.Fatalf("expected invalid time range, got %v", err)
+		}
+	})
+
+	t.Run("courier not found", func(t *testing.T) {
+		t.Parallel()
+		repo := newMockLocationRepository(t)
+		repo.getLocationFn = func(ctx context.Context, courierId int) (*model.LocationPing, error) {
+			return nil, repoerrors.ErrCourierNotFound
+		}
+		uc := NewLocationUsecase(repo, func() time.Time { return now })
+		if _, err := uc.Trail(context.Background(), 1, time.Time{}, time.Time{}); !errors.Is(err, repoerrors.ErrCourierNotFound) {
+			t.Fatalf("expected courier not found, got %v", err)
+		}
+	})
+
+	t.Run("merges buffered pings", func(t *testing.T) {
+		t.Parallel()
+		repo := newMockLocationRepository(t)
+		repo.getLocationFn = func(ctx context.Context, courierId int) (*model.LocationPing, error) {
+			return nil, repoerrors.ErrLocationNotFound
+		}
+		repo.getTrailFn = func(ctx context.Context, courierId int, from, to time.Time) ([]*model.LocationPing, error) {
+			if !from.Equal(now.Add(-time.Hour)) || !to.Equal(now) {
+				t.Fatalf("unexpected range: %s - %s", from, to)
+			}
+			return []*model.LocationPing{{CourierID: courierId, RecordedAt: now.Add(-time.Minute * 30)}}, nil
+		}
+		uc := NewLocationUsecase(repo, func() time.Time { return now })
+		for _, ping := range []*model.LocationPing{
+			{CourierID: 1, RecordedAt: now.Add(-time.Minute * 45)},
+			{CourierID: 2, RecordedAt: now.Add(-time.Minute * 40)},
+			{CourierID: 1, RecordedAt: now.Add(-time.Minute * 10)},
+		} {
+			if err := uc.ReportLocation(context.Background(), ping); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		trail, err := uc.Trail(context.Background(), 1, time.Time{}, time.Time{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := []time.Duration{-time.Minute * 45, -time.Minute * 30, -time.Minute * 10}
+		if len(trail) != len(expected) {
+			t.Fatalf("expected %d points, got %d", len(expected), len(trail))
+		}
+		for i, offset := range expected {
+			if !trail[i].RecordedAt.Equal(now.Add(offset)) {
+				t.Fatalf("unexpected point %d: %s", i, trail[i].RecordedAt)
+			}
+		}
+	})
+}
+
+func TestLocationUsecase_TrimTrail(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC)
+	repo := newMockLocationRepository(t)
+	repo.trimFn = func(ctx context.Context, before time.Time) error {
+		if !before.Equal(now.Add(-time.Hour * 6)) {
+			t.Fatalf("unexpected cutoff: %s", before)
+		}
+		return nil
+	}
+	uc := NewLocationUsecase(repo, func() time.Time { return now }, WithTrailRetention(time.Hour*6))
+	if err := uc.TrimTrail(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
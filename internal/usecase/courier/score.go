@@ -0,0 +1,65 @@
+package courier
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+)
+
+const defaultScoreWindow = time.Hour * 24 * 30
+
+// ScoreUsecase recalculates the performance scores of couriers from their
+// recent deliveries.
+type ScoreUsecase struct {
+	repo   scoreRepository
+	now    func() time.Time
+	window time.Duration
+}
+
+// ScoreOption customizes optional behaviour of ScoreUsecase.
+type ScoreOption func(*ScoreUsecase)
+
+// WithScoreWindow sets how far back deliveries are taken into account.
+func WithScoreWindow(window time.Duration) ScoreOption {
+	return func(uc *ScoreUsecase) {
+		if window > 0 {
+			uc.window = window
+		}
+	}
+}
+
+func NewScoreUsecase(repo scoreRepository, now func() time.Time, opts ...ScoreOption) *ScoreUsecase {
+	uc := &ScoreUsecase{
+		repo:   repo,
+		now:    now,
+		window: defaultScoreWindow,
+	}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// RecalculateScores scores every courier with deliveries assigned within the
+// window and finished since. Couriers without such deliveries lose their score.
+// It returns the number of couriers scored.
+func (uc *ScoreUsecase) RecalculateScores(ctx context.Context) (int, error) {
+	now := uc.now()
+	stats, err := uc.repo.DeliveryStats(ctx, now.Add(-uc.window))
+	if err != nil {
+		return 0, fmt.Errorf("get delivery stats: %w", err)
+	}
+
+	scores := make([]*model.CourierScore, 0, len(stats))
+	for _, s := range stats {
+		if score := model.NewCourierScore(*s, now); score != nil {
+			scores = append(scores, score)
+		}
+	}
+	if err := uc.repo.SaveScores(ctx, scores); err != nil {
+		return 0, fmt.Errorf("save courier scores: %w", err)
+	}
+	return len(scores), nil
+}
@@ -0,0 +1,124 @@
+package courier
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+)
+
+type mockScoreRepository struct {
+	t            *testing.T
+	statsFn      func(ctx context.Context, since time.Time) ([]*model.CourierStats, error)
+	saveScoresFn func(ctx context.Context, scores []*model.CourierScore) error
+}
+
+func newMockScoreRepository(t *testing.T) *mockScoreRepository {
+	return &mockScoreRepository{t: t}
+}
+
+func (m *mockScoreRepository) DeliveryStats(ctx context.Context, since time.Time) ([]*model.CourierStats, error) {
+	if m.statsFn == nil {
+		m.t.Fatalf("DeliveryStats called unexpectedly")
+	}
+	return m.statsFn(ctx, since)
+}
+
+func (m *mockScoreRepository) SaveScores(ctx context.Context, scores []*model.CourierScore) error {
+	if m.saveScoresFn == nil {
+		m.t.Fatalf("SaveScores called unexpectedly")
+	}
+	return m.saveScoresFn(ctx, scores)
+}
+
+func TestScoreUsecase_RecalculateScores(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC)
+	errBoom := errors.New("boom")
+
+	tests := []struct {
+		name      string
+		stats     []*model.CourierStats
+		statsErr  error
+		saveErr   error
+		expected  map[int]model.CourierScore
+		expectErr error
+	}{
+		{
+			name: "components weighted",
+			stats: []*model.CourierStats{
+				// Always on time within half of the deadline.
+				{CourierID: 1, Delivered: 4, OnTime: 4, DeadlineUsage: 0.4},
+				// Half late, one of four taken away, deliveries at 75% of the deadline.
+				{CourierID: 2, Delivered: 2, OnTime: 1, Cancelled: 1, Expired: 1, DeadlineUsage: 0.75},
+				// Nothing but cancellations.
+				{CourierID: 3, Cancelled: 2},
+				{CourierID: 4},
+			},
+			expected: map[int]model.CourierScore{
+				1: {Score: 1, OnTimeRate: 1, DeadlineUsage: 0.4, Deliveries: 4},
+				2: {Score: 0.5*(1.0/3) + 0.3*0.75 + 0.2*0.5, OnTimeRate: 1.0 / 3, CancelRate: 0.25, DeadlineUsage: 0.75, Deliveries: 4},
+				3: {Score: 0, CancelRate: 1, Deliveries: 2},
+			},
+		},
+		{
+			name:      "stats error",
+			statsErr:  errBoom,
+			expectErr: errBoom,
+		},
+		{
+			name:      "save error",
+			stats:     []*model.CourierStats{{CourierID: 1, Delivered: 1, OnTime: 1}},
+			saveErr:   errBoom,
+			expectErr: errBoom,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := newMockScoreRepository(t)
+			repo.statsFn = func(ctx context.Context, since time.Time) ([]*model.CourierStats, error) {
+				if want := now.Add(-time.Hour * 24 * 7); !since.Equal(want) {
+					t.Fatalf("expected stats since %s, got %s", want, since)
+				}
+				return tt.stats, tt.statsErr
+			}
+			var saved []*model.CourierScore
+			repo.saveScoresFn = func(ctx context.Context, scores []*model.CourierScore) error {
+				saved = scores
+				return tt.saveErr
+			}
+
+			uc := NewScoreUsecase(repo, func() time.Time { return now }, WithScoreWindow(time.Hour*24*7))
+			scored, err := uc.RecalculateScores(context.Background())
+			if !errors.Is(err, tt.expectErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+			}
+			if tt.expectErr != nil {
+				return
+			}
+
+			if scored != len(tt.expected) || len(saved) != len(tt.expected) {
+				t.Fatalf("expected %d scores, got %d saved of %d", len(tt.expected), len(saved), scored)
+			}
+			for _, got := range saved {
+				want, ok := tt.expected[got.CourierID]
+				if !ok {
+					t.Fatalf("unexpected score for courier %d", got.CourierID)
+				}
+				if math.Abs(got.Score-want.Score) > 1e-9 || math.Abs(got.OnTimeRate-want.OnTimeRate) > 1e-9 ||
+					got.CancelRate != want.CancelRate || got.DeadlineUsage != want.DeadlineUsage ||
+					got.Deliveries != want.Deliveries || !got.UpdatedAt.Equal(now) {
+					t.Fatalf("courier %d: expected %+v, got %+v", got.CourierID, want, *got)
+				}
+			}
+		})
+	}
+}
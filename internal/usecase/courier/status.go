@@ -0,0 +1,87 @@
+package courier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+	repo "github.com/cdxy1/go-courier-service/internal/repository/courier"
+)
+
+// allowedTransitions lists the status changes clients may ask for. Busy is
+// never requested: a courier turns busy with its first delivery and available
+// again with its last one.
+var allowedTransitions = map[model.CourierStatus][]model.CourierStatus{
+	model.CourierStatusAvailable: {model.CourierStatusPaused},
+	model.CourierStatusBusy:      {model.CourierStatusPaused},
+	model.CourierStatusPaused:    {model.CourierStatusAvailable},
+}
+
+func canTransition(from, to model.CourierStatus) bool {
+	for _, next := range allowedTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// nextStatus returns the status to store when the courier is moved to the
+// requested one. Requesting the current status changes nothing, and a courier
+// resuming work while it still holds deliveries becomes busy. Pausing a courier
+// with active deliveries needs force.
+func nextStatus(courier *model.CourierModel, to model.CourierStatus, force bool) (model.CourierStatus, error) {
+	if !to.IsValid() {
+		return "", fmt.Errorf("%w: %q", ErrInvalidStatus, to)
+	}
+	if courier.Status == to {
+		return to, nil
+	}
+	if !canTransition(courier.Status, to) {
+		return "", fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, courier.Status, to)
+	}
+	if to == model.CourierStatusPaused && courier.ActiveDeliveries > 0 && !force {
+		return "", fmt.Errorf("%w: courier %d holds %d", ErrActiveDeliveries, courier.ID, courier.ActiveDeliveries)
+	}
+	if to == model.CourierStatusAvailable && courier.ActiveDeliveries > 0 {
+		return model.CourierStatusBusy, nil
+	}
+	return to, nil
+}
+
+// ChangeStatus moves the courier to the requested status and returns it. A
+// courier forced into paused keeps its deliveries but gets no new ones.
+func (uc *CourierUsecase) ChangeStatus(ctx context.Context, id int, status model.CourierStatus, force bool) (*model.CourierModel, error) {
+	if id <= 0 {
+		return nil, ErrInvalidID
+	}
+	if !status.IsValid() {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidStatus, status)
+	}
+
+	var courier *model.CourierModel
+	err := uc.tm.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		if courier, err = uc.repo.GetOneByIdForUpdate(ctx, id); err != nil {
+			if errors.Is(err, repo.ErrCourierNotFound) {
+				return repo.ErrCourierNotFound
+			}
+			return fmt.Errorf("get courier: %w", err)
+		}
+		next, err := nextStatus(courier, status, force)
+		if err != nil || next == courier.Status {
+			return err
+		}
+		if err := uc.repo.UpdateStatus(ctx, next, id); err != nil {
+			return fmt.Errorf("update courier status: %w", err)
+		}
+		courier.Status = next
+		courier.Version++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return courier, nil
+}
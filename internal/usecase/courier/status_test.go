@@ -0,0 +1,155 @@
+package courier
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+	repoerrors "github.com/cdxy1/go-courier-service/internal/repository/courier"
+)
+
+func TestCourierUsecase_ChangeStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		id           int
+		current      *model.CourierModel
+		status       model.CourierStatus
+		force        bool
+		getErr       error
+		expectStatus model.CourierStatus
+		expectUpdate bool
+		expectErr    error
+	}{
+		{
+			name:      "invalid id",
+			status:    model.CourierStatusPaused,
+			expectErr: ErrInvalidID,
+		},
+		{
+			name:      "unknown status",
+			id:        1,
+			status:    "active",
+			expectErr: ErrInvalidStatus,
+		},
+		{
+			name:      "courier not found",
+			id:        1,
+			status:    model.CourierStatusPaused,
+			getErr:    repoerrors.ErrCourierNotFound,
+			expectErr: repoerrors.ErrCourierNotFound,
+		},
+		{
+			name:      "repository error",
+			id:        1,
+			status:    model.CourierStatusPaused,
+			getErr:    errBoom,
+			expectErr: errBoom,
+		},
+		{
+			name:         "pause available courier",
+			id:           1,
+			current:      &model.CourierModel{ID: 1, Status: model.CourierStatusAvailable},
+			status:       model.CourierStatusPaused,
+			expectStatus: model.CourierStatusPaused,
+			expectUpdate: true,
+		},
+		{
+			name:         "resume paused courier",
+			id:           1,
+			current:      &model.CourierModel{ID: 1, Status: model.CourierStatusPaused},
+			status:       model.CourierStatusAvailable,
+			expectStatus: model.CourierStatusAvailable,
+			expectUpdate: true,
+		},
+		{
+			name:         "resume with deliveries becomes busy",
+			id:           1,
+			current:      &model.CourierModel{ID: 1, Status: model.CourierStatusPaused, ActiveDeliveries: 1},
+			status:       model.CourierStatusAvailable,
+			expectStatus: model.CourierStatusBusy,
+			expectUpdate: true,
+		},
+		{
+			name:      "pause with deliveries",
+			id:        1,
+			current:   &model.CourierModel{ID: 1, Status: model.CourierStatusBusy, ActiveDeliveries: 1},
+			status:    model.CourierStatusPaused,
+			expectErr: ErrActiveDeliveries,
+		},
+		{
+			name:         "forced pause with deliveries",
+			id:           1,
+			current:      &model.CourierModel{ID: 1, Status: model.CourierStatusBusy, ActiveDeliveries: 1},
+			status:       model.CourierStatusPaused,
+			force:        true,
+			expectStatus: model.CourierStatusPaused,
+			expectUpdate: true,
+		},
+		{
+			name:      "busy is set by the service",
+			id:        1,
+			current:   &model.CourierModel{ID: 1, Status: model.CourierStatusAvailable},
+			status:    model.CourierStatusBusy,
+			expectErr: ErrInvalidStatusTransition,
+		},
+		{
+			name:      "busy courier cannot be made available",
+			id:        1,
+			current:   &model.CourierModel{ID: 1, Status: model.CourierStatusBusy, ActiveDeliveries: 1},
+			status:    model.CourierStatusAvailable,
+			expectErr: ErrInvalidStatusTransition,
+		},
+		{
+			name:         "same status",
+			id:           1,
+			current:      &model.CourierModel{ID: 1, Status: model.CourierStatusPaused},
+			status:       model.CourierStatusPaused,
+			expectStatus: model.CourierStatusPaused,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := newMockCourierRepository(t)
+			repo.getForUpdateFn = func(ctx context.Context, id int) (*model.CourierModel, error) {
+				if tt.getErr != nil {
+					return nil, tt.getErr
+				}
+				current := *tt.current
+				return &current, nil
+			}
+			updated := false
+			repo.updateStatusFn = func(ctx context.Context, status model.CourierStatus, id int) error {
+				if status != tt.expectStatus || id != tt.id {
+					t.Fatalf("unexpected update of courier %d to %s", id, status)
+				}
+				updated = true
+				return nil
+			}
+			uc := NewCourierUsecase(repo, mockTxManager{})
+
+			courier, err := uc.ChangeStatus(context.Background(), tt.id, tt.status, tt.force)
+			if tt.expectErr != nil {
+				if !errors.Is(err, tt.expectErr) {
+					t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if courier.Status != tt.expectStatus {
+				t.Fatalf("expected status %s, got %s", tt.expectStatus, courier.Status)
+			}
+			if updated != tt.expectUpdate {
+				t.Fatalf("expected update %v, got %v", tt.expectUpdate, updated)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/geo"
+	"github.com/cdxy1/go-courier-service/internal/matching"
+	"github.com/cdxy1/go-courier-service/internal/model"
+	courierRepo "github.com/cdxy1/go-courier-service/internal/repository/courier"
+)
+
+const (
+	defaultLoadPenalty = 5 * time.Minute
+	// zonePenalty is added for a courier from a neighbouring zone, twice for
+	// one without a zone or from farther away, when the zone fallback allows
+	// them at all.
+	zonePenalty = 15 * time.Minute
+)
+
+// batchOrder is an order of a batch still waiting for a courier.
+type batchOrder struct {
+	index      int
+	req        model.AssignCourierRequest
+	transports []model.TransportType
+	zone       *model.Zone
+	neighbours map[int]bool
+	delivery   model.DeliveryContext
+}
+
+// batchSlot is one more delivery an available courier may take.
+type batchSlot struct {
+	candidate *model.CourierCandidate
+	// load is the number of deliveries given to the courier earlier in the batch.
+	load int
+}
+
+// AssignBatch assigns couriers to orders received together from the order
+// service, see AssignCourierBatch.
+func (uc *DeliveryUsecase) AssignBatch(ctx context.Context, orders []*model.Order) ([]*model.AssignmentResult, error) {
+	reqs := make([]model.AssignCourierRequest, len(orders))
+	for i, order := range orders {
+		reqs[i] = orderRequest(order)
+	}
+	return uc.AssignCourierBatch(ctx, reqs)
+}
+
+// AssignCourierBatch assigns couriers to several orders at once. Instead of
+// handing every order the best courier left, as AssignCourier does one order at
+// a time, it matches all orders with the available couriers at the lowest total
+// cost (see WithBatchCost) and creates the deliveries in one transaction. Orders
+// already assigned keep their delivery. Orders left without a courier, or all
+// orders when the batch fails, go through AssignCourier one by one and are
+// queued when nobody is free; the error reports why the batch failed. The
+// selector is not used, zone preference and the customer promise are part of
+// the cost. Requests naming a courier are not supported and are passed to
+// AssignCourier.
+func (uc *DeliveryUsecase) AssignCourierBatch(ctx context.Context, reqs []model.AssignCourierRequest) ([]*model.AssignmentResult, error) {
+	results := make([]*model.AssignmentResult, len(reqs))
+	err := uc.tm.WithTx(ctx, func(ctx context.Context) error {
+		return uc.assignBatch(ctx, reqs, results)
+	})
+	if err != nil {
+		clear(results)
+		err = fmt.Errorf("assign batch: %w", err)
+	}
+
+	for i, req := range reqs {
+		if results[i] != nil {
+			continue
+		}
+		d, courier, assignErr := uc.AssignCourier(ctx, req)
+		results[i] = &model.AssignmentResult{OrderID: req.OrderID, Delivery: d, Courier: courier, Err: assignErr}
+	}
+	return results, err
+}
+
+// assignBatch fills in the results of the orders it settles and leaves the
+// others nil.
+func (uc *DeliveryUsecase) assignBatch(ctx context.Context, reqs []model.AssignCourierRequest, results []*model.AssignmentResult) error {
+	var open []batchOrder
+	for i, req := range reqs {
+		if req.CourierID != 0 {
+			continue
+		}
+		existing, err := uc.findAssignment(ctx, req)
+		if err != nil {
+			results[i] = &model.AssignmentResult{OrderID: req.OrderID, Err: err}
+			continue
+		}
+		if existing != nil {
+			courier, err := uc.courierRepo.GetOneById(ctx, existing.CourierId)
+			if err != nil {
+				return fmt.Errorf("get courier: %w", err)
+			}
+			results[i] = &model.AssignmentResult{OrderID: req.OrderID, Delivery: existing, Courier: courier}
+			continue
+		}
+
+		transports, err := uc.eligibleTransports(req)
+		if err != nil {
+			results[i] = &model.AssignmentResult{OrderID: req.OrderID, Err: err}
+			continue
+		}
+		open = append(open, batchOrder{
+			index:      i,
+			req:        req,
+			transports: transports,
+			delivery:   model.DeliveryContext{Pickup: req.Pickup, Dropoff: req.Dropoff, Order: req.Order},
+		})
+	}
+	if len(open) == 0 {
+		return nil
+	}
+
+	if uc.zones != nil {
+		zones, err := uc.zones.GetAll(ctx)
+		if err != nil {
+			return fmt.Errorf("list zones: %w", err)
+		}
+		for i := range open {
+			point := orderPoint(open[i].req)
+			if point == nil {
+				continue
+			}
+			if zone := model.ZoneOf(zones, *point); zone != nil {
+				open[i].zone = zone
+				open[i].neighbours = model.NeighboursOf(zones, zone.ID)
+				open[i].delivery.ZoneID = &zone.ID
+			}
+		}
+	}
+
+	candidates, err := uc.courierRepo.ListAvailable(ctx, uc.availableFilter())
+	if err != nil {
+		return fmt.Errorf("get available courier: %w", err)
+	}
+
+	ids := make([]int, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.Courier.ID
+	}
+	limits, err := uc.shiftLimits(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	plan := uc.planBatch(open, candidates, limits)
+
+	locked := make(map[int]*model.CourierModel)
+	given := make(map[int]int)
+	for i, o := range open {
+		if plan[i] == nil {
+			continue
+		}
+		id := plan[i].Courier.ID
+		courier, ok := locked[id]
+		if !ok {
+			courier, err = uc.courierRepo.GetOneByIdSkipLocked(ctx, id)
+			if err != nil && !errors.Is(err, courierRepo.ErrCourierNotFound) {
+				return fmt.Errorf("get available courier: %w", err)
+			}
+			locked[id] = courier
+		}
+		// A courier taken by a concurrent assignment leaves the order to
+		// AssignCourier.
+		if courier == nil || !uc.capacity.CanTake(courier) ||
+			given[id] >= uc.capacity.For(courier.TransportType)-courier.ActiveDeliveries {
+			continue
+		}
+
+		d, err := uc.createDelivery(ctx, o.req, courier, "")
+		if err != nil {
+			return err
+		}
+		given[id]++
+		results[o.index] = &model.AssignmentResult{OrderID: o.req.OrderID, Delivery: d, Courier: courier}
+	}
+	return nil
+}
+
+// planBatch returns the courier chosen for every order, nil for orders left
+// without one. A courier appears once for every delivery it may still take and
+// never gets an order it cannot finish within its shift.
+func (uc *DeliveryUsecase) planBatch(
+	orders []batchOrder,
+	candidates []*model.CourierCandidate,
+	limits shiftLimits,
+) []*model.CourierCandidate {
+	var slots []batchSlot
+	for _, c := range candidates {
+		free := uc.capacity.For(c.Courier.TransportType) - c.Courier.ActiveDeliveries
+		for load := 0; load < free && load < len(orders); load++ {
+			slots = append(slots, batchSlot{candidate: c, load: load})
+		}
+	}
+
+	now := uc.now()
+	cost := make([][]float64, len(orders))
+	for i, o := range orders {
+		late := uc.lateness(o, now)
+		deadlines := uc.orderDeadlines(o.delivery, o.req.PromisedBy, now)
+		row := make([]float64, len(slots))
+		for j, slot := range slots {
+			courier := slot.candidate.Courier
+			if len(limits) > 0 && !limits.allows(courier.ID, deadlines.For(courier.TransportType)) {
+				row[j] = matching.Forbidden
+				continue
+			}
+			row[j] = uc.batchCost(o, slot, late)
+		}
+		cost[i] = row
+	}
+
+	plan := make([]*model.CourierCandidate, len(orders))
+	for i, j := range matching.Solve(cost) {
+		if j >= 0 {
+			plan[i] = slots[j].candidate
+		}
+	}
+	return plan
+}
+
+// lateness returns by how much each transport would miss the customer promise
+// of the order.
+func (uc *DeliveryUsecase) lateness(o batchOrder, now time.Time) map[model.TransportType]time.Duration {
+	if o.req.PromisedBy == nil {
+		return nil
+	}
+	late := make(map[model.TransportType]time.Duration)
+	for _, transport := range []model.TransportType{model.TransportOnFoot, model.TransportScooter, model.TransportCar} {
+		delivery := o.delivery
+		delivery.Transport = transport
+		if d := uc.timeFactory.For(delivery).Deadline(now).Sub(*o.req.PromisedBy); d > 0 {
+			late[transport] = d
+		}
+	}
+	return late
+}
+
+// batchCost returns the cost of giving the order to the courier slot in
+// seconds, or matching.Forbidden when the courier must not take it.
+func (uc *DeliveryUsecase) batchCost(o batchOrder, slot batchSlot, late map[model.TransportType]time.Duration) float64 {
+	c := slot.candidate
+	courier := c.Courier
+	if o.transports != nil && !slices.Contains(o.transports, courier.TransportType) {
+		return matching.Forbidden
+	}
+
+	var cost time.Duration
+	if o.zone != nil {
+		dist, ok := uc.zoneDistance(courier, o.zone, o.neighbours)
+		if !ok {
+			return matching.Forbidden
+		}
+		cost += time.Duration(min(dist, zoneNone)) * zonePenalty
+	}
+	if o.req.Pickup != nil && courier.Location != nil {
+		cost += uc.speeds.TravelTime(courier.TransportType, geo.Distance(*courier.Location, *o.req.Pickup))
+	}
+	cost += late[courier.TransportType]
+	load := c.RecentLoad + float64(courier.ActiveDeliveries+slot.load)
+	cost += time.Duration(load * float64(uc.loadPenalty))
+	return cost.Seconds()
+}
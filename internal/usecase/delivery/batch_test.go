@@ -0,0 +1,192 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/geo"
+	"github.com/cdxy1/go-courier-service/internal/model"
+	courierrepo "github.com/cdxy1/go-courier-service/internal/repository/courier"
+	repoerrors "github.com/cdxy1/go-courier-service/internal/repository/delivery"
+)
+
+func TestDeliveryUsecase_AssignBatch(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.December, 22, 10, 30, 0, 0, time.UTC)
+	// 0.009 degrees of latitude are about 1 km.
+	at := func(km float64) *geo.Point { return &geo.Point{Lat: km * 0.009, Lon: 0} }
+	courier := func(id int, km float64) *model.CourierModel {
+		return &model.CourierModel{ID: id, Status: model.CourierStatusAvailable, TransportType: model.TransportCar, Location: at(km)}
+	}
+
+	tests := []struct {
+		name     string
+		reqs     []model.AssignCourierRequest
+		couriers []*model.CourierModel
+		existing map[string]*model.DeliveryModel
+		// listAvail answers the n-th ListAvailable call, starting at 0.
+		listAvail func(n int, couriers []*model.CourierModel) ([]*model.CourierCandidate, error)
+		// expect maps orders to their courier, 0 for a queued order.
+		expect  map[string]int
+		wantErr bool
+	}{
+		{
+			name:     "lowest total travel time",
+			reqs:     []model.AssignCourierRequest{{OrderID: "a", Pickup: at(0)}, {OrderID: "b", Pickup: at(10)}},
+			couriers: []*model.CourierModel{courier(1, 9), courier(2, -10)},
+			// Nearest-first dispatch would give a courier 1 and send courier 2
+			// 20 km to b.
+			expect: map[string]int{"a": 2, "b": 1},
+		},
+		{
+			name:     "load spread without locations",
+			reqs:     []model.AssignCourierRequest{{OrderID: "a"}, {OrderID: "b"}},
+			couriers: []*model.CourierModel{courier(1, 0), courier(2, 0)},
+			expect:   map[string]int{"a": 1, "b": 2},
+		},
+		{
+			name:     "assigned order kept",
+			reqs:     []model.AssignCourierRequest{{OrderID: "a"}, {OrderID: "b"}},
+			couriers: []*model.CourierModel{courier(1, 0), courier(2, 0)},
+			existing: map[string]*model.DeliveryModel{"a": {OrderId: "a", CourierId: 2, Status: model.DeliveryStatusAssigned}},
+			expect:   map[string]int{"a": 2, "b": 1},
+		},
+		{
+			name:     "order left over is queued",
+			reqs:     []model.AssignCourierRequest{{OrderID: "a", Pickup: at(0)}, {OrderID: "b", Pickup: at(5)}},
+			couriers: []*model.CourierModel{courier(1, 4)},
+			listAvail: func(n int, couriers []*model.CourierModel) ([]*model.CourierCandidate, error) {
+				if n > 0 {
+					return nil, nil
+				}
+				return []*model.CourierCandidate{{Courier: couriers[0]}}, nil
+			},
+			expect: map[string]int{"a": 0, "b": 1},
+		},
+		{
+			name:     "failed batch assigned one by one",
+			reqs:     []model.AssignCourierRequest{{OrderID: "a"}},
+			couriers: []*model.CourierModel{courier(1, 0)},
+			listAvail: func(n int, couriers []*model.CourierModel) ([]*model.CourierCandidate, error) {
+				if n == 0 {
+					return nil, errBoom
+				}
+				return []*model.CourierCandidate{{Courier: couriers[0]}}, nil
+			},
+			expect:  map[string]int{"a": 1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			byID := make(map[int]*model.CourierModel)
+			for _, c := range tt.couriers {
+				byID[c.ID] = c
+			}
+
+			cRepo := newMockCourierRepository(t)
+			dRepo := newMockDeliveryRepository(t)
+			calls := 0
+			cRepo.listAvailFn = func(ctx context.Context, filter model.AvailableCourierFilter) ([]*model.CourierCandidate, error) {
+				defer func() { calls++ }()
+				if tt.listAvail != nil {
+					return tt.listAvail(calls, tt.couriers)
+				}
+				candidates := make([]*model.CourierCandidate, 0, len(tt.couriers))
+				for _, c := range tt.couriers {
+					candidates = append(candidates, &model.CourierCandidate{Courier: c})
+				}
+				return candidates, nil
+			}
+			cRepo.skipLockedFn = func(ctx context.Context, id int) (*model.CourierModel, error) {
+				if c, ok := byID[id]; ok {
+					return c, nil
+				}
+				return nil, courierrepo.ErrCourierNotFound
+			}
+			cRepo.getOneByIDFn = func(ctx context.Context, id int) (*model.CourierModel, error) { return byID[id], nil }
+			cRepo.markAssignedFn = func(ctx context.Context, id int) error { return nil }
+			dRepo.getByOrderIDFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+				if d, ok := tt.existing[orderId]; ok {
+					return d, nil
+				}
+				return nil, repoerrors.ErrDeliveryNotFound
+			}
+			dRepo.createFn = func(ctx context.Context, delivery *model.DeliveryModel) error { return nil }
+			dRepo.enqueueFn = func(ctx context.Context, pending *model.PendingAssignment) error { return nil }
+
+			factory := model.NewDeliveryTimeFactory(time.Minute*30, time.Minute*15, time.Minute*5)
+			uc := NewDeliveryUsecase(cRepo, dRepo, newMockTxManager(t), factory, func() time.Time { return now })
+
+			results, err := uc.AssignCourierBatch(context.Background(), tt.reqs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(results) != len(tt.reqs) {
+				t.Fatalf("expected %d results, got %d", len(tt.reqs), len(results))
+			}
+			for _, r := range results {
+				want := tt.expect[r.OrderID]
+				if want == 0 {
+					if !errors.Is(r.Err, ErrAssignmentQueued) {
+						t.Fatalf("expected order %s queued, got %v", r.OrderID, r.Err)
+					}
+					continue
+				}
+				if r.Err != nil {
+					t.Fatalf("order %s: unexpected error: %v", r.OrderID, r.Err)
+				}
+				if r.Courier.ID != want {
+					t.Fatalf("expected courier %d for order %s, got %d", want, r.OrderID, r.Courier.ID)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkDeliveryUsecase_PlanBatch(b *testing.B) {
+	now := time.Date(2025, time.December, 22, 10, 30, 0, 0, time.UTC)
+	factory := model.NewDeliveryTimeFactory(time.Minute*30, time.Minute*15, time.Minute*5)
+	uc := NewDeliveryUsecase(nil, nil, nil, factory, func() time.Time { return now })
+	rnd := rand.New(rand.NewPCG(1, 2))
+	point := func() *geo.Point {
+		return &geo.Point{Lat: 55.6 + rnd.Float64()*0.3, Lon: 37.4 + rnd.Float64()*0.4}
+	}
+	transports := []model.TransportType{model.TransportOnFoot, model.TransportScooter, model.TransportCar}
+
+	orders := make([]batchOrder, 1000)
+	for i := range orders {
+		req := model.AssignCourierRequest{OrderID: strconv.Itoa(i), Pickup: point(), Dropoff: point()}
+		if i%2 == 0 {
+			promisedBy := now.Add(20 * time.Minute)
+			req.PromisedBy = &promisedBy
+		}
+		orders[i] = batchOrder{index: i, req: req, delivery: model.DeliveryContext{Pickup: req.Pickup, Dropoff: req.Dropoff}}
+	}
+	candidates := make([]*model.CourierCandidate, 1000)
+	for i := range candidates {
+		candidates[i] = &model.CourierCandidate{
+			Courier: &model.CourierModel{
+				ID:            i + 1,
+				Status:        model.CourierStatusAvailable,
+				TransportType: transports[i%len(transports)],
+				Location:      point(),
+			},
+			RecentLoad: float64(rnd.IntN(10)),
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		uc.planBatch(orders, candidates, nil)
+	}
+}
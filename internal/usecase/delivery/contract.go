@@ -2,6 +2,7 @@ package delivery
 
 import (
 	"context"
+	"time"
 
 	"github.com/cdxy1/go-courier-service/internal/model"
 )
@@ -10,18 +11,39 @@ type courierRepository interface {
 	Create(ctx context.Context, courier *model.CourierModel) (int, error)
 	Update(ctx context.Context, courier *model.CourierModel) error
 	GetOneById(ctx context.Context, id int) (*model.CourierModel, error)
+	GetOneByIdForUpdate(ctx context.Context, id int) (*model.CourierModel, error)
 	GetAll(ctx context.Context) ([]*model.CourierModel, error)
 	GetByStatus(ctx context.Context, status model.CourierStatus) (*model.CourierModel, error)
 	UpdateStatus(ctx context.Context, status model.CourierStatus, id int) error
 	MarkAssigned(ctx context.Context, id int) error
-	GetAvailableLeastDelivered(ctx context.Context) (*model.CourierModel, error)
+	ListAvailable(ctx context.Context, filter model.AvailableCourierFilter) ([]*model.CourierCandidate, error)
+	GetOneByIdSkipLocked(ctx context.Context, id int) (*model.CourierModel, error)
+	MarkReleased(ctx context.Context, id int) error
 }
 
 type deliveryRepository interface {
 	Create(ctx context.Context, delivery *model.DeliveryModel) error
-	Delete(ctx context.Context, orderId string) (int, error)
-	GetCourierID(ctx context.Context, orderId string) (int, error)
-	ReleaseExpiredCouriers(ctx context.Context) (int, error)
+	GetByOrderID(ctx context.Context, orderId string) (*model.DeliveryModel, error)
+	GetByIdempotencyKey(ctx context.Context, key string) (*model.DeliveryModel, error)
+	List(ctx context.Context, filter model.DeliveryFilter) ([]*model.DeliveryModel, int, error)
+	GetByOrderIDForUpdate(ctx context.Context, orderId string) (*model.DeliveryModel, error)
+	UpdateStatus(ctx context.Context, delivery *model.DeliveryModel) error
+	GetOverdueForUpdate(ctx context.Context, now time.Time) ([]*model.DeliveryModel, error)
+	CreateEvent(ctx context.Context, event *model.DeliveryEvent) error
+	Enqueue(ctx context.Context, pending *model.PendingAssignment) error
+	ListPending(ctx context.Context, limit int) ([]*model.PendingAssignment, error)
+	DeletePending(ctx context.Context, orderId string) (bool, error)
+	MarkPendingAttempt(ctx context.Context, orderId string, at time.Time, reason string) error
+	PendingStats(ctx context.Context) (*model.PendingQueueStats, error)
+	GetEventsByOrderID(ctx context.Context, orderId string) ([]*model.DeliveryEvent, error)
+}
+
+type zoneRepository interface {
+	GetAll(ctx context.Context) ([]*model.Zone, error)
+}
+
+type shiftRepository interface {
+	ShiftEnds(ctx context.Context, ids []int, at time.Time) (map[int]time.Time, error)
 }
 
 type txManager interface {
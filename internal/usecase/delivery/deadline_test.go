@@ -0,0 +1,197 @@
+package delivery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/geo"
+	"github.com/cdxy1/go-courier-service/internal/model"
+	repoerrors "github.com/cdxy1/go-courier-service/internal/repository/delivery"
+)
+
+func TestDeliveryUsecase_AssignDistanceDeadline(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.December, 22, 10, 30, 0, 0, time.UTC)
+	speeds := model.NewTransportSpeeds(5, 15, 25)
+	factory := model.NewDeliveryTimeFactory(time.Minute*30, time.Minute*15, time.Minute*5,
+		model.WithDistanceDeadlines(speeds, time.Minute*10))
+
+	pickup := geo.Point{Lat: 55.75, Lon: 37.61}
+	// 0.027 degrees of latitude are about 3 km.
+	dropoff := geo.Point{Lat: 55.777, Lon: 37.61}
+
+	tests := []struct {
+		name      string
+		transport model.TransportType
+		pickup    *geo.Point
+		dropoff   *geo.Point
+		expect    time.Duration
+	}{
+		{
+			name:      "on foot",
+			transport: model.TransportOnFoot,
+			pickup:    &pickup,
+			dropoff:   &dropoff,
+			expect:    46 * time.Minute,
+		},
+		{
+			name:      "car",
+			transport: model.TransportCar,
+			pickup:    &pickup,
+			dropoff:   &dropoff,
+			expect:    17 * time.Minute,
+		},
+		{
+			name:      "same place",
+			transport: model.TransportScooter,
+			pickup:    &pickup,
+			dropoff:   &pickup,
+			expect:    10 * time.Minute,
+		},
+		{
+			name:      "dropoff unknown",
+			transport: model.TransportScooter,
+			pickup:    &pickup,
+			expect:    15 * time.Minute,
+		},
+		{
+			name:      "no coordinates",
+			transport: model.TransportOnFoot,
+			expect:    30 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cRepo := newMockCourierRepository(t)
+			dRepo := newMockDeliveryRepository(t)
+			cRepo.withAvailable(func(excludeIds []int) (*model.CourierModel, error) {
+				return &model.CourierModel{ID: 1, Status: model.CourierStatusAvailable, TransportType: tt.transport}, nil
+			})
+			cRepo.markAssignedFn = func(ctx context.Context, id int) error { return nil }
+			dRepo.getByOrderIDFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+				return nil, repoerrors.ErrDeliveryNotFound
+			}
+			dRepo.createFn = func(ctx context.Context, delivery *model.DeliveryModel) error { return nil }
+
+			uc := NewDeliveryUsecase(cRepo, dRepo, newMockTxManager(t), factory, func() time.Time { return now })
+
+			delivery, _, err := uc.AssignCourier(context.Background(), model.AssignCourierRequest{
+				OrderID: "order-1",
+				Pickup:  tt.pickup,
+				Dropoff: tt.dropoff,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := delivery.Deadline.Sub(now).Round(time.Minute); got != tt.expect {
+				t.Fatalf("expected deadline in %s, got %s", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestDeliveryUsecase_AssignCalendarDeadline(t *testing.T) {
+	t.Parallel()
+
+	calendar, err := model.ParseDeadlineCalendar([]byte(`{
+		"timezone": "Europe/Moscow",
+		"rules": [
+			{"name": "new year", "dates": ["2025-12-31"], "extra_minutes": 30},
+			{"name": "lunch", "days": ["mon", "tue", "wed", "thu", "fri"], "from": "12:00", "to": "14:00", "multiplier": 1.5},
+			{"name": "night", "from": "23:00", "to": "02:00", "zone_id": 1, "extra_minutes": 10}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := model.ParseDeadlineCalendar([]byte(`{"rules": [{"days": ["someday"]}]}`)); err == nil {
+		t.Fatalf("expected error for unknown day")
+	}
+
+	factory := model.NewDeliveryTimeFactory(time.Minute*30, time.Minute*15, time.Minute*5,
+		model.WithDeadlineCalendar(calendar))
+	zones := stubZoneRepository{zones: []*model.Zone{
+		{ID: 1, Area: geo.Polygon{{Lat: 0, Lon: 0}, {Lat: 0, Lon: 1}, {Lat: 1, Lon: 1}, {Lat: 1, Lon: 0}}},
+	}}
+	inZone := geo.Point{Lat: 0.5, Lon: 0.5}
+	outside := geo.Point{Lat: 5, Lon: 5}
+
+	// Moscow is UTC+3 all year.
+	tests := []struct {
+		name   string
+		now    time.Time
+		pickup *geo.Point
+		expect time.Duration
+	}{
+		{
+			name:   "weekday lunch",
+			now:    time.Date(2025, time.December, 22, 9, 30, 0, 0, time.UTC),
+			expect: 45 * time.Minute,
+		},
+		{
+			name:   "before lunch",
+			now:    time.Date(2025, time.December, 22, 8, 59, 0, 0, time.UTC),
+			expect: 30 * time.Minute,
+		},
+		{
+			name:   "weekend lunch",
+			now:    time.Date(2025, time.December, 27, 9, 30, 0, 0, time.UTC),
+			expect: 30 * time.Minute,
+		},
+		{
+			name:   "holiday overrides weekday",
+			now:    time.Date(2025, time.December, 31, 9, 30, 0, 0, time.UTC),
+			expect: 60 * time.Minute,
+		},
+		{
+			name:   "window across midnight in zone",
+			now:    time.Date(2025, time.December, 22, 21, 0, 0, 0, time.UTC),
+			pickup: &inZone,
+			expect: 40 * time.Minute,
+		},
+		{
+			name:   "window across midnight outside zone",
+			now:    time.Date(2025, time.December, 22, 21, 0, 0, 0, time.UTC),
+			pickup: &outside,
+			expect: 30 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cRepo := newMockCourierRepository(t)
+			dRepo := newMockDeliveryRepository(t)
+			cRepo.withAvailable(func(excludeIds []int) (*model.CourierModel, error) {
+				return &model.CourierModel{ID: 1, Status: model.CourierStatusAvailable, TransportType: model.TransportOnFoot}, nil
+			})
+			cRepo.markAssignedFn = func(ctx context.Context, id int) error { return nil }
+			dRepo.getByOrderIDFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+				return nil, repoerrors.ErrDeliveryNotFound
+			}
+			dRepo.createFn = func(ctx context.Context, delivery *model.DeliveryModel) error { return nil }
+
+			uc := NewDeliveryUsecase(cRepo, dRepo, newMockTxManager(t), factory, func() time.Time { return tt.now },
+				WithZones(zones, model.ZoneFallbackAny))
+
+			delivery, _, err := uc.AssignCourier(context.Background(), model.AssignCourierRequest{
+				OrderID: "order-1",
+				Pickup:  tt.pickup,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := delivery.Deadline.Sub(tt.now); got != tt.expect {
+				t.Fatalf("expected deadline in %s, got %s", tt.expect, got)
+			}
+		})
+	}
+}
@@ -2,17 +2,111 @@ package delivery
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/cdxy1/go-courier-service/internal/model"
+	courierRepo "github.com/cdxy1/go-courier-service/internal/repository/courier"
+	repo "github.com/cdxy1/go-courier-service/internal/repository/delivery"
 )
 
 type DeliveryUsecase struct {
-	courierRepo  courierRepository
-	deliveryRepo deliveryRepository
-	tm           txManager
-	timeFactory  *model.DeliveryTimeFactory
-	now          model.NowFunc
+	courierRepo   courierRepository
+	deliveryRepo  deliveryRepository
+	tm            txManager
+	timeFactory   *model.DeliveryTimeFactory
+	now           model.NowFunc
+	expiredPolicy model.ExpiredPolicy
+	capacity      model.CourierCapacity
+	selector      CourierSelector
+	zones         zoneRepository
+	zoneFallback  model.ZoneFallback
+	shifts        shiftRepository
+	eligibility   model.EligibilityRules
+	speeds        model.TransportSpeeds
+	loadPenalty   time.Duration
+	fairness      model.FairnessWindow
+	released      chan struct{}
+}
+
+// Option customizes optional behaviour of DeliveryUsecase.
+type Option func(*DeliveryUsecase)
+
+// WithExpiredPolicy sets how ProcessExpiredDeliveries treats overdue orders.
+// Unknown policies are ignored and the default model.ExpiredPolicyFlag is kept.
+func WithExpiredPolicy(policy model.ExpiredPolicy) Option {
+	return func(uc *DeliveryUsecase) {
+		if policy.IsValid() {
+			uc.expiredPolicy = policy
+		}
+	}
+}
+
+// WithCourierCapacity sets how many deliveries a courier may carry at once per
+// transport type. By default every courier takes a single delivery.
+func WithCourierCapacity(capacity model.CourierCapacity) Option {
+	return func(uc *DeliveryUsecase) {
+		uc.capacity = capacity
+	}
+}
+
+// WithZones makes assignment prefer couriers whose home zone contains the
+// order. Unknown fallbacks are ignored and model.ZoneFallbackNone is kept.
+func WithZones(zones zoneRepository, fallback model.ZoneFallback) Option {
+	return func(uc *DeliveryUsecase) {
+		uc.zones = zones
+		if fallback.IsValid() {
+			uc.zoneFallback = fallback
+		}
+	}
+}
+
+// WithShifts keeps couriers on a shift schedule from taking deliveries whose
+// deadline falls after the end of their shift.
+func WithShifts(shifts shiftRepository) Option {
+	return func(uc *DeliveryUsecase) {
+		uc.shifts = shifts
+	}
+}
+
+// WithEligibilityRules limits the transports that may carry an order based on
+// its contents. Orders without details, or matched by no rule, may go to any courier.
+func WithEligibilityRules(rules model.EligibilityRules) Option {
+	return func(uc *DeliveryUsecase) {
+		uc.eligibility = rules
+	}
+}
+
+// WithBatchCost tunes the cost AssignBatch minimizes: the time a courier needs
+// to reach the pickup point at the speed of its transport, plus loadPenalty for
+// every delivery the courier already has today or is given in the same batch.
+func WithBatchCost(speeds model.TransportSpeeds, loadPenalty time.Duration) Option {
+	return func(uc *DeliveryUsecase) {
+		uc.speeds = speeds
+		if loadPenalty >= 0 {
+			uc.loadPenalty = loadPenalty
+		}
+	}
+}
+
+// WithFairnessWindow sets the period the recent load of couriers is counted
+// over. By default it is the last 24 hours.
+func WithFairnessWindow(window model.FairnessWindow) Option {
+	return func(uc *DeliveryUsecase) {
+		uc.fairness = window
+	}
+}
+
+// WithCourierSelector sets the strategy that picks a courier when the request
+// does not name one. By default model.DispatchLeastRecent is used.
+func WithCourierSelector(selector CourierSelector) Option {
+	return func(uc *DeliveryUsecase) {
+		if selector != nil {
+			uc.selector = selector
+		}
+	}
 }
 
 func NewDeliveryUsecase(
@@ -21,39 +115,106 @@ func NewDeliveryUsecase(
 	tm txManager,
 	timeFactory *model.DeliveryTimeFactory,
 	now model.NowFunc,
+	opts ...Option,
 ) *DeliveryUsecase {
-	return &DeliveryUsecase{
-		courierRepo:  courierRepo,
-		deliveryRepo: deliveryRepo,
-		tm:           tm,
-		timeFactory:  timeFactory,
-		now:          now,
+	uc := &DeliveryUsecase{
+		courierRepo:   courierRepo,
+		deliveryRepo:  deliveryRepo,
+		tm:            tm,
+		timeFactory:   timeFactory,
+		now:           now,
+		expiredPolicy: model.ExpiredPolicyFlag,
+		selector:      leastRecentSelector{},
+		zoneFallback:  model.ZoneFallbackNone,
+		speeds:        model.NewTransportSpeeds(5, 15, 25),
+		loadPenalty:   defaultLoadPenalty,
+		fairness:      model.NewFairnessWindow(model.FairnessRolling, 0, 0, nil),
+		released:      make(chan struct{}, 1),
 	}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// Assign assigns a courier to an order received from the order service. The
+// order contents decide which transports may carry it; an order known only by
+// its id may go to any courier. The estimated delivery time bounds the deadline.
+func (uc *DeliveryUsecase) Assign(ctx context.Context, order *model.Order) (*model.DeliveryModel, *model.CourierModel, error) {
+	return uc.AssignCourier(ctx, orderRequest(order))
+}
+
+// orderRequest builds the assignment request for an order from the order service.
+func orderRequest(order *model.Order) model.AssignCourierRequest {
+	req := model.AssignCourierRequest{OrderID: order.ID}
+	if !order.EstimatedDelivery.IsZero() {
+		promisedBy := order.EstimatedDelivery
+		req.PromisedBy = &promisedBy
+	}
+	if details := order.Details(); details != (model.OrderDetails{}) {
+		req.Order = &details
+	}
+	return req
+}
+
+// AssignCourier creates a delivery for the order. The courier from the request is
+// used when set, otherwise the least loaded available courier is picked.
+// Repeated calls are idempotent: while the order has an active delivery, or when
+// the idempotency key was already used, the existing assignment is returned.
+// When no courier is free the order is put into the pending queue and
+// ErrAssignmentQueued is returned.
+func (uc *DeliveryUsecase) AssignCourier(ctx context.Context, req model.AssignCourierRequest) (*model.DeliveryModel, *model.CourierModel, error) {
+	d, courier, err := uc.tryAssign(ctx, req)
+	if req.CourierID == 0 && errors.Is(err, courierRepo.ErrCourierNotFound) {
+		if err := uc.enqueue(ctx, req); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("%w: %s", ErrAssignmentQueued, req.OrderID)
+	}
+	return d, courier, err
+}
+
+// tryAssign assigns a courier to the order once, without queueing it on failure.
+func (uc *DeliveryUsecase) tryAssign(ctx context.Context, req model.AssignCourierRequest) (*model.DeliveryModel, *model.CourierModel, error) {
+	d, courier, err := uc.assignCourier(ctx, req)
+	if errors.Is(err, repo.ErrActiveDeliveryExists) || errors.Is(err, repo.ErrIdempotencyKeyExists) {
+		// A concurrent request created the delivery first, hand back its result.
+		return uc.existingAssignment(ctx, req)
+	}
+	return d, courier, err
 }
 
-func (uc *DeliveryUsecase) Assign(ctx context.Context, order_id string) (*model.DeliveryModel, *model.CourierModel, error) {
+func (uc *DeliveryUsecase) assignCourier(ctx context.Context, req model.AssignCourierRequest) (*model.DeliveryModel, *model.CourierModel, error) {
 	var createdDelivery *model.DeliveryModel
 	var assignedCourier *model.CourierModel
 
 	if err := uc.tm.WithTx(ctx, func(ctx context.Context) error {
-		courier, err := uc.courierRepo.GetAvailableLeastDelivered(ctx)
+		existing, err := uc.findAssignment(ctx, req)
 		if err != nil {
-			return fmt.Errorf("get available courier: %w", err)
+			return err
 		}
-
-		deadline := uc.timeFactory.ForTransport(courier.TransportType).Deadline(uc.now())
-		d := &model.DeliveryModel{
-			CourierId: courier.ID,
-			OrderId:   order_id,
-			Deadline:  deadline,
+		if existing != nil {
+			courier, err := uc.courierRepo.GetOneById(ctx, existing.CourierId)
+			if err != nil {
+				return fmt.Errorf("get courier: %w", err)
+			}
+			// The order may have been queued before it was assigned elsewhere.
+			if _, err := uc.deliveryRepo.DeletePending(ctx, req.OrderID); err != nil {
+				return fmt.Errorf("delete pending assignment: %w", err)
+			}
+			createdDelivery = existing
+			assignedCourier = courier
+			return nil
 		}
 
-		if err := uc.deliveryRepo.Create(ctx, d); err != nil {
-			return fmt.Errorf("create delivery: %w", err)
+		courier, err := uc.pickCourier(ctx, req, nil)
+		if err != nil {
+			return err
 		}
 
-		if err := uc.courierRepo.MarkAssigned(ctx, courier.ID); err != nil {
-			return fmt.Errorf("mark courier assigned: %w", err)
+		d, err := uc.createDelivery(ctx, req, courier, "")
+		if err != nil {
+			return err
 		}
 
 		createdDelivery = d
@@ -66,55 +227,222 @@ func (uc *DeliveryUsecase) Assign(ctx context.Context, order_id string) (*model.
 	return createdDelivery, assignedCourier, nil
 }
 
-func (uc *DeliveryUsecase) Unassign(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
-	var courierId int
-	if err := uc.tm.WithTx(ctx, func(ctx context.Context) error {
-		cid, err := uc.deliveryRepo.Delete(ctx, orderId)
-		if err != nil {
-			return fmt.Errorf("delete delivery: %w", err)
+// createDelivery stores a new delivery for the already locked courier and marks
+// the courier as assigned. The order leaves the pending queue in the same
+// transaction, whichever way it was assigned, so a queued order is never
+// assigned twice.
+func (uc *DeliveryUsecase) createDelivery(
+	ctx context.Context,
+	req model.AssignCourierRequest,
+	courier *model.CourierModel,
+	reason string,
+) (*model.DeliveryModel, error) {
+	deliveryCtx, err := uc.deliveryContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	deliveryCtx.Transport = courier.TransportType
+
+	now := uc.now()
+	deadline := uc.timeFactory.For(deliveryCtx).Deadline(now)
+	d := &model.DeliveryModel{
+		CourierId:      courier.ID,
+		OrderId:        req.OrderID,
+		Status:         model.DeliveryStatusAssigned,
+		AssignedAt:     now,
+		Deadline:       deadline,
+		UpdatedAt:      now,
+		IdempotencyKey: req.IdempotencyKey,
+		Pickup:         req.Pickup,
+		Dropoff:        req.Dropoff,
+		Order:          req.Order,
+		PromisedBy:     req.PromisedBy,
+		AtRisk:         missesPromise(deadline, req.PromisedBy),
+	}
+
+	if err := uc.deliveryRepo.Create(ctx, d); err != nil {
+		return nil, fmt.Errorf("create delivery: %w", err)
+	}
+	if _, err := uc.deliveryRepo.DeletePending(ctx, req.OrderID); err != nil {
+		return nil, fmt.Errorf("delete pending assignment: %w", err)
+	}
+
+	if err := uc.courierRepo.MarkAssigned(ctx, courier.ID); err != nil {
+		return nil, fmt.Errorf("mark courier assigned: %w", err)
+	}
+
+	if err := uc.recordEvent(ctx, model.DeliveryEventAssigned, d, "", reason); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// pickCourier locks the courier that will take the delivery. Without a courier
+// in the request the selector chooses among the available couriers not in
+// excludeIds. Only couriers with a transport eligible for the order, and on
+// shift until its deadline when they have shifts, are considered.
+func (uc *DeliveryUsecase) pickCourier(ctx context.Context, req model.AssignCourierRequest, excludeIds []int) (*model.CourierModel, error) {
+	transports, err := uc.eligibleTransports(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.CourierID == 0 {
+		return uc.selectCourier(ctx, req, transports, excludeIds)
+	}
+
+	courier, err := uc.courierRepo.GetOneByIdForUpdate(ctx, req.CourierID)
+	if err != nil {
+		return nil, fmt.Errorf("get courier: %w", err)
+	}
+	if transports != nil && !slices.Contains(transports, courier.TransportType) {
+		return nil, fmt.Errorf("%w: courier %d uses %s", ErrTransportNotAllowed, courier.ID, courier.TransportType)
+	}
+	if !uc.capacity.CanTake(courier) {
+		return nil, fmt.Errorf("%w: courier %d is %s with %d active deliveries",
+			ErrCourierUnavailable, courier.ID, courier.Status, courier.ActiveDeliveries)
+	}
+	if err := uc.checkShift(ctx, req, courier); err != nil {
+		return nil, err
+	}
+	return courier, nil
+}
+
+// selectCourier locks the first courier in the selector's ranking that can still
+// take a delivery. Candidates locked by a concurrent assignment are skipped.
+func (uc *DeliveryUsecase) selectCourier(
+	ctx context.Context,
+	req model.AssignCourierRequest,
+	transports []model.TransportType,
+	excludeIds []int,
+) (*model.CourierModel, error) {
+	filter := uc.availableFilter()
+	filter.ExcludeIDs = excludeIds
+	filter.Transports = transports
+	if area, ok := uc.selector.(AreaSelector); ok {
+		filter.Cells = area.SearchCells(req)
+	}
+	candidates, err := uc.courierRepo.ListAvailable(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("get available courier: %w", err)
+	}
+	if candidates, err = uc.withinShift(ctx, req, candidates); err != nil {
+		return nil, fmt.Errorf("get available courier: %w", err)
+	}
+
+	ranked := uc.selector.Rank(req, candidates)
+	if req.PromisedBy != nil {
+		if ranked, err = uc.preferOnTime(ctx, req, ranked); err != nil {
+			return nil, fmt.Errorf("get available courier: %w", err)
 		}
-		courierId = cid
+	}
+	if uc.zones != nil {
+		if ranked, err = uc.preferZone(ctx, req, ranked); err != nil {
+			return nil, fmt.Errorf("get available courier: %w", err)
+		}
+	}
 
-		if err := uc.courierRepo.UpdateStatus(ctx, model.CourierStatusAvailable, courierId); err != nil {
-			return fmt.Errorf("update courier status: %w", err)
+	for _, candidate := range ranked {
+		courier, err := uc.courierRepo.GetOneByIdSkipLocked(ctx, candidate.Courier.ID)
+		if errors.Is(err, courierRepo.ErrCourierNotFound) {
+			continue
 		}
-		return nil
-	}); err != nil {
-		return nil, err
+		if err != nil {
+			return nil, fmt.Errorf("get available courier: %w", err)
+		}
+		if uc.capacity.CanTake(courier) {
+			return courier, nil
+		}
+	}
+	return nil, fmt.Errorf("get available courier: %w", courierRepo.ErrCourierNotFound)
+}
+
+// availableFilter returns the filter for couriers with spare capacity, with
+// their load counted over the fairness window.
+func (uc *DeliveryUsecase) availableFilter() model.AvailableCourierFilter {
+	now := uc.now()
+	return model.AvailableCourierFilter{
+		Capacity:     uc.capacity,
+		Since:        now.Truncate(24 * time.Hour),
+		LoadSince:    uc.fairness.Since(now),
+		LoadByShift:  uc.fairness.ByCourierShift(),
+		LoadHalfLife: uc.fairness.HalfLife(),
+		Now:          now,
+	}
+}
+
+// eligibleTransports returns the transports allowed to carry the order, nil
+// when any transport may.
+func (uc *DeliveryUsecase) eligibleTransports(req model.AssignCourierRequest) ([]model.TransportType, error) {
+	if req.Order == nil {
+		return nil, nil
+	}
+	transports, ok := uc.eligibility.Allowed(*req.Order)
+	if !ok {
+		return nil, nil
 	}
+	if len(transports) == 0 {
+		return nil, fmt.Errorf("%w: order %s", ErrNoEligibleTransport, req.OrderID)
+	}
+	return transports, nil
+}
+
+func (uc *DeliveryUsecase) Unassign(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+	return uc.changeStatus(ctx, orderId, model.DeliveryStatusCancelled, model.DeliveryEventUnassigned)
+}
 
-	return &model.DeliveryModel{OrderId: orderId, CourierId: courierId}, nil
+func (uc *DeliveryUsecase) PickUp(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+	return uc.changeStatus(ctx, orderId, model.DeliveryStatusPickedUp, model.DeliveryEventPickedUp)
+}
+
+// StartTransit marks that the courier is on the way to the customer.
+func (uc *DeliveryUsecase) StartTransit(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+	return uc.changeStatus(ctx, orderId, model.DeliveryStatusInTransit, model.DeliveryEventInTransit)
 }
 
 func (uc *DeliveryUsecase) Complete(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
-	var courierId int
+	return uc.changeStatus(ctx, orderId, model.DeliveryStatusDelivered, model.DeliveryEventCompleted)
+}
+
+// changeStatus moves the latest delivery of the order to the given status.
+// When the new status is final the delivery is released from the courier in the
+// same transaction.
+func (uc *DeliveryUsecase) changeStatus(
+	ctx context.Context,
+	orderId string,
+	status model.DeliveryStatus,
+	eventType model.DeliveryEventType,
+) (*model.DeliveryModel, error) {
+	var delivery *model.DeliveryModel
 	if err := uc.tm.WithTx(ctx, func(ctx context.Context) error {
-		cid, err := uc.deliveryRepo.GetCourierID(ctx, orderId)
+		d, err := uc.deliveryRepo.GetByOrderIDForUpdate(ctx, orderId)
 		if err != nil {
-			return fmt.Errorf("get delivery courier: %w", err)
+			return fmt.Errorf("get delivery: %w", err)
+		}
+
+		from := d.Status
+		if err := transition(d, status, uc.now()); err != nil {
+			return err
+		}
+		if err := uc.deliveryRepo.UpdateStatus(ctx, d); err != nil {
+			return fmt.Errorf("update delivery status: %w", err)
+		}
+		if err := uc.recordEvent(ctx, eventType, d, from, ""); err != nil {
+			return err
 		}
-		courierId = cid
 
-		if err := uc.courierRepo.UpdateStatus(ctx, model.CourierStatusAvailable, courierId); err != nil {
-			return fmt.Errorf("update courier status: %w", err)
+		if !status.IsActive() {
+			if err := uc.courierRepo.MarkReleased(ctx, d.CourierId); err != nil {
+				return fmt.Errorf("release courier: %w", err)
+			}
 		}
+		delivery = d
 		return nil
 	}); err != nil {
 		return nil, err
 	}
 
-	return &model.DeliveryModel{OrderId: orderId, CourierId: courierId}, nil
-}
-
-func (uc *DeliveryUsecase) ProcessExpiredDeliveries(ctx context.Context) (int, error) {
-	var updated int
-	err := uc.tm.WithTx(ctx, func(ctx context.Context) error {
-		count, err := uc.deliveryRepo.ReleaseExpiredCouriers(ctx)
-		if err != nil {
-			return fmt.Errorf("release expired couriers: %w", err)
-		}
-		updated = count
-		return nil
-	})
-	return updated, err
+	if !status.IsActive() {
+		uc.notifyReleased()
+	}
+	return delivery, nil
 }
@@ -7,15 +7,21 @@ import (
 	"time"
 
 	"github.com/cdxy1/go-courier-service/internal/model"
+	courierrepo "github.com/cdxy1/go-courier-service/internal/repository/courier"
+	repoerrors "github.com/cdxy1/go-courier-service/internal/repository/delivery"
 )
 
 var errBoom = errors.New("failed")
 
 type mockCourierRepository struct {
 	t              *testing.T
-	getAvailableFn func(ctx context.Context) (*model.CourierModel, error)
+	getOneByIDFn   func(ctx context.Context, id int) (*model.CourierModel, error)
+	getForUpdateFn func(ctx context.Context, id int) (*model.CourierModel, error)
+	listAvailFn    func(ctx context.Context, filter model.AvailableCourierFilter) ([]*model.CourierCandidate, error)
+	skipLockedFn   func(ctx context.Context, id int) (*model.CourierModel, error)
 	updateStatusFn func(ctx context.Context, status model.CourierStatus, id int) error
 	markAssignedFn func(ctx context.Context, id int) error
+	markReleasedFn func(ctx context.Context, id int) error
 }
 
 func newMockCourierRepository(t *testing.T) *mockCourierRepository {
@@ -33,8 +39,17 @@ func (m *mockCourierRepository) Update(ctx context.Context, courier *model.Couri
 }
 
 func (m *mockCourierRepository) GetOneById(ctx context.Context, id int) (*model.CourierModel, error) {
-	m.t.Fatalf("GetOneById called unexpectedly")
-	return nil, nil
+	if m.getOneByIDFn == nil {
+		m.t.Fatalf("GetOneById called unexpectedly")
+	}
+	return m.getOneByIDFn(ctx, id)
+}
+
+func (m *mockCourierRepository) GetOneByIdForUpdate(ctx context.Context, id int) (*model.CourierModel, error) {
+	if m.getForUpdateFn == nil {
+		m.t.Fatalf("GetOneByIdForUpdate called unexpectedly")
+	}
+	return m.getForUpdateFn(ctx, id)
 }
 
 func (m *mockCourierRepository) GetAll(ctx context.Context) ([]*model.CourierModel, error) {
@@ -61,19 +76,64 @@ func (m *mockCourierRepository) MarkAssigned(ctx context.Context, id int) error
 	return m.markAssignedFn(ctx, id)
 }
 
-func (m *mockCourierRepository) GetAvailableLeastDelivered(ctx context.Context) (*model.CourierModel, error) {
-	if m.getAvailableFn == nil {
-		m.t.Fatalf("GetAvailableLeastDelivered called unexpectedly")
+func (m *mockCourierRepository) MarkReleased(ctx context.Context, id int) error {
+	if m.markReleasedFn == nil {
+		m.t.Fatalf("MarkReleased called unexpectedly")
+	}
+	return m.markReleasedFn(ctx, id)
+}
+
+func (m *mockCourierRepository) ListAvailable(ctx context.Context, filter model.AvailableCourierFilter) ([]*model.CourierCandidate, error) {
+	if m.listAvailFn == nil {
+		m.t.Fatalf("ListAvailable called unexpectedly")
+	}
+	return m.listAvailFn(ctx, filter)
+}
+
+func (m *mockCourierRepository) GetOneByIdSkipLocked(ctx context.Context, id int) (*model.CourierModel, error) {
+	if m.skipLockedFn == nil {
+		m.t.Fatalf("GetOneByIdSkipLocked called unexpectedly")
+	}
+	return m.skipLockedFn(ctx, id)
+}
+
+// withAvailable makes the courier returned by fn the only candidate for
+// automatic assignment. A nil courier means nobody is available.
+func (m *mockCourierRepository) withAvailable(fn func(excludeIds []int) (*model.CourierModel, error)) {
+	var courier *model.CourierModel
+	m.listAvailFn = func(ctx context.Context, filter model.AvailableCourierFilter) ([]*model.CourierCandidate, error) {
+		c, err := fn(filter.ExcludeIDs)
+		if err != nil || c == nil {
+			return nil, err
+		}
+		courier = c
+		return []*model.CourierCandidate{{Courier: c}}, nil
+	}
+	m.skipLockedFn = func(ctx context.Context, id int) (*model.CourierModel, error) {
+		if courier == nil || courier.ID != id {
+			m.t.Fatalf("unexpected courier lock: %d", id)
+		}
+		return courier, nil
 	}
-	return m.getAvailableFn(ctx)
 }
 
 type mockDeliveryRepository struct {
-	t                *testing.T
-	createFn         func(ctx context.Context, delivery *model.DeliveryModel) error
-	deleteFn         func(ctx context.Context, orderId string) (int, error)
-	getCourierIDFn   func(ctx context.Context, orderId string) (int, error)
-	releaseExpiredFn func(ctx context.Context) (int, error)
+	t              *testing.T
+	createFn       func(ctx context.Context, delivery *model.DeliveryModel) error
+	getByOrderIDFn func(ctx context.Context, orderId string) (*model.DeliveryModel, error)
+	getByKeyFn     func(ctx context.Context, key string) (*model.DeliveryModel, error)
+	listFn         func(ctx context.Context, filter model.DeliveryFilter) ([]*model.DeliveryModel, int, error)
+	getForUpdateFn func(ctx context.Context, orderId string) (*model.DeliveryModel, error)
+	updateStatusFn func(ctx context.Context, delivery *model.DeliveryModel) error
+	getOverdueFn   func(ctx context.Context, now time.Time) ([]*model.DeliveryModel, error)
+	createEventFn  func(ctx context.Context, event *model.DeliveryEvent) error
+	getEventsFn    func(ctx context.Context, orderId string) ([]*model.DeliveryEvent, error)
+	enqueueFn      func(ctx context.Context, pending *model.PendingAssignment) error
+	listPendingFn  func(ctx context.Context, limit int) ([]*model.PendingAssignment, error)
+	deletePendFn   func(ctx context.Context, orderId string) (bool, error)
+	markAttemptFn  func(ctx context.Context, orderId string, at time.Time, reason string) error
+	pendingStatsFn func(ctx context.Context) (*model.PendingQueueStats, error)
+	events         []*model.DeliveryEvent
 }
 
 func newMockDeliveryRepository(t *testing.T) *mockDeliveryRepository {
@@ -87,25 +147,96 @@ func (m *mockDeliveryRepository) Create(ctx context.Context, delivery *model.Del
 	return m.createFn(ctx, delivery)
 }
 
-func (m *mockDeliveryRepository) Delete(ctx context.Context, orderId string) (int, error) {
-	if m.deleteFn == nil {
-		m.t.Fatalf("Delete called unexpectedly")
+func (m *mockDeliveryRepository) GetByOrderID(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+	if m.getByOrderIDFn == nil {
+		m.t.Fatalf("GetByOrderID called unexpectedly")
+	}
+	return m.getByOrderIDFn(ctx, orderId)
+}
+
+func (m *mockDeliveryRepository) GetByIdempotencyKey(ctx context.Context, key string) (*model.DeliveryModel, error) {
+	if m.getByKeyFn == nil {
+		m.t.Fatalf("GetByIdempotencyKey called unexpectedly")
+	}
+	return m.getByKeyFn(ctx, key)
+}
+
+func (m *mockDeliveryRepository) List(ctx context.Context, filter model.DeliveryFilter) ([]*model.DeliveryModel, int, error) {
+	if m.listFn == nil {
+		m.t.Fatalf("List called unexpectedly")
+	}
+	return m.listFn(ctx, filter)
+}
+
+func (m *mockDeliveryRepository) GetByOrderIDForUpdate(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+	if m.getForUpdateFn == nil {
+		m.t.Fatalf("GetByOrderIDForUpdate called unexpectedly")
+	}
+	return m.getForUpdateFn(ctx, orderId)
+}
+
+func (m *mockDeliveryRepository) UpdateStatus(ctx context.Context, delivery *model.DeliveryModel) error {
+	if m.updateStatusFn == nil {
+		m.t.Fatalf("UpdateStatus called unexpectedly")
+	}
+	return m.updateStatusFn(ctx, delivery)
+}
+
+func (m *mockDeliveryRepository) GetOverdueForUpdate(ctx context.Context, now time.Time) ([]*model.DeliveryModel, error) {
+	if m.getOverdueFn == nil {
+		m.t.Fatalf("GetOverdueForUpdate called unexpectedly")
+	}
+	return m.getOverdueFn(ctx, now)
+}
+
+func (m *mockDeliveryRepository) CreateEvent(ctx context.Context, event *model.DeliveryEvent) error {
+	if m.createEventFn != nil {
+		return m.createEventFn(ctx, event)
+	}
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *mockDeliveryRepository) GetEventsByOrderID(ctx context.Context, orderId string) ([]*model.DeliveryEvent, error) {
+	if m.getEventsFn == nil {
+		m.t.Fatalf("GetEventsByOrderID called unexpectedly")
+	}
+	return m.getEventsFn(ctx, orderId)
+}
+
+func (m *mockDeliveryRepository) Enqueue(ctx context.Context, pending *model.PendingAssignment) error {
+	if m.enqueueFn == nil {
+		m.t.Fatalf("Enqueue called unexpectedly")
+	}
+	return m.enqueueFn(ctx, pending)
+}
+
+func (m *mockDeliveryRepository) ListPending(ctx context.Context, limit int) ([]*model.PendingAssignment, error) {
+	if m.listPendingFn == nil {
+		m.t.Fatalf("ListPending called unexpectedly")
+	}
+	return m.listPendingFn(ctx, limit)
+}
+
+func (m *mockDeliveryRepository) DeletePending(ctx context.Context, orderId string) (bool, error) {
+	if m.deletePendFn == nil {
+		return false, nil
 	}
-	return m.deleteFn(ctx, orderId)
+	return m.deletePendFn(ctx, orderId)
 }
 
-func (m *mockDeliveryRepository) GetCourierID(ctx context.Context, orderId string) (int, error) {
-	if m.getCourierIDFn == nil {
-		m.t.Fatalf("GetCourierID called unexpectedly")
+func (m *mockDeliveryRepository) MarkPendingAttempt(ctx context.Context, orderId string, at time.Time, reason string) error {
+	if m.markAttemptFn == nil {
+		m.t.Fatalf("MarkPendingAttempt called unexpectedly")
 	}
-	return m.getCourierIDFn(ctx, orderId)
+	return m.markAttemptFn(ctx, orderId, at, reason)
 }
 
-func (m *mockDeliveryRepository) ReleaseExpiredCouriers(ctx context.Context) (int, error) {
-	if m.releaseExpiredFn == nil {
-		m.t.Fatalf("ReleaseExpiredCouriers called unexpectedly")
+func (m *mockDeliveryRepository) PendingStats(ctx context.Context) (*model.PendingQueueStats, error) {
+	if m.pendingStatsFn == nil {
+		m.t.Fatalf("PendingStats called unexpectedly")
 	}
-	return m.releaseExpiredFn(ctx)
+	return m.pendingStatsFn(ctx)
 }
 
 type mockTxManager struct {
@@ -139,10 +270,10 @@ func TestDeliveryUsecase_Assign(t *testing.T) {
 		{
 			name: "success",
 			setup: func(cRepo *mockCourierRepository, dRepo *mockDeliveryRepository, tm *mockTxManager) {
-				courier := &model.CourierModel{ID: 7, TransportType: model.TransportCar}
-				cRepo.getAvailableFn = func(ctx context.Context) (*model.CourierModel, error) {
+				courier := &model.CourierModel{ID: 7, Status: model.CourierStatusAvailable, TransportType: model.TransportCar}
+				cRepo.withAvailable(func(excludeIds []int) (*model.CourierModel, error) {
 					return courier, nil
-				}
+				})
 				dRepo.createFn = func(ctx context.Context, delivery *model.DeliveryModel) error {
 					if delivery.CourierId != courier.ID {
 						t.Fatalf("unexpected courier id: %d", delivery.CourierId)
@@ -153,6 +284,9 @@ func TestDeliveryUsecase_Assign(t *testing.T) {
 					if delivery.Deadline.IsZero() {
 						t.Fatalf("deadline must be set")
 					}
+					if delivery.Status != model.DeliveryStatusAssigned {
+						t.Fatalf("unexpected status: %s", delivery.Status)
+					}
 					return nil
 				}
 				cRepo.markAssignedFn = func(ctx context.Context, id int) error {
@@ -166,18 +300,18 @@ func TestDeliveryUsecase_Assign(t *testing.T) {
 		{
 			name: "get courier error",
 			setup: func(cRepo *mockCourierRepository, dRepo *mockDeliveryRepository, tm *mockTxManager) {
-				cRepo.getAvailableFn = func(ctx context.Context) (*model.CourierModel, error) {
+				cRepo.withAvailable(func(excludeIds []int) (*model.CourierModel, error) {
 					return nil, errBoom
-				}
+				})
 			},
 			expectErr: errBoom,
 		},
 		{
 			name: "create delivery error",
 			setup: func(cRepo *mockCourierRepository, dRepo *mockDeliveryRepository, tm *mockTxManager) {
-				cRepo.getAvailableFn = func(ctx context.Context) (*model.CourierModel, error) {
-					return &model.CourierModel{ID: 1}, nil
-				}
+				cRepo.withAvailable(func(excludeIds []int) (*model.CourierModel, error) {
+					return &model.CourierModel{ID: 1, Status: model.CourierStatusAvailable}, nil
+				})
 				dRepo.createFn = func(ctx context.Context, delivery *model.DeliveryModel) error {
 					return errBoom
 				}
@@ -187,9 +321,9 @@ func TestDeliveryUsecase_Assign(t *testing.T) {
 		{
 			name: "mark assigned error",
 			setup: func(cRepo *mockCourierRepository, dRepo *mockDeliveryRepository, tm *mockTxManager) {
-				cRepo.getAvailableFn = func(ctx context.Context) (*model.CourierModel, error) {
-					return &model.CourierModel{ID: 1}, nil
-				}
+				cRepo.withAvailable(func(excludeIds []int) (*model.CourierModel, error) {
+					return &model.CourierModel{ID: 1, Status: model.CourierStatusAvailable}, nil
+				})
 				dRepo.createFn = func(ctx context.Context, delivery *model.DeliveryModel) error {
 					return nil
 				}
@@ -209,10 +343,13 @@ func TestDeliveryUsecase_Assign(t *testing.T) {
 			dRepo := newMockDeliveryRepository(t)
 			tm := newMockTxManager(t)
 
+			dRepo.getByOrderIDFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+				return nil, repoerrors.ErrDeliveryNotFound
+			}
 			tt.setup(cRepo, dRepo, tm)
 
 			uc := NewDeliveryUsecase(cRepo, dRepo, tm, factory, func() time.Time { return now })
-			delivery, courier, err := uc.Assign(context.Background(), orderID)
+			delivery, courier, err := uc.Assign(context.Background(), &model.Order{ID: orderID})
 
 			if tt.expectErr != nil {
 				if err == nil || !errors.Is(err, tt.expectErr) {
@@ -240,10 +377,96 @@ func TestDeliveryUsecase_Assign(t *testing.T) {
 	}
 }
 
+func TestDeliveryUsecase_AssignCourier(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.December, 22, 10, 0, 0, 0, time.UTC)
+	factory := model.NewDeliveryTimeFactory(time.Minute*30, time.Minute*15, time.Minute*5)
+
+	tests := []struct {
+		name      string
+		courier   *model.CourierModel
+		getErr    error
+		expectErr error
+	}{
+		{
+			name:    "available courier",
+			courier: &model.CourierModel{ID: 12, Status: model.CourierStatusAvailable, TransportType: model.TransportScooter},
+		},
+		{
+			name:      "busy courier",
+			courier:   &model.CourierModel{ID: 12, Status: model.CourierStatusBusy, TransportType: model.TransportScooter},
+			expectErr: ErrCourierUnavailable,
+		},
+		{
+			name:    "busy courier with spare capacity",
+			courier: &model.CourierModel{ID: 12, Status: model.CourierStatusBusy, TransportType: model.TransportScooter, ActiveDeliveries: 1},
+		},
+		{
+			name:      "courier at capacity",
+			courier:   &model.CourierModel{ID: 12, Status: model.CourierStatusBusy, TransportType: model.TransportScooter, ActiveDeliveries: 2},
+			expectErr: ErrCourierUnavailable,
+		},
+		{
+			name:      "paused courier",
+			courier:   &model.CourierModel{ID: 12, Status: model.CourierStatusPaused, TransportType: model.TransportScooter},
+			expectErr: ErrCourierUnavailable,
+		},
+		{
+			name:      "courier not found",
+			getErr:    courierrepo.ErrCourierNotFound,
+			expectErr: courierrepo.ErrCourierNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cRepo := newMockCourierRepository(t)
+			dRepo := newMockDeliveryRepository(t)
+
+			cRepo.getForUpdateFn = func(ctx context.Context, id int) (*model.CourierModel, error) {
+				if id != 12 {
+					t.Fatalf("unexpected courier id: %d", id)
+				}
+				return tt.courier, tt.getErr
+			}
+			cRepo.markAssignedFn = func(ctx context.Context, id int) error { return nil }
+			dRepo.createFn = func(ctx context.Context, delivery *model.DeliveryModel) error { return nil }
+			dRepo.getByOrderIDFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+				return nil, repoerrors.ErrDeliveryNotFound
+			}
+
+			uc := NewDeliveryUsecase(cRepo, dRepo, newMockTxManager(t), factory, func() time.Time { return now },
+				WithCourierCapacity(model.NewCourierCapacity(1, 2, 4)))
+			delivery, courier, err := uc.AssignCourier(context.Background(), model.AssignCourierRequest{OrderID: "order-12", CourierID: 12})
+
+			if tt.expectErr != nil {
+				if err == nil || !errors.Is(err, tt.expectErr) {
+					t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if courier.ID != 12 || delivery.CourierId != 12 {
+				t.Fatalf("unexpected courier: %+v", courier)
+			}
+			if want := now.Add(time.Minute * 15); !delivery.Deadline.Equal(want) {
+				t.Fatalf("unexpected deadline: %s", delivery.Deadline)
+			}
+		})
+	}
+}
+
 func TestDeliveryUsecase_Unassign(t *testing.T) {
 	t.Parallel()
 
 	orderID := "order-77"
+	now := time.Date(2025, time.December, 22, 10, 0, 0, 0, time.UTC)
 
 	tests := []struct {
 		name      string
@@ -253,16 +476,22 @@ func TestDeliveryUsecase_Unassign(t *testing.T) {
 		{
 			name: "success",
 			setup: func(cRepo *mockCourierRepository, dRepo *mockDeliveryRepository, tm *mockTxManager) {
-				dRepo.deleteFn = func(ctx context.Context, orderId string) (int, error) {
+				dRepo.getForUpdateFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
 					if orderId != orderID {
 						t.Fatalf("unexpected order id: %s", orderId)
 					}
-					return 5, nil
+					return &model.DeliveryModel{ID: 1, OrderId: orderId, CourierId: 5, Status: model.DeliveryStatusAssigned}, nil
 				}
-				cRepo.updateStatusFn = func(ctx context.Context, status model.CourierStatus, id int) error {
-					if status != model.CourierStatusAvailable {
-						t.Fatalf("unexpected status: %s", status)
+				dRepo.updateStatusFn = func(ctx context.Context, delivery *model.DeliveryModel) error {
+					if delivery.Status != model.DeliveryStatusCancelled {
+						t.Fatalf("unexpected delivery status: %s", delivery.Status)
 					}
+					if delivery.CancelledAt == nil || !delivery.CancelledAt.Equal(now) {
+						t.Fatalf("cancelled_at must be set")
+					}
+					return nil
+				}
+				cRepo.markReleasedFn = func(ctx context.Context, id int) error {
 					if id != 5 {
 						t.Fatalf("unexpected id: %d", id)
 					}
@@ -271,21 +500,45 @@ func TestDeliveryUsecase_Unassign(t *testing.T) {
 			},
 		},
 		{
-			name: "delete error",
+			name: "get delivery error",
+			setup: func(cRepo *mockCourierRepository, dRepo *mockDeliveryRepository, tm *mockTxManager) {
+				dRepo.getForUpdateFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+					return nil, errBoom
+				}
+			},
+			expectErr: errBoom,
+		},
+		{
+			name: "already delivered",
+			setup: func(cRepo *mockCourierRepository, dRepo *mockDeliveryRepository, tm *mockTxManager) {
+				dRepo.getForUpdateFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+					return &model.DeliveryModel{ID: 1, OrderId: orderId, CourierId: 5, Status: model.DeliveryStatusDelivered}, nil
+				}
+			},
+			expectErr: ErrInvalidStatusTransition,
+		},
+		{
+			name: "update delivery error",
 			setup: func(cRepo *mockCourierRepository, dRepo *mockDeliveryRepository, tm *mockTxManager) {
-				dRepo.deleteFn = func(ctx context.Context, orderId string) (int, error) {
-					return 0, errBoom
+				dRepo.getForUpdateFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+					return &model.DeliveryModel{ID: 1, OrderId: orderId, CourierId: 5, Status: model.DeliveryStatusAssigned}, nil
+				}
+				dRepo.updateStatusFn = func(ctx context.Context, delivery *model.DeliveryModel) error {
+					return errBoom
 				}
 			},
 			expectErr: errBoom,
 		},
 		{
-			name: "update status error",
+			name: "release courier error",
 			setup: func(cRepo *mockCourierRepository, dRepo *mockDeliveryRepository, tm *mockTxManager) {
-				dRepo.deleteFn = func(ctx context.Context, orderId string) (int, error) {
-					return 3, nil
+				dRepo.getForUpdateFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+					return &model.DeliveryModel{ID: 1, OrderId: orderId, CourierId: 5, Status: model.DeliveryStatusPickedUp}, nil
+				}
+				dRepo.updateStatusFn = func(ctx context.Context, delivery *model.DeliveryModel) error {
+					return nil
 				}
-				cRepo.updateStatusFn = func(ctx context.Context, status model.CourierStatus, id int) error {
+				cRepo.markReleasedFn = func(ctx context.Context, id int) error {
 					return errBoom
 				}
 			},
@@ -303,8 +556,9 @@ func TestDeliveryUsecase_Unassign(t *testing.T) {
 
 			tt.setup(cRepo, dRepo, tm)
 
-			uc := NewDeliveryUsecase(cRepo, dRepo, tm, model.NewDeliveryTimeFactory(time.Minute, time.Minute, time.Minute), time.Now)
-			result, err := uc.Unassign(context.Background(), orderID)
+			uc := NewDeliveryUsecase(cRepo, dRepo, tm, model.NewDeliveryTimeFactory(time.Minute, time.Minute, time.Minute), func() time.Time { return now })
+			ctx := model.WithEventTrigger(context.Background(), model.EventTrigger{Source: model.EventSourceHTTP, Actor: "dispatcher-1", Reason: "customer request"})
+			result, err := uc.Unassign(ctx, orderID)
 
 			if tt.expectErr != nil {
 				if err == nil || !errors.Is(err, tt.expectErr) {
@@ -325,30 +579,227 @@ func TestDeliveryUsecase_Unassign(t *testing.T) {
 			if result.CourierId != 5 {
 				t.Fatalf("unexpected courier id: %d", result.CourierId)
 			}
+			if result.Status != model.DeliveryStatusCancelled {
+				t.Fatalf("unexpected status: %s", result.Status)
+			}
+			if len(dRepo.events) != 1 {
+				t.Fatalf("expected 1 event, got %d", len(dRepo.events))
+			}
+			event := dRepo.events[0]
+			if event.Type != model.DeliveryEventUnassigned || event.FromStatus != model.DeliveryStatusAssigned || event.ToStatus != model.DeliveryStatusCancelled {
+				t.Fatalf("unexpected event: %+v", event)
+			}
+			if event.Source != model.EventSourceHTTP || event.Actor != "dispatcher-1" || event.Reason != "customer request" {
+				t.Fatalf("unexpected event trigger: %+v", event)
+			}
+		})
+	}
+}
+
+func TestDeliveryUsecase_Complete(t *testing.T) {
+	t.Parallel()
+
+	orderID := "order-88"
+	now := time.Date(2025, time.December, 22, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		status    model.DeliveryStatus
+		expectErr error
+	}{
+		{name: "from assigned", status: model.DeliveryStatusAssigned},
+		{name: "from in transit", status: model.DeliveryStatusInTransit},
+		{name: "already cancelled", status: model.DeliveryStatusCancelled, expectErr: ErrInvalidStatusTransition},
+		{name: "already expired", status: model.DeliveryStatusExpired, expectErr: ErrInvalidStatusTransition},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cRepo := newMockCourierRepository(t)
+			dRepo := newMockDeliveryRepository(t)
+			tm := newMockTxManager(t)
+
+			dRepo.getForUpdateFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+				return &model.DeliveryModel{ID: 3, OrderId: orderId, CourierId: 9, Status: tt.status}, nil
+			}
+			if tt.expectErr == nil {
+				dRepo.updateStatusFn = func(ctx context.Context, delivery *model.DeliveryModel) error {
+					if delivery.DeliveredAt == nil || !delivery.DeliveredAt.Equal(now) {
+						t.Fatalf("delivered_at must be set")
+					}
+					return nil
+				}
+				cRepo.markReleasedFn = func(ctx context.Context, id int) error {
+					if id != 9 {
+						t.Fatalf("unexpected courier released: %d", id)
+					}
+					return nil
+				}
+			}
+
+			uc := NewDeliveryUsecase(cRepo, dRepo, tm, model.NewDeliveryTimeFactory(time.Minute, time.Minute, time.Minute), func() time.Time { return now })
+			result, err := uc.Complete(context.Background(), orderID)
+
+			if tt.expectErr != nil {
+				if err == nil || !errors.Is(err, tt.expectErr) {
+					t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Status != model.DeliveryStatusDelivered {
+				t.Fatalf("unexpected status: %s", result.Status)
+			}
 		})
 	}
 }
 
-func TestDeliveryUsecase_ProcessExpiredDeliveries(t *testing.T) {
+func TestDeliveryUsecase_PickUp(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.December, 22, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		status    model.DeliveryStatus
+		expectErr error
+	}{
+		{name: "from assigned", status: model.DeliveryStatusAssigned},
+		{name: "picked up twice", status: model.DeliveryStatusPickedUp, expectErr: ErrInvalidStatusTransition},
+		{name: "already delivered", status: model.DeliveryStatusDelivered, expectErr: ErrInvalidStatusTransition},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cRepo := newMockCourierRepository(t)
+			dRepo := newMockDeliveryRepository(t)
+
+			dRepo.getForUpdateFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+				return &model.DeliveryModel{ID: 3, OrderId: orderId, CourierId: 9, Status: tt.status}, nil
+			}
+			dRepo.updateStatusFn = func(ctx context.Context, delivery *model.DeliveryModel) error {
+				if delivery.PickedUpAt == nil || !delivery.PickedUpAt.Equal(now) {
+					t.Fatalf("picked_up_at must be set")
+				}
+				return nil
+			}
+
+			uc := NewDeliveryUsecase(cRepo, dRepo, newMockTxManager(t), model.NewDeliveryTimeFactory(time.Minute, time.Minute, time.Minute), func() time.Time { return now })
+			result, err := uc.PickUp(context.Background(), "order-9")
+
+			if tt.expectErr != nil {
+				if err == nil || !errors.Is(err, tt.expectErr) {
+					t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Status != model.DeliveryStatusPickedUp {
+				t.Fatalf("unexpected status: %s", result.Status)
+			}
+			if len(dRepo.events) != 1 || dRepo.events[0].Type != model.DeliveryEventPickedUp {
+				t.Fatalf("unexpected events: %+v", dRepo.events)
+			}
+		})
+	}
+}
+
+func TestDeliveryUsecase_StartTransit(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.December, 22, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		status    model.DeliveryStatus
+		expectErr error
+	}{
+		{name: "from picked up", status: model.DeliveryStatusPickedUp},
+		{name: "from assigned", status: model.DeliveryStatusAssigned},
+		{name: "in transit twice", status: model.DeliveryStatusInTransit, expectErr: ErrInvalidStatusTransition},
+		{name: "already delivered", status: model.DeliveryStatusDelivered, expectErr: ErrInvalidStatusTransition},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cRepo := newMockCourierRepository(t)
+			dRepo := newMockDeliveryRepository(t)
+
+			dRepo.getForUpdateFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+				return &model.DeliveryModel{ID: 3, OrderId: orderId, CourierId: 9, Status: tt.status}, nil
+			}
+			dRepo.updateStatusFn = func(ctx context.Context, delivery *model.DeliveryModel) error {
+				if delivery.InTransitAt == nil || !delivery.InTransitAt.Equal(now) {
+					t.Fatalf("in_transit_at must be set")
+				}
+				return nil
+			}
+
+			uc := NewDeliveryUsecase(cRepo, dRepo, newMockTxManager(t), model.NewDeliveryTimeFactory(time.Minute, time.Minute, time.Minute), func() time.Time { return now })
+			result, err := uc.StartTransit(context.Background(), "order-9")
+
+			if tt.expectErr != nil {
+				if err == nil || !errors.Is(err, tt.expectErr) {
+					t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Status != model.DeliveryStatusInTransit {
+				t.Fatalf("unexpected status: %s", result.Status)
+			}
+			if len(dRepo.events) != 1 || dRepo.events[0].Type != model.DeliveryEventInTransit {
+				t.Fatalf("unexpected events: %+v", dRepo.events)
+			}
+		})
+	}
+}
+
+func TestDeliveryUsecase_History(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
 		name        string
-		releaseFn   func(ctx context.Context) (int, error)
+		getEventsFn func(ctx context.Context, orderId string) ([]*model.DeliveryEvent, error)
 		expectCount int
 		expectErr   error
 	}{
 		{
 			name: "success",
-			releaseFn: func(ctx context.Context) (int, error) {
-				return 3, nil
+			getEventsFn: func(ctx context.Context, orderId string) ([]*model.DeliveryEvent, error) {
+				return []*model.DeliveryEvent{
+					{ID: 1, OrderID: orderId, Type: model.DeliveryEventAssigned},
+					{ID: 2, OrderID: orderId, Type: model.DeliveryEventCompleted},
+				}, nil
 			},
-			expectCount: 3,
+			expectCount: 2,
 		},
 		{
-			name: "release error",
-			releaseFn: func(ctx context.Context) (int, error) {
-				return 0, errBoom
+			name: "no events",
+			getEventsFn: func(ctx context.Context, orderId string) ([]*model.DeliveryEvent, error) {
+				return []*model.DeliveryEvent{}, nil
+			},
+			expectErr: repoerrors.ErrDeliveryNotFound,
+		},
+		{
+			name: "repository error",
+			getEventsFn: func(ctx context.Context, orderId string) ([]*model.DeliveryEvent, error) {
+				return nil, errBoom
 			},
 			expectErr: errBoom,
 		},
@@ -358,14 +809,11 @@ func TestDeliveryUsecase_ProcessExpiredDeliveries(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			cRepo := newMockCourierRepository(t)
 			dRepo := newMockDeliveryRepository(t)
-			m := newMockTxManager(t)
-
-			dRepo.releaseExpiredFn = tt.releaseFn
+			dRepo.getEventsFn = tt.getEventsFn
 
-			uc := NewDeliveryUsecase(cRepo, dRepo, m, model.NewDeliveryTimeFactory(time.Minute, time.Minute, time.Minute), time.Now)
-			count, err := uc.ProcessExpiredDeliveries(context.Background())
+			uc := NewDeliveryUsecase(newMockCourierRepository(t), dRepo, newMockTxManager(t), model.NewDeliveryTimeFactory(time.Minute, time.Minute, time.Minute), time.Now)
+			events, err := uc.History(context.Background(), "order-1")
 
 			if tt.expectErr != nil {
 				if err == nil || !errors.Is(err, tt.expectErr) {
@@ -377,8 +825,8 @@ func TestDeliveryUsecase_ProcessExpiredDeliveries(t *testing.T) {
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
-			if count != tt.expectCount {
-				t.Fatalf("expected count %d, got %d", tt.expectCount, count)
+			if len(events) != tt.expectCount {
+				t.Fatalf("expected %d events, got %d", tt.expectCount, len(events))
 			}
 		})
 	}
@@ -0,0 +1,140 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+	repoerrors "github.com/cdxy1/go-courier-service/internal/repository/delivery"
+)
+
+func TestDeliveryUsecase_AssignEligibility(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.December, 22, 10, 30, 0, 0, time.UTC)
+	factory := model.NewDeliveryTimeFactory(time.Minute*30, time.Minute*15, time.Minute*5)
+	rules := model.EligibilityRules{
+		{Name: "catering", MinItems: 10, Transports: []model.TransportType{model.TransportCar}},
+		{Name: "heavy", MinWeightGrams: 5000, Transports: []model.TransportType{model.TransportScooter, model.TransportCar}},
+		{Name: "fragile", MinTotalPrice: 50000, MinQuantity: 3, Transports: []model.TransportType{model.TransportOnFoot}},
+	}
+
+	tests := []struct {
+		name           string
+		order          *model.Order
+		wantTransports []model.TransportType
+		expectErr      error
+	}{
+		{
+			name:  "no rule matches",
+			order: &model.Order{ID: "order-1", Items: []model.Item{{Quantity: 2}}, TotalPrice: 1200},
+		},
+		{
+			name:  "order known by id only",
+			order: &model.Order{ID: "order-1"},
+		},
+		{
+			name:           "item count",
+			order:          &model.Order{ID: "order-1", Items: make([]model.Item, 12)},
+			wantTransports: []model.TransportType{model.TransportCar},
+		},
+		{
+			name:           "declared weight",
+			order:          &model.Order{ID: "order-1", Items: make([]model.Item, 1), WeightGrams: 8000},
+			wantTransports: []model.TransportType{model.TransportScooter, model.TransportCar},
+		},
+		{
+			name:           "every threshold of a rule must be reached",
+			order:          &model.Order{ID: "order-1", Items: []model.Item{{Quantity: 2}}, TotalPrice: 60000},
+			wantTransports: nil,
+		},
+		{
+			name:           "matching rules are intersected",
+			order:          &model.Order{ID: "order-1", Items: make([]model.Item, 10), WeightGrams: 6000},
+			wantTransports: []model.TransportType{model.TransportCar},
+		},
+		{
+			name:      "no transport left",
+			order:     &model.Order{ID: "order-1", Items: []model.Item{{Quantity: 3}}, TotalPrice: 50000, WeightGrams: 5000},
+			expectErr: ErrNoEligibleTransport,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cRepo := newMockCourierRepository(t)
+			dRepo := newMockDeliveryRepository(t)
+			courier := &model.CourierModel{ID: 1, Status: model.CourierStatusAvailable, TransportType: model.TransportCar}
+			cRepo.listAvailFn = func(ctx context.Context, filter model.AvailableCourierFilter) ([]*model.CourierCandidate, error) {
+				if !reflect.DeepEqual(filter.Transports, tt.wantTransports) {
+					t.Fatalf("expected transports %v, got %v", tt.wantTransports, filter.Transports)
+				}
+				return []*model.CourierCandidate{{Courier: courier}}, nil
+			}
+			cRepo.skipLockedFn = func(ctx context.Context, id int) (*model.CourierModel, error) {
+				return courier, nil
+			}
+			cRepo.markAssignedFn = func(ctx context.Context, id int) error { return nil }
+			dRepo.getByOrderIDFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+				return nil, repoerrors.ErrDeliveryNotFound
+			}
+			dRepo.createFn = func(ctx context.Context, delivery *model.DeliveryModel) error {
+				if delivery.Order == nil && len(tt.order.Items) > 0 {
+					t.Fatalf("expected order details to be stored")
+				}
+				return nil
+			}
+
+			uc := NewDeliveryUsecase(cRepo, dRepo, newMockTxManager(t), factory, func() time.Time { return now },
+				WithEligibilityRules(rules))
+
+			_, _, err := uc.Assign(context.Background(), tt.order)
+			if tt.expectErr != nil {
+				if !errors.Is(err, tt.expectErr) {
+					t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestDeliveryUsecase_AssignCourierTransportNotAllowed(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.December, 22, 10, 30, 0, 0, time.UTC)
+	factory := model.NewDeliveryTimeFactory(time.Minute*30, time.Minute*15, time.Minute*5)
+	rules := model.EligibilityRules{
+		{Name: "catering", MinItems: 10, Transports: []model.TransportType{model.TransportCar}},
+	}
+
+	cRepo := newMockCourierRepository(t)
+	dRepo := newMockDeliveryRepository(t)
+	cRepo.getForUpdateFn = func(ctx context.Context, id int) (*model.CourierModel, error) {
+		return &model.CourierModel{ID: id, Status: model.CourierStatusAvailable, TransportType: model.TransportOnFoot}, nil
+	}
+	dRepo.getByOrderIDFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+		return nil, repoerrors.ErrDeliveryNotFound
+	}
+
+	uc := NewDeliveryUsecase(cRepo, dRepo, newMockTxManager(t), factory, func() time.Time { return now },
+		WithEligibilityRules(rules))
+
+	_, _, err := uc.AssignCourier(context.Background(), model.AssignCourierRequest{
+		OrderID:   "order-1",
+		CourierID: 3,
+		Order:     &model.OrderDetails{ItemCount: 12, Quantity: 12},
+	})
+	if !errors.Is(err, ErrTransportNotAllowed) {
+		t.Fatalf("expected error %v, got %v", ErrTransportNotAllowed, err)
+	}
+}
@@ -0,0 +1,16 @@
+package delivery
+
+import "errors"
+
+var (
+	ErrInvalidStatusTransition = errors.New("invalid delivery status transition")
+	ErrInvalidFilter           = errors.New("invalid delivery filter")
+	ErrCourierUnavailable      = errors.New("courier is not available")
+	ErrSameCourier             = errors.New("delivery is already assigned to this courier")
+	ErrOrderAlreadyAssigned    = errors.New("order is already assigned to another courier")
+	ErrIdempotencyKeyReused    = errors.New("idempotency key was used for another order")
+	ErrAssignmentQueued        = errors.New("no courier available, order queued for assignment")
+	ErrNoCourierAvailable      = errors.New("no other courier is available")
+	ErrNoEligibleTransport     = errors.New("no transport is allowed to carry the order")
+	ErrTransportNotAllowed     = errors.New("courier transport is not allowed to carry the order")
+)
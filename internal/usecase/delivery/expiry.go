@@ -0,0 +1,98 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+	courierRepo "github.com/cdxy1/go-courier-service/internal/repository/courier"
+)
+
+// ProcessExpiredDeliveries marks every active delivery past its deadline as expired,
+// frees its courier and then applies the configured expired policy to the order.
+// The returned slice lists every affected order and what was done with it.
+func (uc *DeliveryUsecase) ProcessExpiredDeliveries(ctx context.Context) ([]*model.ExpiredDelivery, error) {
+	var result []*model.ExpiredDelivery
+	err := uc.tm.WithTx(ctx, func(ctx context.Context) error {
+		now := uc.now()
+		overdue, err := uc.deliveryRepo.GetOverdueForUpdate(ctx, now)
+		if err != nil {
+			return fmt.Errorf("get overdue deliveries: %w", err)
+		}
+		if len(overdue) == 0 {
+			return nil
+		}
+
+		expired := make([]*model.ExpiredDelivery, 0, len(overdue))
+		for _, d := range overdue {
+			from := d.Status
+			if err := transition(d, model.DeliveryStatusExpired, now); err != nil {
+				return err
+			}
+			if err := uc.deliveryRepo.UpdateStatus(ctx, d); err != nil {
+				return fmt.Errorf("expire delivery: %w", err)
+			}
+			if err := uc.recordEvent(ctx, model.DeliveryEventExpired, d, from, reasonDeadlineExceeded); err != nil {
+				return err
+			}
+			if err := uc.courierRepo.MarkReleased(ctx, d.CourierId); err != nil {
+				return fmt.Errorf("release expired courier: %w", err)
+			}
+
+			item, err := uc.applyExpiredPolicy(ctx, d)
+			if err != nil {
+				return err
+			}
+			expired = append(expired, item)
+		}
+
+		result = expired
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(result) > 0 {
+		uc.notifyReleased()
+	}
+	return result, nil
+}
+
+// applyExpiredPolicy decides the follow-up for an expired delivery. When the
+// reassign policy finds no free or eligible courier the order is escalated instead.
+func (uc *DeliveryUsecase) applyExpiredPolicy(ctx context.Context, d *model.DeliveryModel) (*model.ExpiredDelivery, error) {
+	item := &model.ExpiredDelivery{
+		OrderID:    d.OrderId,
+		DeliveryID: d.ID,
+		CourierID:  d.CourierId,
+		Action:     model.ExpiredActionFlagged,
+	}
+
+	switch uc.expiredPolicy {
+	case model.ExpiredPolicyEscalate:
+		item.Action = model.ExpiredActionEscalated
+	case model.ExpiredPolicyReassign:
+		req := model.AssignCourierRequest{
+			OrderID:    d.OrderId,
+			Pickup:     d.Pickup,
+			Dropoff:    d.Dropoff,
+			Order:      d.Order,
+			PromisedBy: d.PromisedBy,
+		}
+		courier, err := uc.pickCourier(ctx, req, []int{d.CourierId})
+		if errors.Is(err, courierRepo.ErrCourierNotFound) || errors.Is(err, ErrNoEligibleTransport) {
+			item.Action = model.ExpiredActionEscalated
+			return item, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, err := uc.createDelivery(ctx, req, courier, reasonDeadlineExceeded); err != nil {
+			return nil, err
+		}
+		item.Action = model.ExpiredActionReassigned
+		item.NewCourierID = courier.ID
+	}
+	return item, nil
+}
@@ -0,0 +1,166 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+	courierrepo "github.com/cdxy1/go-courier-service/internal/repository/courier"
+)
+
+func TestDeliveryUsecase_ProcessExpiredDeliveries(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.December, 22, 10, 0, 0, 0, time.UTC)
+	overdue := func(ctx context.Context, now time.Time) ([]*model.DeliveryModel, error) {
+		return []*model.DeliveryModel{
+			{ID: 1, OrderId: "order-1", CourierId: 4, Status: model.DeliveryStatusAssigned},
+			{ID: 2, OrderId: "order-2", CourierId: 6, Status: model.DeliveryStatusInTransit},
+		}, nil
+	}
+
+	tests := []struct {
+		name          string
+		policy        model.ExpiredPolicy
+		overdueFn     func(ctx context.Context, now time.Time) ([]*model.DeliveryModel, error)
+		availableFn   func(excludeIds []int) (*model.CourierModel, error)
+		releaseErr    error
+		expectActions []model.ExpiredAction
+		expectErr     error
+	}{
+		{
+			name:          "flag",
+			policy:        model.ExpiredPolicyFlag,
+			overdueFn:     overdue,
+			expectActions: []model.ExpiredAction{model.ExpiredActionFlagged, model.ExpiredActionFlagged},
+		},
+		{
+			name:          "unknown policy falls back to flag",
+			policy:        "drop",
+			overdueFn:     overdue,
+			expectActions: []model.ExpiredAction{model.ExpiredActionFlagged, model.ExpiredActionFlagged},
+		},
+		{
+			name:          "escalate",
+			policy:        model.ExpiredPolicyEscalate,
+			overdueFn:     overdue,
+			expectActions: []model.ExpiredAction{model.ExpiredActionEscalated, model.ExpiredActionEscalated},
+		},
+		{
+			name:      "reassign",
+			policy:    model.ExpiredPolicyReassign,
+			overdueFn: overdue,
+			availableFn: func(excludeIds []int) (*model.CourierModel, error) {
+				if len(excludeIds) != 1 || (excludeIds[0] != 4 && excludeIds[0] != 6) {
+					t.Fatalf("expired courier must be excluded, got %v", excludeIds)
+				}
+				if excludeIds[0] == 6 {
+					return nil, courierrepo.ErrCourierNotFound
+				}
+				return &model.CourierModel{ID: 9, Status: model.CourierStatusAvailable, TransportType: model.TransportCar}, nil
+			},
+			expectActions: []model.ExpiredAction{model.ExpiredActionReassigned, model.ExpiredActionEscalated},
+		},
+		{
+			name:   "reassign error",
+			policy: model.ExpiredPolicyReassign,
+			overdueFn: func(ctx context.Context, now time.Time) ([]*model.DeliveryModel, error) {
+				return []*model.DeliveryModel{{ID: 1, OrderId: "order-1", CourierId: 4, Status: model.DeliveryStatusAssigned}}, nil
+			},
+			availableFn: func(excludeIds []int) (*model.CourierModel, error) {
+				return nil, errBoom
+			},
+			expectErr: errBoom,
+		},
+		{
+			name:   "nothing overdue",
+			policy: model.ExpiredPolicyFlag,
+			overdueFn: func(ctx context.Context, now time.Time) ([]*model.DeliveryModel, error) {
+				return nil, nil
+			},
+		},
+		{
+			name:   "overdue error",
+			policy: model.ExpiredPolicyFlag,
+			overdueFn: func(ctx context.Context, now time.Time) ([]*model.DeliveryModel, error) {
+				return nil, errBoom
+			},
+			expectErr: errBoom,
+		},
+		{
+			name:       "release error",
+			policy:     model.ExpiredPolicyFlag,
+			overdueFn:  overdue,
+			releaseErr: errBoom,
+			expectErr:  errBoom,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cRepo := newMockCourierRepository(t)
+			dRepo := newMockDeliveryRepository(t)
+			m := newMockTxManager(t)
+
+			var created []*model.DeliveryModel
+			dRepo.getOverdueFn = tt.overdueFn
+			var released []int
+			cRepo.markReleasedFn = func(ctx context.Context, id int) error {
+				released = append(released, id)
+				return tt.releaseErr
+			}
+			dRepo.updateStatusFn = func(ctx context.Context, delivery *model.DeliveryModel) error {
+				if delivery.Status != model.DeliveryStatusExpired || delivery.ExpiredAt == nil {
+					t.Fatalf("delivery must be expired: %+v", delivery)
+				}
+				return nil
+			}
+			dRepo.createFn = func(ctx context.Context, delivery *model.DeliveryModel) error {
+				created = append(created, delivery)
+				return nil
+			}
+			if tt.availableFn != nil {
+				cRepo.withAvailable(tt.availableFn)
+			}
+			cRepo.markAssignedFn = func(ctx context.Context, id int) error { return nil }
+
+			uc := NewDeliveryUsecase(cRepo, dRepo, m, model.NewDeliveryTimeFactory(time.Minute, time.Minute, time.Minute),
+				func() time.Time { return now }, WithExpiredPolicy(tt.policy))
+			expired, err := uc.ProcessExpiredDeliveries(context.Background())
+
+			if tt.expectErr != nil {
+				if err == nil || !errors.Is(err, tt.expectErr) {
+					t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(released) != len(expired) {
+				t.Fatalf("every expired courier must be released, got %v", released)
+			}
+			if len(expired) != len(tt.expectActions) {
+				t.Fatalf("expected %d expired deliveries, got %d", len(tt.expectActions), len(expired))
+			}
+			for i, action := range tt.expectActions {
+				if expired[i].Action != action {
+					t.Fatalf("unexpected action for %s: %s", expired[i].OrderID, expired[i].Action)
+				}
+			}
+			if tt.policy == model.ExpiredPolicyReassign {
+				if len(created) != 1 || created[0].OrderId != "order-1" || created[0].CourierId != 9 {
+					t.Fatalf("unexpected reassigned deliveries: %+v", created)
+				}
+				if expired[0].NewCourierID != 9 {
+					t.Fatalf("unexpected new courier: %d", expired[0].NewCourierID)
+				}
+			}
+		})
+	}
+}
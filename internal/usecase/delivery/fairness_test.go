@@ -0,0 +1,105 @@
+package delivery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+	repoerrors "github.com/cdxy1/go-courier-service/internal/repository/delivery"
+)
+
+func TestDeliveryUsecase_FairnessWindow(t *testing.T) {
+	t.Parallel()
+
+	morning := time.Date(2025, time.December, 22, 10, 30, 0, 0, time.UTC)
+	night := time.Date(2025, time.December, 22, 5, 0, 0, 0, time.UTC)
+	shifts, err := model.ParseShiftStarts("20:00, 08:00")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := model.ParseShiftStarts("8am"); err == nil {
+		t.Fatalf("expected error for invalid shift start")
+	}
+
+	tests := []struct {
+		name         string
+		opts         []Option
+		now          time.Time
+		wantSince    time.Time
+		wantByShift  bool
+		wantHalfLife time.Duration
+	}{
+		{
+			name:      "last 24 hours by default",
+			now:       morning,
+			wantSince: morning.Add(-24 * time.Hour),
+		},
+		{
+			name:      "rolling period",
+			opts:      []Option{WithFairnessWindow(model.NewFairnessWindow(model.FairnessRolling, 8*time.Hour, 0, nil))},
+			now:       morning,
+			wantSince: morning.Add(-8 * time.Hour),
+		},
+		{
+			name:        "current shift",
+			opts:        []Option{WithFairnessWindow(model.NewFairnessWindow(model.FairnessShift, 0, 0, shifts))},
+			now:         morning,
+			wantSince:   time.Date(2025, time.December, 22, 8, 0, 0, 0, time.UTC),
+			wantByShift: true,
+		},
+		{
+			name:        "shift started yesterday",
+			opts:        []Option{WithFairnessWindow(model.NewFairnessWindow(model.FairnessShift, 0, 0, shifts))},
+			now:         night,
+			wantSince:   time.Date(2025, time.December, 21, 20, 0, 0, 0, time.UTC),
+			wantByShift: true,
+		},
+		{
+			name:        "shift from midnight",
+			opts:        []Option{WithFairnessWindow(model.NewFairnessWindow(model.FairnessShift, 0, 0, nil))},
+			now:         morning,
+			wantSince:   time.Date(2025, time.December, 22, 0, 0, 0, 0, time.UTC),
+			wantByShift: true,
+		},
+		{
+			name:         "weighted decay",
+			opts:         []Option{WithFairnessWindow(model.NewFairnessWindow(model.FairnessDecay, 12*time.Hour, 2*time.Hour, nil))},
+			now:          morning,
+			wantSince:    morning.Add(-12 * time.Hour),
+			wantHalfLife: 2 * time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cRepo := newMockCourierRepository(t)
+			dRepo := newMockDeliveryRepository(t)
+			courier := &model.CourierModel{ID: 1, Status: model.CourierStatusAvailable, TransportType: model.TransportCar}
+			cRepo.listAvailFn = func(ctx context.Context, filter model.AvailableCourierFilter) ([]*model.CourierCandidate, error) {
+				if !filter.LoadSince.Equal(tt.wantSince) || filter.LoadByShift != tt.wantByShift ||
+					filter.LoadHalfLife != tt.wantHalfLife || !filter.Now.Equal(tt.now) {
+					t.Fatalf("unexpected load window: since %s, by shift %v, half-life %s, now %s",
+						filter.LoadSince, filter.LoadByShift, filter.LoadHalfLife, filter.Now)
+				}
+				return []*model.CourierCandidate{{Courier: courier}}, nil
+			}
+			cRepo.skipLockedFn = func(ctx context.Context, id int) (*model.CourierModel, error) { return courier, nil }
+			cRepo.markAssignedFn = func(ctx context.Context, id int) error { return nil }
+			dRepo.getByOrderIDFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+				return nil, repoerrors.ErrDeliveryNotFound
+			}
+			dRepo.createFn = func(ctx context.Context, delivery *model.DeliveryModel) error { return nil }
+
+			factory := model.NewDeliveryTimeFactory(time.Minute*30, time.Minute*15, time.Minute*5)
+			uc := NewDeliveryUsecase(cRepo, dRepo, newMockTxManager(t), factory, func() time.Time { return tt.now }, tt.opts...)
+
+			if _, _, err := uc.AssignCourier(context.Background(), model.AssignCourierRequest{OrderID: "order-1"}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
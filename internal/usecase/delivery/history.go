@@ -0,0 +1,55 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+	repo "github.com/cdxy1/go-courier-service/internal/repository/delivery"
+)
+
+const reasonDeadlineExceeded = "deadline exceeded"
+
+// recordEvent appends an entry to the delivery history. It must be called inside
+// the transaction that changed the delivery so both are committed together.
+// A non-empty reason overrides the one carried by the context trigger.
+func (uc *DeliveryUsecase) recordEvent(
+	ctx context.Context,
+	eventType model.DeliveryEventType,
+	delivery *model.DeliveryModel,
+	from model.DeliveryStatus,
+	reason string,
+) error {
+	trigger := model.EventTriggerFromContext(ctx)
+	if reason == "" {
+		reason = trigger.Reason
+	}
+
+	event := &model.DeliveryEvent{
+		DeliveryID: delivery.ID,
+		OrderID:    delivery.OrderId,
+		CourierID:  delivery.CourierId,
+		Type:       eventType,
+		FromStatus: from,
+		ToStatus:   delivery.Status,
+		Source:     trigger.Source,
+		Actor:      trigger.Actor,
+		Reason:     reason,
+		CreatedAt:  delivery.UpdatedAt,
+	}
+	if err := uc.deliveryRepo.CreateEvent(ctx, event); err != nil {
+		return fmt.Errorf("record delivery event: %w", err)
+	}
+	return nil
+}
+
+func (uc *DeliveryUsecase) History(ctx context.Context, orderId string) ([]*model.DeliveryEvent, error) {
+	events, err := uc.deliveryRepo.GetEventsByOrderID(ctx, orderId)
+	if err != nil {
+		return nil, fmt.Errorf("get delivery events: %w", err)
+	}
+	if len(events) == 0 {
+		return nil, repo.ErrDeliveryNotFound
+	}
+	return events, nil
+}
@@ -0,0 +1,61 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+	repo "github.com/cdxy1/go-courier-service/internal/repository/delivery"
+)
+
+// findAssignment returns the delivery a repeated assign request should get back,
+// or nil when a new delivery has to be created. A delivery created with the same
+// idempotency key wins; otherwise the active delivery of the order is used.
+func (uc *DeliveryUsecase) findAssignment(ctx context.Context, req model.AssignCourierRequest) (*model.DeliveryModel, error) {
+	if req.IdempotencyKey != "" {
+		d, err := uc.deliveryRepo.GetByIdempotencyKey(ctx, req.IdempotencyKey)
+		if err != nil && !errors.Is(err, repo.ErrDeliveryNotFound) {
+			return nil, fmt.Errorf("get delivery by idempotency key: %w", err)
+		}
+		if d != nil {
+			if d.OrderId != req.OrderID {
+				return nil, fmt.Errorf("%w: %s", ErrIdempotencyKeyReused, d.OrderId)
+			}
+			return d, nil
+		}
+	}
+
+	d, err := uc.deliveryRepo.GetByOrderID(ctx, req.OrderID)
+	if err != nil {
+		if errors.Is(err, repo.ErrDeliveryNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get delivery: %w", err)
+	}
+	if !d.Status.IsActive() {
+		return nil, nil
+	}
+	if req.CourierID != 0 && d.CourierId != req.CourierID {
+		return nil, fmt.Errorf("%w: courier %d", ErrOrderAlreadyAssigned, d.CourierId)
+	}
+	return d, nil
+}
+
+// existingAssignment loads the assignment that made the insert of a new delivery
+// violate one of the uniqueness guarantees.
+func (uc *DeliveryUsecase) existingAssignment(ctx context.Context, req model.AssignCourierRequest) (*model.DeliveryModel, *model.CourierModel, error) {
+	d, err := uc.findAssignment(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	if d == nil {
+		return nil, nil, fmt.Errorf("get delivery: %w", repo.ErrDeliveryNotFound)
+	}
+
+	courier, err := uc.courierRepo.GetOneById(ctx, d.CourierId)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get courier: %w", err)
+	}
+	return d, courier, nil
+}
@@ -0,0 +1,152 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+	repoerrors "github.com/cdxy1/go-courier-service/internal/repository/delivery"
+)
+
+func TestDeliveryUsecase_AssignCourierIdempotency(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.December, 22, 10, 0, 0, 0, time.UTC)
+	factory := model.NewDeliveryTimeFactory(time.Minute*30, time.Minute*15, time.Minute*5)
+	active := &model.DeliveryModel{ID: 5, OrderId: "order-5", CourierId: 3, Status: model.DeliveryStatusPickedUp}
+
+	tests := []struct {
+		name         string
+		req          model.AssignCourierRequest
+		byKey        *model.DeliveryModel
+		latest       *model.DeliveryModel
+		createErr    error
+		expectCreate bool
+		expectID     int
+		expectErr    error
+	}{
+		{
+			name:     "active delivery is returned",
+			req:      model.AssignCourierRequest{OrderID: "order-5"},
+			latest:   active,
+			expectID: 5,
+		},
+		{
+			name:     "same courier requested again",
+			req:      model.AssignCourierRequest{OrderID: "order-5", CourierID: 3},
+			latest:   active,
+			expectID: 5,
+		},
+		{
+			name:      "another courier requested",
+			req:       model.AssignCourierRequest{OrderID: "order-5", CourierID: 8},
+			latest:    active,
+			expectErr: ErrOrderAlreadyAssigned,
+		},
+		{
+			name:         "finished delivery is not reused",
+			req:          model.AssignCourierRequest{OrderID: "order-5"},
+			latest:       &model.DeliveryModel{ID: 4, OrderId: "order-5", CourierId: 3, Status: model.DeliveryStatusCancelled},
+			expectCreate: true,
+			expectID:     10,
+		},
+		{
+			name:     "idempotency key replay",
+			req:      model.AssignCourierRequest{OrderID: "order-5", IdempotencyKey: "key-1"},
+			byKey:    &model.DeliveryModel{ID: 4, OrderId: "order-5", CourierId: 3, Status: model.DeliveryStatusDelivered},
+			expectID: 4,
+		},
+		{
+			name:      "idempotency key reused for another order",
+			req:       model.AssignCourierRequest{OrderID: "order-5", IdempotencyKey: "key-1"},
+			byKey:     &model.DeliveryModel{ID: 2, OrderId: "order-2", CourierId: 3, Status: model.DeliveryStatusAssigned},
+			expectErr: ErrIdempotencyKeyReused,
+		},
+		{
+			name:         "new key creates delivery",
+			req:          model.AssignCourierRequest{OrderID: "order-5", IdempotencyKey: "key-2"},
+			expectCreate: true,
+			expectID:     10,
+		},
+		{
+			name:         "concurrent assignment wins",
+			req:          model.AssignCourierRequest{OrderID: "order-5"},
+			createErr:    repoerrors.ErrActiveDeliveryExists,
+			expectCreate: true,
+			expectID:     5,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cRepo := newMockCourierRepository(t)
+			dRepo := newMockDeliveryRepository(t)
+
+			created := false
+			dRepo.getByKeyFn = func(ctx context.Context, key string) (*model.DeliveryModel, error) {
+				if key != tt.req.IdempotencyKey {
+					t.Fatalf("unexpected key: %s", key)
+				}
+				if tt.byKey == nil {
+					return nil, repoerrors.ErrDeliveryNotFound
+				}
+				return tt.byKey, nil
+			}
+			dRepo.getByOrderIDFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+				if created && tt.createErr != nil {
+					// the concurrent request committed its delivery meanwhile
+					return active, nil
+				}
+				if tt.latest == nil {
+					return nil, repoerrors.ErrDeliveryNotFound
+				}
+				return tt.latest, nil
+			}
+			dRepo.createFn = func(ctx context.Context, delivery *model.DeliveryModel) error {
+				created = true
+				if delivery.IdempotencyKey != tt.req.IdempotencyKey {
+					t.Fatalf("unexpected idempotency key: %q", delivery.IdempotencyKey)
+				}
+				delivery.ID = 10
+				return tt.createErr
+			}
+			cRepo.withAvailable(func(excludeIds []int) (*model.CourierModel, error) {
+				return &model.CourierModel{ID: 7, Status: model.CourierStatusAvailable, TransportType: model.TransportCar}, nil
+			})
+			cRepo.getForUpdateFn = func(ctx context.Context, id int) (*model.CourierModel, error) {
+				return &model.CourierModel{ID: id, Status: model.CourierStatusAvailable, TransportType: model.TransportCar}, nil
+			}
+			cRepo.getOneByIDFn = func(ctx context.Context, id int) (*model.CourierModel, error) {
+				return &model.CourierModel{ID: id, TransportType: model.TransportCar}, nil
+			}
+			cRepo.markAssignedFn = func(ctx context.Context, id int) error { return nil }
+
+			uc := NewDeliveryUsecase(cRepo, dRepo, newMockTxManager(t), factory, func() time.Time { return now })
+			delivery, courier, err := uc.AssignCourier(context.Background(), tt.req)
+
+			if created != tt.expectCreate {
+				t.Fatalf("expected create %v, got %v", tt.expectCreate, created)
+			}
+			if tt.expectErr != nil {
+				if err == nil || !errors.Is(err, tt.expectErr) {
+					t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if delivery.ID != tt.expectID {
+				t.Fatalf("expected delivery %d, got %d", tt.expectID, delivery.ID)
+			}
+			if courier == nil || courier.ID != delivery.CourierId {
+				t.Fatalf("unexpected courier: %+v", courier)
+			}
+		})
+	}
+}
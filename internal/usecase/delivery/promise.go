@@ -0,0 +1,94 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+)
+
+// preferOnTime moves the candidates whose transport can keep the customer
+// promise ahead of those that would miss it. The ranking is kept within both
+// groups and late couriers are still offered the order.
+func (uc *DeliveryUsecase) preferOnTime(
+	ctx context.Context,
+	req model.AssignCourierRequest,
+	ranked []*model.CourierCandidate,
+) ([]*model.CourierCandidate, error) {
+	delivery, err := uc.deliveryContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	now := uc.now()
+
+	inTime := make(map[model.TransportType]bool)
+	var onTime, late []*model.CourierCandidate
+	for _, c := range ranked {
+		transport := c.Courier.TransportType
+		ok, known := inTime[transport]
+		if !known {
+			delivery.Transport = transport
+			ok = !uc.timeFactory.For(delivery).Deadline(now).After(*req.PromisedBy)
+			inTime[transport] = ok
+		}
+		if ok {
+			onTime = append(onTime, c)
+		} else {
+			late = append(late, c)
+		}
+	}
+	return append(onTime, late...), nil
+}
+
+// deliveryContext describes the order for deadline policies. The transport is
+// left for the caller to fill in.
+func (uc *DeliveryUsecase) deliveryContext(ctx context.Context, req model.AssignCourierRequest) (model.DeliveryContext, error) {
+	delivery := model.DeliveryContext{
+		Pickup:  req.Pickup,
+		Dropoff: req.Dropoff,
+		Order:   req.Order,
+	}
+	if uc.zones != nil && uc.timeFactory.UsesZones() {
+		zone, _, err := uc.orderZone(ctx, req)
+		if err != nil {
+			return delivery, err
+		}
+		if zone != nil {
+			delivery.ZoneID = &zone.ID
+		}
+	}
+	return delivery, nil
+}
+
+// missesPromise reports whether the delivery is at risk, i.e. the policy
+// deadline allows more time than the promise leaves. The deadline itself is
+// kept, so the delivery only expires once the policy deadline has passed.
+func missesPromise(deadline time.Time, promisedBy *time.Time) bool {
+	return promisedBy != nil && deadline.After(*promisedBy)
+}
+
+// dueBy returns when the delivery is expected to be done: the promised time
+// when it comes before the deadline and has not passed yet, the deadline
+// otherwise.
+func dueBy(deadline time.Time, promisedBy *time.Time, now time.Time) time.Time {
+	if missesPromise(deadline, promisedBy) && promisedBy.After(now) {
+		return *promisedBy
+	}
+	return deadline
+}
+
+// CountAtRisk returns the number of active deliveries flagged as likely to
+// miss the customer promise.
+func (uc *DeliveryUsecase) CountAtRisk(ctx context.Context) (int, error) {
+	_, total, err := uc.deliveryRepo.List(ctx, model.DeliveryFilter{
+		Statuses:   model.ActiveDeliveryStatuses,
+		AtRiskOnly: true,
+		Now:        uc.now(),
+		Limit:      1,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("count at risk deliveries: %w", err)
+	}
+	return total, nil
+}
@@ -0,0 +1,168 @@
+package delivery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+	courierrepo "github.com/cdxy1/go-courier-service/internal/repository/courier"
+	repoerrors "github.com/cdxy1/go-courier-service/internal/repository/delivery"
+)
+
+func TestDeliveryUsecase_AssignPromise(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.December, 22, 10, 30, 0, 0, time.UTC)
+	promise := func(d time.Duration) *time.Time {
+		at := now.Add(d)
+		return &at
+	}
+
+	// The on foot courier ranks first with fewer assignments today.
+	onFoot := &model.CourierModel{ID: 1, Status: model.CourierStatusAvailable, TransportType: model.TransportOnFoot}
+	car := &model.CourierModel{ID: 2, Status: model.CourierStatusAvailable, TransportType: model.TransportCar}
+
+	tests := []struct {
+		name        string
+		promisedBy  *time.Time
+		expectID    int
+		expectIn    time.Duration
+		expectRisky bool
+	}{
+		{
+			name:     "no promise",
+			expectID: 1,
+			expectIn: 30 * time.Minute,
+		},
+		{
+			name:       "promise kept by every transport",
+			promisedBy: promise(time.Hour),
+			expectID:   1,
+			expectIn:   30 * time.Minute,
+		},
+		{
+			name:       "faster transport preferred",
+			promisedBy: promise(20 * time.Minute),
+			expectID:   2,
+			expectIn:   5 * time.Minute,
+		},
+		{
+			name:        "deadline kept beyond promise",
+			promisedBy:  promise(3 * time.Minute),
+			expectID:    1,
+			expectIn:    30 * time.Minute,
+			expectRisky: true,
+		},
+		{
+			name:        "promise already missed",
+			promisedBy:  promise(-time.Minute),
+			expectID:    1,
+			expectIn:    30 * time.Minute,
+			expectRisky: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cRepo := newMockCourierRepository(t)
+			dRepo := newMockDeliveryRepository(t)
+			cRepo.listAvailFn = func(ctx context.Context, filter model.AvailableCourierFilter) ([]*model.CourierCandidate, error) {
+				return []*model.CourierCandidate{
+					{Courier: car, AssignmentsToday: 3},
+					{Courier: onFoot},
+				}, nil
+			}
+			cRepo.skipLockedFn = func(ctx context.Context, id int) (*model.CourierModel, error) {
+				switch id {
+				case onFoot.ID:
+					return onFoot, nil
+				case car.ID:
+					return car, nil
+				}
+				return nil, courierrepo.ErrCourierNotFound
+			}
+			cRepo.markAssignedFn = func(ctx context.Context, id int) error { return nil }
+			dRepo.getByOrderIDFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+				return nil, repoerrors.ErrDeliveryNotFound
+			}
+			dRepo.createFn = func(ctx context.Context, delivery *model.DeliveryModel) error { return nil }
+
+			factory := model.NewDeliveryTimeFactory(time.Minute*30, time.Minute*15, time.Minute*5)
+			uc := NewDeliveryUsecase(cRepo, dRepo, newMockTxManager(t), factory, func() time.Time { return now })
+
+			delivery, courier, err := uc.AssignCourier(context.Background(), model.AssignCourierRequest{
+				OrderID:    "order-1",
+				PromisedBy: tt.promisedBy,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if courier.ID != tt.expectID {
+				t.Fatalf("expected courier %d, got %d", tt.expectID, courier.ID)
+			}
+			if got := delivery.Deadline.Sub(now); got != tt.expectIn {
+				t.Fatalf("expected deadline in %s, got %s", tt.expectIn, got)
+			}
+			if delivery.AtRisk != tt.expectRisky {
+				t.Fatalf("expected at risk %v, got %v", tt.expectRisky, delivery.AtRisk)
+			}
+			if delivery.PromisedBy != tt.promisedBy {
+				t.Fatalf("expected promise %v, got %v", tt.promisedBy, delivery.PromisedBy)
+			}
+		})
+	}
+}
+
+func TestDeliveryUsecase_AssignOrderPromise(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.December, 22, 10, 30, 0, 0, time.UTC)
+	cRepo := newMockCourierRepository(t)
+	dRepo := newMockDeliveryRepository(t)
+	cRepo.withAvailable(func(excludeIds []int) (*model.CourierModel, error) {
+		return &model.CourierModel{ID: 1, Status: model.CourierStatusAvailable, TransportType: model.TransportScooter}, nil
+	})
+	cRepo.markAssignedFn = func(ctx context.Context, id int) error { return nil }
+	dRepo.getByOrderIDFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+		return nil, repoerrors.ErrDeliveryNotFound
+	}
+	dRepo.createFn = func(ctx context.Context, delivery *model.DeliveryModel) error { return nil }
+
+	factory := model.NewDeliveryTimeFactory(time.Minute*30, time.Minute*15, time.Minute*5)
+	uc := NewDeliveryUsecase(cRepo, dRepo, newMockTxManager(t), factory, func() time.Time { return now })
+
+	delivery, _, err := uc.Assign(context.Background(), &model.Order{ID: "order-1", EstimatedDelivery: now.Add(10 * time.Minute)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !delivery.AtRisk || !delivery.Deadline.Equal(now.Add(15*time.Minute)) {
+		t.Fatalf("expected policy deadline at risk, got %s at risk %v", delivery.Deadline, delivery.AtRisk)
+	}
+}
+
+func TestDeliveryUsecase_CountAtRisk(t *testing.T) {
+	t.Parallel()
+
+	dRepo := newMockDeliveryRepository(t)
+	dRepo.listFn = func(ctx context.Context, filter model.DeliveryFilter) ([]*model.DeliveryModel, int, error) {
+		if !filter.AtRiskOnly || len(filter.Statuses) != len(model.ActiveDeliveryStatuses) {
+			t.Fatalf("unexpected filter: %+v", filter)
+		}
+		return nil, 4, nil
+	}
+
+	factory := model.NewDeliveryTimeFactory(time.Minute*30, time.Minute*15, time.Minute*5)
+	uc := NewDeliveryUsecase(newMockCourierRepository(t), dRepo, newMockTxManager(t), factory, time.Now)
+
+	got, err := uc.CountAtRisk(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != 4 {
+		t.Fatalf("expected 4, got %d", got)
+	}
+}
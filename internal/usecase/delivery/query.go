@@ -0,0 +1,72 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+	courierRepo "github.com/cdxy1/go-courier-service/internal/repository/courier"
+	repo "github.com/cdxy1/go-courier-service/internal/repository/delivery"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+func (uc *DeliveryUsecase) Get(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+	delivery, err := uc.deliveryRepo.GetByOrderID(ctx, orderId)
+	if err != nil {
+		if errors.Is(err, repo.ErrDeliveryNotFound) {
+			return nil, repo.ErrDeliveryNotFound
+		}
+		return nil, fmt.Errorf("get delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+func (uc *DeliveryUsecase) List(ctx context.Context, filter model.DeliveryFilter) (*model.DeliveryPage, error) {
+	if filter.CourierID < 0 || filter.Limit < 0 || filter.Limit > maxPageLimit || filter.Offset < 0 {
+		return nil, ErrInvalidFilter
+	}
+	if filter.AssignedFrom != nil && filter.AssignedTo != nil && !filter.AssignedFrom.Before(*filter.AssignedTo) {
+		return nil, ErrInvalidFilter
+	}
+	for _, status := range filter.Statuses {
+		if !isKnownStatus(status) {
+			return nil, ErrInvalidFilter
+		}
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultPageLimit
+	}
+	filter.Now = uc.now()
+
+	deliveries, total, err := uc.deliveryRepo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("list deliveries: %w", err)
+	}
+
+	return &model.DeliveryPage{
+		Items:  deliveries,
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}, nil
+}
+
+func (uc *DeliveryUsecase) ListByCourier(ctx context.Context, courierId int, filter model.DeliveryFilter) (*model.DeliveryPage, error) {
+	if courierId <= 0 {
+		return nil, ErrInvalidFilter
+	}
+	if _, err := uc.courierRepo.GetOneById(ctx, courierId); err != nil {
+		if errors.Is(err, courierRepo.ErrCourierNotFound) {
+			return nil, courierRepo.ErrCourierNotFound
+		}
+		return nil, fmt.Errorf("get courier: %w", err)
+	}
+
+	filter.CourierID = courierId
+	return uc.List(ctx, filter)
+}
@@ -0,0 +1,214 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+	courierrepo "github.com/cdxy1/go-courier-service/internal/repository/courier"
+	repoerrors "github.com/cdxy1/go-courier-service/internal/repository/delivery"
+)
+
+func TestDeliveryUsecase_Get(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		getFn     func(ctx context.Context, orderId string) (*model.DeliveryModel, error)
+		expectErr error
+	}{
+		{
+			name: "success",
+			getFn: func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+				return &model.DeliveryModel{ID: 1, OrderId: orderId, Status: model.DeliveryStatusDelivered}, nil
+			},
+		},
+		{
+			name: "not found",
+			getFn: func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+				return nil, repoerrors.ErrDeliveryNotFound
+			},
+			expectErr: repoerrors.ErrDeliveryNotFound,
+		},
+		{
+			name: "repository error",
+			getFn: func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+				return nil, errBoom
+			},
+			expectErr: errBoom,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			dRepo := newMockDeliveryRepository(t)
+			dRepo.getByOrderIDFn = tt.getFn
+
+			uc := NewDeliveryUsecase(newMockCourierRepository(t), dRepo, newMockTxManager(t), model.NewDeliveryTimeFactory(time.Minute, time.Minute, time.Minute), time.Now)
+			delivery, err := uc.Get(context.Background(), "order-1")
+
+			if tt.expectErr != nil {
+				if err == nil || !errors.Is(err, tt.expectErr) {
+					t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if delivery.OrderId != "order-1" {
+				t.Fatalf("unexpected order id: %s", delivery.OrderId)
+			}
+		})
+	}
+}
+
+func TestDeliveryUsecase_List(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.December, 22, 10, 0, 0, 0, time.UTC)
+	from := now.Add(-time.Hour)
+
+	tests := []struct {
+		name        string
+		filter      model.DeliveryFilter
+		listFn      func(ctx context.Context, filter model.DeliveryFilter) ([]*model.DeliveryModel, int, error)
+		expectLimit int
+		expectErr   error
+	}{
+		{
+			name:   "default limit and reference time",
+			filter: model.DeliveryFilter{OverdueOnly: true},
+			listFn: func(ctx context.Context, filter model.DeliveryFilter) ([]*model.DeliveryModel, int, error) {
+				if filter.Limit != defaultPageLimit {
+					t.Fatalf("unexpected limit: %d", filter.Limit)
+				}
+				if !filter.Now.Equal(now) {
+					t.Fatalf("unexpected now: %s", filter.Now)
+				}
+				return []*model.DeliveryModel{{ID: 1}}, 41, nil
+			},
+			expectLimit: defaultPageLimit,
+		},
+		{
+			name:      "limit too large",
+			filter:    model.DeliveryFilter{Limit: maxPageLimit + 1},
+			expectErr: ErrInvalidFilter,
+		},
+		{
+			name:      "negative offset",
+			filter:    model.DeliveryFilter{Offset: -1},
+			expectErr: ErrInvalidFilter,
+		},
+		{
+			name:      "unknown status",
+			filter:    model.DeliveryFilter{Statuses: []model.DeliveryStatus{"lost"}},
+			expectErr: ErrInvalidFilter,
+		},
+		{
+			name:      "empty time range",
+			filter:    model.DeliveryFilter{AssignedFrom: &now, AssignedTo: &from},
+			expectErr: ErrInvalidFilter,
+		},
+		{
+			name:   "repository error",
+			filter: model.DeliveryFilter{Limit: 5},
+			listFn: func(ctx context.Context, filter model.DeliveryFilter) ([]*model.DeliveryModel, int, error) {
+				return nil, 0, errBoom
+			},
+			expectErr: errBoom,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			dRepo := newMockDeliveryRepository(t)
+			dRepo.listFn = tt.listFn
+
+			uc := NewDeliveryUsecase(newMockCourierRepository(t), dRepo, newMockTxManager(t), model.NewDeliveryTimeFactory(time.Minute, time.Minute, time.Minute), func() time.Time { return now })
+			page, err := uc.List(context.Background(), tt.filter)
+
+			if tt.expectErr != nil {
+				if err == nil || !errors.Is(err, tt.expectErr) {
+					t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if page.Limit != tt.expectLimit || page.Total != 41 || len(page.Items) != 1 {
+				t.Fatalf("unexpected page: %+v", page)
+			}
+		})
+	}
+}
+
+func TestDeliveryUsecase_ListByCourier(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		courierID int
+		getFn     func(ctx context.Context, id int) (*model.CourierModel, error)
+		expectErr error
+	}{
+		{
+			name:      "invalid courier id",
+			courierID: 0,
+			expectErr: ErrInvalidFilter,
+		},
+		{
+			name:      "courier not found",
+			courierID: 3,
+			getFn: func(ctx context.Context, id int) (*model.CourierModel, error) {
+				return nil, courierrepo.ErrCourierNotFound
+			},
+			expectErr: courierrepo.ErrCourierNotFound,
+		},
+		{
+			name:      "success",
+			courierID: 3,
+			getFn: func(ctx context.Context, id int) (*model.CourierModel, error) {
+				return &model.CourierModel{ID: id}, nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cRepo := newMockCourierRepository(t)
+			cRepo.getOneByIDFn = tt.getFn
+			dRepo := newMockDeliveryRepository(t)
+			dRepo.listFn = func(ctx context.Context, filter model.DeliveryFilter) ([]*model.DeliveryModel, int, error) {
+				if filter.CourierID != tt.courierID {
+					t.Fatalf("unexpected courier id: %d", filter.CourierID)
+				}
+				return []*model.DeliveryModel{}, 0, nil
+			}
+
+			uc := NewDeliveryUsecase(cRepo, dRepo, newMockTxManager(t), model.NewDeliveryTimeFactory(time.Minute, time.Minute, time.Minute), time.Now)
+			_, err := uc.ListByCourier(context.Background(), tt.courierID, model.DeliveryFilter{CourierID: 99})
+
+			if tt.expectErr != nil {
+				if err == nil || !errors.Is(err, tt.expectErr) {
+					t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+	courierRepo "github.com/cdxy1/go-courier-service/internal/repository/courier"
+)
+
+// CourierReleased signals that a courier may have been freed, so queued orders
+// are worth another assignment attempt. Signals are coalesced.
+func (uc *DeliveryUsecase) CourierReleased() <-chan struct{} {
+	return uc.released
+}
+
+func (uc *DeliveryUsecase) notifyReleased() {
+	select {
+	case uc.released <- struct{}{}:
+	default:
+	}
+}
+
+func (uc *DeliveryUsecase) enqueue(ctx context.Context, req model.AssignCourierRequest) error {
+	pending := &model.PendingAssignment{
+		OrderID:    req.OrderID,
+		Priority:   req.Priority,
+		Pickup:     req.Pickup,
+		Dropoff:    req.Dropoff,
+		Order:      req.Order,
+		PromisedBy: req.PromisedBy,
+		EnqueuedAt: uc.now(),
+	}
+	if err := uc.deliveryRepo.Enqueue(ctx, pending); err != nil {
+		return fmt.Errorf("enqueue pending assignment: %w", err)
+	}
+	return nil
+}
+
+// ProcessPendingAssignments retries up to limit queued orders in queue order and
+// returns the deliveries created. An order that finds no courier it may take,
+// because of its zone, transport or the couriers' shifts, is skipped so it does
+// not hold back later orders that other couriers can take. Processing stops once
+// no courier is free at all.
+func (uc *DeliveryUsecase) ProcessPendingAssignments(ctx context.Context, limit int) ([]*model.DeliveryModel, error) {
+	pending, err := uc.deliveryRepo.ListPending(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list pending assignments: %w", err)
+	}
+
+	assigned := []*model.DeliveryModel{}
+	for _, p := range pending {
+		d, _, err := uc.tryAssign(ctx, model.AssignCourierRequest{
+			OrderID:    p.OrderID,
+			Priority:   p.Priority,
+			Pickup:     p.Pickup,
+			Dropoff:    p.Dropoff,
+			Order:      p.Order,
+			PromisedBy: p.PromisedBy,
+		})
+		if err != nil {
+			if markErr := uc.deliveryRepo.MarkPendingAttempt(ctx, p.OrderID, uc.now(), err.Error()); markErr != nil {
+				return assigned, fmt.Errorf("mark pending attempt: %w", markErr)
+			}
+			if errors.Is(err, courierRepo.ErrCourierNotFound) {
+				free, err := uc.anyCourierFree(ctx)
+				if err != nil {
+					return assigned, err
+				}
+				if !free {
+					break
+				}
+			}
+			continue
+		}
+		assigned = append(assigned, d)
+	}
+	return assigned, nil
+}
+
+// anyCourierFree reports whether some courier can take a delivery, whatever
+// the order.
+func (uc *DeliveryUsecase) anyCourierFree(ctx context.Context) (bool, error) {
+	candidates, err := uc.courierRepo.ListAvailable(ctx, uc.availableFilter())
+	if err != nil {
+		return false, fmt.Errorf("get available courier: %w", err)
+	}
+	return len(candidates) > 0, nil
+}
+
+// CancelPending drops the order from the pending queue and reports whether it was queued.
+func (uc *DeliveryUsecase) CancelPending(ctx context.Context, orderId string) (bool, error) {
+	removed, err := uc.deliveryRepo.DeletePending(ctx, orderId)
+	if err != nil {
+		return false, fmt.Errorf("delete pending assignment: %w", err)
+	}
+	return removed, nil
+}
+
+func (uc *DeliveryUsecase) PendingStats(ctx context.Context) (*model.PendingQueueStats, error) {
+	stats, err := uc.deliveryRepo.PendingStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get pending stats: %w", err)
+	}
+	if stats.OldestEnqueuedAt != nil {
+		if wait := uc.now().Sub(*stats.OldestEnqueuedAt); wait > 0 {
+			stats.OldestWait = wait
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,447 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/geo"
+	"github.com/cdxy1/go-courier-service/internal/model"
+	courierrepo "github.com/cdxy1/go-courier-service/internal/repository/courier"
+	repoerrors "github.com/cdxy1/go-courier-service/internal/repository/delivery"
+)
+
+func TestDeliveryUsecase_AssignCourierQueued(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.December, 22, 10, 0, 0, 0, time.UTC)
+	factory := model.NewDeliveryTimeFactory(time.Minute*30, time.Minute*15, time.Minute*5)
+
+	tests := []struct {
+		name        string
+		req         model.AssignCourierRequest
+		enqueueErr  error
+		expectQueue bool
+		expectErr   error
+	}{
+		{
+			name:        "queued when no courier is free",
+			req:         model.AssignCourierRequest{OrderID: "order-1", Priority: 3},
+			expectQueue: true,
+			expectErr:   ErrAssignmentQueued,
+		},
+		{
+			name:        "enqueue error",
+			req:         model.AssignCourierRequest{OrderID: "order-1"},
+			enqueueErr:  errBoom,
+			expectQueue: true,
+			expectErr:   errBoom,
+		},
+		{
+			name:      "specific courier is not queued",
+			req:       model.AssignCourierRequest{OrderID: "order-1", CourierID: 4},
+			expectErr: courierrepo.ErrCourierNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cRepo := newMockCourierRepository(t)
+			dRepo := newMockDeliveryRepository(t)
+
+			var queued *model.PendingAssignment
+			dRepo.getByOrderIDFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+				return nil, repoerrors.ErrDeliveryNotFound
+			}
+			dRepo.enqueueFn = func(ctx context.Context, pending *model.PendingAssignment) error {
+				queued = pending
+				return tt.enqueueErr
+			}
+			cRepo.withAvailable(func(excludeIds []int) (*model.CourierModel, error) {
+				return nil, courierrepo.ErrCourierNotFound
+			})
+			cRepo.getForUpdateFn = func(ctx context.Context, id int) (*model.CourierModel, error) {
+				return nil, courierrepo.ErrCourierNotFound
+			}
+
+			uc := NewDeliveryUsecase(cRepo, dRepo, newMockTxManager(t), factory, func() time.Time { return now })
+			_, _, err := uc.AssignCourier(context.Background(), tt.req)
+
+			if err == nil || !errors.Is(err, tt.expectErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+			}
+			if (queued != nil) != tt.expectQueue {
+				t.Fatalf("expected queued %v, got %+v", tt.expectQueue, queued)
+			}
+			if queued != nil {
+				if queued.OrderID != tt.req.OrderID || queued.Priority != tt.req.Priority || !queued.EnqueuedAt.Equal(now) {
+					t.Fatalf("unexpected pending assignment: %+v", queued)
+				}
+			}
+		})
+	}
+}
+
+func TestDeliveryUsecase_ProcessPendingAssignments(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.December, 22, 10, 0, 0, 0, time.UTC)
+	factory := model.NewDeliveryTimeFactory(time.Minute*30, time.Minute*15, time.Minute*5)
+
+	tests := []struct {
+		name            string
+		couriers        []error
+		expectAssigned  []string
+		expectAttempted []string
+	}{
+		{
+			name:           "all assigned",
+			couriers:       []error{nil, nil, nil},
+			expectAssigned: []string{"order-1", "order-2", "order-3"},
+		},
+		{
+			name:            "continues after other errors",
+			couriers:        []error{errBoom, nil, nil},
+			expectAssigned:  []string{"order-2", "order-3"},
+			expectAttempted: []string{"order-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cRepo := newMockCourierRepository(t)
+			dRepo := newMockDeliveryRepository(t)
+
+			var deleted, attempted []string
+			dRepo.listPendingFn = func(ctx context.Context, limit int) ([]*model.PendingAssignment, error) {
+				if limit != 10 {
+					t.Fatalf("unexpected limit: %d", limit)
+				}
+				return []*model.PendingAssignment{
+					{OrderID: "order-1"}, {OrderID: "order-2"}, {OrderID: "order-3"},
+				}, nil
+			}
+			dRepo.getByOrderIDFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+				return nil, repoerrors.ErrDeliveryNotFound
+			}
+			dRepo.createFn = func(ctx context.Context, delivery *model.DeliveryModel) error { return nil }
+			dRepo.deletePendFn = func(ctx context.Context, orderId string) (bool, error) {
+				deleted = append(deleted, orderId)
+				return true, nil
+			}
+			dRepo.markAttemptFn = func(ctx context.Context, orderId string, at time.Time, reason string) error {
+				if !at.Equal(now) || reason == "" {
+					t.Fatalf("unexpected attempt: %s %q", at, reason)
+				}
+				attempted = append(attempted, orderId)
+				return nil
+			}
+			calls := 0
+			cRepo.withAvailable(func(excludeIds []int) (*model.CourierModel, error) {
+				if calls >= len(tt.couriers) {
+					t.Fatalf("unexpected courier lookup #%d", calls+1)
+				}
+				err := tt.couriers[calls]
+				calls++
+				if err != nil {
+					return nil, err
+				}
+				return &model.CourierModel{ID: calls, Status: model.CourierStatusAvailable, TransportType: model.TransportCar}, nil
+			})
+			cRepo.markAssignedFn = func(ctx context.Context, id int) error { return nil }
+
+			uc := NewDeliveryUsecase(cRepo, dRepo, newMockTxManager(t), factory, func() time.Time { return now })
+			assigned, err := uc.ProcessPendingAssignments(context.Background(), 10)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(assigned) != len(tt.expectAssigned) {
+				t.Fatalf("expected %d assigned, got %d", len(tt.expectAssigned), len(assigned))
+			}
+			for i, orderID := range tt.expectAssigned {
+				if assigned[i].OrderId != orderID || deleted[i] != orderID {
+					t.Fatalf("unexpected assignment #%d: %+v, deleted %v", i, assigned[i], deleted)
+				}
+			}
+			if len(attempted) != len(tt.expectAttempted) {
+				t.Fatalf("expected attempts %v, got %v", tt.expectAttempted, attempted)
+			}
+			for i, orderID := range tt.expectAttempted {
+				if attempted[i] != orderID {
+					t.Fatalf("expected attempts %v, got %v", tt.expectAttempted, attempted)
+				}
+			}
+		})
+	}
+}
+
+func TestDeliveryUsecase_ProcessPendingAssignmentsSkips(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.December, 22, 10, 0, 0, 0, time.UTC)
+	factory := model.NewDeliveryTimeFactory(time.Minute*30, time.Minute*15, time.Minute*5)
+	zoneOf := func(id int) *int { return &id }
+
+	// The zones are not neighbours, so with the default fallback a courier of
+	// one zone never takes orders of the other.
+	zones := stubZoneRepository{zones: []*model.Zone{
+		{ID: 1, Area: geo.Polygon{{Lat: 0, Lon: 0}, {Lat: 0, Lon: 1}, {Lat: 1, Lon: 1}, {Lat: 1, Lon: 0}}},
+		{ID: 2, Area: geo.Polygon{{Lat: 0, Lon: 5}, {Lat: 0, Lon: 6}, {Lat: 1, Lon: 6}, {Lat: 1, Lon: 5}}},
+	}}
+	inZone1 := geo.Point{Lat: 0.5, Lon: 0.5}
+	inZone2 := geo.Point{Lat: 0.5, Lon: 5.5}
+	rules := model.EligibilityRules{
+		{Name: "catering", MinItems: 10, Transports: []model.TransportType{model.TransportCar}},
+	}
+	catering := &model.OrderDetails{ItemCount: 12}
+
+	tests := []struct {
+		name            string
+		pending         []*model.PendingAssignment
+		couriers        []*model.CourierModel
+		expectAssigned  []string
+		expectAttempted []string
+	}{
+		{
+			name: "zone without couriers",
+			pending: []*model.PendingAssignment{
+				{OrderID: "order-1", Pickup: &inZone1},
+				{OrderID: "order-2", Pickup: &inZone2},
+				{OrderID: "order-3", Pickup: &inZone1},
+			},
+			couriers: []*model.CourierModel{
+				{ID: 1, Status: model.CourierStatusAvailable, TransportType: model.TransportCar, ZoneID: zoneOf(2)},
+			},
+			expectAssigned:  []string{"order-2"},
+			expectAttempted: []string{"order-1", "order-3"},
+		},
+		{
+			name: "no eligible transport",
+			pending: []*model.PendingAssignment{
+				{OrderID: "order-1", Order: catering},
+				{OrderID: "order-2"},
+			},
+			couriers: []*model.CourierModel{
+				{ID: 1, Status: model.CourierStatusAvailable, TransportType: model.TransportOnFoot},
+			},
+			expectAssigned:  []string{"order-2"},
+			expectAttempted: []string{"order-1"},
+		},
+		{
+			name: "stops when no courier is free",
+			pending: []*model.PendingAssignment{
+				{OrderID: "order-1", Pickup: &inZone1},
+				{OrderID: "order-2", Pickup: &inZone2},
+			},
+			expectAttempted: []string{"order-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cRepo := newMockCourierRepository(t)
+			dRepo := newMockDeliveryRepository(t)
+
+			var assignedIds, attempted []string
+			taken := map[int]bool{}
+			dRepo.listPendingFn = func(ctx context.Context, limit int) ([]*model.PendingAssignment, error) {
+				return tt.pending, nil
+			}
+			dRepo.getByOrderIDFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+				return nil, repoerrors.ErrDeliveryNotFound
+			}
+			dRepo.createFn = func(ctx context.Context, delivery *model.DeliveryModel) error { return nil }
+			dRepo.deletePendFn = func(ctx context.Context, orderId string) (bool, error) {
+				assignedIds = append(assignedIds, orderId)
+				return true, nil
+			}
+			dRepo.markAttemptFn = func(ctx context.Context, orderId string, at time.Time, reason string) error {
+				attempted = append(attempted, orderId)
+				return nil
+			}
+			cRepo.listAvailFn = func(ctx context.Context, filter model.AvailableCourierFilter) ([]*model.CourierCandidate, error) {
+				candidates := []*model.CourierCandidate{}
+				for _, c := range tt.couriers {
+					if taken[c.ID] || (filter.Transports != nil && !slices.Contains(filter.Transports, c.TransportType)) {
+						continue
+					}
+					candidates = append(candidates, &model.CourierCandidate{Courier: c})
+				}
+				return candidates, nil
+			}
+			cRepo.skipLockedFn = func(ctx context.Context, id int) (*model.CourierModel, error) {
+				return tt.couriers[id-1], nil
+			}
+			cRepo.markAssignedFn = func(ctx context.Context, id int) error {
+				taken[id] = true
+				return nil
+			}
+
+			uc := NewDeliveryUsecase(cRepo, dRepo, newMockTxManager(t), factory, func() time.Time { return now },
+				WithZones(zones, model.ZoneFallbackNone), WithEligibilityRules(rules))
+			assigned, err := uc.ProcessPendingAssignments(context.Background(), 10)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(assigned) != len(tt.expectAssigned) || !slices.Equal(assignedIds, tt.expectAssigned) {
+				t.Fatalf("expected assigned %v, got %v", tt.expectAssigned, assignedIds)
+			}
+			if !slices.Equal(attempted, tt.expectAttempted) {
+				t.Fatalf("expected attempts %v, got %v", tt.expectAttempted, attempted)
+			}
+		})
+	}
+}
+
+func TestDeliveryUsecase_PendingStats(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.December, 22, 10, 0, 0, 0, time.UTC)
+	oldest := now.Add(-time.Minute * 7)
+	factory := model.NewDeliveryTimeFactory(time.Minute*30, time.Minute*15, time.Minute*5)
+
+	cRepo := newMockCourierRepository(t)
+	dRepo := newMockDeliveryRepository(t)
+	dRepo.pendingStatsFn = func(ctx context.Context) (*model.PendingQueueStats, error) {
+		return &model.PendingQueueStats{Depth: 2, OldestEnqueuedAt: &oldest}, nil
+	}
+
+	uc := NewDeliveryUsecase(cRepo, dRepo, newMockTxManager(t), factory, func() time.Time { return now })
+	stats, err := uc.PendingStats(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Depth != 2 || stats.OldestWait != time.Minute*7 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestDeliveryUsecase_CourierReleased(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.December, 22, 10, 0, 0, 0, time.UTC)
+	factory := model.NewDeliveryTimeFactory(time.Minute*30, time.Minute*15, time.Minute*5)
+
+	cRepo := newMockCourierRepository(t)
+	dRepo := newMockDeliveryRepository(t)
+	dRepo.getForUpdateFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+		return &model.DeliveryModel{ID: 1, OrderId: orderId, CourierId: 4, Status: model.DeliveryStatusPickedUp}, nil
+	}
+	dRepo.updateStatusFn = func(ctx context.Context, delivery *model.DeliveryModel) error { return nil }
+	cRepo.markReleasedFn = func(ctx context.Context, id int) error { return nil }
+
+	uc := NewDeliveryUsecase(cRepo, dRepo, newMockTxManager(t), factory, func() time.Time { return now })
+	for i := 0; i < 2; i++ {
+		if _, err := uc.Complete(context.Background(), "order-1"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	select {
+	case <-uc.CourierReleased():
+	default:
+		t.Fatalf("expected courier released signal")
+	}
+	select {
+	case <-uc.CourierReleased():
+		t.Fatalf("signals must be coalesced")
+	default:
+	}
+}
+
+func TestDeliveryUsecase_ProcessPendingAssignmentsAfterAssign(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.December, 22, 10, 0, 0, 0, time.UTC)
+	factory := model.NewDeliveryTimeFactory(time.Minute*30, time.Minute*15, time.Minute*5)
+
+	cRepo := newMockCourierRepository(t)
+	dRepo := newMockDeliveryRepository(t)
+
+	courier := &model.CourierModel{ID: 1, Status: model.CourierStatusAvailable, TransportType: model.TransportCar}
+	pending := map[string]*model.PendingAssignment{}
+	var deliveries []*model.DeliveryModel
+	latest := func(orderId string) (*model.DeliveryModel, error) {
+		for i := len(deliveries) - 1; i >= 0; i-- {
+			if deliveries[i].OrderId == orderId {
+				return deliveries[i], nil
+			}
+		}
+		return nil, repoerrors.ErrDeliveryNotFound
+	}
+
+	dRepo.enqueueFn = func(ctx context.Context, p *model.PendingAssignment) error {
+		pending[p.OrderID] = p
+		return nil
+	}
+	dRepo.listPendingFn = func(ctx context.Context, limit int) ([]*model.PendingAssignment, error) {
+		list := []*model.PendingAssignment{}
+		for _, p := range pending {
+			list = append(list, p)
+		}
+		return list, nil
+	}
+	dRepo.deletePendFn = func(ctx context.Context, orderId string) (bool, error) {
+		_, ok := pending[orderId]
+		delete(pending, orderId)
+		return ok, nil
+	}
+	dRepo.markAttemptFn = func(ctx context.Context, orderId string, at time.Time, reason string) error { return nil }
+	dRepo.getByOrderIDFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+		return latest(orderId)
+	}
+	dRepo.getForUpdateFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+		return latest(orderId)
+	}
+	dRepo.createFn = func(ctx context.Context, delivery *model.DeliveryModel) error {
+		delivery.ID = len(deliveries) + 1
+		deliveries = append(deliveries, delivery)
+		return nil
+	}
+	dRepo.updateStatusFn = func(ctx context.Context, delivery *model.DeliveryModel) error { return nil }
+
+	free := false
+	cRepo.withAvailable(func(excludeIds []int) (*model.CourierModel, error) {
+		if !free {
+			return nil, nil
+		}
+		return courier, nil
+	})
+	cRepo.markAssignedFn = func(ctx context.Context, id int) error { return nil }
+	cRepo.markReleasedFn = func(ctx context.Context, id int) error { return nil }
+
+	uc := NewDeliveryUsecase(cRepo, dRepo, newMockTxManager(t), factory, func() time.Time { return now })
+	order := &model.Order{ID: "order-1"}
+	if _, _, err := uc.Assign(context.Background(), order); !errors.Is(err, ErrAssignmentQueued) {
+		t.Fatalf("expected order to be queued, got %v", err)
+	}
+
+	free = true
+	if _, _, err := uc.Assign(context.Background(), order); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("expected the assigned order to leave the queue, got %v", pending)
+	}
+	if _, err := uc.Complete(context.Background(), order.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assigned, err := uc.ProcessPendingAssignments(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(assigned) != 0 || len(deliveries) != 1 {
+		t.Fatalf("expected no second delivery, got %d assigned and %d deliveries", len(assigned), len(deliveries))
+	}
+}
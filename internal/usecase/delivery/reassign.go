@@ -0,0 +1,84 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+	courierRepo "github.com/cdxy1/go-courier-service/internal/repository/courier"
+)
+
+// Reassign hands an in-flight delivery over to another courier in one transaction.
+// The current delivery is cancelled and its courier freed, then a new delivery is
+// created for toCourierId, or for the least loaded available courier other than
+// the previous one when toCourierId is nil. The deadline is recalculated for the
+// new courier's transport. The freed courier may take queued orders afterwards.
+// When no other courier is free nothing changes and ErrNoCourierAvailable is
+// returned.
+func (uc *DeliveryUsecase) Reassign(
+	ctx context.Context,
+	orderId string,
+	toCourierId *int,
+	reason string,
+) (*model.DeliveryModel, *model.CourierModel, error) {
+	var createdDelivery *model.DeliveryModel
+	var assignedCourier *model.CourierModel
+
+	if err := uc.tm.WithTx(ctx, func(ctx context.Context) error {
+		current, err := uc.deliveryRepo.GetByOrderIDForUpdate(ctx, orderId)
+		if err != nil {
+			return fmt.Errorf("get delivery: %w", err)
+		}
+
+		req := model.AssignCourierRequest{
+			OrderID:    orderId,
+			Pickup:     current.Pickup,
+			Dropoff:    current.Dropoff,
+			Order:      current.Order,
+			PromisedBy: current.PromisedBy,
+		}
+		if toCourierId != nil {
+			req.CourierID = *toCourierId
+			if req.CourierID == current.CourierId {
+				return ErrSameCourier
+			}
+		}
+
+		from := current.Status
+		if err := transition(current, model.DeliveryStatusCancelled, uc.now()); err != nil {
+			return err
+		}
+		if err := uc.deliveryRepo.UpdateStatus(ctx, current); err != nil {
+			return fmt.Errorf("update delivery status: %w", err)
+		}
+		if err := uc.recordEvent(ctx, model.DeliveryEventReassigned, current, from, reason); err != nil {
+			return err
+		}
+		if err := uc.courierRepo.MarkReleased(ctx, current.CourierId); err != nil {
+			return fmt.Errorf("release courier: %w", err)
+		}
+
+		courier, err := uc.pickCourier(ctx, req, []int{current.CourierId})
+		if toCourierId == nil && errors.Is(err, courierRepo.ErrCourierNotFound) {
+			return fmt.Errorf("%w: order %s", ErrNoCourierAvailable, orderId)
+		}
+		if err != nil {
+			return err
+		}
+
+		d, err := uc.createDelivery(ctx, req, courier, reason)
+		if err != nil {
+			return err
+		}
+
+		createdDelivery = d
+		assignedCourier = courier
+		return nil
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	uc.notifyReleased()
+	return createdDelivery, assignedCourier, nil
+}
@@ -0,0 +1,149 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+	courierrepo "github.com/cdxy1/go-courier-service/internal/repository/courier"
+)
+
+func TestDeliveryUsecase_Reassign(t *testing.T) {
+	t.Parallel()
+
+	orderID := "order-31"
+	now := time.Date(2025, time.December, 22, 10, 0, 0, 0, time.UTC)
+	factory := model.NewDeliveryTimeFactory(time.Minute*30, time.Minute*15, time.Minute*5)
+	target := 9
+	same := 4
+
+	tests := []struct {
+		name        string
+		toCourierID *int
+		status      model.DeliveryStatus
+		courier     *model.CourierModel
+		pickErr     error
+		expectErr   error
+	}{
+		{
+			name:    "auto pick",
+			status:  model.DeliveryStatusPickedUp,
+			courier: &model.CourierModel{ID: 8, Status: model.CourierStatusAvailable, TransportType: model.TransportCar},
+		},
+		{
+			name:        "specific courier",
+			toCourierID: &target,
+			status:      model.DeliveryStatusAssigned,
+			courier:     &model.CourierModel{ID: 9, Status: model.CourierStatusAvailable, TransportType: model.TransportScooter},
+		},
+		{
+			name:        "same courier",
+			toCourierID: &same,
+			status:      model.DeliveryStatusAssigned,
+			expectErr:   ErrSameCourier,
+		},
+		{
+			name:        "target busy",
+			toCourierID: &target,
+			status:      model.DeliveryStatusAssigned,
+			courier:     &model.CourierModel{ID: 9, Status: model.CourierStatusBusy, TransportType: model.TransportScooter},
+			expectErr:   ErrCourierUnavailable,
+		},
+		{
+			name:      "no courier available",
+			status:    model.DeliveryStatusAssigned,
+			pickErr:   courierrepo.ErrCourierNotFound,
+			expectErr: ErrNoCourierAvailable,
+		},
+		{
+			name:      "already delivered",
+			status:    model.DeliveryStatusDelivered,
+			expectErr: ErrInvalidStatusTransition,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cRepo := newMockCourierRepository(t)
+			dRepo := newMockDeliveryRepository(t)
+
+			var updated *model.DeliveryModel
+			var created *model.DeliveryModel
+			var released int
+
+			dRepo.getForUpdateFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+				return &model.DeliveryModel{ID: 1, OrderId: orderId, CourierId: 4, Status: tt.status}, nil
+			}
+			dRepo.updateStatusFn = func(ctx context.Context, delivery *model.DeliveryModel) error {
+				updated = delivery
+				return nil
+			}
+			dRepo.createFn = func(ctx context.Context, delivery *model.DeliveryModel) error {
+				delivery.ID = 2
+				created = delivery
+				return nil
+			}
+			cRepo.markReleasedFn = func(ctx context.Context, id int) error {
+				released = id
+				return nil
+			}
+			cRepo.withAvailable(func(excludeIds []int) (*model.CourierModel, error) {
+				if len(excludeIds) != 1 || excludeIds[0] != 4 {
+					t.Fatalf("previous courier must be excluded, got %v", excludeIds)
+				}
+				return tt.courier, tt.pickErr
+			})
+			cRepo.getForUpdateFn = func(ctx context.Context, id int) (*model.CourierModel, error) {
+				if id != target {
+					t.Fatalf("unexpected courier id: %d", id)
+				}
+				return tt.courier, tt.pickErr
+			}
+			cRepo.markAssignedFn = func(ctx context.Context, id int) error { return nil }
+
+			uc := NewDeliveryUsecase(cRepo, dRepo, newMockTxManager(t), factory, func() time.Time { return now })
+			delivery, courier, err := uc.Reassign(context.Background(), orderID, tt.toCourierID, "scooter broke down")
+
+			if tt.expectErr != nil {
+				if err == nil || !errors.Is(err, tt.expectErr) {
+					t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if updated == nil || updated.Status != model.DeliveryStatusCancelled {
+				t.Fatalf("previous delivery must be cancelled: %+v", updated)
+			}
+			if released != 4 {
+				t.Fatalf("previous courier must be released, got %d", released)
+			}
+			if created != delivery || delivery.CourierId != courier.ID || delivery.OrderId != orderID {
+				t.Fatalf("unexpected delivery: %+v", delivery)
+			}
+			if want := factory.ForTransport(courier.TransportType).Deadline(now); !delivery.Deadline.Equal(want) {
+				t.Fatalf("unexpected deadline: %s", delivery.Deadline)
+			}
+			if len(dRepo.events) != 2 {
+				t.Fatalf("expected 2 events, got %d", len(dRepo.events))
+			}
+			if e := dRepo.events[0]; e.Type != model.DeliveryEventReassigned || e.CourierID != 4 || e.Reason != "scooter broke down" {
+				t.Fatalf("unexpected reassign event: %+v", e)
+			}
+			if e := dRepo.events[1]; e.Type != model.DeliveryEventAssigned || e.CourierID != courier.ID || e.Reason != "scooter broke down" {
+				t.Fatalf("unexpected assign event: %+v", e)
+			}
+			select {
+			case <-uc.CourierReleased():
+			default:
+				t.Fatalf("expected courier released signal")
+			}
+		})
+	}
+}
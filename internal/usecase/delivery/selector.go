@@ -0,0 +1,284 @@
+package delivery
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/geo"
+	"github.com/cdxy1/go-courier-service/internal/model"
+)
+
+// CourierSelector decides which courier takes a new delivery. Rank returns the
+// candidates from the most to the least preferred, dropping the ones that must
+// not get the order; the usecase locks them in that order and assigns the first
+// one that can still take the delivery.
+type CourierSelector interface {
+	Rank(req model.AssignCourierRequest, candidates []*model.CourierCandidate) []*model.CourierCandidate
+}
+
+// AreaSelector is a CourierSelector that only considers couriers around the
+// pickup point. SearchCells returns the geohash cells candidates are loaded
+// from, or nil to load every available courier.
+type AreaSelector interface {
+	CourierSelector
+	SearchCells(req model.AssignCourierRequest) []string
+}
+
+type selectorConfig struct {
+	maxRadius   float64
+	speeds      model.TransportSpeeds
+	scoreWeight float64
+}
+
+// SelectorOption tunes the built-in selectors.
+type SelectorOption func(*selectorConfig)
+
+// WithMaxRadius limits geo-aware selection to couriers within meters of the pickup point.
+func WithMaxRadius(meters float64) SelectorOption {
+	return func(c *selectorConfig) {
+		if meters > 0 {
+			c.maxRadius = meters
+		}
+	}
+}
+
+// WithTransportSpeeds sets the speeds geo-aware selection estimates travel time with.
+func WithTransportSpeeds(speeds model.TransportSpeeds) SelectorOption {
+	return func(c *selectorConfig) {
+		c.speeds = speeds
+	}
+}
+
+// WithScoreWeight blends the performance score of couriers into the ranking of
+// the strategy. A weight of 0 keeps the ranking of the strategy, 1 ranks by
+// score alone; values outside that range are clamped.
+func WithScoreWeight(weight float64) SelectorOption {
+	return func(c *selectorConfig) {
+		c.scoreWeight = min(max(weight, 0), 1)
+	}
+}
+
+// NewCourierSelector returns the built-in selector for the strategy. Unknown
+// strategies fall back to model.DispatchLeastRecent.
+func NewCourierSelector(strategy model.DispatchStrategy, opts ...SelectorOption) CourierSelector {
+	cfg := selectorConfig{
+		maxRadius: defaultMaxRadius,
+		speeds:    model.NewTransportSpeeds(5, 15, 25),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	var selector CourierSelector
+	switch strategy {
+	case model.DispatchNearest:
+		selector = &nearestSelector{maxRadius: cfg.maxRadius, speeds: cfg.speeds, fallback: leastRecentSelector{}}
+	case model.DispatchLeastToday:
+		selector = leastTodaySelector{}
+	case model.DispatchRoundRobin:
+		selector = &roundRobinSelector{}
+	case model.DispatchLongestIdle:
+		selector = longestIdleSelector{}
+	case model.DispatchRandomWeighted:
+		selector = newRandomWeightedSelector(rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())))
+	default:
+		selector = leastRecentSelector{}
+	}
+	if cfg.scoreWeight > 0 {
+		selector = &scoreWeightedSelector{base: selector, weight: cfg.scoreWeight}
+	}
+	return selector
+}
+
+// leastRecentSelector prefers couriers with the fewest active deliveries and
+// then the lowest load in the fairness window. It serves model.DispatchLeastRecent
+// and the deprecated model.DispatchLeastLifetime.
+type leastRecentSelector struct{}
+
+func (leastRecentSelector) Rank(_ model.AssignCourierRequest, candidates []*model.CourierCandidate) []*model.CourierCandidate {
+	return sortedCandidates(candidates, func(a, b *model.CourierCandidate) bool {
+		if a.Courier.ActiveDeliveries != b.Courier.ActiveDeliveries {
+			return a.Courier.ActiveDeliveries < b.Courier.ActiveDeliveries
+		}
+		return a.RecentLoad < b.RecentLoad
+	})
+}
+
+// leastTodaySelector prefers couriers with the fewest deliveries assigned today.
+type leastTodaySelector struct{}
+
+func (leastTodaySelector) Rank(_ model.AssignCourierRequest, candidates []*model.CourierCandidate) []*model.CourierCandidate {
+	return sortedCandidates(candidates, func(a, b *model.CourierCandidate) bool {
+		if a.AssignmentsToday != b.AssignmentsToday {
+			return a.AssignmentsToday < b.AssignmentsToday
+		}
+		return a.RecentLoad < b.RecentLoad
+	})
+}
+
+// longestIdleSelector prefers couriers that never had a delivery, then the one
+// whose last delivery was assigned the longest time ago.
+type longestIdleSelector struct{}
+
+func (longestIdleSelector) Rank(_ model.AssignCourierRequest, candidates []*model.CourierCandidate) []*model.CourierCandidate {
+	return sortedCandidates(candidates, func(a, b *model.CourierCandidate) bool {
+		switch {
+		case a.LastAssignedAt == nil || b.LastAssignedAt == nil:
+			return a.LastAssignedAt == nil && b.LastAssignedAt != nil
+		default:
+			return a.LastAssignedAt.Before(*b.LastAssignedAt)
+		}
+	})
+}
+
+// roundRobinSelector walks couriers in id order, starting after the courier
+// ranked first by the previous call. The position is kept in memory, so every
+// instance of the service cycles on its own.
+type roundRobinSelector struct {
+	mu     sync.Mutex
+	lastId int
+}
+
+func (s *roundRobinSelector) Rank(_ model.AssignCourierRequest, candidates []*model.CourierCandidate) []*model.CourierCandidate {
+	ranked := sortedCandidates(candidates, func(a, b *model.CourierCandidate) bool { return false })
+	if len(ranked) == 0 {
+		return ranked
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	start := sort.Search(len(ranked), func(i int) bool { return ranked[i].Courier.ID > s.lastId })
+	if start == len(ranked) {
+		start = 0
+	}
+	ranked = append(ranked[start:], ranked[:start]...)
+	s.lastId = ranked[0].Courier.ID
+	return ranked
+}
+
+// randomWeightedSelector shuffles the candidates so that a courier with fewer
+// deliveries today and fewer active deliveries is more likely to come first.
+type randomWeightedSelector struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+func newRandomWeightedSelector(rnd *rand.Rand) *randomWeightedSelector {
+	return &randomWeightedSelector{rnd: rnd}
+}
+
+func (s *randomWeightedSelector) Rank(_ model.AssignCourierRequest, candidates []*model.CourierCandidate) []*model.CourierCandidate {
+	// Weighted shuffle: every candidate draws u^(1/w) and the highest keys win.
+	keys := make(map[int]float64, len(candidates))
+	s.mu.Lock()
+	for _, c := range candidates {
+		weight := 1 / float64(1+c.AssignmentsToday+c.Courier.ActiveDeliveries)
+		keys[c.Courier.ID] = math.Pow(s.rnd.Float64(), 1/weight)
+	}
+	s.mu.Unlock()
+
+	return sortedCandidates(candidates, func(a, b *model.CourierCandidate) bool {
+		return keys[a.Courier.ID] > keys[b.Courier.ID]
+	})
+}
+
+const defaultMaxRadius = 5000
+
+// nearestSelector ranks couriers by the estimated time to reach the pickup
+// point: the haversine distance from their last known position divided by the
+// speed of their transport. Couriers farther than maxRadius or without a known
+// position are dropped. Requests without a pickup point use the fallback.
+type nearestSelector struct {
+	maxRadius float64
+	speeds    model.TransportSpeeds
+	fallback  CourierSelector
+}
+
+func (s *nearestSelector) SearchCells(req model.AssignCourierRequest) []string {
+	if req.Pickup == nil {
+		return nil
+	}
+	return geo.Cover(*req.Pickup, s.maxRadius, geo.CellPrecision)
+}
+
+func (s *nearestSelector) Rank(req model.AssignCourierRequest, candidates []*model.CourierCandidate) []*model.CourierCandidate {
+	if req.Pickup == nil {
+		return s.fallback.Rank(req, candidates)
+	}
+
+	travel := make(map[int]time.Duration, len(candidates))
+	reachable := make([]*model.CourierCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		if c.Courier.Location == nil {
+			continue
+		}
+		distance := geo.Distance(*c.Courier.Location, *req.Pickup)
+		if distance > s.maxRadius {
+			continue
+		}
+		travel[c.Courier.ID] = s.speeds.TravelTime(c.Courier.TransportType, distance)
+		reachable = append(reachable, c)
+	}
+
+	return sortedCandidates(reachable, func(a, b *model.CourierCandidate) bool {
+		return travel[a.Courier.ID] < travel[b.Courier.ID]
+	})
+}
+
+// scoreWeightedSelector reorders the ranking of the base selector by the
+// performance score of the couriers. Every candidate gets the weighted sum of
+// its position in the base ranking, scaled to [0, 1], and of its score
+// shortfall 1-score; the lowest sum comes first. Couriers without a score count
+// as model.NeutralScore, ties keep the base order.
+type scoreWeightedSelector struct {
+	base   CourierSelector
+	weight float64
+}
+
+func (s *scoreWeightedSelector) SearchCells(req model.AssignCourierRequest) []string {
+	if area, ok := s.base.(AreaSelector); ok {
+		return area.SearchCells(req)
+	}
+	return nil
+}
+
+func (s *scoreWeightedSelector) Rank(req model.AssignCourierRequest, candidates []*model.CourierCandidate) []*model.CourierCandidate {
+	ranked := s.base.Rank(req, candidates)
+	if len(ranked) < 2 {
+		return ranked
+	}
+
+	keys := make(map[int]float64, len(ranked))
+	last := float64(len(ranked) - 1)
+	for i, c := range ranked {
+		keys[c.Courier.ID] = (1-s.weight)*float64(i)/last + s.weight*(1-model.ScoreOf(c.Courier))
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return keys[ranked[i].Courier.ID] < keys[ranked[j].Courier.ID]
+	})
+	return ranked
+}
+
+// sortedCandidates returns a sorted copy of candidates. Ties are broken by the
+// courier id so the order is deterministic.
+func sortedCandidates(
+	candidates []*model.CourierCandidate,
+	less func(a, b *model.CourierCandidate) bool,
+) []*model.CourierCandidate {
+	ranked := make([]*model.CourierCandidate, len(candidates))
+	copy(ranked, candidates)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return a.Courier.ID < b.Courier.ID
+	})
+	return ranked
+}
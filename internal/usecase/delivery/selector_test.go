@@ -0,0 +1,377 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/geo"
+	"github.com/cdxy1/go-courier-service/internal/model"
+	courierrepo "github.com/cdxy1/go-courier-service/internal/repository/courier"
+	repoerrors "github.com/cdxy1/go-courier-service/internal/repository/delivery"
+)
+
+func candidateIDs(candidates []*model.CourierCandidate) []int {
+	ids := make([]int, 0, len(candidates))
+	for _, c := range candidates {
+		ids = append(ids, c.Courier.ID)
+	}
+	return ids
+}
+
+func equalIDs(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCourierSelector_Rank(t *testing.T) {
+	t.Parallel()
+
+	early := time.Date(2025, time.December, 22, 8, 0, 0, 0, time.UTC)
+	late := time.Date(2025, time.December, 22, 9, 0, 0, 0, time.UTC)
+	candidates := []*model.CourierCandidate{
+		{Courier: &model.CourierModel{ID: 1, ActiveDeliveries: 1}, AssignmentsToday: 2, RecentLoad: 2, LastAssignedAt: &late},
+		{Courier: &model.CourierModel{ID: 2}, AssignmentsToday: 0, RecentLoad: 1.5, LastAssignedAt: &early},
+		{Courier: &model.CourierModel{ID: 3}, AssignmentsToday: 3, RecentLoad: 3, LastAssignedAt: &late},
+		{Courier: &model.CourierModel{ID: 4}, AssignmentsToday: 0},
+	}
+
+	tests := []struct {
+		name     string
+		strategy model.DispatchStrategy
+		expected []int
+	}{
+		{name: "least recent assignments", strategy: model.DispatchLeastRecent, expected: []int{4, 2, 3, 1}},
+		{name: "deprecated lifetime strategy", strategy: model.DispatchLeastLifetime, expected: []int{4, 2, 3, 1}},
+		{name: "unknown strategy", strategy: "nearest", expected: []int{4, 2, 3, 1}},
+		{name: "least assignments today", strategy: model.DispatchLeastToday, expected: []int{4, 2, 1, 3}},
+		{name: "longest idle", strategy: model.DispatchLongestIdle, expected: []int{4, 2, 1, 3}},
+		{name: "round robin", strategy: model.DispatchRoundRobin, expected: []int{1, 2, 3, 4}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ranked := NewCourierSelector(tt.strategy).Rank(model.AssignCourierRequest{}, candidates)
+			if got := candidateIDs(ranked); !equalIDs(got, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCourierSelector_RoundRobin(t *testing.T) {
+	t.Parallel()
+
+	selector := NewCourierSelector(model.DispatchRoundRobin)
+	candidates := []*model.CourierCandidate{
+		{Courier: &model.CourierModel{ID: 5}},
+		{Courier: &model.CourierModel{ID: 2}},
+		{Courier: &model.CourierModel{ID: 9}},
+	}
+
+	var firsts []int
+	for i := 0; i < 4; i++ {
+		firsts = append(firsts, selector.Rank(model.AssignCourierRequest{}, candidates)[0].Courier.ID)
+	}
+	if expected := []int{2, 5, 9, 2}; !equalIDs(firsts, expected) {
+		t.Fatalf("expected %v, got %v", expected, firsts)
+	}
+
+	// a courier that went offline is skipped without restarting the cycle
+	ranked := selector.Rank(model.AssignCourierRequest{}, candidates[:2])
+	if got := candidateIDs(ranked); !equalIDs(got, []int{5, 2}) {
+		t.Fatalf("unexpected ranking: %v", got)
+	}
+}
+
+func TestCourierSelector_RandomWeighted(t *testing.T) {
+	t.Parallel()
+
+	selector := newRandomWeightedSelector(rand.New(rand.NewPCG(1, 2)))
+	candidates := []*model.CourierCandidate{
+		{Courier: &model.CourierModel{ID: 1}, AssignmentsToday: 9},
+		{Courier: &model.CourierModel{ID: 2}, AssignmentsToday: 0},
+	}
+
+	wins := map[int]int{}
+	for i := 0; i < 1000; i++ {
+		ranked := selector.Rank(model.AssignCourierRequest{}, candidates)
+		if len(ranked) != len(candidates) {
+			t.Fatalf("expected %d candidates, got %d", len(candidates), len(ranked))
+		}
+		wins[ranked[0].Courier.ID]++
+	}
+	if wins[1] == 0 || wins[2] < wins[1]*5 {
+		t.Fatalf("less loaded courier must usually win, got %v", wins)
+	}
+}
+
+func TestDeliveryUsecase_AssignWithSelector(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.December, 22, 10, 30, 0, 0, time.UTC)
+	factory := model.NewDeliveryTimeFactory(time.Minute*30, time.Minute*15, time.Minute*5)
+	candidates := []*model.CourierCandidate{
+		{Courier: &model.CourierModel{ID: 1, Status: model.CourierStatusAvailable}, AssignmentsToday: 0},
+		{Courier: &model.CourierModel{ID: 2, Status: model.CourierStatusAvailable}, AssignmentsToday: 1},
+		{Courier: &model.CourierModel{ID: 3, Status: model.CourierStatusAvailable}, AssignmentsToday: 2},
+	}
+
+	tests := []struct {
+		name      string
+		locked    map[int]*model.CourierModel
+		expectID  int
+		expectErr error
+	}{
+		{
+			name: "first ranked courier",
+			locked: map[int]*model.CourierModel{
+				1: {ID: 1, Status: model.CourierStatusAvailable, TransportType: model.TransportCar},
+			},
+			expectID: 1,
+		},
+		{
+			name: "skips locked and full couriers",
+			locked: map[int]*model.CourierModel{
+				2: {ID: 2, Status: model.CourierStatusBusy, ActiveDeliveries: 1, TransportType: model.TransportCar},
+				3: {ID: 3, Status: model.CourierStatusAvailable, TransportType: model.TransportCar},
+			},
+			expectID: 3,
+		},
+		{
+			name:      "nobody can take the order",
+			locked:    map[int]*model.CourierModel{},
+			expectErr: ErrAssignmentQueued,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cRepo := newMockCourierRepository(t)
+			dRepo := newMockDeliveryRepository(t)
+
+			cRepo.listAvailFn = func(ctx context.Context, filter model.AvailableCourierFilter) ([]*model.CourierCandidate, error) {
+				if want := time.Date(2025, time.December, 22, 0, 0, 0, 0, time.UTC); !filter.Since.Equal(want) {
+					t.Fatalf("unexpected since: %s", filter.Since)
+				}
+				if filter.Cells != nil {
+					t.Fatalf("unexpected cells: %v", filter.Cells)
+				}
+				return candidates, nil
+			}
+			cRepo.skipLockedFn = func(ctx context.Context, id int) (*model.CourierModel, error) {
+				if courier, ok := tt.locked[id]; ok {
+					return courier, nil
+				}
+				return nil, courierrepo.ErrCourierNotFound
+			}
+			cRepo.markAssignedFn = func(ctx context.Context, id int) error { return nil }
+			dRepo.getByOrderIDFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+				return nil, repoerrors.ErrDeliveryNotFound
+			}
+			dRepo.createFn = func(ctx context.Context, delivery *model.DeliveryModel) error { return nil }
+			dRepo.enqueueFn = func(ctx context.Context, pending *model.PendingAssignment) error { return nil }
+
+			uc := NewDeliveryUsecase(cRepo, dRepo, newMockTxManager(t), factory, func() time.Time { return now },
+				WithCourierSelector(NewCourierSelector(model.DispatchLeastToday)))
+			delivery, courier, err := uc.Assign(context.Background(), &model.Order{ID: "order-1"})
+
+			if tt.expectErr != nil {
+				if err == nil || !errors.Is(err, tt.expectErr) {
+					t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if courier.ID != tt.expectID || delivery.CourierId != tt.expectID {
+				t.Fatalf("expected courier %d, got %d", tt.expectID, courier.ID)
+			}
+		})
+	}
+}
+
+func TestCourierSelector_ScoreWeight(t *testing.T) {
+	t.Parallel()
+
+	candidates := []*model.CourierCandidate{
+		{Courier: &model.CourierModel{ID: 1, Score: &model.CourierScore{Score: 0.2}}},
+		{Courier: &model.CourierModel{ID: 2}, RecentLoad: 1},
+		{Courier: &model.CourierModel{ID: 3, Score: &model.CourierScore{Score: 0.95}}, RecentLoad: 2},
+	}
+
+	tests := []struct {
+		name     string
+		strategy model.DispatchStrategy
+		weight   float64
+		expected []int
+	}{
+		{name: "no weight keeps the strategy", strategy: model.DispatchLeastRecent, weight: 0, expected: []int{1, 2, 3}},
+		{name: "light weight", strategy: model.DispatchLeastRecent, weight: 0.5, expected: []int{1, 2, 3}},
+		{name: "heavy weight", strategy: model.DispatchLeastRecent, weight: 0.6, expected: []int{3, 1, 2}},
+		{name: "score alone", strategy: model.DispatchLeastRecent, weight: 1, expected: []int{3, 2, 1}},
+		{name: "weight clamped", strategy: model.DispatchLeastRecent, weight: 5, expected: []int{3, 2, 1}},
+		{name: "other strategy", strategy: model.DispatchRoundRobin, weight: 1, expected: []int{3, 2, 1}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ranked := NewCourierSelector(tt.strategy, WithScoreWeight(tt.weight)).Rank(model.AssignCourierRequest{}, candidates)
+			if got := candidateIDs(ranked); !equalIDs(got, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("nearest keeps its search area", func(t *testing.T) {
+		t.Parallel()
+		pickup := geo.Point{Lat: 55.7558, Lon: 37.6173}
+		selector, ok := NewCourierSelector(model.DispatchNearest, WithScoreWeight(0.5)).(AreaSelector)
+		if !ok {
+			t.Fatalf("expected an area selector")
+		}
+		if cells := selector.SearchCells(model.AssignCourierRequest{Pickup: &pickup}); len(cells) == 0 {
+			t.Fatalf("expected search cells around the pickup")
+		}
+	})
+}
+
+func TestCourierSelector_Nearest(t *testing.T) {
+	t.Parallel()
+
+	pickup := geo.Point{Lat: 55.7558, Lon: 37.6173}
+	// northOf moves the pickup point north by roughly the given number of meters.
+	northOf := func(meters float64) *geo.Point {
+		return &geo.Point{Lat: pickup.Lat + meters/111_195, Lon: pickup.Lon}
+	}
+	candidates := []*model.CourierCandidate{
+		{Courier: &model.CourierModel{ID: 1, TransportType: model.TransportOnFoot, Location: northOf(500)}},
+		{Courier: &model.CourierModel{ID: 2, TransportType: model.TransportCar, Location: northOf(2000)}},
+		{Courier: &model.CourierModel{ID: 3, TransportType: model.TransportScooter, Location: northOf(1000)}},
+		{Courier: &model.CourierModel{ID: 4, TransportType: model.TransportCar, Location: northOf(8000)}},
+		{Courier: &model.CourierModel{ID: 5, TransportType: model.TransportCar}},
+	}
+	candidates[0].RecentLoad = 3
+
+	tests := []struct {
+		name     string
+		req      model.AssignCourierRequest
+		opts     []SelectorOption
+		expected []int
+	}{
+		{
+			name:     "fastest to reach the pickup first",
+			req:      model.AssignCourierRequest{Pickup: &pickup},
+			expected: []int{3, 2, 1},
+		},
+		{
+			name:     "wider radius",
+			req:      model.AssignCourierRequest{Pickup: &pickup},
+			opts:     []SelectorOption{WithMaxRadius(10000)},
+			expected: []int{3, 2, 1, 4},
+		},
+		{
+			name:     "walking is as fast as driving",
+			req:      model.AssignCourierRequest{Pickup: &pickup},
+			opts:     []SelectorOption{WithTransportSpeeds(model.NewTransportSpeeds(10, 10, 10))},
+			expected: []int{1, 3, 2},
+		},
+		{
+			name:     "without pickup point",
+			expected: []int{2, 3, 4, 5, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			selector := NewCourierSelector(model.DispatchNearest, tt.opts...)
+			ranked := selector.Rank(tt.req, candidates)
+			if got := candidateIDs(ranked); !equalIDs(got, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+
+			cells := selector.(AreaSelector).SearchCells(tt.req)
+			if tt.req.Pickup == nil {
+				if cells != nil {
+					t.Fatalf("expected no cells without pickup, got %d", len(cells))
+				}
+				return
+			}
+			for _, id := range tt.expected {
+				c := candidates[id-1].Courier
+				found := false
+				for _, cell := range cells {
+					found = found || cell == geo.Encode(*c.Location, geo.CellPrecision)
+				}
+				if !found {
+					t.Fatalf("courier %d is outside the search cells", id)
+				}
+			}
+		})
+	}
+}
+
+func TestDeliveryUsecase_AssignNearest(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.December, 22, 10, 30, 0, 0, time.UTC)
+	factory := model.NewDeliveryTimeFactory(time.Minute*30, time.Minute*15, time.Minute*5)
+	pickup := geo.Point{Lat: 55.7558, Lon: 37.6173}
+	near := &model.CourierModel{ID: 7, Status: model.CourierStatusAvailable, TransportType: model.TransportCar,
+		Location: &geo.Point{Lat: 55.7600, Lon: 37.6200}}
+
+	cRepo := newMockCourierRepository(t)
+	dRepo := newMockDeliveryRepository(t)
+	cRepo.listAvailFn = func(ctx context.Context, filter model.AvailableCourierFilter) ([]*model.CourierCandidate, error) {
+		pickupCell := geo.Encode(pickup, geo.CellPrecision)
+		found := false
+		for _, cell := range filter.Cells {
+			found = found || cell == pickupCell
+		}
+		if !found {
+			t.Fatalf("search cells must include the pickup cell %s", pickupCell)
+		}
+		return []*model.CourierCandidate{{Courier: near}}, nil
+	}
+	cRepo.skipLockedFn = func(ctx context.Context, id int) (*model.CourierModel, error) { return near, nil }
+	cRepo.markAssignedFn = func(ctx context.Context, id int) error { return nil }
+	dRepo.getByOrderIDFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+		return nil, repoerrors.ErrDeliveryNotFound
+	}
+	var created *model.DeliveryModel
+	dRepo.createFn = func(ctx context.Context, delivery *model.DeliveryModel) error {
+		created = delivery
+		return nil
+	}
+
+	uc := NewDeliveryUsecase(cRepo, dRepo, newMockTxManager(t), factory, func() time.Time { return now },
+		WithCourierSelector(NewCourierSelector(model.DispatchNearest)))
+	_, courier, err := uc.AssignCourier(context.Background(), model.AssignCourierRequest{OrderID: "order-1", Pickup: &pickup})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if courier.ID != near.ID {
+		t.Fatalf("unexpected courier: %d", courier.ID)
+	}
+	if created == nil || created.Pickup == nil || *created.Pickup != pickup {
+		t.Fatalf("pickup must be stored with the delivery: %+v", created)
+	}
+}
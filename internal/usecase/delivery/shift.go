@@ -0,0 +1,114 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+)
+
+// shiftLimits holds the end of the running shift of couriers on a shift
+// schedule, the zero time for those off shift. Couriers without shifts are
+// not listed.
+type shiftLimits map[int]time.Time
+
+// allows reports whether the courier can finish a delivery due at the
+// deadline within its shift.
+func (l shiftLimits) allows(courierId int, deadline time.Time) bool {
+	end, scheduled := l[courierId]
+	return !scheduled || !deadline.After(end)
+}
+
+// shiftLimits loads the shift ends of the couriers, nil without WithShifts.
+func (uc *DeliveryUsecase) shiftLimits(ctx context.Context, ids []int) (shiftLimits, error) {
+	if uc.shifts == nil || len(ids) == 0 {
+		return nil, nil
+	}
+	ends, err := uc.shifts.ShiftEnds(ctx, ids, uc.now())
+	if err != nil {
+		return nil, fmt.Errorf("get courier shifts: %w", err)
+	}
+	return ends, nil
+}
+
+// orderDeadlines computes when one order is due with each transport: its
+// deadline, or the customer promise when that comes first.
+type orderDeadlines struct {
+	uc          *DeliveryUsecase
+	delivery    model.DeliveryContext
+	promisedBy  *time.Time
+	now         time.Time
+	byTransport map[model.TransportType]time.Time
+}
+
+func (uc *DeliveryUsecase) orderDeadlines(delivery model.DeliveryContext, promisedBy *time.Time, now time.Time) *orderDeadlines {
+	return &orderDeadlines{uc: uc, delivery: delivery, promisedBy: promisedBy, now: now}
+}
+
+func (d *orderDeadlines) For(transport model.TransportType) time.Time {
+	if deadline, ok := d.byTransport[transport]; ok {
+		return deadline
+	}
+	if d.byTransport == nil {
+		d.byTransport = make(map[model.TransportType]time.Time)
+	}
+	delivery := d.delivery
+	delivery.Transport = transport
+	deadline := dueBy(d.uc.timeFactory.For(delivery).Deadline(d.now), d.promisedBy, d.now)
+	d.byTransport[transport] = deadline
+	return deadline
+}
+
+// withinShift drops the candidates on a shift schedule that are off shift or
+// whose shift ends before the deadline the order would get with their transport.
+func (uc *DeliveryUsecase) withinShift(
+	ctx context.Context,
+	req model.AssignCourierRequest,
+	candidates []*model.CourierCandidate,
+) ([]*model.CourierCandidate, error) {
+	ids := make([]int, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.Courier.ID
+	}
+	limits, err := uc.shiftLimits(ctx, ids)
+	if err != nil || len(limits) == 0 {
+		return candidates, err
+	}
+	delivery, err := uc.deliveryContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	deadlines := uc.orderDeadlines(delivery, req.PromisedBy, uc.now())
+	kept := make([]*model.CourierCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		if limits.allows(c.Courier.ID, deadlines.For(c.Courier.TransportType)) {
+			kept = append(kept, c)
+		}
+	}
+	return kept, nil
+}
+
+// checkShift returns ErrCourierUnavailable when the courier requested for the
+// order is off shift or would finish it after its shift ends.
+func (uc *DeliveryUsecase) checkShift(ctx context.Context, req model.AssignCourierRequest, courier *model.CourierModel) error {
+	limits, err := uc.shiftLimits(ctx, []int{courier.ID})
+	if err != nil || len(limits) == 0 {
+		return err
+	}
+	delivery, err := uc.deliveryContext(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	deadline := uc.orderDeadlines(delivery, req.PromisedBy, uc.now()).For(courier.TransportType)
+	if limits.allows(courier.ID, deadline) {
+		return nil
+	}
+	if end := limits[courier.ID]; !end.IsZero() {
+		return fmt.Errorf("%w: courier %d shift ends at %s, before the deadline %s",
+			ErrCourierUnavailable, courier.ID, end.Format(time.RFC3339), deadline.Format(time.RFC3339))
+	}
+	return fmt.Errorf("%w: courier %d is off shift", ErrCourierUnavailable, courier.ID)
+}
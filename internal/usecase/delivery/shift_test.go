@@ -0,0 +1,163 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+	repoerrors "github.com/cdxy1/go-courier-service/internal/repository/delivery"
+)
+
+type stubShiftRepository struct {
+	ends map[int]time.Time
+	err  error
+}
+
+func (s stubShiftRepository) ShiftEnds(ctx context.Context, ids []int, at time.Time) (map[int]time.Time, error) {
+	ends := make(map[int]time.Time)
+	for _, id := range ids {
+		if end, ok := s.ends[id]; ok {
+			ends[id] = end
+		}
+	}
+	return ends, s.err
+}
+
+func TestDeliveryUsecase_AssignShift(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.December, 22, 10, 0, 0, 0, time.UTC)
+	factory := model.NewDeliveryTimeFactory(time.Minute*30, time.Minute*15, time.Minute*5)
+	promise := now.Add(15 * time.Minute)
+
+	couriers := []*model.CourierModel{
+		{ID: 1, Status: model.CourierStatusAvailable, TransportType: model.TransportOnFoot},
+		{ID: 2, Status: model.CourierStatusAvailable, TransportType: model.TransportOnFoot},
+		{ID: 3, Status: model.CourierStatusAvailable, TransportType: model.TransportCar},
+		{ID: 4, Status: model.CourierStatusAvailable, TransportType: model.TransportOnFoot},
+	}
+	// Courier 1 is off shift, courier 4 has no shifts. Without shifts the
+	// lower id is picked first.
+	shifts := stubShiftRepository{ends: map[int]time.Time{
+		1: {},
+		2: now.Add(20 * time.Minute),
+		3: now.Add(10 * time.Minute),
+	}}
+
+	tests := []struct {
+		name      string
+		req       model.AssignCourierRequest
+		available []int
+		shifts    shiftRepository
+		expectId  int
+		expectErr error
+	}{
+		{
+			name:      "shift ends before the deadline",
+			available: []int{2, 4},
+			shifts:    shifts,
+			expectId:  4,
+		},
+		{
+			name:      "off shift",
+			available: []int{1, 4},
+			shifts:    shifts,
+			expectId:  4,
+		},
+		{
+			name:      "nobody within shift queues the order",
+			available: []int{1, 2},
+			shifts:    shifts,
+			expectErr: ErrAssignmentQueued,
+		},
+		{
+			name:      "promise shortens the deadline",
+			req:       model.AssignCourierRequest{PromisedBy: &promise},
+			available: []int{2, 4},
+			shifts:    shifts,
+			expectId:  2,
+		},
+		{
+			name:      "requested courier off shift",
+			req:       model.AssignCourierRequest{CourierID: 1},
+			shifts:    shifts,
+			expectErr: ErrCourierUnavailable,
+		},
+		{
+			name:      "requested courier shift too short",
+			req:       model.AssignCourierRequest{CourierID: 2},
+			shifts:    shifts,
+			expectErr: ErrCourierUnavailable,
+		},
+		{
+			name:     "requested courier within shift",
+			req:      model.AssignCourierRequest{CourierID: 3},
+			shifts:   shifts,
+			expectId: 3,
+		},
+		{
+			name:      "shifts unavailable",
+			available: []int{2, 4},
+			shifts:    stubShiftRepository{err: errBoom},
+			expectErr: errBoom,
+		},
+		{
+			name:      "without shifts",
+			available: []int{2, 4},
+			expectId:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cRepo := newMockCourierRepository(t)
+			dRepo := newMockDeliveryRepository(t)
+			cRepo.listAvailFn = func(ctx context.Context, filter model.AvailableCourierFilter) ([]*model.CourierCandidate, error) {
+				candidates := make([]*model.CourierCandidate, 0, len(tt.available))
+				for _, id := range tt.available {
+					candidates = append(candidates, &model.CourierCandidate{Courier: couriers[id-1]})
+				}
+				return candidates, nil
+			}
+			cRepo.skipLockedFn = func(ctx context.Context, id int) (*model.CourierModel, error) {
+				return couriers[id-1], nil
+			}
+			cRepo.getForUpdateFn = func(ctx context.Context, id int) (*model.CourierModel, error) {
+				return couriers[id-1], nil
+			}
+			cRepo.markAssignedFn = func(ctx context.Context, id int) error { return nil }
+			dRepo.getByOrderIDFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+				return nil, repoerrors.ErrDeliveryNotFound
+			}
+			dRepo.createFn = func(ctx context.Context, delivery *model.DeliveryModel) error { return nil }
+			dRepo.enqueueFn = func(ctx context.Context, pending *model.PendingAssignment) error { return nil }
+
+			var opts []Option
+			if tt.shifts != nil {
+				opts = append(opts, WithShifts(tt.shifts))
+			}
+			uc := NewDeliveryUsecase(cRepo, dRepo, newMockTxManager(t), factory, func() time.Time { return now }, opts...)
+
+			req := tt.req
+			req.OrderID = "order-1"
+			_, courier, err := uc.AssignCourier(context.Background(), req)
+			if tt.expectErr != nil {
+				if !errors.Is(err, tt.expectErr) {
+					t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if courier.ID != tt.expectId {
+				t.Fatalf("expected courier %d, got %d", tt.expectId, courier.ID)
+			}
+		})
+	}
+}
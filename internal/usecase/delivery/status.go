@@ -0,0 +1,76 @@
+package delivery
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+)
+
+var allowedTransitions = map[model.DeliveryStatus][]model.DeliveryStatus{
+	model.DeliveryStatusAssigned: {
+		model.DeliveryStatusPickedUp,
+		model.DeliveryStatusInTransit,
+		model.DeliveryStatusDelivered,
+		model.DeliveryStatusCancelled,
+		model.DeliveryStatusExpired,
+	},
+	model.DeliveryStatusPickedUp: {
+		model.DeliveryStatusInTransit,
+		model.DeliveryStatusDelivered,
+		model.DeliveryStatusCancelled,
+		model.DeliveryStatusExpired,
+	},
+	model.DeliveryStatusInTransit: {
+		model.DeliveryStatusDelivered,
+		model.DeliveryStatusCancelled,
+		model.DeliveryStatusExpired,
+	},
+}
+
+func isKnownStatus(status model.DeliveryStatus) bool {
+	switch status {
+	case model.DeliveryStatusAssigned,
+		model.DeliveryStatusPickedUp,
+		model.DeliveryStatusInTransit,
+		model.DeliveryStatusDelivered,
+		model.DeliveryStatusCancelled,
+		model.DeliveryStatusExpired,
+		model.DeliveryStatusLegacy:
+		return true
+	}
+	return false
+}
+
+func canTransition(from, to model.DeliveryStatus) bool {
+	for _, next := range allowedTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// transition moves the delivery to the given status and stamps the matching
+// stage timestamp. The delivery is left untouched when the move is not allowed.
+func transition(delivery *model.DeliveryModel, to model.DeliveryStatus, at time.Time) error {
+	if !canTransition(delivery.Status, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, delivery.Status, to)
+	}
+
+	switch to {
+	case model.DeliveryStatusPickedUp:
+		delivery.PickedUpAt = &at
+	case model.DeliveryStatusInTransit:
+		delivery.InTransitAt = &at
+	case model.DeliveryStatusDelivered:
+		delivery.DeliveredAt = &at
+	case model.DeliveryStatusCancelled:
+		delivery.CancelledAt = &at
+	case model.DeliveryStatusExpired:
+		delivery.ExpiredAt = &at
+	}
+	delivery.Status = to
+	delivery.UpdatedAt = at
+	return nil
+}
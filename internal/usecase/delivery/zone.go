@@ -0,0 +1,91 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cdxy1/go-courier-service/internal/geo"
+	"github.com/cdxy1/go-courier-service/internal/model"
+)
+
+// preferZone narrows the ranked candidates down for an order inside a zone: the
+// couriers of that zone come first, followed by couriers of neighbouring zones,
+// couriers without a home zone and then everyone else, as far as the zone
+// fallback allows. The selector's order is kept within each group. Orders
+// outside every zone, or without a pickup or dropoff point, are not constrained.
+func (uc *DeliveryUsecase) preferZone(
+	ctx context.Context,
+	req model.AssignCourierRequest,
+	ranked []*model.CourierCandidate,
+) ([]*model.CourierCandidate, error) {
+	zone, zones, err := uc.orderZone(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if zone == nil {
+		return ranked, nil
+	}
+	neighbours := model.NeighboursOf(zones, zone.ID)
+
+	var groups [zoneOther + 1][]*model.CourierCandidate
+	for _, c := range ranked {
+		if dist, ok := uc.zoneDistance(c.Courier, zone, neighbours); ok {
+			groups[dist] = append(groups[dist], c)
+		}
+	}
+
+	preferred := make([]*model.CourierCandidate, 0, len(ranked))
+	for _, group := range groups {
+		preferred = append(preferred, group...)
+	}
+	return preferred, nil
+}
+
+// Distances of a courier from the order's zone, closest first.
+const (
+	zoneHome = iota
+	zoneNeighbour
+	zoneNone
+	zoneOther
+)
+
+// zoneDistance returns how far the courier is from the order's zone, and false
+// when the zone fallback excludes the courier. Couriers without a home zone are
+// never excluded, so orders of a new zone are not stuck before its couriers are
+// assigned to it.
+func (uc *DeliveryUsecase) zoneDistance(courier *model.CourierModel, zone *model.Zone, neighbours map[int]bool) (int, bool) {
+	switch {
+	case courier.ZoneID == nil:
+		return zoneNone, true
+	case *courier.ZoneID == zone.ID:
+		return zoneHome, true
+	case neighbours[*courier.ZoneID]:
+		return zoneNeighbour, uc.zoneFallback != model.ZoneFallbackNone
+	}
+	return zoneOther, uc.zoneFallback == model.ZoneFallbackAny
+}
+
+// orderZone returns the zone of the order's pickup point, or of its dropoff
+// point when there is no pickup, together with all zones. The zone is nil for
+// orders outside every zone or without a location.
+func (uc *DeliveryUsecase) orderZone(ctx context.Context, req model.AssignCourierRequest) (*model.Zone, []*model.Zone, error) {
+	point := orderPoint(req)
+	if point == nil {
+		return nil, nil, nil
+	}
+
+	zones, err := uc.zones.GetAll(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list zones: %w", err)
+	}
+	return model.ZoneOf(zones, *point), zones, nil
+}
+
+// orderPoint returns the point that places the order in a zone: its pickup, or
+// its dropoff when there is no pickup.
+func orderPoint(req model.AssignCourierRequest) *geo.Point {
+	if req.Pickup != nil {
+		return req.Pickup
+	}
+	return req.Dropoff
+}
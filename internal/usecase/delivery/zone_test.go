@@ -0,0 +1,186 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/geo"
+	"github.com/cdxy1/go-courier-service/internal/model"
+	repoerrors "github.com/cdxy1/go-courier-service/internal/repository/delivery"
+)
+
+type stubZoneRepository struct {
+	zones []*model.Zone
+	err   error
+}
+
+func (s stubZoneRepository) GetAll(ctx context.Context) ([]*model.Zone, error) {
+	return s.zones, s.err
+}
+
+func TestDeliveryUsecase_AssignZone(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, time.December, 22, 10, 30, 0, 0, time.UTC)
+	factory := model.NewDeliveryTimeFactory(time.Minute*30, time.Minute*15, time.Minute*5)
+	zoneOf := func(id int) *int { return &id }
+
+	// Zones 1 and 2 are neighbours side by side, zone 3 lists zone 2 as its neighbour.
+	zones := stubZoneRepository{zones: []*model.Zone{
+		{ID: 1, Area: geo.Polygon{{Lat: 0, Lon: 0}, {Lat: 0, Lon: 1}, {Lat: 1, Lon: 1}, {Lat: 1, Lon: 0}}, NeighbourIDs: []int{2}},
+		{ID: 2, Area: geo.Polygon{{Lat: 0, Lon: 1}, {Lat: 0, Lon: 2}, {Lat: 1, Lon: 2}, {Lat: 1, Lon: 1}}},
+		{ID: 3, Area: geo.Polygon{{Lat: 1, Lon: 1}, {Lat: 1, Lon: 2}, {Lat: 2, Lon: 2}, {Lat: 2, Lon: 1}}, NeighbourIDs: []int{2}},
+	}}
+	inZone1 := geo.Point{Lat: 0.5, Lon: 0.5}
+	inZone2 := geo.Point{Lat: 0.5, Lon: 1.5}
+	outside := geo.Point{Lat: 5, Lon: 5}
+
+	// Couriers are listed in the order the default selector ranks them.
+	couriers := []*model.CourierModel{
+		{ID: 1, Status: model.CourierStatusAvailable},
+		{ID: 2, Status: model.CourierStatusAvailable, ZoneID: zoneOf(3)},
+		{ID: 3, Status: model.CourierStatusAvailable, ZoneID: zoneOf(2)},
+		{ID: 4, Status: model.CourierStatusAvailable, ZoneID: zoneOf(1)},
+	}
+
+	tests := []struct {
+		name      string
+		req       model.AssignCourierRequest
+		available []int
+		fallback  model.ZoneFallback
+		zones     stubZoneRepository
+		expectId  int
+		expectErr error
+	}{
+		{
+			name:      "in-zone courier preferred",
+			req:       model.AssignCourierRequest{Pickup: &inZone1},
+			available: []int{1, 2, 3, 4},
+			fallback:  model.ZoneFallbackAny,
+			zones:     zones,
+			expectId:  4,
+		},
+		{
+			name:      "dropoff used without pickup",
+			req:       model.AssignCourierRequest{Dropoff: &inZone2},
+			available: []int{1, 2, 3, 4},
+			zones:     zones,
+			expectId:  3,
+		},
+		{
+			name:      "no fallback keeps couriers without a zone",
+			req:       model.AssignCourierRequest{Pickup: &inZone1},
+			available: []int{1, 2, 3},
+			fallback:  model.ZoneFallbackNone,
+			zones:     zones,
+			expectId:  1,
+		},
+		{
+			name:      "no fallback queues the order",
+			req:       model.AssignCourierRequest{Pickup: &inZone1},
+			available: []int{2, 3},
+			fallback:  model.ZoneFallbackNone,
+			zones:     zones,
+			expectErr: ErrAssignmentQueued,
+		},
+		{
+			name:      "neighbour fallback",
+			req:       model.AssignCourierRequest{Pickup: &inZone1},
+			available: []int{1, 2, 3},
+			fallback:  model.ZoneFallbackNeighbours,
+			zones:     zones,
+			expectId:  3,
+		},
+		{
+			name:      "neighbourhood is symmetric",
+			req:       model.AssignCourierRequest{Pickup: &inZone2},
+			available: []int{1, 2},
+			fallback:  model.ZoneFallbackNeighbours,
+			zones:     zones,
+			expectId:  2,
+		},
+		{
+			name:      "any fallback",
+			req:       model.AssignCourierRequest{Pickup: &inZone1},
+			available: []int{2},
+			fallback:  model.ZoneFallbackAny,
+			zones:     zones,
+			expectId:  2,
+		},
+		{
+			name:      "couriers without a zone before other zones",
+			req:       model.AssignCourierRequest{Pickup: &inZone1},
+			available: []int{2, 1},
+			fallback:  model.ZoneFallbackAny,
+			zones:     zones,
+			expectId:  1,
+		},
+		{
+			name:      "order outside every zone",
+			req:       model.AssignCourierRequest{Pickup: &outside},
+			available: []int{1, 2, 3, 4},
+			zones:     zones,
+			expectId:  1,
+		},
+		{
+			name:      "order without location",
+			available: []int{1, 2, 3, 4},
+			zones:     zones,
+			expectId:  1,
+		},
+		{
+			name:      "zones unavailable",
+			req:       model.AssignCourierRequest{Pickup: &inZone1},
+			available: []int{1, 2, 3, 4},
+			zones:     stubZoneRepository{err: errBoom},
+			expectErr: errBoom,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cRepo := newMockCourierRepository(t)
+			dRepo := newMockDeliveryRepository(t)
+			cRepo.listAvailFn = func(ctx context.Context, filter model.AvailableCourierFilter) ([]*model.CourierCandidate, error) {
+				candidates := make([]*model.CourierCandidate, 0, len(tt.available))
+				for _, id := range tt.available {
+					candidates = append(candidates, &model.CourierCandidate{Courier: couriers[id-1]})
+				}
+				return candidates, nil
+			}
+			cRepo.skipLockedFn = func(ctx context.Context, id int) (*model.CourierModel, error) {
+				return couriers[id-1], nil
+			}
+			cRepo.markAssignedFn = func(ctx context.Context, id int) error { return nil }
+			dRepo.getByOrderIDFn = func(ctx context.Context, orderId string) (*model.DeliveryModel, error) {
+				return nil, repoerrors.ErrDeliveryNotFound
+			}
+			dRepo.createFn = func(ctx context.Context, delivery *model.DeliveryModel) error { return nil }
+			dRepo.enqueueFn = func(ctx context.Context, pending *model.PendingAssignment) error { return nil }
+
+			uc := NewDeliveryUsecase(cRepo, dRepo, newMockTxManager(t), factory, func() time.Time { return now },
+				WithZones(tt.zones, tt.fallback))
+
+			req := tt.req
+			req.OrderID = "order-1"
+			_, courier, err := uc.AssignCourier(context.Background(), req)
+			if tt.expectErr != nil {
+				if !errors.Is(err, tt.expectErr) {
+					t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if courier.ID != tt.expectId {
+				t.Fatalf("expected courier %d, got %d", tt.expectId, courier.ID)
+			}
+		})
+	}
+}
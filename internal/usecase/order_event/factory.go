@@ -15,10 +15,12 @@ type HandlerFactory struct {
 	handlers map[string]Handler
 }
 
-func NewHandlerFactory(uc deliveryUsecase) *HandlerFactory {
+// NewHandlerFactory creates the handlers of order status events. Created orders
+// are fetched through orders before a courier is assigned.
+func NewHandlerFactory(uc deliveryUsecase, orders orderGateway) *HandlerFactory {
 	f := &HandlerFactory{
 		handlers: map[string]Handler{
-			statusCreated:   &createdHandler{uc: uc},
+			statusCreated:   &createdHandler{uc: uc, orders: orders},
 			statusCancelled: &cancelledHandler{uc: uc},
 			statusCanceled:  &cancelledHandler{uc: uc},
 			statusCompleted: &completedHandler{uc: uc},
@@ -2,9 +2,13 @@ package order_event
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 
 	"github.com/cdxy1/go-courier-service/internal/model"
+	deliveryRepo "github.com/cdxy1/go-courier-service/internal/repository/delivery"
+	"github.com/cdxy1/go-courier-service/internal/usecase/delivery"
 )
 
 const (
@@ -16,18 +20,34 @@ const (
 )
 
 type deliveryUsecase interface {
-	Assign(ctx context.Context, orderID string) (*model.DeliveryModel, *model.CourierModel, error)
+	Assign(ctx context.Context, order *model.Order) (*model.DeliveryModel, *model.CourierModel, error)
 	Unassign(ctx context.Context, orderID string) (*model.DeliveryModel, error)
 	Complete(ctx context.Context, orderID string) (*model.DeliveryModel, error)
+	CancelPending(ctx context.Context, orderID string) (bool, error)
+}
+
+type orderGateway interface {
+	GetOrder(ctx context.Context, orderID string) (*model.Order, error)
 }
 
 type createdHandler struct {
-	uc deliveryUsecase
+	uc     deliveryUsecase
+	orders orderGateway
 }
 
 func (h *createdHandler) Handle(ctx context.Context, event model.OrderStatusEvent) error {
-	_, _, err := h.uc.Assign(ctx, event.OrderID)
+	// Status events carry no order contents. The order is fetched so that
+	// eligibility rules and the promised time apply just like for polled orders.
+	order, err := h.orders.GetOrder(ctx, event.OrderID)
+	if err != nil {
+		return fmt.Errorf("fetch order: %w", err)
+	}
+	_, _, err = h.uc.Assign(ctx, order)
 	if err != nil {
+		if errors.Is(err, delivery.ErrAssignmentQueued) {
+			log.Printf("order %s queued: no courier available", event.OrderID)
+			return nil
+		}
 		return fmt.Errorf("assign courier: %w", err)
 	}
 	return nil
@@ -40,6 +60,16 @@ type cancelledHandler struct {
 func (h *cancelledHandler) Handle(ctx context.Context, event model.OrderStatusEvent) error {
 	_, err := h.uc.Unassign(ctx, event.OrderID)
 	if err != nil {
+		if errors.Is(err, deliveryRepo.ErrDeliveryNotFound) {
+			// the order may still be waiting for a courier
+			removed, cancelErr := h.uc.CancelPending(ctx, event.OrderID)
+			if cancelErr != nil {
+				return fmt.Errorf("cancel pending assignment: %w", cancelErr)
+			}
+			if removed {
+				return nil
+			}
+		}
 		return fmt.Errorf("unassign courier: %w", err)
 	}
 	return nil
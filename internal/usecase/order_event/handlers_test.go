@@ -0,0 +1,101 @@
+package order_event
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+	"github.com/cdxy1/go-courier-service/internal/usecase/delivery"
+)
+
+var errBoom = errors.New("failed")
+
+type stubOrderGateway struct {
+	order *model.Order
+	err   error
+}
+
+func (s stubOrderGateway) GetOrder(ctx context.Context, orderID string) (*model.Order, error) {
+	return s.order, s.err
+}
+
+type stubDeliveryUsecase struct {
+	assigned  *model.Order
+	assignErr error
+}
+
+func (s *stubDeliveryUsecase) Assign(ctx context.Context, order *model.Order) (*model.DeliveryModel, *model.CourierModel, error) {
+	s.assigned = order
+	return nil, nil, s.assignErr
+}
+
+func (s *stubDeliveryUsecase) Unassign(ctx context.Context, orderID string) (*model.DeliveryModel, error) {
+	return nil, nil
+}
+
+func (s *stubDeliveryUsecase) Complete(ctx context.Context, orderID string) (*model.DeliveryModel, error) {
+	return nil, nil
+}
+
+func (s *stubDeliveryUsecase) CancelPending(ctx context.Context, orderID string) (bool, error) {
+	return false, nil
+}
+
+func TestCreatedHandler_Handle(t *testing.T) {
+	t.Parallel()
+
+	order := &model.Order{ID: "order-1", Items: make([]model.Item, 12)}
+
+	tests := []struct {
+		name         string
+		orders       stubOrderGateway
+		assignErr    error
+		expectAssign bool
+		expectErr    error
+	}{
+		{
+			name:         "assigns the fetched order",
+			orders:       stubOrderGateway{order: order},
+			expectAssign: true,
+		},
+		{
+			name:         "queued order",
+			orders:       stubOrderGateway{order: order},
+			assignErr:    delivery.ErrAssignmentQueued,
+			expectAssign: true,
+		},
+		{
+			name:         "assign error",
+			orders:       stubOrderGateway{order: order},
+			assignErr:    errBoom,
+			expectAssign: true,
+			expectErr:    errBoom,
+		},
+		{
+			name:      "order unavailable",
+			orders:    stubOrderGateway{err: errBoom},
+			expectErr: errBoom,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := &stubDeliveryUsecase{assignErr: tt.assignErr}
+			h := &createdHandler{uc: uc, orders: tt.orders}
+			err := h.Handle(context.Background(), model.OrderStatusEvent{OrderID: "order-1", Status: statusCreated})
+			if !errors.Is(err, tt.expectErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+			}
+			if tt.expectAssign && uc.assigned != order {
+				t.Fatalf("expected the fetched order to be assigned, got %+v", uc.assigned)
+			}
+			if !tt.expectAssign && uc.assigned != nil {
+				t.Fatalf("unexpected assignment: %+v", uc.assigned)
+			}
+		})
+	}
+}
@@ -42,6 +42,10 @@ func (p *Processor) Handle(ctx context.Context, event model.OrderStatusEvent) er
 		return nil
 	}
 
+	ctx = model.WithEventTrigger(ctx, model.EventTrigger{
+		Source: model.EventSourceKafka,
+		Reason: fmt.Sprintf("order %s", strings.ToLower(strings.TrimSpace(event.Status))),
+	})
 	return handler.Handle(ctx, event)
 }
 
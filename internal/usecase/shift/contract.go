@@ -0,0 +1,29 @@
+package shift
+
+import (
+	"context"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+)
+
+type shiftRepository interface {
+	Create(ctx context.Context, shift *model.Shift) (int, error)
+	Update(ctx context.Context, shift *model.Shift) error
+	Delete(ctx context.Context, id int) error
+	GetOneById(ctx context.Context, id int) (*model.Shift, error)
+	List(ctx context.Context, filter model.ShiftFilter) ([]*model.Shift, error)
+	CreateTemplate(ctx context.Context, template *model.ShiftTemplate) (int, error)
+	UpdateTemplate(ctx context.Context, template *model.ShiftTemplate) error
+	DeleteTemplate(ctx context.Context, id int) error
+	GetTemplate(ctx context.Context, id int) (*model.ShiftTemplate, error)
+	ListTemplates(ctx context.Context, courierId int) ([]*model.ShiftTemplate, error)
+	PlanShifts(ctx context.Context, template *model.ShiftTemplate, shifts []*model.Shift, until time.Time) (int, error)
+	DeletePlannedShifts(ctx context.Context, templateId int, after time.Time) error
+	StartShifts(ctx context.Context, now time.Time) (int, error)
+	EndShifts(ctx context.Context, now time.Time) (int, error)
+}
+
+type txManager interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
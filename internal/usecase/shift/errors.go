@@ -0,0 +1,12 @@
+package shift
+
+import "errors"
+
+var (
+	ErrInvalidID           = errors.New("invalid id")
+	ErrInvalidCourierID    = errors.New("invalid courier id")
+	ErrInvalidShiftTime    = errors.New("shift must end after it starts and last at most 24 hours")
+	ErrInvalidWeekdays     = errors.New("shift template needs at least one weekday")
+	ErrInvalidTemplateTime = errors.New("shift template must start and end at different times of day")
+	ErrInvalidFilter       = errors.New("invalid shift filter")
+)
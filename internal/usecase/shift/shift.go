@@ -0,0 +1,305 @@
+package shift
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+	repo "github.com/cdxy1/go-courier-service/internal/repository/shift"
+)
+
+const defaultHorizon = 7 * 24 * time.Hour
+
+type ShiftUsecase struct {
+	repo    shiftRepository
+	tm      txManager
+	now     model.NowFunc
+	horizon time.Duration
+}
+
+// Option customizes optional behaviour of ShiftUsecase.
+type Option func(*ShiftUsecase)
+
+// WithHorizon sets how far ahead shifts are planned from templates. By default
+// it is a week.
+func WithHorizon(horizon time.Duration) Option {
+	return func(uc *ShiftUsecase) {
+		if horizon > 0 {
+			uc.horizon = horizon
+		}
+	}
+}
+
+func NewShiftUsecase(repo shiftRepository, tm txManager, now model.NowFunc, opts ...Option) *ShiftUsecase {
+	uc := &ShiftUsecase{repo: repo, tm: tm, now: now, horizon: defaultHorizon}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+func (uc *ShiftUsecase) GetShift(ctx context.Context, id int) (*model.Shift, error) {
+	if id <= 0 {
+		return nil, ErrInvalidID
+	}
+	shift, err := uc.repo.GetOneById(ctx, id)
+	if err != nil {
+		return nil, repoError("get shift", err)
+	}
+	return shift, nil
+}
+
+func (uc *ShiftUsecase) ListShifts(ctx context.Context, filter model.ShiftFilter) ([]*model.Shift, error) {
+	if filter.CourierID < 0 {
+		return nil, fmt.Errorf("%w: courier_id must be positive", ErrInvalidFilter)
+	}
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		return nil, fmt.Errorf("%w: to must be after from", ErrInvalidFilter)
+	}
+	shifts, err := uc.repo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("list shifts: %w", err)
+	}
+	return shifts, nil
+}
+
+// CreateShift plans a single shift. It must not overlap another shift of the courier.
+func (uc *ShiftUsecase) CreateShift(ctx context.Context, req *model.Shift) (int, error) {
+	if req.CourierID <= 0 {
+		return 0, ErrInvalidCourierID
+	}
+	if err := validateShift(req); err != nil {
+		return 0, err
+	}
+	id, err := uc.repo.Create(ctx, req)
+	if err != nil {
+		return 0, repoError("create shift", err)
+	}
+	return id, nil
+}
+
+// UpdateShift moves a shift. The courier of a shift does not change.
+func (uc *ShiftUsecase) UpdateShift(ctx context.Context, req *model.Shift) error {
+	if req.ID <= 0 {
+		return ErrInvalidID
+	}
+	if err := validateShift(req); err != nil {
+		return err
+	}
+	if err := uc.repo.Update(ctx, req); err != nil {
+		return repoError("update shift", err)
+	}
+	return nil
+}
+
+// DeleteShift removes a shift. A shift planned from a template is not planned again.
+func (uc *ShiftUsecase) DeleteShift(ctx context.Context, id int) error {
+	if id <= 0 {
+		return ErrInvalidID
+	}
+	if err := uc.repo.Delete(ctx, id); err != nil {
+		return repoError("delete shift", err)
+	}
+	return nil
+}
+
+func (uc *ShiftUsecase) GetTemplate(ctx context.Context, id int) (*model.ShiftTemplate, error) {
+	if id <= 0 {
+		return nil, ErrInvalidID
+	}
+	template, err := uc.repo.GetTemplate(ctx, id)
+	if err != nil {
+		return nil, repoError("get shift template", err)
+	}
+	return template, nil
+}
+
+// ListTemplates returns the templates of the courier, of every courier when
+// courierId is 0.
+func (uc *ShiftUsecase) ListTemplates(ctx context.Context, courierId int) ([]*model.ShiftTemplate, error) {
+	if courierId < 0 {
+		return nil, fmt.Errorf("%w: courier_id must be positive", ErrInvalidFilter)
+	}
+	templates, err := uc.repo.ListTemplates(ctx, courierId)
+	if err != nil {
+		return nil, fmt.Errorf("list shift templates: %w", err)
+	}
+	return templates, nil
+}
+
+// CreateTemplate stores the template and plans its shifts over the horizon,
+// including one already running today.
+func (uc *ShiftUsecase) CreateTemplate(ctx context.Context, req *model.ShiftTemplate) (int, error) {
+	if req.CourierID <= 0 {
+		return 0, ErrInvalidCourierID
+	}
+	if err := validateTemplate(req); err != nil {
+		return 0, err
+	}
+
+	var id int
+	err := uc.tm.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		if id, err = uc.repo.CreateTemplate(ctx, req); err != nil {
+			return repoError("create shift template", err)
+		}
+		template := *req
+		template.ID = id
+		template.GeneratedUntil = nil
+		_, err = uc.plan(ctx, &template, uc.now())
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// UpdateTemplate changes the schedule of the template. Shifts planned from it
+// that have not started yet are planned again.
+func (uc *ShiftUsecase) UpdateTemplate(ctx context.Context, req *model.ShiftTemplate) error {
+	if req.ID <= 0 {
+		return ErrInvalidID
+	}
+	if err := validateTemplate(req); err != nil {
+		return err
+	}
+
+	return uc.tm.WithTx(ctx, func(ctx context.Context) error {
+		if err := uc.repo.UpdateTemplate(ctx, req); err != nil {
+			return repoError("update shift template", err)
+		}
+		now := uc.now()
+		if err := uc.repo.DeletePlannedShifts(ctx, req.ID, now); err != nil {
+			return fmt.Errorf("delete planned shifts: %w", err)
+		}
+		template, err := uc.repo.GetTemplate(ctx, req.ID)
+		if err != nil {
+			return repoError("get shift template", err)
+		}
+		_, err = uc.plan(ctx, template, now)
+		return err
+	})
+}
+
+// DeleteTemplate removes the template and the shifts planned from it that
+// have not started yet.
+func (uc *ShiftUsecase) DeleteTemplate(ctx context.Context, id int) error {
+	if id <= 0 {
+		return ErrInvalidID
+	}
+	return uc.tm.WithTx(ctx, func(ctx context.Context) error {
+		if err := uc.repo.DeletePlannedShifts(ctx, id, uc.now()); err != nil {
+			return fmt.Errorf("delete planned shifts: %w", err)
+		}
+		if err := uc.repo.DeleteTemplate(ctx, id); err != nil {
+			return repoError("delete shift template", err)
+		}
+		return nil
+	})
+}
+
+// ApplySchedule plans shifts from the templates whose plan runs short of half
+// the horizon, makes paused couriers available whose shift is running and
+// started after they were paused, and pauses available couriers with shifts
+// but none running now. Busy couriers are left alone and paused at a later run
+// once they are free.
+func (uc *ShiftUsecase) ApplySchedule(ctx context.Context) (*model.ShiftRun, error) {
+	run := &model.ShiftRun{At: uc.now()}
+	err := uc.tm.WithTx(ctx, func(ctx context.Context) error {
+		templates, err := uc.repo.ListTemplates(ctx, 0)
+		if err != nil {
+			return fmt.Errorf("list shift templates: %w", err)
+		}
+		for _, template := range templates {
+			if template.GeneratedUntil != nil && template.GeneratedUntil.After(run.At.Add(uc.horizon/2)) {
+				continue
+			}
+			planned, err := uc.plan(ctx, template, run.At)
+			if err != nil {
+				return err
+			}
+			run.Planned += planned
+		}
+
+		if run.Started, err = uc.repo.StartShifts(ctx, run.At); err != nil {
+			return fmt.Errorf("start shifts: %w", err)
+		}
+		if run.Ended, err = uc.repo.EndShifts(ctx, run.At); err != nil {
+			return fmt.Errorf("end shifts: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// plan stores the shifts of the template up to the horizon, from where the
+// last plan ended or, for a new plan, from the shift running now.
+func (uc *ShiftUsecase) plan(ctx context.Context, template *model.ShiftTemplate, now time.Time) (int, error) {
+	from := now.Add(-template.Length())
+	if template.GeneratedUntil != nil && template.GeneratedUntil.After(from) {
+		from = *template.GeneratedUntil
+	}
+	until := now.Add(uc.horizon)
+	if !until.After(from) {
+		return 0, nil
+	}
+	planned, err := uc.repo.PlanShifts(ctx, template, template.Occurrences(from, until), until)
+	if err != nil {
+		return 0, fmt.Errorf("plan shifts of template %d: %w", template.ID, err)
+	}
+	return planned, nil
+}
+
+func validateShift(req *model.Shift) error {
+	req.StartsAt, req.EndsAt = req.StartsAt.UTC(), req.EndsAt.UTC()
+	if req.StartsAt.IsZero() || !req.EndsAt.After(req.StartsAt) || req.EndsAt.Sub(req.StartsAt) > model.MaxShiftLength {
+		return ErrInvalidShiftTime
+	}
+	return nil
+}
+
+// validateTemplate checks the template fields; duplicate weekdays are dropped.
+func validateTemplate(req *model.ShiftTemplate) error {
+	if len(req.Weekdays) == 0 {
+		return ErrInvalidWeekdays
+	}
+	days := make([]time.Weekday, 0, len(req.Weekdays))
+	for _, d := range req.Weekdays {
+		if d < time.Sunday || d > time.Saturday {
+			return ErrInvalidWeekdays
+		}
+		if !slices.Contains(days, d) {
+			days = append(days, d)
+		}
+	}
+	slices.Sort(days)
+	req.Weekdays = days
+
+	inDay := func(minute int) bool { return minute >= 0 && minute < 24*60 }
+	if !inDay(req.StartMinute) || !inDay(req.EndMinute) || req.StartMinute == req.EndMinute {
+		return ErrInvalidTemplateTime
+	}
+	return nil
+}
+
+// repoError keeps the repository errors callers tell apart and wraps the others.
+func repoError(op string, err error) error {
+	for _, known := range []error{
+		repo.ErrShiftNotFound,
+		repo.ErrTemplateNotFound,
+		repo.ErrShiftOverlaps,
+		repo.ErrCourierNotFound,
+	} {
+		if errors.Is(err, known) {
+			return known
+		}
+	}
+	return fmt.Errorf("%s: %w", op, err)
+}
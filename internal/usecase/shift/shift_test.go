@@ -0,0 +1,433 @@
+package shift
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+	repoerrors "github.com/cdxy1/go-courier-service/internal/repository/shift"
+)
+
+var errBoom = errors.New("failed")
+
+// now is a Monday.
+var now = time.Date(2025, time.December, 22, 10, 0, 0, 0, time.UTC)
+
+type mockShiftRepository struct {
+	t                *testing.T
+	createFn         func(ctx context.Context, shift *model.Shift) (int, error)
+	updateFn         func(ctx context.Context, shift *model.Shift) error
+	deleteFn         func(ctx context.Context, id int) error
+	getOneByIDFn     func(ctx context.Context, id int) (*model.Shift, error)
+	listFn           func(ctx context.Context, filter model.ShiftFilter) ([]*model.Shift, error)
+	createTemplateFn func(ctx context.Context, template *model.ShiftTemplate) (int, error)
+	updateTemplateFn func(ctx context.Context, template *model.ShiftTemplate) error
+	deleteTemplateFn func(ctx context.Context, id int) error
+	getTemplateFn    func(ctx context.Context, id int) (*model.ShiftTemplate, error)
+	listTemplatesFn  func(ctx context.Context, courierId int) ([]*model.ShiftTemplate, error)
+	planShiftsFn     func(ctx context.Context, template *model.ShiftTemplate, shifts []*model.Shift, until time.Time) (int, error)
+	deletePlannedFn  func(ctx context.Context, templateId int, after time.Time) error
+	startShiftsFn    func(ctx context.Context, now time.Time) (int, error)
+	endShiftsFn      func(ctx context.Context, now time.Time) (int, error)
+}
+
+func newMockShiftRepository(t *testing.T) *mockShiftRepository {
+	return &mockShiftRepository{t: t}
+}
+
+func (m *mockShiftRepository) Create(ctx context.Context, shift *model.Shift) (int, error) {
+	if m.createFn == nil {
+		m.t.Fatalf("Create called unexpectedly")
+	}
+	return m.createFn(ctx, shift)
+}
+
+func (m *mockShiftRepository) Update(ctx context.Context, shift *model.Shift) error {
+	if m.updateFn == nil {
+		m.t.Fatalf("Update called unexpectedly")
+	}
+	return m.updateFn(ctx, shift)
+}
+
+func (m *mockShiftRepository) Delete(ctx context.Context, id int) error {
+	if m.deleteFn == nil {
+		m.t.Fatalf("Delete called unexpectedly")
+	}
+	return m.deleteFn(ctx, id)
+}
+
+func (m *mockShiftRepository) GetOneById(ctx context.Context, id int) (*model.Shift, error) {
+	if m.getOneByIDFn == nil {
+		m.t.Fatalf("GetOneById called unexpectedly")
+	}
+	return m.getOneByIDFn(ctx, id)
+}
+
+func (m *mockShiftRepository) List(ctx context.Context, filter model.ShiftFilter) ([]*model.Shift, error) {
+	if m.listFn == nil {
+		m.t.Fatalf("List called unexpectedly")
+	}
+	return m.listFn(ctx, filter)
+}
+
+func (m *mockShiftRepository) CreateTemplate(ctx context.Context, template *model.ShiftTemplate) (int, error) {
+	if m.createTemplateFn == nil {
+		m.t.Fatalf("CreateTemplate called unexpectedly")
+	}
+	return m.createTemplateFn(ctx, template)
+}
+
+func (m *mockShiftRepository) UpdateTemplate(ctx context.Context, template *model.ShiftTemplate) error {
+	if m.updateTemplateFn == nil {
+		m.t.Fatalf("UpdateTemplate called unexpectedly")
+	}
+	return m.updateTemplateFn(ctx, template)
+}
+
+func (m *mockShiftRepository) DeleteTemplate(ctx context.Context, id int) error {
+	if m.deleteTemplateFn == nil {
+		m.t.Fatalf("DeleteTemplate called unexpectedly")
+	}
+	return m.deleteTemplateFn(ctx, id)
+}
+
+func (m *mockShiftRepository) GetTemplate(ctx context.Context, id int) (*model.ShiftTemplate, error) {
+	if m.getTemplateFn == nil {
+		m.t.Fatalf("GetTemplate called unexpectedly")
+	}
+	return m.getTemplateFn(ctx, id)
+}
+
+func (m *mockShiftRepository) ListTemplates(ctx context.Context, courierId int) ([]*model.ShiftTemplate, error) {
+	if m.listTemplatesFn == nil {
+		m.t.Fatalf("ListTemplates called unexpectedly")
+	}
+	return m.listTemplatesFn(ctx, courierId)
+}
+
+func (m *mockShiftRepository) PlanShifts(ctx context.Context, template *model.ShiftTemplate, shifts []*model.Shift, until time.Time) (int, error) {
+	if m.planShiftsFn == nil {
+		m.t.Fatalf("PlanShifts called unexpectedly")
+	}
+	return m.planShiftsFn(ctx, template, shifts, until)
+}
+
+func (m *mockShiftRepository) DeletePlannedShifts(ctx context.Context, templateId int, after time.Time) error {
+	if m.deletePlannedFn == nil {
+		m.t.Fatalf("DeletePlannedShifts called unexpectedly")
+	}
+	return m.deletePlannedFn(ctx, templateId, after)
+}
+
+func (m *mockShiftRepository) StartShifts(ctx context.Context, now time.Time) (int, error) {
+	if m.startShiftsFn == nil {
+		m.t.Fatalf("StartShifts called unexpectedly")
+	}
+	return m.startShiftsFn(ctx, now)
+}
+
+func (m *mockShiftRepository) EndShifts(ctx context.Context, now time.Time) (int, error) {
+	if m.endShiftsFn == nil {
+		m.t.Fatalf("EndShifts called unexpectedly")
+	}
+	return m.endShiftsFn(ctx, now)
+}
+
+type passTxManager struct{}
+
+func (passTxManager) WithTx(ctx context.Context, fn func(context.Context) error) error {
+	return fn(ctx)
+}
+
+func newUsecase(repo *mockShiftRepository, opts ...Option) *ShiftUsecase {
+	return NewShiftUsecase(repo, passTxManager{}, func() time.Time { return now }, opts...)
+}
+
+func TestShiftUsecase_CreateShift(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		req       model.Shift
+		createErr error
+		expectErr error
+	}{
+		{
+			name:      "missing courier",
+			req:       model.Shift{StartsAt: now, EndsAt: now.Add(time.Hour)},
+			expectErr: ErrInvalidCourierID,
+		},
+		{
+			name:      "missing start",
+			req:       model.Shift{CourierID: 1, EndsAt: now},
+			expectErr: ErrInvalidShiftTime,
+		},
+		{
+			name:      "ends before start",
+			req:       model.Shift{CourierID: 1, StartsAt: now, EndsAt: now.Add(-time.Hour)},
+			expectErr: ErrInvalidShiftTime,
+		},
+		{
+			name:      "too long",
+			req:       model.Shift{CourierID: 1, StartsAt: now, EndsAt: now.Add(25 * time.Hour)},
+			expectErr: ErrInvalidShiftTime,
+		},
+		{
+			name:      "overlap",
+			req:       model.Shift{CourierID: 1, StartsAt: now, EndsAt: now.Add(time.Hour)},
+			createErr: repoerrors.ErrShiftOverlaps,
+			expectErr: repoerrors.ErrShiftOverlaps,
+		},
+		{
+			name:      "repository error",
+			req:       model.Shift{CourierID: 1, StartsAt: now, EndsAt: now.Add(time.Hour)},
+			createErr: errBoom,
+			expectErr: errBoom,
+		},
+		{
+			name: "success",
+			req:  model.Shift{CourierID: 1, StartsAt: now, EndsAt: now.Add(8 * time.Hour)},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := newMockShiftRepository(t)
+			repo.createFn = func(ctx context.Context, shift *model.Shift) (int, error) {
+				return 5, tt.createErr
+			}
+			uc := newUsecase(repo)
+
+			req := tt.req
+			id, err := uc.CreateShift(context.Background(), &req)
+			if !errors.Is(err, tt.expectErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+			}
+			if tt.expectErr == nil && id != 5 {
+				t.Fatalf("expected id 5, got %d", id)
+			}
+		})
+	}
+}
+
+func TestShiftUsecase_CreateTemplate(t *testing.T) {
+	t.Parallel()
+
+	repo := newMockShiftRepository(t)
+	repo.createTemplateFn = func(ctx context.Context, template *model.ShiftTemplate) (int, error) {
+		if len(template.Weekdays) != 2 || template.Weekdays[0] != time.Monday || template.Weekdays[1] != time.Tuesday {
+			t.Fatalf("expected sorted unique weekdays, got %v", template.Weekdays)
+		}
+		return 3, nil
+	}
+	var planned []*model.Shift
+	repo.planShiftsFn = func(ctx context.Context, template *model.ShiftTemplate, shifts []*model.Shift, until time.Time) (int, error) {
+		if template.ID != 3 {
+			t.Fatalf("expected template 3, got %d", template.ID)
+		}
+		if !until.Equal(now.Add(48 * time.Hour)) {
+			t.Fatalf("unexpected plan end %s", until)
+		}
+		planned = shifts
+		return len(shifts), nil
+	}
+	uc := newUsecase(repo, WithHorizon(48*time.Hour))
+
+	// 08:00-16:00 on Mondays and Tuesdays: the shift running now is planned too.
+	id, err := uc.CreateTemplate(context.Background(), &model.ShiftTemplate{
+		CourierID:   1,
+		Weekdays:    []time.Weekday{time.Tuesday, time.Monday, time.Tuesday},
+		StartMinute: 8 * 60,
+		EndMinute:   16 * 60,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != 3 {
+		t.Fatalf("expected id 3, got %d", id)
+	}
+
+	monday := time.Date(2025, time.December, 22, 8, 0, 0, 0, time.UTC)
+	if len(planned) != 2 || !planned[0].StartsAt.Equal(monday) || !planned[1].StartsAt.Equal(monday.Add(24*time.Hour)) {
+		t.Fatalf("unexpected shifts: %+v", planned)
+	}
+	if !planned[0].EndsAt.Equal(monday.Add(8*time.Hour)) || *planned[0].TemplateID != 3 {
+		t.Fatalf("unexpected shift: %+v", planned[0])
+	}
+}
+
+func TestShiftUsecase_CreateTemplate_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		req       model.ShiftTemplate
+		expectErr error
+	}{
+		{
+			name:      "missing courier",
+			req:       model.ShiftTemplate{Weekdays: []time.Weekday{time.Monday}, EndMinute: 60},
+			expectErr: ErrInvalidCourierID,
+		},
+		{
+			name:      "no weekdays",
+			req:       model.ShiftTemplate{CourierID: 1, EndMinute: 60},
+			expectErr: ErrInvalidWeekdays,
+		},
+		{
+			name:      "unknown weekday",
+			req:       model.ShiftTemplate{CourierID: 1, Weekdays: []time.Weekday{7}, EndMinute: 60},
+			expectErr: ErrInvalidWeekdays,
+		},
+		{
+			name:      "empty shift",
+			req:       model.ShiftTemplate{CourierID: 1, Weekdays: []time.Weekday{time.Monday}, StartMinute: 60, EndMinute: 60},
+			expectErr: ErrInvalidTemplateTime,
+		},
+		{
+			name:      "past midnight",
+			req:       model.ShiftTemplate{CourierID: 1, Weekdays: []time.Weekday{time.Monday}, EndMinute: 24 * 60},
+			expectErr: ErrInvalidTemplateTime,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc := newUsecase(newMockShiftRepository(t))
+			req := tt.req
+			if _, err := uc.CreateTemplate(context.Background(), &req); !errors.Is(err, tt.expectErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestShiftUsecase_UpdateTemplate(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	repo := newMockShiftRepository(t)
+	repo.updateTemplateFn = func(ctx context.Context, template *model.ShiftTemplate) error {
+		calls = append(calls, "update")
+		return nil
+	}
+	repo.deletePlannedFn = func(ctx context.Context, templateId int, after time.Time) error {
+		if templateId != 3 || !after.Equal(now) {
+			t.Fatalf("unexpected delete of template %d after %s", templateId, after)
+		}
+		calls = append(calls, "delete planned")
+		return nil
+	}
+	// The running shift was planned before, so the new plan starts after it.
+	plannedUntil := now.Add(time.Hour)
+	repo.getTemplateFn = func(ctx context.Context, id int) (*model.ShiftTemplate, error) {
+		return &model.ShiftTemplate{
+			ID: id, CourierID: 1, Weekdays: []time.Weekday{time.Monday, time.Tuesday},
+			StartMinute: 9 * 60, EndMinute: 17 * 60, GeneratedUntil: &plannedUntil,
+		}, nil
+	}
+	repo.planShiftsFn = func(ctx context.Context, template *model.ShiftTemplate, shifts []*model.Shift, until time.Time) (int, error) {
+		calls = append(calls, "plan")
+		if len(shifts) != 1 || !shifts[0].StartsAt.Equal(time.Date(2025, time.December, 23, 9, 0, 0, 0, time.UTC)) {
+			t.Fatalf("unexpected shifts: %+v", shifts)
+		}
+		return 1, nil
+	}
+	uc := newUsecase(repo, WithHorizon(48*time.Hour))
+
+	err := uc.UpdateTemplate(context.Background(), &model.ShiftTemplate{
+		ID: 3, Weekdays: []time.Weekday{time.Monday, time.Tuesday}, StartMinute: 9 * 60, EndMinute: 17 * 60,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(calls) != 3 || calls[0] != "update" || calls[1] != "delete planned" || calls[2] != "plan" {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+}
+
+func TestShiftUsecase_UpdateTemplate_NotFound(t *testing.T) {
+	t.Parallel()
+
+	repo := newMockShiftRepository(t)
+	repo.updateTemplateFn = func(ctx context.Context, template *model.ShiftTemplate) error {
+		return repoerrors.ErrTemplateNotFound
+	}
+	uc := newUsecase(repo)
+
+	err := uc.UpdateTemplate(context.Background(), &model.ShiftTemplate{
+		ID: 3, Weekdays: []time.Weekday{time.Monday}, StartMinute: 60, EndMinute: 120,
+	})
+	if !errors.Is(err, repoerrors.ErrTemplateNotFound) {
+		t.Fatalf("expected ErrTemplateNotFound, got %v", err)
+	}
+}
+
+func TestShiftUsecase_ApplySchedule(t *testing.T) {
+	t.Parallel()
+
+	farAhead := now.Add(6 * 24 * time.Hour)
+	runsShort := now.Add(2 * 24 * time.Hour)
+
+	repo := newMockShiftRepository(t)
+	repo.listTemplatesFn = func(ctx context.Context, courierId int) ([]*model.ShiftTemplate, error) {
+		return []*model.ShiftTemplate{
+			{ID: 1, CourierID: 1, Weekdays: []time.Weekday{time.Monday}, EndMinute: 60, GeneratedUntil: &farAhead},
+			{ID: 2, CourierID: 2, Weekdays: []time.Weekday{time.Monday}, EndMinute: 60, GeneratedUntil: &runsShort},
+			{ID: 3, CourierID: 3, Weekdays: []time.Weekday{time.Monday}, EndMinute: 60},
+		}, nil
+	}
+	var plannedTemplates []int
+	repo.planShiftsFn = func(ctx context.Context, template *model.ShiftTemplate, shifts []*model.Shift, until time.Time) (int, error) {
+		plannedTemplates = append(plannedTemplates, template.ID)
+		return len(shifts), nil
+	}
+	repo.startShiftsFn = func(ctx context.Context, n time.Time) (int, error) {
+		if !n.Equal(now) {
+			t.Fatalf("unexpected start time %s", n)
+		}
+		return 2, nil
+	}
+	repo.endShiftsFn = func(ctx context.Context, n time.Time) (int, error) {
+		return 1, nil
+	}
+	uc := newUsecase(repo)
+
+	run, err := uc.ApplySchedule(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plannedTemplates) != 2 || plannedTemplates[0] != 2 || plannedTemplates[1] != 3 {
+		t.Fatalf("expected templates 2 and 3 planned, got %v", plannedTemplates)
+	}
+	// Template 2 gets the Monday after its plan, template 3 the next one only:
+	// today's shift already ended.
+	if run.Planned != 2 || run.Started != 2 || run.Ended != 1 || !run.At.Equal(now) {
+		t.Fatalf("unexpected run: %+v", run)
+	}
+}
+
+func TestShiftUsecase_ApplySchedule_Error(t *testing.T) {
+	t.Parallel()
+
+	repo := newMockShiftRepository(t)
+	repo.listTemplatesFn = func(ctx context.Context, courierId int) ([]*model.ShiftTemplate, error) {
+		return nil, nil
+	}
+	repo.startShiftsFn = func(ctx context.Context, now time.Time) (int, error) {
+		return 0, errBoom
+	}
+	uc := newUsecase(repo)
+
+	if _, err := uc.ApplySchedule(context.Background()); !errors.Is(err, errBoom) {
+		t.Fatalf("expected errBoom, got %v", err)
+	}
+}
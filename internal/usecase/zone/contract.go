@@ -0,0 +1,15 @@
+package zone
+
+import (
+	"context"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+)
+
+type zoneRepository interface {
+	Create(ctx context.Context, zone *model.Zone) (int, error)
+	Update(ctx context.Context, zone *model.Zone) error
+	Delete(ctx context.Context, id int) error
+	GetOneById(ctx context.Context, id int) (*model.Zone, error)
+	GetAll(ctx context.Context) ([]*model.Zone, error)
+}
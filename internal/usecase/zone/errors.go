@@ -0,0 +1,10 @@
+package zone
+
+import "errors"
+
+var (
+	ErrInvalidID        = errors.New("invalid id")
+	ErrInvalidName      = errors.New("invalid name")
+	ErrInvalidArea      = errors.New("zone area must be a polygon of at least three valid points")
+	ErrInvalidNeighbour = errors.New("invalid neighbour zone")
+)
@@ -0,0 +1,121 @@
+package zone
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+	repo "github.com/cdxy1/go-courier-service/internal/repository/zone"
+)
+
+type ZoneUsecase struct {
+	repo zoneRepository
+}
+
+func NewZoneUsecase(repo zoneRepository) *ZoneUsecase {
+	return &ZoneUsecase{repo: repo}
+}
+
+func (uc *ZoneUsecase) GetOneById(ctx context.Context, id int) (*model.Zone, error) {
+	if id <= 0 {
+		return nil, ErrInvalidID
+	}
+	zone, err := uc.repo.GetOneById(ctx, id)
+	if err != nil {
+		if errors.Is(err, repo.ErrZoneNotFound) {
+			return nil, repo.ErrZoneNotFound
+		}
+		return nil, fmt.Errorf("get zone: %w", err)
+	}
+	return zone, nil
+}
+
+func (uc *ZoneUsecase) GetAll(ctx context.Context) ([]*model.Zone, error) {
+	return uc.repo.GetAll(ctx)
+}
+
+func (uc *ZoneUsecase) Create(ctx context.Context, req *model.Zone) (int, error) {
+	if err := uc.validate(ctx, req); err != nil {
+		return 0, err
+	}
+	id, err := uc.repo.Create(ctx, req)
+	if err != nil {
+		if errors.Is(err, repo.ErrZoneNameExists) {
+			return 0, repo.ErrZoneNameExists
+		}
+		return 0, fmt.Errorf("create zone: %w", err)
+	}
+	return id, nil
+}
+
+func (uc *ZoneUsecase) Update(ctx context.Context, req *model.Zone) error {
+	if req.ID <= 0 {
+		return ErrInvalidID
+	}
+	if err := uc.validate(ctx, req); err != nil {
+		return err
+	}
+	if err := uc.repo.Update(ctx, req); err != nil {
+		if errors.Is(err, repo.ErrZoneNotFound) {
+			return repo.ErrZoneNotFound
+		}
+		if errors.Is(err, repo.ErrZoneNameExists) {
+			return repo.ErrZoneNameExists
+		}
+		return fmt.Errorf("update zone: %w", err)
+	}
+	return nil
+}
+
+func (uc *ZoneUsecase) Delete(ctx context.Context, id int) error {
+	if id <= 0 {
+		return ErrInvalidID
+	}
+	if err := uc.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, repo.ErrZoneNotFound) {
+			return repo.ErrZoneNotFound
+		}
+		return fmt.Errorf("delete zone: %w", err)
+	}
+	return nil
+}
+
+// validate checks the zone fields. Neighbours must be other existing zones;
+// duplicates are dropped.
+func (uc *ZoneUsecase) validate(ctx context.Context, req *model.Zone) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return ErrInvalidName
+	}
+	if !req.Area.Valid() {
+		return ErrInvalidArea
+	}
+	if len(req.NeighbourIDs) == 0 {
+		return nil
+	}
+
+	zones, err := uc.repo.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("list zones: %w", err)
+	}
+	existing := make(map[int]bool, len(zones))
+	for _, z := range zones {
+		existing[z.ID] = true
+	}
+
+	seen := make(map[int]bool, len(req.NeighbourIDs))
+	neighbours := make([]int, 0, len(req.NeighbourIDs))
+	for _, id := range req.NeighbourIDs {
+		if id == req.ID || !existing[id] {
+			return ErrInvalidNeighbour
+		}
+		if !seen[id] {
+			seen[id] = true
+			neighbours = append(neighbours, id)
+		}
+	}
+	req.NeighbourIDs = neighbours
+	return nil
+}
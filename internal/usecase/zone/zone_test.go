@@ -0,0 +1,254 @@
+package zone
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/cdxy1/go-courier-service/internal/geo"
+	"github.com/cdxy1/go-courier-service/internal/model"
+	repoerrors "github.com/cdxy1/go-courier-service/internal/repository/zone"
+)
+
+var errBoom = errors.New("failed")
+
+var square = geo.Polygon{{Lat: 0, Lon: 0}, {Lat: 0, Lon: 1}, {Lat: 1, Lon: 1}, {Lat: 1, Lon: 0}}
+
+type mockZoneRepository struct {
+	t            *testing.T
+	createFn     func(ctx context.Context, zone *model.Zone) (int, error)
+	updateFn     func(ctx context.Context, zone *model.Zone) error
+	deleteFn     func(ctx context.Context, id int) error
+	getOneByIDFn func(ctx context.Context, id int) (*model.Zone, error)
+	getAllFn     func(ctx context.Context) ([]*model.Zone, error)
+}
+
+func newMockZoneRepository(t *testing.T) *mockZoneRepository {
+	return &mockZoneRepository{t: t}
+}
+
+func (m *mockZoneRepository) Create(ctx context.Context, zone *model.Zone) (int, error) {
+	if m.createFn == nil {
+		m.t.Fatalf("Create called unexpectedly")
+	}
+	return m.createFn(ctx, zone)
+}
+
+func (m *mockZoneRepository) Update(ctx context.Context, zone *model.Zone) error {
+	if m.updateFn == nil {
+		m.t.Fatalf("Update called unexpectedly")
+	}
+	return m.updateFn(ctx, zone)
+}
+
+func (m *mockZoneRepository) Delete(ctx context.Context, id int) error {
+	if m.deleteFn == nil {
+		m.t.Fatalf("Delete called unexpectedly")
+	}
+	return m.deleteFn(ctx, id)
+}
+
+func (m *mockZoneRepository) GetOneById(ctx context.Context, id int) (*model.Zone, error) {
+	if m.getOneByIDFn == nil {
+		m.t.Fatalf("GetOneById called unexpectedly")
+	}
+	return m.getOneByIDFn(ctx, id)
+}
+
+func (m *mockZoneRepository) GetAll(ctx context.Context) ([]*model.Zone, error) {
+	if m.getAllFn == nil {
+		m.t.Fatalf("GetAll called unexpectedly")
+	}
+	return m.getAllFn(ctx)
+}
+
+func withZones(repo *mockZoneRepository, ids ...int) {
+	repo.getAllFn = func(ctx context.Context) ([]*model.Zone, error) {
+		zones := make([]*model.Zone, 0, len(ids))
+		for _, id := range ids {
+			zones = append(zones, &model.Zone{ID: id})
+		}
+		return zones, nil
+	}
+}
+
+func TestZoneUsecase_Create(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		req            model.Zone
+		repoSetup      func(*mockZoneRepository)
+		expectErr      error
+		expectID       int
+		wantNeighbours []int
+	}{
+		{
+			name:      "empty name",
+			req:       model.Zone{Name: " ", Area: square},
+			repoSetup: func(_ *mockZoneRepository) {},
+			expectErr: ErrInvalidName,
+		},
+		{
+			name:      "invalid area",
+			req:       model.Zone{Name: "north", Area: square[:2]},
+			repoSetup: func(_ *mockZoneRepository) {},
+			expectErr: ErrInvalidArea,
+		},
+		{
+			name:      "unknown neighbour",
+			req:       model.Zone{Name: "north", Area: square, NeighbourIDs: []int{7}},
+			repoSetup: func(repo *mockZoneRepository) { withZones(repo, 1, 2) },
+			expectErr: ErrInvalidNeighbour,
+		},
+		{
+			name: "name exists",
+			req:  model.Zone{Name: "north", Area: square},
+			repoSetup: func(repo *mockZoneRepository) {
+				repo.createFn = func(ctx context.Context, zone *model.Zone) (int, error) {
+					return 0, repoerrors.ErrZoneNameExists
+				}
+			},
+			expectErr: repoerrors.ErrZoneNameExists,
+		},
+		{
+			name: "repository error",
+			req:  model.Zone{Name: "north", Area: square},
+			repoSetup: func(repo *mockZoneRepository) {
+				repo.createFn = func(ctx context.Context, zone *model.Zone) (int, error) {
+					return 0, errBoom
+				}
+			},
+			expectErr: errBoom,
+		},
+		{
+			name: "success",
+			req:  model.Zone{Name: " north ", Area: square, NeighbourIDs: []int{2, 1, 2}},
+			repoSetup: func(repo *mockZoneRepository) {
+				withZones(repo, 1, 2)
+				repo.createFn = func(ctx context.Context, zone *model.Zone) (int, error) {
+					if zone.Name != "north" {
+						repo.t.Fatalf("unexpected name: %q", zone.Name)
+					}
+					return 3, nil
+				}
+			},
+			expectID:       3,
+			wantNeighbours: []int{2, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := newMockZoneRepository(t)
+			tt.repoSetup(repo)
+			uc := NewZoneUsecase(repo)
+
+			req := tt.req
+			id, err := uc.Create(context.Background(), &req)
+			if tt.expectErr != nil {
+				if !errors.Is(err, tt.expectErr) {
+					t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if id != tt.expectID {
+				t.Fatalf("expected id %d, got %d", tt.expectID, id)
+			}
+			if !reflect.DeepEqual(req.NeighbourIDs, tt.wantNeighbours) {
+				t.Fatalf("expected neighbours %v, got %v", tt.wantNeighbours, req.NeighbourIDs)
+			}
+		})
+	}
+}
+
+func TestZoneUsecase_Update(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		req       model.Zone
+		repoSetup func(*mockZoneRepository)
+		expectErr error
+	}{
+		{
+			name:      "invalid id",
+			req:       model.Zone{Name: "north", Area: square},
+			repoSetup: func(_ *mockZoneRepository) {},
+			expectErr: ErrInvalidID,
+		},
+		{
+			name:      "neighbour of itself",
+			req:       model.Zone{ID: 1, Name: "north", Area: square, NeighbourIDs: []int{1}},
+			repoSetup: func(repo *mockZoneRepository) { withZones(repo, 1, 2) },
+			expectErr: ErrInvalidNeighbour,
+		},
+		{
+			name: "not found",
+			req:  model.Zone{ID: 5, Name: "north", Area: square},
+			repoSetup: func(repo *mockZoneRepository) {
+				repo.updateFn = func(ctx context.Context, zone *model.Zone) error {
+					return repoerrors.ErrZoneNotFound
+				}
+			},
+			expectErr: repoerrors.ErrZoneNotFound,
+		},
+		{
+			name: "success",
+			req:  model.Zone{ID: 1, Name: "north", Area: square, NeighbourIDs: []int{2}},
+			repoSetup: func(repo *mockZoneRepository) {
+				withZones(repo, 1, 2)
+				repo.updateFn = func(ctx context.Context, zone *model.Zone) error {
+					return nil
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := newMockZoneRepository(t)
+			tt.repoSetup(repo)
+			uc := NewZoneUsecase(repo)
+
+			req := tt.req
+			err := uc.Update(context.Background(), &req)
+			if !errors.Is(err, tt.expectErr) {
+				t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestZoneUsecase_Delete(t *testing.T) {
+	t.Parallel()
+
+	repo := newMockZoneRepository(t)
+	repo.deleteFn = func(ctx context.Context, id int) error {
+		if id == 2 {
+			return repoerrors.ErrZoneNotFound
+		}
+		return nil
+	}
+	uc := NewZoneUsecase(repo)
+
+	if err := uc.Delete(context.Background(), 0); !errors.Is(err, ErrInvalidID) {
+		t.Fatalf("expected invalid id, got %v", err)
+	}
+	if err := uc.Delete(context.Background(), 2); !errors.Is(err, repoerrors.ErrZoneNotFound) {
+		t.Fatalf("expected zone not found, got %v", err)
+	}
+	if err := uc.Delete(context.Background(), 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
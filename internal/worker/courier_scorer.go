@@ -0,0 +1,54 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/observability"
+)
+
+type CourierScorerUsecase interface {
+	RecalculateScores(ctx context.Context) (int, error)
+}
+
+// CourierScorer recalculates the performance scores of couriers periodically.
+type CourierScorer struct {
+	uc       CourierScorerUsecase
+	interval time.Duration
+	logger   *log.Logger
+}
+
+func NewCourierScorer(uc CourierScorerUsecase, interval time.Duration, logger *log.Logger) *CourierScorer {
+	if logger == nil {
+		logger = log.New(os.Stdout, "[INFO] ", log.LstdFlags)
+	}
+	return &CourierScorer{uc: uc, interval: interval, logger: logger}
+}
+
+func (w *CourierScorer) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.logger.Printf("starting courier scorer, interval=%s", w.interval)
+	w.recalculate(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Println("stopping courier scorer")
+			return
+		case <-ticker.C:
+			w.recalculate(ctx)
+		}
+	}
+}
+
+func (w *CourierScorer) recalculate(ctx context.Context) {
+	scored, err := w.uc.RecalculateScores(ctx)
+	if err != nil {
+		w.logger.Printf("error recalculating courier scores: %v", err)
+		return
+	}
+	observability.SetCouriersScored(scored)
+}
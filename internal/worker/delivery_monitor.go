@@ -7,11 +7,14 @@ import (
 	"os"
 	"time"
 
+	"github.com/cdxy1/go-courier-service/internal/model"
+	"github.com/cdxy1/go-courier-service/internal/observability"
 	deliveryRepo "github.com/cdxy1/go-courier-service/internal/repository/delivery"
 )
 
 type DeliveryMonitorUsecase interface {
-	ProcessExpiredDeliveries(ctx context.Context) (int, error)
+	ProcessExpiredDeliveries(ctx context.Context) ([]*model.ExpiredDelivery, error)
+	CountAtRisk(ctx context.Context) (int, error)
 }
 
 type DeliveryMonitor struct {
@@ -32,13 +35,14 @@ func (m *DeliveryMonitor) Start(ctx context.Context) {
 	defer ticker.Stop()
 
 	m.logger.Printf("starting delivery deadline monitor, interval=%s", m.interval)
+	ctx = model.WithEventTrigger(ctx, model.EventTrigger{Source: model.EventSourceMonitor})
 	for {
 		select {
 		case <-ctx.Done():
 			m.logger.Println("stopping delivery deadline monitor")
 			return
 		case <-ticker.C:
-			updated, err := m.uc.ProcessExpiredDeliveries(ctx)
+			expired, err := m.uc.ProcessExpiredDeliveries(ctx)
 			if err != nil {
 				if errors.Is(err, deliveryRepo.ErrDeliveryTableMissing) {
 					m.logger.Println("warning: delivery table does not exist, please run migrations: make migrate")
@@ -47,9 +51,33 @@ func (m *DeliveryMonitor) Start(ctx context.Context) {
 				m.logger.Printf("error processing expired deliveries: %v", err)
 				continue
 			}
-			if updated > 0 {
-				m.logger.Printf("delivery monitor: %d couriers marked available", updated)
+			m.report(expired)
+
+			atRisk, err := m.uc.CountAtRisk(ctx)
+			if err != nil {
+				m.logger.Printf("error counting at risk deliveries: %v", err)
+				continue
 			}
+			observability.SetDeliveriesAtRisk(atRisk)
+		}
+	}
+}
+
+func (m *DeliveryMonitor) report(expired []*model.ExpiredDelivery) {
+	if len(expired) == 0 {
+		return
+	}
+
+	m.logger.Printf("delivery monitor: %d deliveries expired", len(expired))
+	for _, e := range expired {
+		observability.IncDeliveriesExpired(string(e.Action))
+		switch e.Action {
+		case model.ExpiredActionReassigned:
+			m.logger.Printf("delivery monitor: order %s reassigned from courier %d to courier %d", e.OrderID, e.CourierID, e.NewCourierID)
+		case model.ExpiredActionEscalated:
+			m.logger.Printf("warning: delivery monitor: order %s expired with courier %d and needs a dispatcher", e.OrderID, e.CourierID)
+		default:
+			m.logger.Printf("delivery monitor: order %s expired with courier %d", e.OrderID, e.CourierID)
 		}
 	}
 }
@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/observability"
+)
+
+type LocationFlusherUsecase interface {
+	FlushLocations(ctx context.Context) (int, error)
+	TrimTrail(ctx context.Context) error
+	BatchReady() <-chan struct{}
+	Buffered() int
+}
+
+// LocationFlusher writes buffered courier pings periodically and whenever a full
+// batch is waiting, and trims the location trail.
+type LocationFlusher struct {
+	uc           LocationFlusherUsecase
+	interval     time.Duration
+	trimInterval time.Duration
+	logger       *log.Logger
+}
+
+func NewLocationFlusher(uc LocationFlusherUsecase, interval, trimInterval time.Duration, logger *log.Logger) *LocationFlusher {
+	if logger == nil {
+		logger = log.New(os.Stdout, "[INFO] ", log.LstdFlags)
+	}
+	return &LocationFlusher{uc: uc, interval: interval, trimInterval: trimInterval, logger: logger}
+}
+
+func (w *LocationFlusher) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	trimTicker := time.NewTicker(w.trimInterval)
+	defer trimTicker.Stop()
+
+	w.logger.Printf("starting location flusher, interval=%s", w.interval)
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Println("stopping location flusher")
+			return
+		case <-ticker.C:
+			w.Flush(ctx)
+		case <-w.uc.BatchReady():
+			w.Flush(ctx)
+		case <-trimTicker.C:
+			if err := w.uc.TrimTrail(ctx); err != nil {
+				w.logger.Printf("error trimming location trail: %v", err)
+			}
+		}
+	}
+}
+
+// Flush writes the buffered pings. It is also called on shutdown, once the
+// HTTP server stopped accepting pings.
+func (w *LocationFlusher) Flush(ctx context.Context) {
+	written, err := w.uc.FlushLocations(ctx)
+	if err != nil {
+		w.logger.Printf("error flushing courier locations: %v", err)
+	}
+	observability.AddCourierLocationsFlushed(written)
+	observability.SetCourierLocationsBuffered(w.uc.Buffered())
+}
@@ -2,16 +2,19 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"log"
 	"time"
 
 	"github.com/cdxy1/go-courier-service/internal/gateway/order"
 	"github.com/cdxy1/go-courier-service/internal/model"
+	ucd "github.com/cdxy1/go-courier-service/internal/usecase/delivery"
 )
 
 type OrderAssigner struct {
 	orderGateway orderGateway
 	deliveryUC   deliveryUsecase
+	mode         model.AssignMode
 	ticker       *time.Ticker
 	cursor       time.Time
 }
@@ -21,13 +24,17 @@ type orderGateway interface {
 }
 
 type deliveryUsecase interface {
-	Assign(ctx context.Context, order_id string) (*model.DeliveryModel, *model.CourierModel, error)
+	Assign(ctx context.Context, order *model.Order) (*model.DeliveryModel, *model.CourierModel, error)
+	AssignBatch(ctx context.Context, orders []*model.Order) ([]*model.AssignmentResult, error)
 }
 
-func NewOrderAssigner(orderGateway *order.OrderGateway, deliveryUC deliveryUsecase) *OrderAssigner {
+// NewOrderAssigner creates the poller. With model.AssignBatch the orders of a
+// tick are matched with couriers together, otherwise one after another.
+func NewOrderAssigner(orderGateway *order.OrderGateway, deliveryUC deliveryUsecase, mode model.AssignMode) *OrderAssigner {
 	return &OrderAssigner{
 		orderGateway: orderGateway,
 		deliveryUC:   deliveryUC,
+		mode:         mode,
 		ticker:       time.NewTicker(5 * time.Second),
 		cursor:       time.Now().Add(-5 * time.Second),
 	}
@@ -54,6 +61,7 @@ func (w *OrderAssigner) processTick(ctx context.Context) {
 		cursor = currentCursor
 	}
 
+	ctx = model.WithEventTrigger(ctx, model.EventTrigger{Source: model.EventSourcePoller, Reason: "order polled"})
 	orders, err := w.orderGateway.GetOrders(ctx, cursor)
 	if err != nil {
 		log.Printf("Failed to fetch orders: %v", err)
@@ -65,15 +73,21 @@ func (w *OrderAssigner) processTick(ctx context.Context) {
 		if ord.CreatedAt.After(maxCreatedAt) {
 			maxCreatedAt = ord.CreatedAt
 		}
+	}
 
-		delivery, courier, err := w.deliveryUC.Assign(ctx, ord.ID)
+	if w.mode == model.AssignBatch && len(orders) > 1 {
+		results, err := w.deliveryUC.AssignBatch(ctx, orders)
 		if err != nil {
-			log.Printf("Failed to assign courier to order %s: %v", ord.ID, err)
-			continue
+			log.Printf("Batch assignment failed, orders assigned one by one: %v", err)
+		}
+		for _, r := range results {
+			logAssignment(r)
+		}
+	} else {
+		for _, ord := range orders {
+			delivery, courier, err := w.deliveryUC.Assign(ctx, ord)
+			logAssignment(&model.AssignmentResult{OrderID: ord.ID, Delivery: delivery, Courier: courier, Err: err})
 		}
-
-		log.Printf("Assigned courier %d (transport: %s) to order %s, deadline: %s",
-			courier.ID, courier.TransportType, ord.ID, delivery.Deadline.Format(time.RFC3339))
 	}
 
 	if len(orders) > 0 {
@@ -83,3 +97,17 @@ func (w *OrderAssigner) processTick(ctx context.Context) {
 		w.cursor = currentCursor
 	}
 }
+
+func logAssignment(r *model.AssignmentResult) {
+	if r.Err != nil {
+		if errors.Is(r.Err, ucd.ErrAssignmentQueued) {
+			log.Printf("No courier available for order %s, queued for assignment", r.OrderID)
+			return
+		}
+		log.Printf("Failed to assign courier to order %s: %v", r.OrderID, r.Err)
+		return
+	}
+
+	log.Printf("Assigned courier %d (transport: %s) to order %s, deadline: %s",
+		r.Courier.ID, r.Courier.TransportType, r.OrderID, r.Delivery.Deadline.Format(time.RFC3339))
+}
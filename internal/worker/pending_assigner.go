@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+	"github.com/cdxy1/go-courier-service/internal/observability"
+)
+
+type PendingAssignerUsecase interface {
+	ProcessPendingAssignments(ctx context.Context, limit int) ([]*model.DeliveryModel, error)
+	PendingStats(ctx context.Context) (*model.PendingQueueStats, error)
+	CourierReleased() <-chan struct{}
+}
+
+// PendingAssigner retries queued orders periodically and whenever a courier is freed.
+type PendingAssigner struct {
+	uc        PendingAssignerUsecase
+	interval  time.Duration
+	batchSize int
+	logger    *log.Logger
+}
+
+func NewPendingAssigner(uc PendingAssignerUsecase, interval time.Duration, batchSize int, logger *log.Logger) *PendingAssigner {
+	if logger == nil {
+		logger = log.New(os.Stdout, "[INFO] ", log.LstdFlags)
+	}
+	return &PendingAssigner{uc: uc, interval: interval, batchSize: batchSize, logger: logger}
+}
+
+func (w *PendingAssigner) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.logger.Printf("starting pending assignment worker, interval=%s", w.interval)
+	ctx = model.WithEventTrigger(ctx, model.EventTrigger{Source: model.EventSourceQueue, Reason: "assigned from queue"})
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Println("stopping pending assignment worker")
+			return
+		case <-ticker.C:
+			w.process(ctx)
+		case <-w.uc.CourierReleased():
+			w.process(ctx)
+		}
+	}
+}
+
+func (w *PendingAssigner) process(ctx context.Context) {
+	assigned, err := w.uc.ProcessPendingAssignments(ctx, w.batchSize)
+	if err != nil {
+		w.logger.Printf("error processing pending assignments: %v", err)
+	}
+	for _, d := range assigned {
+		w.logger.Printf("pending assignment: order %s assigned to courier %d", d.OrderId, d.CourierId)
+	}
+
+	stats, err := w.uc.PendingStats(ctx)
+	if err != nil {
+		w.logger.Printf("error reading pending queue stats: %v", err)
+		return
+	}
+	observability.SetPendingAssignments(stats.Depth, stats.OldestWait)
+}
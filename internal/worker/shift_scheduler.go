@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/cdxy1/go-courier-service/internal/model"
+)
+
+type ShiftSchedulerUsecase interface {
+	ApplySchedule(ctx context.Context) (*model.ShiftRun, error)
+}
+
+// ShiftScheduler plans shifts from templates and switches couriers between
+// available and paused at shift boundaries.
+type ShiftScheduler struct {
+	uc       ShiftSchedulerUsecase
+	interval time.Duration
+	logger   *log.Logger
+}
+
+func NewShiftScheduler(uc ShiftSchedulerUsecase, interval time.Duration, logger *log.Logger) *ShiftScheduler {
+	if logger == nil {
+		logger = log.New(os.Stdout, "[INFO] ", log.LstdFlags)
+	}
+	return &ShiftScheduler{uc: uc, interval: interval, logger: logger}
+}
+
+func (w *ShiftScheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.logger.Printf("starting shift scheduler, interval=%s", w.interval)
+	// Shifts that started while the service was down are picked up by the
+	// first run.
+	w.apply(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Println("stopping shift scheduler")
+			return
+		case <-ticker.C:
+			w.apply(ctx)
+		}
+	}
+}
+
+func (w *ShiftScheduler) apply(ctx context.Context) {
+	run, err := w.uc.ApplySchedule(ctx)
+	if err != nil {
+		w.logger.Printf("error applying shift schedule: %v", err)
+		return
+	}
+	if run.Planned > 0 || run.Started > 0 || run.Ended > 0 {
+		w.logger.Printf("shift schedule applied: planned=%d started=%d ended=%d", run.Planned, run.Started, run.Ended)
+	}
+}
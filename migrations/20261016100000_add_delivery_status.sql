@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE delivery
+    ADD COLUMN IF NOT EXISTS status        TEXT NOT NULL DEFAULT 'assigned', -- assigned, picked_up, in_transit, delivered, cancelled, expired, legacy
+    ADD COLUMN IF NOT EXISTS picked_up_at  TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS in_transit_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS delivered_at  TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS cancelled_at  TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS expired_at    TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS updated_at    TIMESTAMP NOT NULL DEFAULT NOW();
+
+-- Existing rows carry no outcome: completed deliveries were kept as they were
+-- and unassigned ones deleted. Only the latest delivery of a busy courier can
+-- still be in progress, every other row is closed as legacy.
+UPDATE delivery d
+SET status = 'legacy'
+WHERE NOT EXISTS (
+    SELECT 1
+    FROM couriers c
+    WHERE c.id = d.courier_id
+      AND c.status = 'busy'
+      AND d.id = (SELECT MAX(id) FROM delivery WHERE courier_id = c.id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_delivery_status_deadline
+    ON delivery (status, deadline);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_delivery_status_deadline;
+
+ALTER TABLE delivery
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS expired_at,
+    DROP COLUMN IF EXISTS cancelled_at,
+    DROP COLUMN IF EXISTS delivered_at,
+    DROP COLUMN IF EXISTS in_transit_at,
+    DROP COLUMN IF EXISTS picked_up_at,
+    DROP COLUMN IF EXISTS status;
+-- +goose StatementEnd
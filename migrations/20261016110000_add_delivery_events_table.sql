@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS delivery_events (
+    id          BIGSERIAL PRIMARY KEY,
+    delivery_id BIGINT NOT NULL REFERENCES delivery(id),
+    order_id    VARCHAR(255) NOT NULL,
+    courier_id  BIGINT NOT NULL,
+    type        TEXT NOT NULL, -- assigned, unassigned, completed, expired
+    from_status TEXT,
+    to_status   TEXT NOT NULL,
+    source      TEXT NOT NULL, -- http, kafka, poller, monitor
+    actor       TEXT NOT NULL DEFAULT '',
+    reason      TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_delivery_events_order_id
+    ON delivery_events (order_id, id);
+
+CREATE OR REPLACE FUNCTION delivery_events_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'delivery_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS delivery_events_no_modify ON delivery_events;
+CREATE TRIGGER delivery_events_no_modify
+    BEFORE UPDATE OR DELETE ON delivery_events
+    FOR EACH ROW EXECUTE FUNCTION delivery_events_immutable();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS delivery_events_no_modify ON delivery_events;
+DROP FUNCTION IF EXISTS delivery_events_immutable();
+DROP TABLE IF EXISTS delivery_events;
+-- +goose StatementEnd
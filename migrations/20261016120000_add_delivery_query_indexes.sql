@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_delivery_courier_assigned_at
+    ON delivery (courier_id, assigned_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_delivery_assigned_at
+    ON delivery (assigned_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_delivery_assigned_at;
+DROP INDEX IF EXISTS idx_delivery_courier_assigned_at;
+-- +goose StatementEnd
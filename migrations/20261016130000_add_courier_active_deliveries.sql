@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE couriers
+    ADD COLUMN IF NOT EXISTS active_deliveries INT NOT NULL DEFAULT 0 CHECK (active_deliveries >= 0);
+
+UPDATE couriers c
+SET active_deliveries = (
+    SELECT COUNT(*)
+    FROM delivery d
+    WHERE d.courier_id = c.id AND d.status IN ('assigned', 'picked_up', 'in_transit')
+);
+
+CREATE INDEX IF NOT EXISTS idx_couriers_status_active_deliveries
+    ON couriers (status, active_deliveries);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_couriers_status_active_deliveries;
+
+ALTER TABLE couriers
+    DROP COLUMN IF EXISTS active_deliveries;
+-- +goose StatementEnd
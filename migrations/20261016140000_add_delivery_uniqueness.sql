@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Keep only the latest active delivery of every order and free the couriers
+-- of the duplicates before the uniqueness guarantee is enforced.
+WITH ranked AS (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY order_id ORDER BY id DESC) AS rn
+    FROM delivery
+    WHERE status IN ('assigned', 'picked_up', 'in_transit')
+), cancelled AS (
+    UPDATE delivery d
+    SET status = 'cancelled', cancelled_at = NOW(), updated_at = NOW()
+    FROM ranked r
+    WHERE d.id = r.id AND r.rn > 1
+    RETURNING d.courier_id
+), released AS (
+    SELECT courier_id, COUNT(*) AS cnt FROM cancelled GROUP BY courier_id
+)
+UPDATE couriers c
+SET active_deliveries = GREATEST(c.active_deliveries - r.cnt, 0),
+    status = CASE WHEN c.active_deliveries - r.cnt <= 0 AND c.status = 'busy' THEN 'available' ELSE c.status END
+FROM released r
+WHERE c.id = r.courier_id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_delivery_active_order
+    ON delivery (order_id)
+    WHERE status IN ('assigned', 'picked_up', 'in_transit');
+
+ALTER TABLE delivery
+    ADD COLUMN IF NOT EXISTS idempotency_key TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_delivery_idempotency_key
+    ON delivery (idempotency_key)
+    WHERE idempotency_key IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS uq_delivery_idempotency_key;
+
+ALTER TABLE delivery
+    DROP COLUMN IF EXISTS idempotency_key;
+
+DROP INDEX IF EXISTS uq_delivery_active_order;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS pending_assignments (
+    id              BIGSERIAL PRIMARY KEY,
+    order_id        VARCHAR(255) NOT NULL UNIQUE,
+    priority        INT NOT NULL DEFAULT 0,
+    attempts        INT NOT NULL DEFAULT 0,
+    last_error      TEXT NOT NULL DEFAULT '',
+    enqueued_at     TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_attempt_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_assignments_order
+    ON pending_assignments (priority DESC, enqueued_at ASC, id ASC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS pending_assignments;
+-- +goose StatementEnd
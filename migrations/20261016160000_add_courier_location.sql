@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE couriers
+    ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS geo_cell VARCHAR(12),
+    ADD COLUMN IF NOT EXISTS location_updated_at TIMESTAMP,
+    ADD CONSTRAINT couriers_location_check
+        CHECK (latitude BETWEEN -90 AND 90 AND longitude BETWEEN -180 AND 180);
+
+CREATE INDEX IF NOT EXISTS idx_couriers_geo_cell
+    ON couriers (geo_cell) WHERE geo_cell IS NOT NULL;
+
+ALTER TABLE delivery
+    ADD COLUMN IF NOT EXISTS pickup_lat DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS pickup_lon DOUBLE PRECISION;
+
+ALTER TABLE pending_assignments
+    ADD COLUMN IF NOT EXISTS pickup_lat DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS pickup_lon DOUBLE PRECISION;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE pending_assignments
+    DROP COLUMN IF EXISTS pickup_lon,
+    DROP COLUMN IF EXISTS pickup_lat;
+
+ALTER TABLE delivery
+    DROP COLUMN IF EXISTS pickup_lon,
+    DROP COLUMN IF EXISTS pickup_lat;
+
+DROP INDEX IF EXISTS idx_couriers_geo_cell;
+
+ALTER TABLE couriers
+    DROP CONSTRAINT IF EXISTS couriers_location_check,
+    DROP COLUMN IF EXISTS location_updated_at,
+    DROP COLUMN IF EXISTS geo_cell,
+    DROP COLUMN IF EXISTS longitude,
+    DROP COLUMN IF EXISTS latitude;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE couriers
+    ADD COLUMN IF NOT EXISTS location_accuracy DOUBLE PRECISION;
+
+CREATE TABLE IF NOT EXISTS courier_locations (
+    courier_id  BIGINT NOT NULL REFERENCES couriers(id) ON DELETE CASCADE,
+    latitude    DOUBLE PRECISION NOT NULL,
+    longitude   DOUBLE PRECISION NOT NULL,
+    accuracy    DOUBLE PRECISION NOT NULL DEFAULT 0,
+    recorded_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (courier_id, recorded_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_courier_locations_recorded_at
+    ON courier_locations (recorded_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS courier_locations;
+
+ALTER TABLE couriers
+    DROP COLUMN IF EXISTS location_accuracy;
+-- +goose StatementEnd
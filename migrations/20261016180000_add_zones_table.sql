@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS zones (
+    id            BIGSERIAL PRIMARY KEY,
+    name          TEXT NOT NULL UNIQUE,
+    area          JSONB NOT NULL, -- [{"lat": ..., "lon": ...}, ...]
+    neighbour_ids BIGINT[] NOT NULL DEFAULT '{}',
+    created_at    TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE couriers
+    ADD COLUMN IF NOT EXISTS zone_id BIGINT REFERENCES zones(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_couriers_zone_id
+    ON couriers (zone_id) WHERE zone_id IS NOT NULL;
+
+ALTER TABLE delivery
+    ADD COLUMN IF NOT EXISTS dropoff_lat DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS dropoff_lon DOUBLE PRECISION;
+
+ALTER TABLE pending_assignments
+    ADD COLUMN IF NOT EXISTS dropoff_lat DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS dropoff_lon DOUBLE PRECISION;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE pending_assignments
+    DROP COLUMN IF EXISTS dropoff_lon,
+    DROP COLUMN IF EXISTS dropoff_lat;
+
+ALTER TABLE delivery
+    DROP COLUMN IF EXISTS dropoff_lon,
+    DROP COLUMN IF EXISTS dropoff_lat;
+
+DROP INDEX IF EXISTS idx_couriers_zone_id;
+
+ALTER TABLE couriers
+    DROP COLUMN IF EXISTS zone_id;
+
+DROP TABLE IF EXISTS zones;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- {"item_count": ..., "quantity": ..., "total_price": ..., "weight_grams": ...}
+ALTER TABLE delivery
+    ADD COLUMN IF NOT EXISTS order_details JSONB;
+
+ALTER TABLE pending_assignments
+    ADD COLUMN IF NOT EXISTS order_details JSONB;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE pending_assignments
+    DROP COLUMN IF EXISTS order_details;
+
+ALTER TABLE delivery
+    DROP COLUMN IF EXISTS order_details;
+-- +goose StatementEnd
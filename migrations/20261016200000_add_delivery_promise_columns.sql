@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE delivery
+    ADD COLUMN IF NOT EXISTS promised_by TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS at_risk BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_delivery_at_risk
+    ON delivery (assigned_at) WHERE at_risk;
+
+ALTER TABLE pending_assignments
+    ADD COLUMN IF NOT EXISTS promised_by TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE pending_assignments
+    DROP COLUMN IF EXISTS promised_by;
+
+DROP INDEX IF EXISTS idx_delivery_at_risk;
+
+ALTER TABLE delivery
+    DROP COLUMN IF EXISTS at_risk,
+    DROP COLUMN IF EXISTS promised_by;
+-- +goose StatementEnd
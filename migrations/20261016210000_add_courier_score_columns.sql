@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE couriers
+    ADD COLUMN IF NOT EXISTS score DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS on_time_rate DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS cancel_rate DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS deadline_usage DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS scored_deliveries INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS scored_at TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE couriers
+    DROP COLUMN IF EXISTS scored_at,
+    DROP COLUMN IF EXISTS scored_deliveries,
+    DROP COLUMN IF EXISTS deadline_usage,
+    DROP COLUMN IF EXISTS cancel_rate,
+    DROP COLUMN IF EXISTS on_time_rate,
+    DROP COLUMN IF EXISTS score;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS shift_templates (
+    id              BIGSERIAL PRIMARY KEY,
+    courier_id      BIGINT NOT NULL REFERENCES couriers(id) ON DELETE CASCADE,
+    weekdays        INT[] NOT NULL,  -- 0 = Sunday
+    start_minute    INT NOT NULL,    -- minutes since midnight UTC
+    end_minute      INT NOT NULL,    -- before start_minute when the shift runs past midnight
+    generated_until TIMESTAMP,
+    created_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_shift_templates_courier_id
+    ON shift_templates (courier_id);
+
+CREATE TABLE IF NOT EXISTS courier_shifts (
+    id          BIGSERIAL PRIMARY KEY,
+    courier_id  BIGINT NOT NULL REFERENCES couriers(id) ON DELETE CASCADE,
+    template_id BIGINT REFERENCES shift_templates(id) ON DELETE SET NULL,
+    starts_at   TIMESTAMP NOT NULL,
+    ends_at     TIMESTAMP NOT NULL,
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_courier_shifts_courier_id_ends_at
+    ON courier_shifts (courier_id, ends_at);
+
+CREATE INDEX IF NOT EXISTS idx_courier_shifts_starts_at
+    ON courier_shifts (starts_at);
+
+CREATE INDEX IF NOT EXISTS idx_courier_shifts_ends_at
+    ON courier_shifts (ends_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS courier_shifts;
+
+DROP TABLE IF EXISTS shift_templates;
+-- +goose StatementEnd
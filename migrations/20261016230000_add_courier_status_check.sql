@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+UPDATE couriers
+SET status = CASE WHEN active_deliveries > 0 THEN 'busy' ELSE 'available' END
+WHERE status NOT IN ('available', 'busy', 'paused')
+   OR (status = 'available' AND active_deliveries > 0)
+   OR (status = 'busy' AND active_deliveries = 0);
+
+ALTER TABLE couriers
+    ALTER COLUMN status SET DEFAULT 'available',
+    ADD CONSTRAINT couriers_status_check CHECK (status IN ('available', 'busy', 'paused'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE couriers
+    DROP CONSTRAINT IF EXISTS couriers_status_check,
+    ALTER COLUMN status DROP DEFAULT;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_couriers_status_assignments_id;
+
+COMMENT ON COLUMN couriers.assignments_count IS
+    'Deprecated: no longer maintained, couriers are ranked by their load in the fairness window';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+COMMENT ON COLUMN couriers.assignments_count IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_couriers_status_assignments_id
+    ON couriers (status, assignments_count, id);
+-- +goose StatementEnd
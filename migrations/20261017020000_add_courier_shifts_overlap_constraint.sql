@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- Concurrent requests could store overlapping shifts before the database
+-- enforced the rule; keep the earliest created one of every overlap.
+DELETE FROM courier_shifts s
+WHERE EXISTS (
+    SELECT 1 FROM courier_shifts o
+    WHERE o.courier_id = s.courier_id AND o.id < s.id
+      AND o.starts_at < s.ends_at AND o.ends_at > s.starts_at
+);
+
+ALTER TABLE courier_shifts
+    ADD CONSTRAINT ex_courier_shifts_overlap
+    EXCLUDE USING gist (courier_id WITH =, tsrange(starts_at, ends_at) WITH &&);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE courier_shifts
+    DROP CONSTRAINT IF EXISTS ex_courier_shifts_overlap;
+-- +goose StatementEnd
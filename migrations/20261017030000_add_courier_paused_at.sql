@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE couriers
+    ADD COLUMN IF NOT EXISTS paused_at TIMESTAMP; -- when the courier was last paused
+
+-- The pause time of couriers paused so far is not known, the last change is
+-- the closest estimate.
+UPDATE couriers SET paused_at = updated_at WHERE status = 'paused';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE couriers
+    DROP COLUMN IF EXISTS paused_at;
+-- +goose StatementEnd
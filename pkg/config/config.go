@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,6 +21,7 @@ type Сonfig struct {
 	Kafka            *KafkaConfig
 	OrderPolling     bool
 	Delivery         *DeliveryConfig
+	Location         *LocationConfig
 	Pprof            *PprofConfig
 }
 
@@ -40,10 +42,51 @@ type KafkaConfig struct {
 }
 
 type DeliveryConfig struct {
-	MonitorInterval time.Duration
-	OnFootDuration  time.Duration
-	ScooterDuration time.Duration
-	CarDuration     time.Duration
+	MonitorInterval  time.Duration
+	OnFootDuration   time.Duration
+	ScooterDuration  time.Duration
+	CarDuration      time.Duration
+	ExpiredPolicy    string
+	OnFootCapacity   int
+	ScooterCapacity  int
+	CarCapacity      int
+	PendingInterval  time.Duration
+	PendingBatch     int
+	DispatchStrategy string
+	NearestRadius    int
+	OnFootSpeed      float64
+	ScooterSpeed     float64
+	CarSpeed         float64
+	ZoneFallback     string
+	// EligibilityRules is a JSON array of transport eligibility rules.
+	EligibilityRules string
+	DeadlinePolicy   string
+	HandlingOverhead time.Duration
+	// CalendarFile is the path of the JSON deadline calendar, empty disables it.
+	CalendarFile string
+	AssignMode   string
+	LoadPenalty  time.Duration
+	// FairnessWindow is shift, rolling or decay.
+	FairnessWindow   string
+	FairnessPeriod   time.Duration
+	FairnessHalfLife time.Duration
+	// ShiftStarts are comma separated "HH:MM" times of day in UTC.
+	ShiftStarts string
+	// ScoreWeight blends courier scores into dispatch, 0 disables it.
+	ScoreWeight   float64
+	ScoreInterval time.Duration
+	ScoreWindow   time.Duration
+	// ShiftInterval is how often couriers are switched at shift boundaries,
+	// ShiftHorizon how far ahead shifts are planned from templates.
+	ShiftInterval time.Duration
+	ShiftHorizon  time.Duration
+}
+
+type LocationConfig struct {
+	FlushInterval  time.Duration
+	BatchSize      int
+	TrailRetention time.Duration
+	TrimInterval   time.Duration
 }
 
 type PprofConfig struct {
@@ -59,9 +102,7 @@ func (p *PostgresConfig) GetURL() string {
 }
 
 func GetEnv() *Сonfig {
-	if err := godotenv.Load(".env", ".env.example"); err != nil && !errors.Is(err, os.ErrNotExist) {
-		log.Fatalf("failed to load .env file: %v\n", err)
-	}
+	loadDotEnv()
 
 	port := getServicePort()
 	postgres := getPostgresConfig()
@@ -70,6 +111,7 @@ func GetEnv() *Сonfig {
 	kafka := getKafkaConfig()
 	orderPolling := getOrderPolling()
 	delivery := getDeliveryConfig()
+	location := getLocationConfig()
 	pprof := getPprofConfig()
 
 	return &Сonfig{
@@ -80,10 +122,24 @@ func GetEnv() *Сonfig {
 		Kafka:            kafka,
 		OrderPolling:     orderPolling,
 		Delivery:         delivery,
+		Location:         location,
 		Pprof:            pprof,
 	}
 }
 
+// GetDeliveryEnv returns the delivery settings alone, for tools that do not
+// run the service.
+func GetDeliveryEnv() *DeliveryConfig {
+	loadDotEnv()
+	return getDeliveryConfig()
+}
+
+func loadDotEnv() {
+	if err := godotenv.Load(".env", ".env.example"); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Fatalf("failed to load .env file: %v\n", err)
+	}
+}
+
 func getServicePort() string {
 	var port string
 
@@ -147,13 +203,104 @@ func getOrderPolling() bool {
 
 func getDeliveryConfig() *DeliveryConfig {
 	return &DeliveryConfig{
-		MonitorInterval: getDuration("DELIVERY_MONITOR_INTERVAL", time.Second*10),
-		OnFootDuration:  getDuration("DELIVERY_DURATION_ON_FOOT", time.Minute*30),
-		ScooterDuration: getDuration("DELIVERY_DURATION_SCOOTER", time.Minute*15),
-		CarDuration:     getDuration("DELIVERY_DURATION_CAR", time.Minute*5),
+		MonitorInterval:  getDuration("DELIVERY_MONITOR_INTERVAL", time.Second*10),
+		OnFootDuration:   getDuration("DELIVERY_DURATION_ON_FOOT", time.Minute*30),
+		ScooterDuration:  getDuration("DELIVERY_DURATION_SCOOTER", time.Minute*15),
+		CarDuration:      getDuration("DELIVERY_DURATION_CAR", time.Minute*5),
+		ExpiredPolicy:    getExpiredPolicy(),
+		OnFootCapacity:   getPositiveInt("DELIVERY_CAPACITY_ON_FOOT", 1),
+		ScooterCapacity:  getPositiveInt("DELIVERY_CAPACITY_SCOOTER", 2),
+		CarCapacity:      getPositiveInt("DELIVERY_CAPACITY_CAR", 4),
+		PendingInterval:  getDuration("DELIVERY_PENDING_INTERVAL", time.Second*15),
+		PendingBatch:     getPositiveInt("DELIVERY_PENDING_BATCH", 50),
+		DispatchStrategy: getDispatchStrategy(),
+		NearestRadius:    getPositiveInt("DELIVERY_NEAREST_MAX_RADIUS", 5000),
+		OnFootSpeed:      getPositiveFloat("DELIVERY_SPEED_ON_FOOT", 5),
+		ScooterSpeed:     getPositiveFloat("DELIVERY_SPEED_SCOOTER", 15),
+		CarSpeed:         getPositiveFloat("DELIVERY_SPEED_CAR", 25),
+		ZoneFallback:     getZoneFallback(),
+		EligibilityRules: strings.TrimSpace(os.Getenv("DELIVERY_ELIGIBILITY_RULES")),
+		DeadlinePolicy:   getDeadlinePolicy(),
+		HandlingOverhead: getDuration("DELIVERY_HANDLING_OVERHEAD", time.Minute*10),
+		CalendarFile:     strings.TrimSpace(os.Getenv("DELIVERY_CALENDAR_FILE")),
+		AssignMode:       getAssignMode(),
+		LoadPenalty:      getDuration("DELIVERY_BATCH_LOAD_PENALTY", time.Minute*5),
+		FairnessWindow:   getFairnessWindow(),
+		FairnessPeriod:   getDuration("DELIVERY_FAIRNESS_PERIOD", time.Hour*24),
+		FairnessHalfLife: getDuration("DELIVERY_FAIRNESS_HALF_LIFE", time.Hour*6),
+		ShiftStarts:      strings.TrimSpace(os.Getenv("DELIVERY_SHIFT_STARTS")),
+		ScoreWeight:      getPositiveFloat("DELIVERY_SCORE_WEIGHT", 0),
+		ScoreInterval:    getDuration("DELIVERY_SCORE_INTERVAL", time.Minute*10),
+		ScoreWindow:      getDuration("DELIVERY_SCORE_WINDOW", time.Hour*24*30),
+		ShiftInterval:    getDuration("DELIVERY_SHIFT_INTERVAL", time.Minute),
+		ShiftHorizon:     getDuration("DELIVERY_SHIFT_HORIZON", time.Hour*24*7),
+	}
+}
+
+func getLocationConfig() *LocationConfig {
+	return &LocationConfig{
+		FlushInterval:  getDuration("LOCATION_FLUSH_INTERVAL", time.Second*2),
+		BatchSize:      getPositiveInt("LOCATION_BATCH_SIZE", 500),
+		TrailRetention: getDuration("LOCATION_TRAIL_RETENTION", time.Hour*24),
+		TrimInterval:   getDuration("LOCATION_TRIM_INTERVAL", time.Minute*10),
 	}
 }
 
+func getExpiredPolicy() string {
+	value := strings.ToLower(strings.TrimSpace(os.Getenv("DELIVERY_EXPIRED_POLICY")))
+	switch value {
+	case "flag", "reassign", "escalate":
+		return value
+	}
+	return "flag"
+}
+
+func getDispatchStrategy() string {
+	value := strings.ToLower(strings.TrimSpace(os.Getenv("DELIVERY_DISPATCH_STRATEGY")))
+	switch value {
+	case "least-recent-assignments", "least-lifetime-assignments", "least-assignments-today", "round-robin",
+		"longest-idle", "random-weighted", "nearest":
+		return value
+	}
+	return "least-recent-assignments"
+}
+
+func getFairnessWindow() string {
+	value := strings.ToLower(strings.TrimSpace(os.Getenv("DELIVERY_FAIRNESS_WINDOW")))
+	switch value {
+	case "shift", "rolling", "decay":
+		return value
+	}
+	return "rolling"
+}
+
+func getZoneFallback() string {
+	value := strings.ToLower(strings.TrimSpace(os.Getenv("DELIVERY_ZONE_FALLBACK")))
+	switch value {
+	case "none", "neighbours", "any":
+		return value
+	}
+	return "none"
+}
+
+func getDeadlinePolicy() string {
+	value := strings.ToLower(strings.TrimSpace(os.Getenv("DELIVERY_DEADLINE_POLICY")))
+	switch value {
+	case "fixed", "distance":
+		return value
+	}
+	return "fixed"
+}
+
+func getAssignMode() string {
+	value := strings.ToLower(strings.TrimSpace(os.Getenv("DELIVERY_ASSIGN_MODE")))
+	switch value {
+	case "greedy", "batch":
+		return value
+	}
+	return "greedy"
+}
+
 func getPprofConfig() *PprofConfig {
 	enabled := strings.TrimSpace(os.Getenv("PPROF_ENABLED"))
 	pprofEnabled := false
@@ -193,6 +340,30 @@ func getDuration(envName string, fallback time.Duration) time.Duration {
 	return parsed
 }
 
+func getPositiveInt(envName string, fallback int) int {
+	raw := strings.TrimSpace(os.Getenv(envName))
+	if raw == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(raw)
+	if err != nil || parsed <= 0 {
+		return fallback
+	}
+	return parsed
+}
+
+func getPositiveFloat(envName string, fallback float64) float64 {
+	raw := strings.TrimSpace(os.Getenv(envName))
+	if raw == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseFloat(raw, 64)
+	if err != nil || parsed <= 0 {
+		return fallback
+	}
+	return parsed
+}
+
 func splitCSV(value string) []string {
 	if value == "" {
 		return nil